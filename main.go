@@ -142,7 +142,7 @@ func setupRouter(appContext *di.ApplicationContext, logger *logger.Logger) *gin.
 	router.Use(gin.Recovery())
 	router.Use(middlewares.CorsHeader())
 	// Add middlewares
-	router.Use(middlewares.ErrorHandler())
+	router.Use(middlewares.LocalizedErrorHandler(appContext.TranslationModule.UseCase))
 	router.Use(middlewares.GinBodyLogMiddleware(appContext.DB, appContext.Logger))
 	router.Use(middlewares.SecurityHeaders())
 	router.Use(appContext.Limiter.RateLimitMiddleware())
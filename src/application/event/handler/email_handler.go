@@ -26,6 +26,8 @@ func (h *EmailEventHandler) Handle(event model.ApplicationEvent) error {
 	switch event.EventType() {
 	case model.ForgetPasswordEventType:
 		return h.handleForgetPassword(event)
+	case model.UserRegisteredEventType, model.EmailVerificationEventType:
+		return h.handleEmailVerification(event)
 	default:
 		return nil
 	}
@@ -59,6 +61,27 @@ func (h *EmailEventHandler) handleForgetPassword(event model.ApplicationEvent) e
 	return res
 }
 
+// handleEmailVerification 注册或重新发送时发送邮箱验证链接，链接为空表示无需验证
+func (h *EmailEventHandler) handleEmailVerification(event model.ApplicationEvent) error {
+	payload, ok := event.Payload().(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid payload format for email verification event")
+	}
+
+	link, _ := getStringValue(payload, "verificationLink")
+	if link == "" {
+		return nil
+	}
+	to, ok := getStringValue(payload, "email")
+	if !ok || to == "" {
+		return fmt.Errorf("missing 'email' field in payload")
+	}
+
+	log.Printf("Sending verification email to %s", to)
+	return h.emailService.SendEmail(to, "Verify your email address",
+		"Please click the link to verify your email address: "+link)
+}
+
 // getStringValue 安全地从map中获取字符串值
 func getStringValue(data map[string]interface{}, key string) (string, bool) {
 	value, exists := data[key]
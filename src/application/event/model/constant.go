@@ -4,4 +4,6 @@ const (
 	UserRegisteredEventType = "UserRegistered"
 	OrderCreatedEventType   = "OrderCreated"
 	ForgetPasswordEventType = "ForgetPassword"
+	// EmailVerificationEventType 重新发送注册验证邮件
+	EmailVerificationEventType = "EmailVerification"
 )
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+// EmailVerificationEvent 重新发送邮箱验证链接事件
+type EmailVerificationEvent struct {
+	ID               string
+	UserID           string
+	Email            string
+	VerificationLink string
+	RegisteredAt     time.Time
+}
+
+// EventID 事件ID
+func (e *EmailVerificationEvent) EventID() string {
+	return e.ID
+}
+
+// EventType 事件类型
+func (e *EmailVerificationEvent) EventType() string {
+	return EmailVerificationEventType
+}
+
+// Timestamp 事件时间戳
+func (e *EmailVerificationEvent) Timestamp() time.Time {
+	return e.RegisteredAt
+}
+
+// Payload 事件载荷
+func (e *EmailVerificationEvent) Payload() interface{} {
+	return map[string]interface{}{
+		"userID":           e.UserID,
+		"email":            e.Email,
+		"verificationLink": e.VerificationLink,
+	}
+}
@@ -6,11 +6,13 @@ import (
 
 // UserRegisteredEvent 用户注册事件
 type UserRegisteredEvent struct {
-	ID           string
-	UserID       string
-	Username     string
-	Email        string
-	RegisteredAt time.Time
+	ID       string
+	UserID   string
+	Username string
+	Email    string
+	// VerificationLink 需要邮箱验证时的签名链接，为空表示无需验证
+	VerificationLink string
+	RegisteredAt     time.Time
 }
 
 // EventID 事件ID
@@ -31,9 +33,10 @@ func (e *UserRegisteredEvent) Timestamp() time.Time {
 // Payload 事件载荷
 func (e *UserRegisteredEvent) Payload() interface{} {
 	return map[string]interface{}{
-		"userID":       e.UserID,
-		"username":     e.Username,
-		"email":        e.Email,
-		"registeredAt": e.RegisteredAt,
+		"userID":           e.UserID,
+		"username":         e.Username,
+		"email":            e.Email,
+		"verificationLink": e.VerificationLink,
+		"registeredAt":     e.RegisteredAt,
 	}
 }
@@ -6,16 +6,24 @@ import (
 	"os"
 	"time"
 
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	invitationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/invitation"
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	jwtBlacklistDomain "github.com/gbrayhan/microservices-go/src/domain/jwt_blacklist"
 	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
 	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
 	captchaLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/captcha"
+	ldapLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/ldap"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	oidcLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/oidc"
+	passwordLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/password"
 	ws "github.com/gbrayhan/microservices-go/src/infrastructure/lib/websocket"
+	passwordHistoryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/password_history"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user_identity"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
 	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
 	"github.com/gin-gonic/gin"
@@ -30,38 +38,90 @@ type IAuthUseCase interface {
 	Register(user RegisterUser) (*domain.CommonResponse[SecurityRegisterUser], error)
 	AccessTokenByRefreshToken(refreshToken string) (*domainUser.User, *AuthTokens, error)
 	SwitchRole(userId int, roleId int64) (*domainUser.User, *AuthTokens, *domainRole.Role, error)
+	OIDCAuthURL() (*OIDCAuthorization, error)
+	OIDCLogin(code, state, binding string, ctx *gin.Context) (*domainUser.User, *AuthTokens, *domainRole.Role, error)
+	OIDCLinkURL(userId int64) (*OIDCAuthorization, error)
+	OIDCLink(code, state, binding string, userId int64) error
+	SyncLDAPUsers() (int, error)
+	LDAPLink(userId int64, username, password string) error
+	VerifyEmail(token string) (*domainUser.User, error)
+	ResendVerificationEmail(email string) error
+	CleanUnverifiedUsers() (int64, error)
+	Impersonate(adminId int64, adminRoleIds []int64, targetUserId int64) (*domainUser.User, *AuthTokens, *domainRole.Role, error)
+	ExitImpersonation(jwtToken string) (*domainUser.User, *AuthTokens, *domainRole.Role, error)
 }
 
 type AuthUseCase struct {
-	UserRepository         user.UserRepositoryInterface
-	RoleRepository         role.ISysRolesRepository
-	JWTService             security.IJWTService
-	Logger                 *logger.Logger
-	jwtBlacklistRepository jwtBlacklistDomain.IJwtBlacklistService
-	RedisClient            *redis.Client
-	sessionManager         *ws.SessionManager
-	captchaHandler         *captchaLib.Captcha
+	UserRepository            user.UserRepositoryInterface
+	RoleRepository            role.ISysRolesRepository
+	UserRoleRepository        user_role.ISysUserRoleRepository
+	JWTService                security.IJWTService
+	Logger                    *logger.Logger
+	jwtBlacklistRepository    jwtBlacklistDomain.IJwtBlacklistService
+	RedisClient               *redis.Client
+	sessionManager            *ws.SessionManager
+	captchaHandler            *captchaLib.Captcha
+	oidcProvider              *oidcLib.Provider
+	provisioner               *externalUserProvisioner
+	ldapAuthenticator         *LDAPAuthenticator
+	authenticators            []Authenticator
+	passwordPolicy            *passwordLib.Policy
+	passwordHistoryRepository passwordHistoryRepo.IPasswordHistoryRepository
+	eventBus                  bus.EventBus
+	invitationService         invitationUseCase.ISysInvitationService
 }
 
 func NewAuthUseCase(
 	userRepository user.UserRepositoryInterface,
 	RoleRepository role.ISysRolesRepository,
+	userRoleRepository user_role.ISysUserRoleRepository,
+	identityRepository user_identity.IUserIdentityRepository,
 	jwtService security.IJWTService,
 	loggerInstance *logger.Logger,
 	jwtBlacklistRepository jwtBlacklistDomain.IJwtBlacklistService,
 	RedisClient *redis.Client,
 	sessionManager *ws.SessionManager,
 	captchaHandler *captchaLib.Captcha,
+	oidcProvider *oidcLib.Provider,
+	ldapClient *ldapLib.Client,
+	passwordPolicy *passwordLib.Policy,
+	passwordHistoryRepository passwordHistoryRepo.IPasswordHistoryRepository,
+	eventBus bus.EventBus,
+	invitationService invitationUseCase.ISysInvitationService,
 ) IAuthUseCase {
+	provisioner := &externalUserProvisioner{
+		userRepository:     userRepository,
+		roleRepository:     RoleRepository,
+		userRoleRepository: userRoleRepository,
+		identityRepository: identityRepository,
+		logger:             loggerInstance,
+	}
+	// 本地用户优先，其次LDAP
+	authenticators := []Authenticator{NewLocalAuthenticator(userRepository)}
+	var ldapAuthenticator *LDAPAuthenticator
+	if ldapClient != nil {
+		ldapAuthenticator = &LDAPAuthenticator{client: ldapClient, provisioner: provisioner}
+		authenticators = append(authenticators, ldapAuthenticator)
+	}
+
 	return &AuthUseCase{
-		UserRepository:         userRepository,
-		RoleRepository:         RoleRepository,
-		JWTService:             jwtService,
-		Logger:                 loggerInstance,
-		jwtBlacklistRepository: jwtBlacklistRepository,
-		RedisClient:            RedisClient,
-		sessionManager:         sessionManager,
-		captchaHandler:         captchaHandler,
+		UserRepository:            userRepository,
+		RoleRepository:            RoleRepository,
+		UserRoleRepository:        userRoleRepository,
+		JWTService:                jwtService,
+		Logger:                    loggerInstance,
+		jwtBlacklistRepository:    jwtBlacklistRepository,
+		RedisClient:               RedisClient,
+		sessionManager:            sessionManager,
+		captchaHandler:            captchaHandler,
+		oidcProvider:              oidcProvider,
+		provisioner:               provisioner,
+		ldapAuthenticator:         ldapAuthenticator,
+		authenticators:            authenticators,
+		passwordPolicy:            passwordPolicy,
+		passwordHistoryRepository: passwordHistoryRepository,
+		eventBus:                  eventBus,
+		invitationService:         invitationService,
 	}
 }
 
@@ -70,44 +130,25 @@ type AuthTokens struct {
 	RefreshToken              string
 	ExpirationAccessDateTime  time.Time
 	ExpirationRefreshDateTime time.Time
+	// 需要修改密码时只返回重置令牌，用于调用 /v1/user/change-password
+	PasswordChangeRequired  bool
+	PasswordResetToken      string
+	ExpirationResetDateTime time.Time
+	ImpersonatorID          int64
+	// RoleIDs 合并模式下令牌携带的全部角色
+	RoleIDs []int64
 }
 
-func (s *AuthUseCase) SwitchRole(userId int, roleId int64) (*domainUser.User, *AuthTokens, *domainRole.Role, error) {
-	s.Logger.Info("User switch attempt", zap.Int("userId", userId))
-	user, err := s.UserRepository.GetByID(int(userId))
-	if err != nil {
-		s.Logger.Error("Error getting user for switch", zap.Error(err), zap.Int("userId", userId))
-		return nil, nil, nil, err
-	}
-	if user.ID == 0 {
-		s.Logger.Warn("Login failed: user not found", zap.Int("userId", userId))
-		return nil, nil, nil, domainErrors.NewAppError(errors.New("user don't no found"), domainErrors.NotAuthorized)
+// passwordChangeRequired 管理员重置后的一次性密码或超过最长有效期的密码
+func (s *AuthUseCase) passwordChangeRequired(user *domainUser.User) bool {
+	if user.MustChangePassword {
+		return true
 	}
-	role, err := s.RoleRepository.GetByID(int(roleId))
-	if err != nil {
-		s.Logger.Error("Error getting role for switch", zap.Error(err), zap.Int("roleId", int(roleId)))
-		return nil, nil, nil, err
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
 	}
-	accessTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "access")
-	if err != nil {
-		s.Logger.Error("Error generating access token", zap.Error(err), zap.Int64("userID", user.ID))
-		return nil, nil, nil, err
-	}
-	refreshTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "refresh")
-	if err != nil {
-		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", user.ID))
-		return nil, nil, nil, err
-	}
-
-	authTokens := &AuthTokens{
-		AccessToken:               accessTokenClaims.Token,
-		RefreshToken:              refreshTokenClaims.Token,
-		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
-		ExpirationRefreshDateTime: refreshTokenClaims.ExpirationTime,
-	}
-
-	s.Logger.Info("User login successful", zap.Int("userId", userId))
-	return user, authTokens, role, nil
+	return s.passwordPolicy.IsExpired(changedAt, time.Now())
 }
 
 func (s *AuthUseCase) Login(username, password, captchaId, CaptchaAnswer string, ginCtx *gin.Context) (*domainUser.User, *AuthTokens, *domainRole.Role, error) {
@@ -115,58 +156,54 @@ func (s *AuthUseCase) Login(username, password, captchaId, CaptchaAnswer string,
 	if !isValid {
 		return nil, nil, nil, domainErrors.NewAppError(errors.New("invalid captcha"), domainErrors.CaptchaError)
 	}
-	user, err := s.UserRepository.GetByUsername(username)
+	s.Logger.Info("User login attempt", zap.String("username", username))
+	user, authenticatorName, err := s.authenticate(username, password)
 	if err != nil {
-		s.Logger.Error("Error getting user for login", zap.Error(err), zap.String("username", username))
 		return nil, nil, nil, err
 	}
-	if user.ID == 0 {
-		s.Logger.Warn("Login failed: user not found", zap.String("username", username))
-		return nil, nil, nil, domainErrors.NewAppError(errors.New("username or password does not match"), domainErrors.NotAuthorized)
+
+	// 一次性密码或已过期的本地密码只签发重置令牌，修改密码后才能正常登录
+	if authenticatorName == localAuthenticatorName && s.passwordChangeRequired(user) {
+		resetTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, 0, "reset")
+		if err != nil {
+			s.Logger.Error("Error generating reset token", zap.Error(err), zap.Int64("userID", user.ID))
+			return nil, nil, nil, err
+		}
+		s.Logger.Info("User login requires password change", zap.String("username", username), zap.Int64("userID", user.ID))
+		return user, &AuthTokens{
+			PasswordChangeRequired:  true,
+			PasswordResetToken:      resetTokenClaims.Token,
+			ExpirationResetDateTime: resetTokenClaims.ExpirationTime,
+		}, nil, nil
 	}
-	s.Logger.Info("User login attempt", zap.String("username", username))
 
 	// Single Sign On offline user
 	if os.Getenv("SERVER_SINGLE_SIGN_ON") == "true" {
 		s.sessionManager.NotifyOtherDevicesOffline(user.ID, ginCtx.Query("deviceId"))
 	}
 
-	isAuthenticated := sharedUtil.CheckPasswordHash(password, user.HashPassword)
-	if !isAuthenticated {
-		s.Logger.Warn("Login failed: invalid password", zap.String("username", username))
-		return nil, nil, nil, domainErrors.NewAppError(errors.New("username or password does not match"), domainErrors.NotAuthorized)
-	}
-
-	var role domainRole.Role
-	var roleId int64
-	if len(user.Roles) > 0 {
-		roleId = user.Roles[0].ID
-		role = user.Roles[0]
-	}
-	accessTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "access")
+	authTokens, role, err := s.issueLoginTokens(user)
 	if err != nil {
-		s.Logger.Error("Error generating access token", zap.Error(err), zap.Int64("userID", user.ID))
-		return nil, nil, nil, err
-	}
-	refreshTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "refresh")
-	if err != nil {
-		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, nil, err
 	}
 
-	authTokens := &AuthTokens{
-		AccessToken:               accessTokenClaims.Token,
-		RefreshToken:              refreshTokenClaims.Token,
-		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
-		ExpirationRefreshDateTime: refreshTokenClaims.ExpirationTime,
+	s.Logger.Info("User login successful", zap.String("username", username), zap.Int64("userID", user.ID))
+	return user, authTokens, role, nil
+}
+
+// issueLoginTokens 以用户的第一个角色（合并模式下为全部角色）签发访问令牌和刷新令牌，并记录为当前活跃会话
+func (s *AuthUseCase) issueLoginTokens(user *domainUser.User) (*AuthTokens, *domainRole.Role, error) {
+	role, roleIds := loginRoles(user)
+	authTokens, err := s.generateTokenPair(user.ID, role.ID, roleIds)
+	if err != nil {
+		return nil, nil, err
 	}
 	ctx := context.Background()
-	s.RedisClient.Set(ctx, GetUserTokenKey(user.ID), accessTokenClaims.Token, UserTokenExpireDuration)
-	s.RedisClient.Set(ctx, GetUserRefreshTokenKey(user.ID), refreshTokenClaims.Token, RefreshTokenExpireDuration)
-
-	s.Logger.Info("User login successful", zap.String("username", username), zap.Int64("userID", user.ID))
-	return user, authTokens, &role, nil
+	s.RedisClient.Set(ctx, GetUserTokenKey(user.ID), authTokens.AccessToken, UserTokenExpireDuration)
+	s.RedisClient.Set(ctx, GetUserRefreshTokenKey(user.ID), authTokens.RefreshToken, RefreshTokenExpireDuration)
+	return authTokens, &role, nil
 }
+
 func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUser.User, *AuthTokens, error) {
 	s.Logger.Info("Refreshing access token")
 
@@ -211,8 +248,16 @@ func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUse
 		return nil, nil, err
 	}
 
+	// 合并模式的令牌按用户当前的角色重新计算，角色变更在刷新后生效
+	var roleIds []int64
+	if _, isUnion := claimsMap["role_ids"]; isUnion {
+		var role domainRole.Role
+		role, roleIds = loginRoles(user)
+		roleId = role.ID
+	}
+
 	// 生成新的访问令牌
-	accessTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "access")
+	accessTokenClaims, err := s.JWTService.GenerateJWTTokenWithRoles(user.ID, roleId, roleIds, "access")
 	if err != nil {
 		s.Logger.Error("Error generating new access token", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, err
@@ -225,6 +270,7 @@ func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUse
 		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
 		RefreshToken:              refreshToken,
 		ExpirationRefreshDateTime: time.Unix(expTime, 0),
+		RoleIDs:                   roleIds,
 	}
 	ctx := context.Background()
 	s.RedisClient.Set(ctx, GetUserTokenKey(user.ID), accessTokenClaims.Token, UserTokenExpireDuration)
@@ -246,6 +292,17 @@ func (s *AuthUseCase) Register(user RegisterUser) (*domain.CommonResponse[Securi
 		return nil,
 			domainErrors.NewAppError(errors.New("The user already exists"), domainErrors.UserExists)
 	}
+	verifyEmail := emailVerificationEnabled()
+	if verifyEmail && user.Email == "" {
+		return nil, domainErrors.NewAppError(errors.New("email is required"), domainErrors.ValidationError)
+	}
+	if err := s.passwordPolicy.Validate(user.Password, user.UserName); err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	invitation, err := s.checkRegistration(user)
+	if err != nil {
+		return nil, err
+	}
 	userRepo := domainUser.User{
 		UserName: user.UserName,
 		Email:    user.Email,
@@ -260,12 +317,26 @@ func (s *AuthUseCase) Register(user RegisterUser) (*domain.CommonResponse[Securi
 
 	// generate uuid
 	userRepo.UUID = uuid.New().String()
-	userRepo.Status = 1
+	userRepo.Status = domainUser.UserStatusEnabled
+	if verifyEmail {
+		userRepo.Status = domainUser.UserStatusPending
+	}
 
 	res, err := s.UserRepository.Create(&userRepo)
 	if err != nil {
+		if invitation != nil {
+			_ = s.invitationService.Release(invitation.ID)
+		}
 		return &domain.CommonResponse[SecurityRegisterUser]{}, err
 	}
+	if err := s.passwordHistoryRepository.Add(res.ID, userRepo.HashPassword, s.passwordPolicy.Config().HistoryCount); err != nil {
+		s.Logger.Warn("Error recording password history", zap.Error(err), zap.Int64("userID", res.ID))
+	}
+	s.assignRegisteredRoles(res.ID, invitation)
+	// 验证邮件发送失败时用户仍可通过重新发送完成验证
+	if err := s.publishRegistered(res); err != nil {
+		s.Logger.Error("Error publishing user registered event", zap.Error(err), zap.Int64("userID", res.ID))
+	}
 
 	return &domain.CommonResponse[SecurityRegisterUser]{
 		Data: SecurityRegisterUser{
@@ -313,6 +384,11 @@ func (s *AuthUseCase) Logout(jwtToken string) (*domain.CommonResponse[string], e
 		return nil, domainErrors.NewAppError(err, domainErrors.TokenError)
 	}
 
+	// 模拟登录令牌没有刷新令牌，也不能影响被模拟用户自己的会话
+	if _, impersonating := claimsMap["impersonator_id"]; impersonating {
+		return &domain.CommonResponse[string]{Data: "true", Status: 0, Message: "success"}, nil
+	}
+
 	// 获取并加入刷新令牌到黑名单
 	if refreshToken, err := s.RedisClient.Get(ctx, GetUserRefreshTokenKey(userID)).Result(); err == nil {
 		if refreshToken != "" && refreshToken != jwtToken {
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"errors"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	ldapLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/ldap"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user_identity"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidCredentials 当前认证后端无法认证该用户，交由下一个后端处理
+var ErrInvalidCredentials = errors.New("username or password does not match")
+
+const localAuthenticatorName = "local"
+
+// Authenticator 用户名密码登录的认证后端
+type Authenticator interface {
+	Name() string
+	// Authenticate 认证成功返回带角色的本地用户；用户不存在或密码错误返回 ErrInvalidCredentials
+	Authenticate(username, password string) (*domainUser.User, error)
+}
+
+// LocalAuthenticator 校验 sys_users 中的 bcrypt 密码
+type LocalAuthenticator struct {
+	UserRepository user.UserRepositoryInterface
+}
+
+func NewLocalAuthenticator(userRepository user.UserRepositoryInterface) Authenticator {
+	return &LocalAuthenticator{UserRepository: userRepository}
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return localAuthenticatorName
+}
+
+func (a *LocalAuthenticator) Authenticate(username, password string) (*domainUser.User, error) {
+	localUser, err := a.UserRepository.GetByUsername(username)
+	if err != nil {
+		var appErr *domainErrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == domainErrors.NotFound {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if localUser.ID == 0 || !sharedUtil.CheckPasswordHash(password, localUser.HashPassword) {
+		return nil, ErrInvalidCredentials
+	}
+	if err := checkLoginStatus(localUser); err != nil {
+		return nil, err
+	}
+	return localUser, nil
+}
+
+// checkLoginStatus 所有登录方式共用的用户状态校验
+func checkLoginStatus(localUser *domainUser.User) error {
+	switch localUser.Status {
+	case domainUser.UserStatusPending:
+		return domainErrors.NewAppError(errors.New("email address is not verified"), domainErrors.NotAuthorized)
+	case domainUser.UserStatusDisabled:
+		return domainErrors.NewAppError(errors.New("user is disabled"), domainErrors.NotAuthorized)
+	}
+	return nil
+}
+
+// LDAPAuthenticator 通过LDAP绑定认证，并将目录用户同步为本地用户
+type LDAPAuthenticator struct {
+	client      *ldapLib.Client
+	provisioner *externalUserProvisioner
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return "ldap"
+}
+
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*domainUser.User, error) {
+	entry, err := a.client.Authenticate(username, password)
+	if err != nil {
+		if errors.Is(err, ldapLib.ErrInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	localUser, err := a.syncEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	localUser, err = a.provisioner.userRepository.GetByID(int(localUser.ID))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLoginStatus(localUser); err != nil {
+		return nil, err
+	}
+	return localUser, nil
+}
+
+// syncEntry 创建或更新目录用户对应的本地用户，并按分组同步角色
+func (a *LDAPAuthenticator) syncEntry(entry *ldapLib.Entry) (*domainUser.User, error) {
+	config := a.client.Config()
+	localUser, created, err := a.provisioner.findOrCreate(ldapExternalIdentity(entry), config.AutoProvision)
+	if err != nil {
+		return nil, err
+	}
+	if updates := ldapProfileUpdates(localUser, entry); !created && len(updates) > 0 {
+		localUser, err = a.provisioner.userRepository.Update(localUser.ID, updates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := a.provisioner.syncRoles(localUser.ID, a.client.MapRoles(entry.Groups), config.DefaultRole, created); err != nil {
+		return nil, err
+	}
+	return localUser, nil
+}
+
+// ldapProfileUpdates 目录属性未经验证，只补全本地为空的字段；邮箱用于重置密码，不能被目录覆盖
+func ldapProfileUpdates(localUser *domainUser.User, entry *ldapLib.Entry) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if localUser.Email == "" && entry.Email != "" {
+		updates["email"] = entry.Email
+	}
+	if localUser.NickName == "" && entry.NickName != "" {
+		updates["nick_name"] = entry.NickName
+	}
+	return updates
+}
+
+// ldapExternalIdentity 目录邮箱未经验证，不参与自动绑定
+func ldapExternalIdentity(entry *ldapLib.Entry) externalIdentity {
+	return externalIdentity{
+		Provider: user_identity.ProviderLDAP,
+		Subject:  entry.Identifier(),
+		Username: entry.Username,
+		Email:    entry.Email,
+		NickName: entry.NickName,
+	}
+}
+
+// LDAPLink 以目录账号密码校验后，将目录账号绑定到当前登录用户
+func (s *AuthUseCase) LDAPLink(userId int64, username, password string) error {
+	if s.ldapAuthenticator == nil {
+		return domainErrors.NewAppError(errors.New("ldap login is not enabled"), domainErrors.NotFound)
+	}
+	entry, err := s.ldapAuthenticator.client.Authenticate(username, password)
+	if err != nil {
+		if errors.Is(err, ldapLib.ErrInvalidCredentials) {
+			return domainErrors.NewAppError(ErrInvalidCredentials, domainErrors.NotAuthorized)
+		}
+		s.Logger.Error("Error authenticating ldap user for linking", zap.Error(err), zap.String("username", username))
+		return err
+	}
+	if err := s.provisioner.link(userId, ldapExternalIdentity(entry)); err != nil {
+		return err
+	}
+	s.Logger.Info("LDAP identity linked", zap.Int64("userID", userId), zap.String("dn", entry.DN))
+	return nil
+}
+
+// SyncLDAPUsers 定时同步目录用户，返回成功同步的用户数
+func (s *AuthUseCase) SyncLDAPUsers() (int, error) {
+	if s.ldapAuthenticator == nil {
+		return 0, nil
+	}
+	entries, err := s.ldapAuthenticator.client.SearchUsers()
+	if err != nil {
+		s.Logger.Error("Error searching ldap users", zap.Error(err))
+		return 0, err
+	}
+	synced := 0
+	for i := range entries {
+		if entries[i].Username == "" {
+			continue
+		}
+		if _, err := s.ldapAuthenticator.syncEntry(&entries[i]); err != nil {
+			s.Logger.Warn("Error syncing ldap user", zap.Error(err), zap.String("dn", entries[i].DN))
+			continue
+		}
+		synced++
+	}
+	s.Logger.Info("Synchronized ldap users", zap.Int("synced", synced), zap.Int("entries", len(entries)))
+	return synced, nil
+}
+
+// authenticate 依次尝试各认证后端
+func (s *AuthUseCase) authenticate(username, password string) (*domainUser.User, string, error) {
+	for _, authenticator := range s.authenticators {
+		authenticatedUser, err := authenticator.Authenticate(username, password)
+		if err == nil {
+			s.Logger.Info("User authenticated", zap.String("username", username), zap.String("authenticator", authenticator.Name()))
+			return authenticatedUser, authenticator.Name(), nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			s.Logger.Error("Error authenticating user", zap.Error(err),
+				zap.String("username", username), zap.String("authenticator", authenticator.Name()))
+			return nil, "", err
+		}
+	}
+	s.Logger.Warn("Login failed: invalid credentials", zap.String("username", username))
+	return nil, "", domainErrors.NewAppError(ErrInvalidCredentials, domainErrors.NotAuthorized)
+}
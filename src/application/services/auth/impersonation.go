@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
+	"go.uber.org/zap"
+)
+
+// errImpersonateBroaderUser 非超级管理员只能模拟角色都在自己角色范围内的用户
+var errImpersonateBroaderUser = errors.New("cannot impersonate a user with roles you do not have")
+
+// Impersonate 管理员以目标用户身份登录，只签发短期访问令牌，令牌中同时记录真实管理员ID。
+// adminRoleIds 为管理员当前的角色，非超级管理员只能模拟角色被其完全覆盖的用户
+func (s *AuthUseCase) Impersonate(adminId int64, adminRoleIds []int64, targetUserId int64) (*domainUser.User, *AuthTokens, *domainRole.Role, error) {
+	if adminId == targetUserId {
+		return nil, nil, nil, domainErrors.NewAppError(errors.New("cannot impersonate yourself"), domainErrors.ValidationError)
+	}
+	isSuper, err := s.RoleRepository.IsSuperUser(targetUserId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if isSuper {
+		return nil, nil, nil, domainErrors.NewAppError(errors.New("super administrator cannot be impersonated"), domainErrors.NotAuthorized)
+	}
+	user, err := s.UserRepository.GetByID(int(targetUserId))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := s.ensureRolesCovered(adminRoleIds, user); err != nil {
+		return nil, nil, nil, err
+	}
+
+	role, roleIds := loginRoles(user)
+	token, err := s.JWTService.GenerateImpersonationToken(user.ID, role.ID, roleIds, adminId)
+	if err != nil {
+		s.Logger.Error("Error generating impersonation token", zap.Error(err), zap.Int64("userID", user.ID))
+		return nil, nil, nil, err
+	}
+
+	s.Logger.Warn("Impersonation started", zap.Int64("impersonatorID", adminId), zap.Int64("userID", user.ID))
+	return user, &AuthTokens{
+		AccessToken:              token.Token,
+		ExpirationAccessDateTime: token.ExpirationTime,
+		ImpersonatorID:           adminId,
+		RoleIDs:                  roleIds,
+	}, &role, nil
+}
+
+// ensureRolesCovered 超级管理员可以模拟任意非超级管理员用户，其他管理员要求目标用户的角色都在自己的角色中
+func (s *AuthUseCase) ensureRolesCovered(adminRoleIds []int64, target *domainUser.User) error {
+	isSuper, err := s.RoleRepository.IsSuperRole(adminRoleIds...)
+	if err != nil || isSuper {
+		return err
+	}
+	for _, role := range target.Roles {
+		if !slices.Contains(adminRoleIds, role.ID) {
+			s.Logger.Warn("Refusing impersonation of user with broader roles",
+				zap.Int64s("adminRoleIds", adminRoleIds), zap.Int64("userID", target.ID), zap.Int64("roleID", role.ID))
+			return domainErrors.NewAppError(errImpersonateBroaderUser, domainErrors.NotAuthorized)
+		}
+	}
+	return nil
+}
+
+// ExitImpersonation 作废模拟登录令牌，并为真实管理员重新签发登录令牌
+func (s *AuthUseCase) ExitImpersonation(jwtToken string) (*domainUser.User, *AuthTokens, *domainRole.Role, error) {
+	claimsMap, err := s.JWTService.GetClaimsAndVerifyToken(jwtToken, security.Access)
+	if err != nil {
+		return nil, nil, nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+	}
+	impersonatorID, ok := claimsMap["impersonator_id"].(float64)
+	if !ok || impersonatorID == 0 {
+		return nil, nil, nil, domainErrors.NewAppError(errors.New("token is not an impersonation token"), domainErrors.ValidationError)
+	}
+	if err := s.jwtBlacklistRepository.AddToBlacklist(jwtToken); err != nil {
+		return nil, nil, nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+	}
+
+	admin, err := s.UserRepository.GetByID(int(impersonatorID))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	authTokens, role, err := s.issueLoginTokens(admin)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s.Logger.Warn("Impersonation ended", zap.Int64("impersonatorID", admin.ID), zap.Int64("userID", int64(claimsMap["id"].(float64))))
+	return admin, authTokens, role, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeRoleRepository 只实现模拟登录用到的超级管理员判断，角色1为超级管理员角色
+type fakeRoleRepository struct {
+	role.ISysRolesRepository
+	superUsers map[int64]bool
+}
+
+func (f *fakeRoleRepository) IsSuperRole(roleIds ...int64) (bool, error) {
+	return slices.Contains(roleIds, 1), nil
+}
+
+func (f *fakeRoleRepository) IsSuperUser(userId int64) (bool, error) {
+	return f.superUsers[userId], nil
+}
+
+func newImpersonationUseCase() *AuthUseCase {
+	return &AuthUseCase{
+		UserRepository: &fakeUserRepository{users: []domainUser.User{
+			{ID: 1, UserName: "root", Roles: []domainRole.Role{{ID: 1}}},
+			{ID: 2, UserName: "alice", Roles: []domainRole.Role{{ID: 2}}},
+			{ID: 4, UserName: "bob", Roles: []domainRole.Role{{ID: 2}, {ID: 3}}},
+		}},
+		RoleRepository: &fakeRoleRepository{superUsers: map[int64]bool{1: true}},
+		JWTService: security.NewJWTServiceWithConfig(security.JWTConfig{
+			AccessSecret:    "access",
+			AccessTime:      60,
+			ImpersonateTime: 15,
+		}),
+		Logger: &logger.Logger{Log: zap.NewNop()},
+	}
+}
+
+func errorType(err error) domainErrors.ErrorType {
+	var appErr *domainErrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Type
+	}
+	return ""
+}
+
+func TestImpersonate_RejectsSuperUser(t *testing.T) {
+	s := newImpersonationUseCase()
+
+	_, tokens, _, err := s.Impersonate(3, []int64{2}, 1)
+
+	assert.Equal(t, domainErrors.NotAuthorized, errorType(err))
+	assert.Nil(t, tokens)
+}
+
+func TestImpersonate_RejectsSelf(t *testing.T) {
+	s := newImpersonationUseCase()
+
+	_, _, _, err := s.Impersonate(2, []int64{2}, 2)
+
+	assert.Equal(t, domainErrors.ValidationError, errorType(err))
+}
+
+func TestImpersonate_IssuesTokenWithImpersonator(t *testing.T) {
+	s := newImpersonationUseCase()
+
+	user, tokens, _, err := s.Impersonate(1, []int64{1}, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, "alice", user.UserName)
+	assert.Equal(t, int64(1), tokens.ImpersonatorID)
+	assert.Empty(t, tokens.RefreshToken)
+	claims, err := s.JWTService.GetClaimsAndVerifyToken(tokens.AccessToken, security.Access)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), claims["impersonator_id"])
+	assert.Equal(t, float64(2), claims["id"])
+}
+
+func TestImpersonate_RequiresCoveredRoles(t *testing.T) {
+	s := newImpersonationUseCase()
+
+	// bob 的角色3不在管理员的角色中
+	_, tokens, _, err := s.Impersonate(5, []int64{2}, 4)
+	assert.Equal(t, domainErrors.NotAuthorized, errorType(err))
+	assert.Nil(t, tokens)
+
+	user, _, _, err := s.Impersonate(5, []int64{2, 3}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.UserName)
+
+	_, _, _, err = s.Impersonate(5, []int64{3}, 2)
+	assert.Equal(t, domainErrors.NotAuthorized, errorType(err))
+
+	// 超级管理员可以模拟任意非超级管理员用户
+	_, _, _, err = s.Impersonate(1, []int64{1}, 4)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	oidcLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/oidc"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user_identity"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// OIDCAuthorization 前端跳转IdP所需的授权地址和state，Binding 由控制器写入 HttpOnly cookie，不出现在响应体中
+type OIDCAuthorization struct {
+	AuthURL string `json:"auth_url"`
+	State   string `json:"state"`
+	Binding string `json:"-"`
+}
+
+// oidcSession 授权请求期间保存在redis中的PKCE verifier和nonce，LinkUserID 非零时为绑定请求。
+// BindingHash 是发起授权的浏览器 cookie 的哈希，回调必须携带同一个 cookie，防止登录CSRF
+type oidcSession struct {
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	LinkUserID   int64  `json:"link_user_id,omitempty"`
+	BindingHash  string `json:"binding_hash"`
+}
+
+var errOIDCDisabled = errors.New("oidc login is not enabled")
+
+// OIDCAuthURL 生成登录授权地址
+func (s *AuthUseCase) OIDCAuthURL() (*OIDCAuthorization, error) {
+	return s.oidcAuthorize(0)
+}
+
+// OIDCLinkURL 生成已登录用户绑定IdP账号的授权地址
+func (s *AuthUseCase) OIDCLinkURL(userId int64) (*OIDCAuthorization, error) {
+	return s.oidcAuthorize(userId)
+}
+
+// oidcAuthorize 生成授权地址，state对应的会话信息在redis中保存10分钟
+func (s *AuthUseCase) oidcAuthorize(linkUserId int64) (*OIDCAuthorization, error) {
+	if s.oidcProvider == nil {
+		return nil, domainErrors.NewAppError(errOIDCDisabled, domainErrors.NotFound)
+	}
+	ctx := context.Background()
+	state := uuid.New().String()
+	binding := oidcLib.NewCodeVerifier()
+	session := oidcSession{
+		CodeVerifier: oidcLib.NewCodeVerifier(),
+		Nonce:        uuid.New().String(),
+		LinkUserID:   linkUserId,
+		BindingHash:  hashOIDCBinding(binding),
+	}
+
+	authURL, err := s.oidcProvider.AuthCodeURL(ctx, state, session.Nonce, session.CodeVerifier)
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	if err := s.RedisClient.Set(ctx, GetOIDCStateKey(state), payload, OIDCStateExpireDuration).Err(); err != nil {
+		s.Logger.Error("Error saving oidc state", zap.Error(err))
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	return &OIDCAuthorization{AuthURL: authURL, State: state, Binding: binding}, nil
+}
+
+// OIDCLogin 使用回调中的code和state完成登录，首次登录时自动创建用户并按分组映射角色
+func (s *AuthUseCase) OIDCLogin(code, state, binding string, ginCtx *gin.Context) (*domainUser.User, *AuthTokens, *domainRole.Role, error) {
+	session, identity, err := s.oidcExchange(code, state, binding)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if session.LinkUserID != 0 {
+		return nil, nil, nil, domainErrors.NewAppError(errors.New("state was issued for account linking"), domainErrors.NotAuthorized)
+	}
+
+	config := s.oidcProvider.Config()
+	user, created, err := s.provisioner.findOrCreate(oidcExternalIdentity(identity), config.AutoProvision)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := checkLoginStatus(user); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := s.provisioner.syncRoles(user.ID, s.oidcProvider.MapRoles(identity.Groups), config.DefaultRole, created); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 重新加载以获取同步后的角色
+	userId := user.ID
+	user, err = s.UserRepository.GetByID(int(userId))
+	if err != nil {
+		s.Logger.Error("Error reloading oidc user", zap.Error(err), zap.Int64("userID", userId))
+		return nil, nil, nil, err
+	}
+
+	if sharedUtil.GetEnv("SERVER_SINGLE_SIGN_ON", "false") == "true" {
+		s.sessionManager.NotifyOtherDevicesOffline(user.ID, ginCtx.Query("deviceId"))
+	}
+
+	authTokens, role, err := s.issueLoginTokens(user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s.Logger.Info("OIDC login successful", zap.String("username", user.UserName), zap.String("subject", identity.Subject))
+	return user, authTokens, role, nil
+}
+
+// OIDCLink 将回调中的IdP账号绑定到当前登录用户，state 必须由该用户的 OIDCLinkURL 生成
+func (s *AuthUseCase) OIDCLink(code, state, binding string, userId int64) error {
+	session, identity, err := s.oidcExchange(code, state, binding)
+	if err != nil {
+		return err
+	}
+	if session.LinkUserID != userId {
+		s.Logger.Warn("OIDC link failed: state belongs to another request", zap.Int64("userID", userId))
+		return domainErrors.NewAppError(errors.New("invalid or expired state"), domainErrors.NotAuthorized)
+	}
+	if err := s.provisioner.link(userId, oidcExternalIdentity(identity)); err != nil {
+		return err
+	}
+	s.Logger.Info("OIDC identity linked", zap.Int64("userID", userId), zap.String("subject", identity.Subject))
+	return nil
+}
+
+// oidcExchange 取出一次性的state会话，校验浏览器绑定后用授权码换取并校验IdP身份
+func (s *AuthUseCase) oidcExchange(code, state, binding string) (*oidcSession, *oidcLib.Identity, error) {
+	if s.oidcProvider == nil {
+		return nil, nil, domainErrors.NewAppError(errOIDCDisabled, domainErrors.NotFound)
+	}
+	ctx := context.Background()
+
+	// state 只能使用一次
+	payload, err := s.RedisClient.GetDel(ctx, GetOIDCStateKey(state)).Result()
+	if err != nil {
+		s.Logger.Warn("OIDC login failed: unknown or expired state", zap.Error(err))
+		return nil, nil, domainErrors.NewAppError(errors.New("invalid or expired state"), domainErrors.NotAuthorized)
+	}
+	var session oidcSession
+	if err := json.Unmarshal([]byte(payload), &session); err != nil {
+		return nil, nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(hashOIDCBinding(binding)), []byte(session.BindingHash)) != 1 {
+		s.Logger.Warn("OIDC login failed: state was issued to another browser")
+		return nil, nil, domainErrors.NewAppError(errors.New("invalid or expired state"), domainErrors.NotAuthorized)
+	}
+
+	identity, err := s.oidcProvider.Exchange(ctx, code, session.CodeVerifier, session.Nonce)
+	if err != nil {
+		s.Logger.Warn("OIDC login failed: code exchange", zap.Error(err))
+		return nil, nil, domainErrors.NewAppError(err, domainErrors.NotAuthorized)
+	}
+	return &session, identity, nil
+}
+
+func hashOIDCBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+func oidcExternalIdentity(identity *oidcLib.Identity) externalIdentity {
+	return externalIdentity{
+		Provider:      user_identity.ProviderOIDC,
+		Issuer:        identity.Issuer,
+		Subject:       identity.Subject,
+		Username:      identity.Username,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		NickName:      identity.NickName,
+	}
+}
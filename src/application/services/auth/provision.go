@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user_identity"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// externalIdentity 外部身份源（OIDC、LDAP）中的用户，Provider + Issuer + Subject 唯一标识外部账号
+type externalIdentity struct {
+	Provider string
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	// EmailVerified 身份源确认邮箱属于该账号，只有此时才按邮箱自动绑定本地用户
+	EmailVerified bool
+	NickName      string
+}
+
+// externalUserProvisioner 将外部身份映射到本地 sys_users 和 sys_roles
+type externalUserProvisioner struct {
+	userRepository     user.UserRepositoryInterface
+	roleRepository     role.ISysRolesRepository
+	userRoleRepository user_role.ISysUserRoleRepository
+	identityRepository user_identity.IUserIdentityRepository
+	logger             *logger.Logger
+}
+
+// errLinkRequired 同名或同邮箱的本地用户已存在，需要该用户登录后手动绑定
+var errLinkRequired = errors.New("a local account with this username or email already exists, sign in and link the identity first")
+
+// findOrCreate 按绑定关系查找本地用户；未绑定时只按已验证的邮箱自动绑定，
+// 不按用户名匹配。未匹配且允许自动创建时新建用户并绑定
+func (p *externalUserProvisioner) findOrCreate(identity externalIdentity, autoProvision bool) (*domainUser.User, bool, error) {
+	userId, err := p.identityRepository.GetUserID(identity.Provider, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, false, err
+	}
+	if userId != 0 {
+		localUser, err := p.userRepository.GetByID(int(userId))
+		if err != nil {
+			return nil, false, err
+		}
+		return localUser, false, nil
+	}
+
+	// GetOneByMap 忽略零值条件，空邮箱会匹配任意用户
+	if identity.Email != "" && identity.EmailVerified {
+		localUser, err := p.userRepository.GetOneByMap(map[string]interface{}{"email": identity.Email})
+		if err != nil {
+			return nil, false, err
+		}
+		if localUser.ID != 0 {
+			if err := p.link(localUser.ID, identity); err != nil {
+				return nil, false, err
+			}
+			p.logger.Info("Linked external identity by verified email", zap.String("provider", identity.Provider),
+				zap.String("subject", identity.Subject), zap.Int64("userID", localUser.ID))
+			return localUser, false, nil
+		}
+	}
+
+	if err := p.ensureNoLocalAccount(identity); err != nil {
+		return nil, false, err
+	}
+	if !autoProvision {
+		p.logger.Warn("External user is not provisioned", zap.String("username", identity.Username))
+		return nil, false, domainErrors.NewAppError(errors.New("user is not provisioned"), domainErrors.NotAuthorized)
+	}
+
+	// 本地密码不可用，仅允许通过外部身份源登录
+	hash, err := sharedUtil.StringToHash(uuid.New().String())
+	if err != nil {
+		return nil, false, err
+	}
+	localUser, err := p.userRepository.Create(&domainUser.User{
+		UUID:         uuid.New().String(),
+		UserName:     identity.Username,
+		NickName:     identity.NickName,
+		Email:        identity.Email,
+		HashPassword: string(hash),
+		Status:       domainUser.UserStatusEnabled,
+	})
+	if err != nil {
+		p.logger.Error("Error provisioning external user", zap.Error(err), zap.String("username", identity.Username))
+		return nil, false, err
+	}
+	if err := p.link(localUser.ID, identity); err != nil {
+		return nil, false, err
+	}
+	p.logger.Info("Provisioned external user", zap.String("username", localUser.UserName), zap.Int64("userID", localUser.ID))
+	return localUser, true, nil
+}
+
+// ensureNoLocalAccount 未绑定的外部身份不能接管同名或同邮箱的本地用户
+func (p *externalUserProvisioner) ensureNoLocalAccount(identity externalIdentity) error {
+	conditions := []map[string]interface{}{{"user_name": identity.Username}}
+	if identity.Email != "" {
+		conditions = append(conditions, map[string]interface{}{"email": identity.Email})
+	}
+	for _, condition := range conditions {
+		localUser, err := p.userRepository.GetOneByMap(condition)
+		if err != nil {
+			return err
+		}
+		if localUser.ID != 0 {
+			p.logger.Warn("External identity collides with an unlinked local user", zap.String("provider", identity.Provider),
+				zap.String("subject", identity.Subject), zap.Int64("userID", localUser.ID))
+			return domainErrors.NewAppError(errLinkRequired, domainErrors.NotAuthorized)
+		}
+	}
+	return nil
+}
+
+// link 绑定外部身份到本地用户，用于首次登录和已登录用户手动绑定
+func (p *externalUserProvisioner) link(userId int64, identity externalIdentity) error {
+	return p.identityRepository.Link(userId, identity.Provider, identity.Issuer, identity.Subject)
+}
+
+// syncRoles 有映射角色时覆盖用户角色；无映射的新用户授予默认角色。
+// 已有的超级管理员角色只能由超级管理员在本地撤销，目录分组变化不会移除
+func (p *externalUserProvisioner) syncRoles(userId int64, roleNames []string, defaultRole string, created bool) error {
+	if len(roleNames) == 0 {
+		if !created || defaultRole == "" {
+			return nil
+		}
+		roleNames = []string{defaultRole}
+	}
+
+	roleIds := make([]interface{}, 0, len(roleNames))
+	for _, roleName := range roleNames {
+		mappedRole, err := p.roleRepository.GetByName(roleName)
+		if err != nil {
+			p.logger.Warn("Mapped role not found", zap.String("role", roleName))
+			continue
+		}
+		roleIds = append(roleIds, strconv.FormatInt(mappedRole.ID, 10))
+	}
+	if len(roleIds) == 0 {
+		return nil
+	}
+	superRoleIds, err := p.superRoleIds(userId)
+	if err != nil {
+		return err
+	}
+	for _, roleId := range superRoleIds {
+		id := strconv.FormatInt(roleId, 10)
+		if !slices.Contains(roleIds, interface{}(id)) {
+			roleIds = append(roleIds, id)
+		}
+	}
+	return p.userRoleRepository.Insert(userId, map[string]any{"roleIds": roleIds})
+}
+
+// superRoleIds 用户当前拥有的超级管理员角色
+func (p *externalUserProvisioner) superRoleIds(userId int64) ([]int64, error) {
+	currentRoleIds, err := p.userRoleRepository.GetByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	superRoleIds := make([]int64, 0)
+	for _, roleId := range currentRoleIds {
+		isSuper, err := p.roleRepository.ContainsSuperRole(int64(roleId))
+		if err != nil {
+			return nil, err
+		}
+		if isSuper {
+			superRoleIds = append(superRoleIds, int64(roleId))
+		}
+	}
+	return superRoleIds, nil
+}
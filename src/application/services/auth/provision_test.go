@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	ldapLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/ldap"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeUserRepository 只实现外部身份映射用到的方法
+type fakeUserRepository struct {
+	user.UserRepositoryInterface
+	users []domainUser.User
+}
+
+func (f *fakeUserRepository) GetByID(id int) (*domainUser.User, error) {
+	for i := range f.users {
+		if f.users[i].ID == int64(id) {
+			return &f.users[i], nil
+		}
+	}
+	return nil, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+}
+
+func (f *fakeUserRepository) GetOneByMap(userMap map[string]interface{}) (*domainUser.User, error) {
+	for i := range f.users {
+		if userMap["user_name"] == f.users[i].UserName || userMap["email"] == f.users[i].Email {
+			return &f.users[i], nil
+		}
+	}
+	return &domainUser.User{}, nil
+}
+
+func (f *fakeUserRepository) Create(newUser *domainUser.User) (*domainUser.User, error) {
+	newUser.ID = int64(len(f.users) + 1)
+	f.users = append(f.users, *newUser)
+	return newUser, nil
+}
+
+type fakeIdentityRepository map[string]int64
+
+func (f fakeIdentityRepository) GetUserID(provider, issuer, subject string) (int64, error) {
+	return f[provider+issuer+subject], nil
+}
+
+func (f fakeIdentityRepository) Link(userId int64, provider, issuer, subject string) error {
+	f[provider+issuer+subject] = userId
+	return nil
+}
+
+func newTestProvisioner() (*externalUserProvisioner, *fakeUserRepository, fakeIdentityRepository) {
+	users := &fakeUserRepository{users: []domainUser.User{
+		{ID: 1, UserName: "admin", Email: "admin@example.com", Status: domainUser.UserStatusEnabled},
+	}}
+	identities := fakeIdentityRepository{}
+	return &externalUserProvisioner{
+		userRepository:     users,
+		identityRepository: identities,
+		logger:             &logger.Logger{Log: zap.NewNop()},
+	}, users, identities
+}
+
+func isLinkRequired(err error) bool {
+	var appErr *domainErrors.AppError
+	return errors.As(err, &appErr) && appErr.Err == errLinkRequired
+}
+
+func TestFindOrCreate_DoesNotLinkByUsername(t *testing.T) {
+	p, _, identities := newTestProvisioner()
+
+	_, _, err := p.findOrCreate(externalIdentity{Provider: "oidc", Issuer: "idp", Subject: "evil", Username: "admin"}, true)
+
+	assert.True(t, isLinkRequired(err))
+	assert.Empty(t, identities)
+}
+
+func TestFindOrCreate_LinksOnlyVerifiedEmail(t *testing.T) {
+	p, _, identities := newTestProvisioner()
+	identity := externalIdentity{Provider: "oidc", Issuer: "idp", Subject: "sub-1", Username: "someone", Email: "admin@example.com"}
+
+	_, _, err := p.findOrCreate(identity, true)
+	assert.True(t, isLinkRequired(err))
+
+	identity.EmailVerified = true
+	linked, created, err := p.findOrCreate(identity, true)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, int64(1), linked.ID)
+	assert.Equal(t, int64(1), identities["oidcidpsub-1"])
+}
+
+func TestFindOrCreate_UsesStoredLink(t *testing.T) {
+	p, _, identities := newTestProvisioner()
+	identities["ldapcn=admin,dc=example"] = 1
+
+	linked, created, err := p.findOrCreate(externalIdentity{Provider: "ldap", Subject: "cn=admin,dc=example", Username: "renamed"}, false)
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "admin", linked.UserName)
+}
+
+func TestFindOrCreate_ProvisionsAndLinksNewUser(t *testing.T) {
+	p, users, identities := newTestProvisioner()
+	identity := externalIdentity{Provider: "oidc", Issuer: "idp", Subject: "sub-2", Username: "alice", Email: "alice@example.com"}
+
+	_, _, err := p.findOrCreate(identity, false)
+	assert.Error(t, err)
+
+	created, isNew, err := p.findOrCreate(identity, true)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+	assert.Len(t, users.users, 2)
+	assert.Equal(t, created.ID, identities["oidcidpsub-2"])
+}
+
+func TestCheckLoginStatus(t *testing.T) {
+	assert.NoError(t, checkLoginStatus(&domainUser.User{Status: domainUser.UserStatusEnabled}))
+	assert.Error(t, checkLoginStatus(&domainUser.User{Status: domainUser.UserStatusDisabled}))
+	assert.Error(t, checkLoginStatus(&domainUser.User{Status: domainUser.UserStatusPending}))
+}
+
+func TestLDAPProfileUpdates_OnlyFillsEmptyFields(t *testing.T) {
+	entry := &ldapLib.Entry{Email: "attacker@example.com", NickName: "Directory Name"}
+
+	updates := ldapProfileUpdates(&domainUser.User{Email: "alice@example.com", NickName: "Alice"}, entry)
+	assert.Empty(t, updates)
+
+	updates = ldapProfileUpdates(&domainUser.User{Email: "alice@example.com"}, entry)
+	assert.Equal(t, map[string]interface{}{"nick_name": "Directory Name"}, updates)
+}
+
+// fakeMappedRoleRepository 角色名即ID，ID为1的角色是超级管理员角色
+type fakeMappedRoleRepository struct {
+	role.ISysRolesRepository
+}
+
+func (f *fakeMappedRoleRepository) GetByName(name string) (*domainRole.Role, error) {
+	id, err := strconv.ParseInt(name, 10, 64)
+	return &domainRole.Role{ID: id}, err
+}
+
+func (f *fakeMappedRoleRepository) ContainsSuperRole(roleIds ...int64) (bool, error) {
+	return slices.Contains(roleIds, 1), nil
+}
+
+type fakeUserRoleRepository struct {
+	user_role.ISysUserRoleRepository
+	roles map[int64][]int
+}
+
+func (f *fakeUserRoleRepository) GetByUserId(userId int64) ([]int, error) {
+	return f.roles[userId], nil
+}
+
+func (f *fakeUserRoleRepository) Insert(userId int64, updateMap map[string]any) error {
+	f.roles[userId] = nil
+	for _, item := range updateMap["roleIds"].([]interface{}) {
+		id, _ := strconv.Atoi(item.(string))
+		f.roles[userId] = append(f.roles[userId], id)
+	}
+	return nil
+}
+
+func TestSyncRoles_KeepsExistingSuperRole(t *testing.T) {
+	p, _, _ := newTestProvisioner()
+	userRoles := &fakeUserRoleRepository{roles: map[int64][]int{1: {1, 2}, 2: {2}}}
+	p.roleRepository = &fakeMappedRoleRepository{}
+	p.userRoleRepository = userRoles
+
+	assert.NoError(t, p.syncRoles(1, []string{"3"}, "", false))
+	assert.ElementsMatch(t, []int{3, 1}, userRoles.roles[1])
+
+	assert.NoError(t, p.syncRoles(2, []string{"3"}, "", false))
+	assert.Equal(t, []int{3}, userRoles.roles[2])
+}
@@ -10,11 +10,16 @@ import (
 const (
 	UserTokenKeyPrefix        = "user_token:%d"
 	UserRefreshTokenKeyPrefix = "user_refresh_token:%d"
+	OIDCStateKeyPrefix        = "oidc_state:%s"
+	// EmailVerifyKeyPrefix 当前有效的邮箱验证令牌，重新发送后旧链接失效
+	EmailVerifyKeyPrefix       = "email_verify:%d"
+	EmailVerifyResendKeyPrefix = "email_verify_resend:%d"
 )
 
 var (
 	UserTokenExpireDuration    = time.Minute * getEnvAsInt64OrDefault("JWT_ACCESS_TIME_MINUTE", 60)
 	RefreshTokenExpireDuration = getEnvAsInt64OrDefault("JWT_REFRESH_TIME_HOUR", 24) * time.Hour
+	OIDCStateExpireDuration    = 10 * time.Minute
 )
 
 func GetUserTokenKey(userID int64) string {
@@ -25,6 +30,18 @@ func GetUserRefreshTokenKey(userID int64) string {
 	return fmt.Sprintf(UserRefreshTokenKeyPrefix, userID)
 }
 
+func GetOIDCStateKey(state string) string {
+	return fmt.Sprintf(OIDCStateKeyPrefix, state)
+}
+
+func GetEmailVerifyKey(userID int64) string {
+	return fmt.Sprintf(EmailVerifyKeyPrefix, userID)
+}
+
+func GetEmailVerifyResendKey(userID int64) string {
+	return fmt.Sprintf(EmailVerifyResendKeyPrefix, userID)
+}
+
 func getEnvAsInt64OrDefault(key string, defaultValue int64) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
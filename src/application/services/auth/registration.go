@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainInvitation "github.com/gbrayhan/microservices-go/src/domain/sys/invitation"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// 自助注册模式
+const (
+	RegisterModeDisabled = "disabled"
+	RegisterModeOpen     = "open"
+	RegisterModeDomain   = "domain" // 仅允许 REGISTER_ALLOWED_DOMAINS 中的邮箱域名
+	RegisterModeInvite   = "invite" // 必须持有管理员发出的邀请
+)
+
+// checkRegistration 按注册模式校验；携带邀请令牌时占用该邀请，任何模式下邀请都可绕过域名限制
+func (s *AuthUseCase) checkRegistration(user RegisterUser) (*domainInvitation.Invitation, error) {
+	mode := sharedUtil.GetEnv("REGISTER_MODE", RegisterModeOpen)
+	switch mode {
+	case RegisterModeDisabled:
+		return nil, domainErrors.NewAppError(errors.New("registration is disabled"), domainErrors.NotAuthorized)
+	case RegisterModeOpen, RegisterModeDomain, RegisterModeInvite:
+	default:
+		s.Logger.Error("Unknown registration mode, rejecting registration", zap.String("mode", mode))
+		return nil, domainErrors.NewAppError(errors.New("registration is disabled"), domainErrors.NotAuthorized)
+	}
+
+	if user.InvitationToken != "" {
+		return s.invitationService.Redeem(user.InvitationToken, user.Email)
+	}
+	switch mode {
+	case RegisterModeInvite:
+		return nil, domainErrors.NewAppError(errors.New("registration requires an invitation"), domainErrors.NotAuthorized)
+	case RegisterModeDomain:
+		allowed := strings.Split(sharedUtil.GetEnv("REGISTER_ALLOWED_DOMAINS", ""), ",")
+		if !emailDomainAllowed(user.Email, allowed) {
+			return nil, domainErrors.NewAppError(errors.New("email domain is not allowed to register"), domainErrors.NotAuthorized)
+		}
+	}
+	return nil, nil
+}
+
+// assignRegisteredRoles 邀请用户授予邀请中的角色，其余用户授予 REGISTER_DEFAULT_ROLE
+func (s *AuthUseCase) assignRegisteredRoles(userId int64, invitation *domainInvitation.Invitation) {
+	var err error
+	if invitation != nil {
+		err = s.UserRoleRepository.Insert(userId, map[string]any{
+			"roleIds": []interface{}{strconv.FormatInt(invitation.RoleID, 10)},
+		})
+		if completeErr := s.invitationService.Complete(invitation.ID, userId); completeErr != nil {
+			s.Logger.Error("Error completing invitation", zap.Error(completeErr), zap.Int64("invitationID", invitation.ID))
+		}
+	} else {
+		err = s.provisioner.syncRoles(userId, nil, sharedUtil.GetEnv("REGISTER_DEFAULT_ROLE", ""), true)
+	}
+	if err != nil {
+		s.Logger.Error("Error assigning roles to registered user", zap.Error(err), zap.Int64("userID", userId))
+	}
+}
+
+func emailDomainAllowed(email string, allowed []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, candidate := range allowed {
+		if candidate = strings.ToLower(strings.TrimSpace(candidate)); candidate != "" && candidate == domain {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	invitationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/invitation"
+	domainInvitation "github.com/gbrayhan/microservices-go/src/domain/sys/invitation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	passwordLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/password"
+	passwordHistoryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/password_history"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakePasswordHistoryRepository 按用户记录写入的密码哈希
+type fakePasswordHistoryRepository struct {
+	passwordHistoryRepo.IPasswordHistoryRepository
+	hashes map[int64][]string
+}
+
+func (f *fakePasswordHistoryRepository) Add(userId int64, hashPassword string, keep int) error {
+	f.hashes[userId] = append(f.hashes[userId], hashPassword)
+	return nil
+}
+
+type fakeEventBus struct {
+	bus.EventBus
+}
+
+func (f *fakeEventBus) Publish(ctx context.Context, event model.ApplicationEvent) error {
+	return nil
+}
+
+// fakeInvitationService 任意令牌都兑换为角色2的邀请
+type fakeInvitationService struct {
+	invitationUseCase.ISysInvitationService
+	completed map[int64]int64
+}
+
+func (f *fakeInvitationService) Redeem(token, email string) (*domainInvitation.Invitation, error) {
+	return &domainInvitation.Invitation{ID: 7, Email: email, RoleID: 2}, nil
+}
+
+func (f *fakeInvitationService) Complete(id int64, userId int64) error {
+	f.completed[id] = userId
+	return nil
+}
+
+func newRegistrationUseCase() (*AuthUseCase, *fakePasswordHistoryRepository, *fakeInvitationService) {
+	loggerInstance := &logger.Logger{Log: zap.NewNop()}
+	provisioner, users, _ := newTestProvisioner()
+	history := &fakePasswordHistoryRepository{hashes: map[int64][]string{}}
+	invitations := &fakeInvitationService{completed: map[int64]int64{}}
+	return &AuthUseCase{
+		UserRepository:            users,
+		UserRoleRepository:        &fakeUserRoleRepository{roles: map[int64][]int{}},
+		Logger:                    loggerInstance,
+		provisioner:               provisioner,
+		passwordPolicy:            passwordLib.New(passwordLib.Config{MinLength: 8, MaxLength: 72, HistoryCount: 5}, loggerInstance),
+		passwordHistoryRepository: history,
+		eventBus:                  &fakeEventBus{},
+		invitationService:         invitations,
+	}, history, invitations
+}
+
+func TestRegister_RecordsInitialPasswordHistory(t *testing.T) {
+	t.Setenv("REGISTER_MODE", RegisterModeOpen)
+	t.Setenv("REGISTER_EMAIL_VERIFICATION", "false")
+	s, history, _ := newRegistrationUseCase()
+
+	res, err := s.Register(RegisterUser{UserName: "alice", Email: "alice@example.com", Password: "Secret123"})
+	require.NoError(t, err)
+
+	hashes := history.hashes[res.Data.Data.ID]
+	require.Len(t, hashes, 1)
+	assert.True(t, sharedUtil.CheckPasswordHash("Secret123", hashes[0]))
+}
+
+func TestRegister_InvitationRecordsInitialPasswordHistory(t *testing.T) {
+	t.Setenv("REGISTER_MODE", RegisterModeInvite)
+	t.Setenv("REGISTER_EMAIL_VERIFICATION", "false")
+	s, history, invitations := newRegistrationUseCase()
+
+	res, err := s.Register(RegisterUser{UserName: "bob", Email: "bob@example.com", Password: "Secret123", InvitationToken: "token"})
+	require.NoError(t, err)
+
+	userId := res.Data.Data.ID
+	assert.Equal(t, userId, invitations.completed[7])
+	hashes := history.hashes[userId]
+	require.Len(t, hashes, 1)
+	assert.True(t, sharedUtil.CheckPasswordHash("Secret123", hashes[0]))
+}
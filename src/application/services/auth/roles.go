@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"errors"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+var errRoleNotAssigned = errors.New("role is not assigned to the user")
+
+// roleUnionEnabled 开启后登录令牌携带用户全部角色，菜单、按钮和接口权限按角色并集计算
+func roleUnionEnabled() bool {
+	return sharedUtil.GetEnv("SERVER_ROLE_UNION", "false") == "true"
+}
+
+// loginRoles 返回登录时的主角色，合并模式下同时返回全部角色ID
+func loginRoles(user *domainUser.User) (domainRole.Role, []int64) {
+	var role domainRole.Role
+	if len(user.Roles) == 0 {
+		return role, nil
+	}
+	role = user.Roles[0]
+	if !roleUnionEnabled() {
+		return role, nil
+	}
+	roleIds := make([]int64, 0, len(user.Roles))
+	for _, r := range user.Roles {
+		roleIds = append(roleIds, r.ID)
+	}
+	return role, roleIds
+}
+
+// assignedRole 切换角色只能缩小到用户已拥有的角色
+func assignedRole(user *domainUser.User, roleId int64) (*domainRole.Role, bool) {
+	for i := range user.Roles {
+		if user.Roles[i].ID == roleId {
+			return &user.Roles[i], true
+		}
+	}
+	return nil, false
+}
+
+// generateTokenPair 签发访问令牌和刷新令牌，roleIds 为空时令牌只携带单个角色
+func (s *AuthUseCase) generateTokenPair(userId int64, roleId int64, roleIds []int64) (*AuthTokens, error) {
+	accessTokenClaims, err := s.JWTService.GenerateJWTTokenWithRoles(userId, roleId, roleIds, security.Access)
+	if err != nil {
+		s.Logger.Error("Error generating access token", zap.Error(err), zap.Int64("userID", userId))
+		return nil, err
+	}
+	refreshTokenClaims, err := s.JWTService.GenerateJWTTokenWithRoles(userId, roleId, roleIds, security.Refresh)
+	if err != nil {
+		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", userId))
+		return nil, err
+	}
+	return &AuthTokens{
+		AccessToken:               accessTokenClaims.Token,
+		RefreshToken:              refreshTokenClaims.Token,
+		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
+		ExpirationRefreshDateTime: refreshTokenClaims.ExpirationTime,
+		RoleIDs:                   roleIds,
+	}, nil
+}
+
+// SwitchRole 切换到单个角色；合并模式下 roleId 为 0 时恢复为全部角色
+func (s *AuthUseCase) SwitchRole(userId int, roleId int64) (*domainUser.User, *AuthTokens, *domainRole.Role, error) {
+	s.Logger.Info("User switch attempt", zap.Int("userId", userId), zap.Int64("roleId", roleId))
+	user, err := s.UserRepository.GetByID(userId)
+	if err != nil {
+		s.Logger.Error("Error getting user for switch", zap.Error(err), zap.Int("userId", userId))
+		return nil, nil, nil, err
+	}
+	if user.ID == 0 {
+		s.Logger.Warn("Switch role failed: user not found", zap.Int("userId", userId))
+		return nil, nil, nil, domainErrors.NewAppError(errors.New("user don't no found"), domainErrors.NotAuthorized)
+	}
+
+	var role domainRole.Role
+	var roleIds []int64
+	if roleId == 0 && roleUnionEnabled() {
+		role, roleIds = loginRoles(user)
+	} else {
+		assigned, ok := assignedRole(user, roleId)
+		if !ok {
+			s.Logger.Warn("Switch role failed: role not assigned", zap.Int("userId", userId), zap.Int64("roleId", roleId))
+			return nil, nil, nil, domainErrors.NewAppError(errRoleNotAssigned, domainErrors.NotAuthorized)
+		}
+		role = *assigned
+	}
+
+	authTokens, err := s.generateTokenPair(user.ID, role.ID, roleIds)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s.Logger.Info("User switch role successful", zap.Int("userId", userId), zap.Int64("roleId", role.ID))
+	return user, authTokens, &role, nil
+}
@@ -24,6 +24,13 @@ type DataSecurityAuthenticated struct {
 	JWTRefreshToken           string    `json:"jwtRefreshToken"`
 	ExpirationAccessDateTime  time.Time `json:"expirationAccessDateTime"`
 	ExpirationRefreshDateTime time.Time `json:"expirationRefreshDateTime"`
+	PasswordChangeRequired    bool      `json:"passwordChangeRequired,omitempty"`
+	PasswordResetToken        string    `json:"passwordResetToken,omitempty"`
+	ExpirationResetDateTime   time.Time `json:"expirationResetDateTime,omitempty"`
+	// ImpersonatorID 模拟登录时的真实管理员ID，前端据此提示并提供退出入口
+	ImpersonatorID int64 `json:"impersonatorId,omitempty"`
+	// RoleIDs 按角色并集鉴权时的全部角色，为空表示只使用当前角色
+	RoleIDs []int64 `json:"roleIds,omitempty"`
 }
 
 type SecurityAuthenticatedUser struct {
@@ -36,7 +43,8 @@ type SecurityRegisterUser struct {
 }
 
 type RegisterUser struct {
-	UserName string `json:"user_name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	UserName        string `json:"user_name"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	InvitationToken string `json:"invitation_token"`
 }
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const FUNCTION_TYPE_CLEAN_UNVERIFIED_USERS = "clean_unverified_users"
+
+var errInvalidVerification = errors.New("invalid or expired verification link")
+
+// emailVerificationEnabled 自助注册是否需要邮箱验证
+func emailVerificationEnabled() bool {
+	return sharedUtil.GetEnv("REGISTER_EMAIL_VERIFICATION", "true") == "true"
+}
+
+// newVerificationLink 签发验证令牌，redis 中只保存最新的令牌，旧链接随之失效
+func (s *AuthUseCase) newVerificationLink(userId int64) (string, error) {
+	token, err := s.JWTService.GenerateJWTToken(userId, 0, security.Verify)
+	if err != nil {
+		s.Logger.Error("Error generating verification token", zap.Error(err), zap.Int64("userID", userId))
+		return "", err
+	}
+	if err := s.RedisClient.Set(context.Background(), GetEmailVerifyKey(userId), token.Token, time.Until(token.ExpirationTime)).Err(); err != nil {
+		s.Logger.Error("Error saving verification token", zap.Error(err), zap.Int64("userID", userId))
+		return "", domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	return fmt.Sprintf("%s/#/auth/verify-email?token=%s", sharedUtil.GetEnv("SERVER_FRONTEND_URL", ""), token.Token), nil
+}
+
+// publishRegistered 发布注册事件，待验证用户附带验证链接由邮件处理器发送
+func (s *AuthUseCase) publishRegistered(user *domainUser.User) error {
+	event := &model.UserRegisteredEvent{
+		ID:           uuid.New().String(),
+		UserID:       strconv.FormatInt(user.ID, 10),
+		Username:     user.UserName,
+		Email:        user.Email,
+		RegisteredAt: time.Now(),
+	}
+	if user.Status == domainUser.UserStatusPending {
+		link, err := s.newVerificationLink(user.ID)
+		if err != nil {
+			return err
+		}
+		event.VerificationLink = link
+	}
+	return s.eventBus.Publish(context.Background(), event)
+}
+
+// VerifyEmail 校验验证链接并激活账号，每个链接只能使用一次
+func (s *AuthUseCase) VerifyEmail(token string) (*domainUser.User, error) {
+	claims, err := s.JWTService.GetClaimsAndVerifyToken(token, security.Verify)
+	if err != nil {
+		s.Logger.Warn("Email verification failed: invalid token", zap.Error(err))
+		return nil, domainErrors.NewAppError(errInvalidVerification, domainErrors.NotAuthorized)
+	}
+	userId := int64(claims["id"].(float64))
+
+	ctx := context.Background()
+	stored, err := s.RedisClient.Get(ctx, GetEmailVerifyKey(userId)).Result()
+	if err != nil || stored != token {
+		s.Logger.Warn("Email verification failed: token used or replaced", zap.Int64("userID", userId))
+		return nil, domainErrors.NewAppError(errInvalidVerification, domainErrors.NotAuthorized)
+	}
+
+	user, err := s.UserRepository.GetByID(int(userId))
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == domainUser.UserStatusPending {
+		user, err = s.UserRepository.Update(userId, map[string]interface{}{"status": domainUser.UserStatusEnabled})
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.RedisClient.Del(ctx, GetEmailVerifyKey(userId))
+	s.Logger.Info("Email verified", zap.Int64("userID", userId))
+	return user, nil
+}
+
+// ResendVerificationEmail 重新发送验证邮件；邮箱不存在或已验证时静默返回，避免泄露账号信息
+func (s *AuthUseCase) ResendVerificationEmail(email string) error {
+	if email == "" {
+		return domainErrors.NewAppError(errors.New("email is required"), domainErrors.ValidationError)
+	}
+	user, err := s.UserRepository.GetByEmail(email)
+	if err != nil {
+		var appErr *domainErrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == domainErrors.NotFound {
+			return nil
+		}
+		return err
+	}
+	if user.Status != domainUser.UserStatusPending {
+		return nil
+	}
+
+	interval := time.Duration(sharedUtil.GetEnvAsInt("REGISTER_RESEND_INTERVAL_SECOND", 60)) * time.Second
+	allowed, err := s.RedisClient.SetNX(context.Background(), GetEmailVerifyResendKey(user.ID), 1, interval).Result()
+	if err != nil {
+		return domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	if !allowed {
+		return domainErrors.NewAppError(errors.New("verification email was sent recently, please try again later"), domainErrors.ValidationError)
+	}
+
+	link, err := s.newVerificationLink(user.ID)
+	if err != nil {
+		return err
+	}
+	s.Logger.Info("Resending verification email", zap.Int64("userID", user.ID))
+	return s.eventBus.Publish(context.Background(), &model.EmailVerificationEvent{
+		ID:               uuid.New().String(),
+		UserID:           strconv.FormatInt(user.ID, 10),
+		Email:            user.Email,
+		VerificationLink: link,
+		RegisteredAt:     time.Now(),
+	})
+}
+
+// CleanUnverifiedUsers 删除超过期限仍未验证的注册用户，由定时任务调用
+func (s *AuthUseCase) CleanUnverifiedUsers() (int64, error) {
+	days := sharedUtil.GetEnvAsInt("REGISTER_PENDING_EXPIRE_DAYS", 7)
+	if days <= 0 {
+		return 0, nil
+	}
+	return s.UserRepository.DeleteUnverifiedBefore(time.Now().AddDate(0, 0, -days))
+}
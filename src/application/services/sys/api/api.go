@@ -6,12 +6,14 @@ import (
 	"mime/multipart"
 	"strings"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/gbrayhan/microservices-go/src/domain"
 	apiDomain "github.com/gbrayhan/microservices-go/src/domain/sys/api"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/excel"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
 	dictionaryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -26,7 +28,7 @@ type ISysApiService interface {
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*apiDomain.Api, error)
 	GetApisGroup(path string) (*[]apiDomain.GroupApiItem, error)
-	SynchronizeRouterToApi(router gin.RoutesInfo) (*int, error)
+	SynchronizeRouterToApi(router gin.RoutesInfo, options apiDomain.SyncOptions) (*apiDomain.SyncResult, error)
 	GenerateTemplate() (*bytes.Buffer, error)
 	Export() (*bytes.Buffer, error)
 	Import(src multipart.File) (*[]apiDomain.Api, *int, *int, error)
@@ -35,6 +37,7 @@ type ISysApiService interface {
 type SysApiUseCase struct {
 	sysApiRepository     apiRepo.ApiRepositoryInterface
 	dictionaryRepository dictionaryRepo.DictionaryRepositoryInterface
+	enforcer             *casbin.Enforcer
 	Logger               *logger.Logger
 	ExcelHandler         *excel.ExcelHandler
 }
@@ -42,10 +45,12 @@ type SysApiUseCase struct {
 func NewSysApiUseCase(
 	sysApiRepository apiRepo.ApiRepositoryInterface,
 	dictionaryRepository dictionaryRepo.DictionaryRepositoryInterface,
+	enforcer *casbin.Enforcer,
 	loggerInstance *logger.Logger) ISysApiService {
 	return &SysApiUseCase{
 		sysApiRepository:     sysApiRepository,
 		dictionaryRepository: dictionaryRepository,
+		enforcer:             enforcer,
 		Logger:               loggerInstance,
 		ExcelHandler:         excel.NewExcelHandler(),
 	}
@@ -66,14 +71,23 @@ func (s *SysApiUseCase) Create(newApi *apiDomain.Api) (*apiDomain.Api, error) {
 	return s.sysApiRepository.Create(newApi)
 }
 
+// Delete 仓储在同一事务中删除接口策略，完成后重新加载 enforcer
 func (s *SysApiUseCase) Delete(ids []int) error {
 	s.Logger.Info("Deleting api", zap.String("ids", fmt.Sprintf("%v", ids)))
-	return s.sysApiRepository.Delete(ids)
+	if err := s.sysApiRepository.Delete(ids); err != nil {
+		return err
+	}
+	return sharedUtil.ReloadCasbinPolicy(s.enforcer)
 }
 
+// Update 路径或方法变化时仓储同步改写接口策略，完成后重新加载 enforcer
 func (s *SysApiUseCase) Update(id int, userMap map[string]interface{}) (*apiDomain.Api, error) {
 	s.Logger.Info("Updating api", zap.Int("id", id))
-	return s.sysApiRepository.Update(id, userMap)
+	api, err := s.sysApiRepository.Update(id, userMap)
+	if err != nil {
+		return api, err
+	}
+	return api, sharedUtil.ReloadCasbinPolicy(s.enforcer)
 }
 
 func (s *SysApiUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[apiDomain.Api], error) {
@@ -133,33 +147,6 @@ func (s *SysApiUseCase) GetApisGroup(path string) (*[]apiDomain.GroupApiItem, er
 	return &groups, nil
 }
 
-func (c *SysApiUseCase) SynchronizeRouterToApi(routes gin.RoutesInfo) (*int, error) {
-	count := 0
-	for _, route := range routes {
-		if c.shouldSyncRoute(route.Path) {
-			apiModel := &apiRepo.SysApi{
-				Path:        route.Path,
-				Method:      route.Method,
-				Description: c.generateDescription(route.Path, route.Method),
-				ApiGroup:    "other",
-			}
-
-			ok, err := c.sysApiRepository.CreateByCondition(apiModel)
-			if err != nil {
-				c.Logger.Error("Failed to sync route",
-					zap.String("path", route.Path),
-					zap.String("method", route.Method),
-					zap.Error(err))
-				continue
-			}
-			if ok {
-				count++
-			}
-		}
-	}
-	return &count, nil
-}
-
 // GenerateTemplate implements ISysApiService.
 func (s *SysApiUseCase) GenerateTemplate() (*bytes.Buffer, error) {
 	headers := []string{"Path", "ApiGroup", "Method", "Description"}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	apiDomain "github.com/gbrayhan/microservices-go/src/domain/sys/api"
+	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultApiGroup 没有 swagger 注释的路由使用的分组
+const defaultApiGroup = "other"
+
+var (
+	swaggerParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+	ginParamPattern     = regexp.MustCompile(`[:*][^/]+`)
+)
+
+// swaggerOperation docs/swagger.json 中单个接口的注释
+type swaggerOperation struct {
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// SynchronizeRouterToApi 比较路由与 sys_apis：新增路由写入，参数名或路径变化的路由保留原记录和 casbin 策略，
+// 已不存在的路由按 options.Stale 保留、标记或连同策略删除
+func (c *SysApiUseCase) SynchronizeRouterToApi(routes gin.RoutesInfo, options apiDomain.SyncOptions) (*apiDomain.SyncResult, error) {
+	apis, err := c.sysApiRepository.GetAll("")
+	if err != nil {
+		return nil, err
+	}
+	operations := c.loadSwaggerOperations()
+
+	existing := make(map[string]apiDomain.Api, len(*apis))
+	for _, api := range *apis {
+		existing[routeKey(api.Method, api.Path)] = api
+	}
+
+	result := &apiDomain.SyncResult{
+		Added:     []apiDomain.Api{},
+		Removed:   []apiDomain.Api{},
+		Renamed:   []apiDomain.RenamedApi{},
+		Suggested: []apiDomain.RenamedApi{},
+		Updated:   []apiDomain.Api{},
+	}
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		key := routeKey(route.Method, route.Path)
+		if !c.shouldSyncRoute(route.Path) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		described := c.describeRoute(route.Method, route.Path, operations)
+		api, exists := existing[key]
+		if !exists {
+			result.Added = append(result.Added, described)
+			continue
+		}
+		// 只覆盖从未分组的记录，避免冲掉手工维护的分组和描述
+		regroup := api.ApiGroup == defaultApiGroup && described.ApiGroup != defaultApiGroup
+		if regroup {
+			api.ApiGroup = described.ApiGroup
+			api.Description = described.Description
+		}
+		if regroup || api.Stale {
+			api.Stale = false
+			result.Updated = append(result.Updated, api)
+		}
+	}
+	for _, api := range *apis {
+		if !seen[routeKey(api.Method, api.Path)] && c.shouldSyncRoute(api.Path) {
+			result.Removed = append(result.Removed, api)
+		}
+	}
+	matchRenamed(result)
+
+	if options.DryRun {
+		return result, nil
+	}
+	return result, c.applySync(result, options)
+}
+
+func (c *SysApiUseCase) applySync(result *apiDomain.SyncResult, options apiDomain.SyncOptions) error {
+	for i, api := range result.Added {
+		model := &apiRepo.SysApi{Path: api.Path, Method: api.Method, Description: api.Description, ApiGroup: api.ApiGroup}
+		if _, err := c.sysApiRepository.CreateByCondition(model); err != nil {
+			c.Logger.Error("Failed to sync route", zap.String("path", api.Path), zap.String("method", api.Method), zap.Error(err))
+			return err
+		}
+		result.Added[i].ID = model.ID
+	}
+	for _, api := range result.Updated {
+		if _, err := c.sysApiRepository.Update(api.ID, map[string]interface{}{
+			"api_group": api.ApiGroup, "description": api.Description, "stale": false,
+		}); err != nil {
+			return err
+		}
+	}
+	for _, renamed := range result.Renamed {
+		// 仓储在同一事务中把策略迁移到新路径
+		if _, err := c.sysApiRepository.Update(renamed.From.ID, map[string]interface{}{
+			"path": renamed.To.Path, "stale": false,
+		}); err != nil {
+			return err
+		}
+	}
+	switch options.Stale {
+	case apiDomain.SyncStaleFlag:
+		for _, api := range result.Removed {
+			if api.Stale {
+				continue
+			}
+			if _, err := c.sysApiRepository.Update(api.ID, map[string]interface{}{"stale": true}); err != nil {
+				return err
+			}
+		}
+	case apiDomain.SyncStaleDelete:
+		ids := make([]int, 0, len(result.Removed))
+		for _, api := range result.Removed {
+			ids = append(ids, api.ID)
+		}
+		if len(ids) > 0 {
+			if err := c.sysApiRepository.Delete(ids); err != nil {
+				return err
+			}
+		}
+	}
+	c.Logger.Info("Synchronized router to api",
+		zap.Int("added", len(result.Added)),
+		zap.Int("removed", len(result.Removed)),
+		zap.Int("renamed", len(result.Renamed)),
+		zap.Int("updated", len(result.Updated)))
+	return sharedUtil.ReloadCasbinPolicy(c.enforcer)
+}
+
+// matchRenamed 同一方法下只有参数名不同的新旧路由视为重命名，迁移原记录和策略；
+// 同分组下 swagger 描述相同的只作为建议返回，不自动迁移策略
+func matchRenamed(result *apiDomain.SyncResult) {
+	added := []apiDomain.Api{}
+	for _, route := range result.Added {
+		index := -1
+		for i, api := range result.Removed {
+			if api.Method == route.Method && routeShape(api.Path) == routeShape(route.Path) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			added = append(added, route)
+			continue
+		}
+		from := result.Removed[index]
+		to := from
+		to.Path = route.Path
+		to.Stale = false
+		result.Renamed = append(result.Renamed, apiDomain.RenamedApi{From: from, To: to})
+		result.Removed = append(result.Removed[:index], result.Removed[index+1:]...)
+	}
+	result.Added = added
+
+	for _, route := range result.Added {
+		if route.ApiGroup == defaultApiGroup {
+			continue
+		}
+		for _, api := range result.Removed {
+			if api.Method == route.Method && api.ApiGroup == route.ApiGroup && api.Description == route.Description {
+				result.Suggested = append(result.Suggested, apiDomain.RenamedApi{From: api, To: route})
+				break
+			}
+		}
+	}
+}
+
+// loadSwaggerOperations 读取 swagger 文档，文档不存在时返回空集合
+func (c *SysApiUseCase) loadSwaggerOperations() map[string]swaggerOperation {
+	operations := make(map[string]swaggerOperation)
+	file := sharedUtil.GetEnv("SERVER_SWAGGER_FILE", "docs/swagger.json")
+	content, err := os.ReadFile(file)
+	if err != nil {
+		c.Logger.Warn("Swagger document not available for api synchronization", zap.String("file", file), zap.Error(err))
+		return operations
+	}
+	var doc struct {
+		Paths map[string]map[string]swaggerOperation `json:"paths"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		c.Logger.Warn("Invalid swagger document", zap.String("file", file), zap.Error(err))
+		return operations
+	}
+	for path, methods := range doc.Paths {
+		ginPath := swaggerParamPattern.ReplaceAllString(path, ":$1")
+		for method, operation := range methods {
+			operations[routeKey(method, ginPath)] = operation
+		}
+	}
+	return operations
+}
+
+// describeRoute 优先使用 swagger 的 tag 和 summary，没有注释时回退到默认分组和生成的描述
+func (c *SysApiUseCase) describeRoute(method, path string, operations map[string]swaggerOperation) apiDomain.Api {
+	api := apiDomain.Api{
+		Path:        path,
+		Method:      method,
+		ApiGroup:    defaultApiGroup,
+		Description: c.generateDescription(path, method),
+	}
+	operation, ok := operations[routeKey(method, path)]
+	if !ok {
+		return api
+	}
+	if len(operation.Tags) > 0 && operation.Tags[0] != "" {
+		api.ApiGroup = operation.Tags[0]
+	}
+	if operation.Summary != "" {
+		api.Description = operation.Summary
+	} else if operation.Description != "" {
+		api.Description = operation.Description
+	}
+	return api
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// routeShape 忽略参数名后的路径
+func routeShape(path string) string {
+	return ginParamPattern.ReplaceAllString(path, ":")
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+
+	apiDomain "github.com/gbrayhan/microservices-go/src/domain/sys/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchRenamed(t *testing.T) {
+	result := &apiDomain.SyncResult{
+		Added: []apiDomain.Api{
+			{Path: "/v1/user/:userId", Method: "GET", ApiGroup: defaultApiGroup, Description: "get one resource"},
+			{Path: "/v1/dept/tree", Method: "GET", ApiGroup: "dept", Description: "get tree depts"},
+			{Path: "/v1/role/fields", Method: "GET", ApiGroup: defaultApiGroup, Description: "get all resources"},
+		},
+		Removed: []apiDomain.Api{
+			{ID: 1, Path: "/v1/user/:id", Method: "GET", ApiGroup: "user", Description: "get user"},
+			{ID: 2, Path: "/v1/department/tree", Method: "GET", ApiGroup: "dept", Description: "get tree depts"},
+			{ID: 3, Path: "/v1/role/list", Method: "GET", ApiGroup: "role", Description: "get all resources"},
+		},
+	}
+	matchRenamed(result)
+
+	// 只有参数名不同才自动迁移
+	assert.Len(t, result.Renamed, 1)
+	assert.Equal(t, 1, result.Renamed[0].From.ID)
+	assert.Equal(t, "/v1/user/:userId", result.Renamed[0].To.Path)
+	// 同分组描述相同只作为建议，新旧路由仍按新增和删除处理
+	assert.Len(t, result.Suggested, 1)
+	assert.Equal(t, 2, result.Suggested[0].From.ID)
+	assert.Equal(t, "/v1/dept/tree", result.Suggested[0].To.Path)
+	assert.Len(t, result.Added, 2)
+	assert.Equal(t, "/v1/dept/tree", result.Added[0].Path)
+	// 生成的描述相同不算重命名
+	assert.Equal(t, "/v1/role/fields", result.Added[1].Path)
+	assert.Len(t, result.Removed, 2)
+}
+
+func TestMatchRenamed_SummaryAcrossGroups(t *testing.T) {
+	result := &apiDomain.SyncResult{
+		Added: []apiDomain.Api{
+			{Path: "/v1/menu/detail/:id", Method: "GET", ApiGroup: "menu", Description: "get by id"},
+		},
+		Removed: []apiDomain.Api{
+			{ID: 7, Path: "/v1/role/detail/:id/info", Method: "GET", ApiGroup: "role", Description: "get by id"},
+		},
+	}
+	matchRenamed(result)
+
+	assert.Empty(t, result.Renamed)
+	assert.Empty(t, result.Suggested)
+	assert.Len(t, result.Added, 1)
+	assert.Len(t, result.Removed, 1)
+}
@@ -0,0 +1,221 @@
+package api_key
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2/util"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainApiKey "github.com/gbrayhan/microservices-go/src/domain/sys/api_key"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	apiKeyRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api_key"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// 密钥格式 ak_<prefix>_<secret>，prefix 可公开展示并用于查找
+	keyScheme = "ak"
+	// 同一密钥两次记录最近使用时间的最小间隔，避免每个请求都写库
+	lastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidApiKey  = errors.New("invalid api key")
+	ErrPathNotAllowed = errors.New("api key is not allowed for this path")
+)
+
+type ISysApiKeyService interface {
+	Create(userId int64, request CreateApiKeyRequest) (*domainApiKey.CreatedApiKey, error)
+	GetByUserID(userId int64) (*[]domainApiKey.ApiKey, error)
+	Revoke(userId int64, id int64) error
+	Authenticate(rawKey, path, ip string) (*domainApiKey.ApiKey, error)
+}
+
+type CreateApiKeyRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	RoleID     int64    `json:"role_id" binding:"required"`
+	Paths      []string `json:"paths"`
+	ExpireDays int      `json:"expire_days"`
+}
+
+type SysApiKeyUseCase struct {
+	apiKeyRepository apiKeyRepo.ISysApiKeyRepository
+	userRepository   user.UserRepositoryInterface
+	Logger           *logger.Logger
+}
+
+func NewSysApiKeyUseCase(
+	apiKeyRepository apiKeyRepo.ISysApiKeyRepository,
+	userRepository user.UserRepositoryInterface,
+	loggerInstance *logger.Logger,
+) ISysApiKeyService {
+	return &SysApiKeyUseCase{
+		apiKeyRepository: apiKeyRepository,
+		userRepository:   userRepository,
+		Logger:           loggerInstance,
+	}
+}
+
+// Create 为用户创建密钥，角色必须是用户已拥有的角色
+func (s *SysApiKeyUseCase) Create(userId int64, request CreateApiKeyRequest) (*domainApiKey.CreatedApiKey, error) {
+	s.Logger.Info("Creating api key", zap.Int64("userId", userId), zap.Int64("roleId", request.RoleID))
+	if !s.userHasRole(userId, request.RoleID) {
+		return nil, domainErrors.NewAppError(errors.New("role is not assigned to the user"), domainErrors.ValidationError)
+	}
+
+	maxDays := sharedUtil.GetEnvAsInt("API_KEY_MAX_EXPIRE_DAYS", 365)
+	expireDays := request.ExpireDays
+	if expireDays <= 0 {
+		expireDays = sharedUtil.GetEnvAsInt("API_KEY_DEFAULT_EXPIRE_DAYS", 90)
+	}
+	if expireDays > maxDays {
+		return nil, domainErrors.NewAppError(errors.New("expire_days exceeds "+strconv.Itoa(maxDays)), domainErrors.ValidationError)
+	}
+	expiresAt := time.Now().AddDate(0, 0, expireDays)
+
+	prefix, secret, err := generateKey()
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	rawKey := prefix + "_" + secret
+
+	created, err := s.apiKeyRepository.Create(&domainApiKey.ApiKey{
+		UserID:    userId,
+		RoleID:    request.RoleID,
+		Name:      request.Name,
+		Prefix:    prefix,
+		KeyHash:   hashKey(rawKey),
+		Paths:     normalizePaths(request.Paths),
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domainApiKey.CreatedApiKey{ApiKey: *created, Key: rawKey}, nil
+}
+
+func (s *SysApiKeyUseCase) GetByUserID(userId int64) (*[]domainApiKey.ApiKey, error) {
+	s.Logger.Info("Getting api keys", zap.Int64("userId", userId))
+	return s.apiKeyRepository.GetByUserID(userId)
+}
+
+// Revoke 只能吊销自己的密钥
+func (s *SysApiKeyUseCase) Revoke(userId int64, id int64) error {
+	s.Logger.Info("Revoking api key", zap.Int64("userId", userId), zap.Int64("id", id))
+	apiKey, err := s.apiKeyRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if apiKey.UserID != userId {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	return s.apiKeyRepository.Revoke(id)
+}
+
+// Authenticate 校验密钥、有效期、路径范围以及用户是否仍拥有该角色
+func (s *SysApiKeyUseCase) Authenticate(rawKey, path, ip string) (*domainApiKey.ApiKey, error) {
+	prefix, ok := parsePrefix(rawKey)
+	if !ok {
+		return nil, ErrInvalidApiKey
+	}
+	apiKey, err := s.apiKeyRepository.GetByPrefix(prefix)
+	if err != nil {
+		var appErr *domainErrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == domainErrors.NotFound {
+			return nil, ErrInvalidApiKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashKey(rawKey))) != 1 {
+		return nil, ErrInvalidApiKey
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return nil, ErrInvalidApiKey
+	}
+	if !allowsPath(apiKey.Paths, path) {
+		return nil, ErrPathNotAllowed
+	}
+	if !s.userHasRole(apiKey.UserID, apiKey.RoleID) {
+		return nil, ErrInvalidApiKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		if err := s.apiKeyRepository.TouchLastUsed(apiKey.ID, ip, now); err != nil {
+			s.Logger.Warn("Error tracking api key usage", zap.Error(err), zap.Int64("id", apiKey.ID))
+		}
+	}
+	return apiKey, nil
+}
+
+// userHasRole 用户存在、已启用且拥有该角色（GetByID 只加载启用的角色）
+func (s *SysApiKeyUseCase) userHasRole(userId int64, roleId int64) bool {
+	owner, err := s.userRepository.GetByID(int(userId))
+	if err != nil || owner.ID == 0 || owner.Status != 1 {
+		return false
+	}
+	for _, role := range owner.Roles {
+		if role.ID == roleId {
+			return true
+		}
+	}
+	return false
+}
+
+func generateKey() (string, string, error) {
+	prefixBytes := make([]byte, 6)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+	return keyScheme + "_" + hex.EncodeToString(prefixBytes), hex.EncodeToString(secretBytes), nil
+}
+
+// hashKey 密钥本身是高熵随机数，使用 sha256 即可，无需 bcrypt
+func hashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func parsePrefix(rawKey string) (string, bool) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != keyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[0] + "_" + parts[1], true
+}
+
+func normalizePaths(paths []string) []string {
+	result := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path != "" {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// allowsPath 路径列表为空时不限制，规则与 casbin 策略相同使用 keyMatch2
+func allowsPath(paths []string, path string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, pattern := range paths {
+		if util.KeyMatch2(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
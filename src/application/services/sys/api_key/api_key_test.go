@@ -0,0 +1,43 @@
+package api_key
+
+import (
+	"testing"
+	"time"
+
+	domainApiKey "github.com/gbrayhan/microservices-go/src/domain/sys/api_key"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKey_RoundTripsPrefix(t *testing.T) {
+	prefix, secret, err := generateKey()
+	assert.NoError(t, err)
+
+	parsed, ok := parsePrefix(prefix + "_" + secret)
+
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+}
+
+func TestParsePrefix_RejectsMalformedKeys(t *testing.T) {
+	for _, rawKey := range []string{"", "ak_abc", "xx_abc_def", "ak__def", "ak_abc_", "ak_a_b_c"} {
+		_, ok := parsePrefix(rawKey)
+		assert.False(t, ok, rawKey)
+	}
+}
+
+func TestAllowsPath(t *testing.T) {
+	assert.True(t, allowsPath(nil, "/v1/user"))
+	assert.True(t, allowsPath([]string{"/v1/user/:id"}, "/v1/user/3"))
+	assert.True(t, allowsPath([]string{"/v1/dictionary/*"}, "/v1/dictionary/search"))
+	assert.False(t, allowsPath([]string{"/v1/user/:id"}, "/v1/role/3"))
+}
+
+func TestApiKeyIsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.True(t, (&domainApiKey.ApiKey{ExpiresAt: &future}).IsActive(now))
+	assert.False(t, (&domainApiKey.ApiKey{ExpiresAt: &past}).IsActive(now))
+	assert.False(t, (&domainApiKey.ApiKey{ExpiresAt: &future, RevokedAt: &past}).IsActive(now))
+}
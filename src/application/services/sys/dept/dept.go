@@ -0,0 +1,206 @@
+package dept
+
+import (
+	"errors"
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	deptDomain "github.com/gbrayhan/microservices-go/src/domain/sys/dept"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	deptRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dept"
+	"go.uber.org/zap"
+)
+
+var (
+	errDeptCycle       = errors.New("a department cannot be moved under itself or its descendants")
+	errDeptHasChildren = errors.New("department has sub departments")
+)
+
+type SysDeptUseCase struct {
+	sysDeptRepository deptRepo.ISysDeptRepository
+	Logger            *logger.Logger
+}
+
+func NewSysDeptUseCase(sysDeptRepository deptRepo.ISysDeptRepository, loggerInstance *logger.Logger) deptDomain.IDeptService {
+	return &SysDeptUseCase{
+		sysDeptRepository: sysDeptRepository,
+		Logger:            loggerInstance,
+	}
+}
+
+// GetAll 返回部门树，节点附带负责人
+func (s *SysDeptUseCase) GetAll(status int) ([]*deptDomain.DeptTree, error) {
+	s.Logger.Info("Getting all depts")
+	depts, err := s.sysDeptRepository.GetAll(status)
+	if err != nil {
+		return nil, err
+	}
+	deptIds := make([]int64, 0, len(*depts))
+	for _, dept := range *depts {
+		deptIds = append(deptIds, dept.ID)
+	}
+	members, err := s.sysDeptRepository.GetMembers(deptIds...)
+	if err != nil {
+		return nil, err
+	}
+	leaders := make(map[int64][]int64)
+	for _, member := range members {
+		if member.IsLeader {
+			leaders[member.DeptID] = append(leaders[member.DeptID], member.UserID)
+		}
+	}
+
+	deptMap := make(map[int64]*deptDomain.DeptTree)
+	roots := make([]*deptDomain.DeptTree, 0)
+	for _, dept := range *depts {
+		deptMap[dept.ID] = &deptDomain.DeptTree{
+			ID:        dept.ID,
+			Name:      dept.Name,
+			ParentID:  dept.ParentID,
+			Order:     dept.Order,
+			Status:    dept.Status,
+			LeaderIDs: leaders[dept.ID],
+			CreatedAt: dept.CreatedAt,
+			UpdatedAt: dept.UpdatedAt,
+			Path:      []int64{dept.ID},
+			Children:  []*deptDomain.DeptTree{},
+		}
+	}
+	for _, dept := range *depts {
+		node := deptMap[dept.ID]
+		if parentNode, exists := deptMap[dept.ParentID]; exists && dept.ParentID != 0 {
+			node.Path = append(node.Path, parentNode.Path...)
+			parentNode.Children = append(parentNode.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots, nil
+}
+
+// GetTreeDepts 与 /role/tree 相同的选择器格式
+func (s *SysDeptUseCase) GetTreeDepts(status int) (*deptDomain.DeptNode, error) {
+	depts, err := s.sysDeptRepository.GetAll(status)
+	if err != nil {
+		return nil, err
+	}
+	deptMap := make(map[int64]*deptDomain.DeptNode)
+	var roots []*deptDomain.DeptNode
+	for _, dept := range *depts {
+		id := strconv.FormatInt(dept.ID, 10)
+		deptMap[dept.ID] = &deptDomain.DeptNode{
+			ID:       id,
+			Name:     dept.Name,
+			Key:      id,
+			Path:     []int64{dept.ID},
+			Children: []*deptDomain.DeptNode{},
+		}
+	}
+	for _, dept := range *depts {
+		node := deptMap[dept.ID]
+		if parentNode, exists := deptMap[dept.ParentID]; exists && dept.ParentID != 0 {
+			node.Path = append(node.Path, parentNode.Path...)
+			parentNode.Children = append(parentNode.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return &deptDomain.DeptNode{
+		ID:       "0",
+		Name:     "根节点",
+		Key:      "0",
+		Children: roots,
+	}, nil
+}
+
+func (s *SysDeptUseCase) GetByID(id int64) (*deptDomain.Dept, error) {
+	return s.sysDeptRepository.GetByID(id)
+}
+
+func (s *SysDeptUseCase) Create(newDept *deptDomain.Dept) (*deptDomain.Dept, error) {
+	s.Logger.Info("Creating new dept", zap.String("name", newDept.Name))
+	if err := s.checkParent(0, newDept.ParentID); err != nil {
+		return nil, err
+	}
+	return s.sysDeptRepository.Create(newDept)
+}
+
+func (s *SysDeptUseCase) Update(id int64, deptMap map[string]interface{}) (*deptDomain.Dept, error) {
+	s.Logger.Info("Updating dept", zap.Int64("id", id))
+	if value, ok := deptMap["parent_id"]; ok {
+		parentId, isNumber := value.(float64)
+		if !isNumber {
+			return nil, domainErrors.NewAppError(errors.New("parent_id must be a number"), domainErrors.ValidationError)
+		}
+		if err := s.checkParent(id, int64(parentId)); err != nil {
+			return nil, err
+		}
+	}
+	return s.sysDeptRepository.Update(id, deptMap)
+}
+
+// Delete 存在下级部门时拒绝删除
+func (s *SysDeptUseCase) Delete(id int64) error {
+	s.Logger.Info("Deleting dept", zap.Int64("id", id))
+	count, err := s.sysDeptRepository.CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domainErrors.NewAppError(errDeptHasChildren, domainErrors.ValidationError)
+	}
+	return s.sysDeptRepository.Delete(id)
+}
+
+func (s *SysDeptUseCase) GetMembers(deptId int64) ([]deptDomain.Member, error) {
+	return s.sysDeptRepository.GetMembers(deptId)
+}
+
+// SetMembers 替换部门成员，负责人自动成为成员
+func (s *SysDeptUseCase) SetMembers(deptId int64, userIds []int64, leaderIds []int64) error {
+	if _, err := s.sysDeptRepository.GetByID(deptId); err != nil {
+		return err
+	}
+	leaders := make(map[int64]bool, len(leaderIds))
+	for _, id := range leaderIds {
+		leaders[id] = true
+	}
+	seen := make(map[int64]bool)
+	members := make([]deptDomain.Member, 0, len(userIds)+len(leaderIds))
+	for _, id := range append(append([]int64{}, userIds...), leaderIds...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		members = append(members, deptDomain.Member{DeptID: deptId, UserID: id, IsLeader: leaders[id]})
+	}
+	s.Logger.Info("Updating dept members", zap.Int64("deptId", deptId), zap.Int("count", len(members)))
+	return s.sysDeptRepository.SetMembers(deptId, members)
+}
+
+func (s *SysDeptUseCase) GetUserDepts(userId int64) ([]deptDomain.Member, error) {
+	return s.sysDeptRepository.GetUserDepts(userId)
+}
+
+// checkParent 上级部门必须存在，且不能是自己或自己的下级
+func (s *SysDeptUseCase) checkParent(id int64, parentId int64) error {
+	if parentId == 0 {
+		return nil
+	}
+	if _, err := s.sysDeptRepository.GetByID(parentId); err != nil {
+		return err
+	}
+	if id == 0 {
+		return nil
+	}
+	depts, err := s.sysDeptRepository.GetAll(0)
+	if err != nil {
+		return err
+	}
+	for _, descendant := range deptDomain.WithDescendants(*depts, []int64{id}) {
+		if descendant == parentId {
+			return domainErrors.NewAppError(errDeptCycle, domainErrors.ValidationError)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package dictionary
 
 import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	dictionaryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary"
 
@@ -19,19 +20,25 @@ type ISysDictionaryService interface {
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*dictionaryDomain.Dictionary, error)
 	GetByType(typeText string) (*dictionaryDomain.Dictionary, error)
+	GetByTypes(types []string) (map[string]*dictionaryDomain.Dictionary, error)
+	// WarmCache 启动时把全部启用的字典加载到缓存
+	WarmCache() error
 }
 
 type SysDictionaryUseCase struct {
 	sysDictionaryRepository dictionaryRepo.DictionaryRepositoryInterface
+	dictionaryCache         *cache.DictionaryCache
 	Logger                  *logger.Logger
 }
 
 func NewSysDictionaryUseCase(
 	sysDictionaryRepository dictionaryRepo.DictionaryRepositoryInterface,
+	dictionaryCache *cache.DictionaryCache,
 	loggerInstance *logger.Logger,
 ) ISysDictionaryService {
 	return &SysDictionaryUseCase{
 		sysDictionaryRepository: sysDictionaryRepository,
+		dictionaryCache:         dictionaryCache,
 		Logger:                  loggerInstance,
 	}
 }
@@ -48,17 +55,31 @@ func (s *SysDictionaryUseCase) GetByID(id int) (*dictionaryDomain.Dictionary, er
 
 func (s *SysDictionaryUseCase) Create(newDictionary *dictionaryDomain.Dictionary) (*dictionaryDomain.Dictionary, error) {
 	s.Logger.Info("Creating new dictionary", zap.String("Name", newDictionary.Name))
-	return s.sysDictionaryRepository.Create(newDictionary)
+	dictionary, err := s.sysDictionaryRepository.Create(newDictionary)
+	if err != nil {
+		return nil, err
+	}
+	s.dictionaryCache.Invalidate()
+	return dictionary, nil
 }
 
 func (s *SysDictionaryUseCase) Delete(id int) error {
 	s.Logger.Info("Deleting dictionary", zap.Int("id", id))
-	return s.sysDictionaryRepository.Delete(id)
+	if err := s.sysDictionaryRepository.Delete(id); err != nil {
+		return err
+	}
+	s.dictionaryCache.Invalidate()
+	return nil
 }
 
 func (s *SysDictionaryUseCase) Update(id int, userMap map[string]interface{}) (*dictionaryDomain.Dictionary, error) {
 	s.Logger.Info("Updating dictionary", zap.Int("id", id))
-	return s.sysDictionaryRepository.Update(id, userMap)
+	dictionary, err := s.sysDictionaryRepository.Update(id, userMap)
+	if err != nil {
+		return nil, err
+	}
+	s.dictionaryCache.Invalidate()
+	return dictionary, nil
 }
 
 func (s *SysDictionaryUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[dictionaryDomain.Dictionary], error) {
@@ -79,6 +100,61 @@ func (s *SysDictionaryUseCase) GetOneByMap(userMap map[string]interface{}) (*dic
 	return s.sysDictionaryRepository.GetOneByMap(userMap)
 }
 
+// GetByType 优先读取缓存，字典不存在时返回 nil
 func (s *SysDictionaryUseCase) GetByType(typeText string) (*dictionaryDomain.Dictionary, error) {
-	return s.sysDictionaryRepository.GetByType(typeText)
+	if dictionary, ok := s.dictionaryCache.Get(typeText); ok {
+		return dictionary, nil
+	}
+	generation := s.dictionaryCache.Generation()
+	dictionary, err := s.sysDictionaryRepository.GetByType(typeText)
+	if err != nil || dictionary == nil {
+		return dictionary, err
+	}
+	s.dictionaryCache.Set(generation, *dictionary)
+	return dictionary, nil
+}
+
+// GetByTypes 按 type 返回字典，未命中缓存的类型一次查询，不存在的类型不出现在结果中
+func (s *SysDictionaryUseCase) GetByTypes(types []string) (map[string]*dictionaryDomain.Dictionary, error) {
+	result := make(map[string]*dictionaryDomain.Dictionary, len(types))
+	missing := make([]string, 0)
+	for _, typeText := range types {
+		if _, exists := result[typeText]; exists {
+			continue
+		}
+		if dictionary, ok := s.dictionaryCache.Get(typeText); ok {
+			result[typeText] = dictionary
+			continue
+		}
+		result[typeText] = nil
+		missing = append(missing, typeText)
+	}
+	if len(missing) > 0 {
+		generation := s.dictionaryCache.Generation()
+		dictionaries, err := s.sysDictionaryRepository.GetByTypes(missing)
+		if err != nil {
+			return nil, err
+		}
+		s.dictionaryCache.Set(generation, dictionaries...)
+		for i := range dictionaries {
+			result[dictionaries[i].Type] = &dictionaries[i]
+		}
+	}
+	for typeText, dictionary := range result {
+		if dictionary == nil {
+			delete(result, typeText)
+		}
+	}
+	return result, nil
+}
+
+func (s *SysDictionaryUseCase) WarmCache() error {
+	generation := s.dictionaryCache.Generation()
+	dictionaries, err := s.sysDictionaryRepository.GetByTypes(nil)
+	if err != nil {
+		return err
+	}
+	s.dictionaryCache.Set(generation, dictionaries...)
+	s.Logger.Info("Dictionary cache warmed", zap.Int("count", len(dictionaries)))
+	return nil
 }
@@ -3,6 +3,7 @@ package dictionary_detail
 import (
 	"fmt"
 
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	dictionaryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary_detail"
 
@@ -22,14 +23,17 @@ type ISysDictionaryService interface {
 	GetOneByMap(userMap map[string]interface{}) (*dictionaryDomain.DictionaryDetail, error)
 }
 
+// SysDictionaryUseCase 详情变化后使字典缓存失效
 type SysDictionaryUseCase struct {
 	sysDictionaryRepository dictionaryRepo.DictionaryRepositoryInterface
+	dictionaryCache         *cache.DictionaryCache
 	Logger                  *logger.Logger
 }
 
-func NewSysDictionaryUseCase(sysDictionaryRepository dictionaryRepo.DictionaryRepositoryInterface, loggerInstance *logger.Logger) ISysDictionaryService {
+func NewSysDictionaryUseCase(sysDictionaryRepository dictionaryRepo.DictionaryRepositoryInterface, dictionaryCache *cache.DictionaryCache, loggerInstance *logger.Logger) ISysDictionaryService {
 	return &SysDictionaryUseCase{
 		sysDictionaryRepository: sysDictionaryRepository,
+		dictionaryCache:         dictionaryCache,
 		Logger:                  loggerInstance,
 	}
 }
@@ -46,17 +50,31 @@ func (s *SysDictionaryUseCase) GetByID(id int) (*dictionaryDomain.DictionaryDeta
 
 func (s *SysDictionaryUseCase) Create(newDictionary *dictionaryDomain.DictionaryDetail) (*dictionaryDomain.DictionaryDetail, error) {
 	s.Logger.Info("Creating new dictionary_detail", zap.String("Label", newDictionary.Label))
-	return s.sysDictionaryRepository.Create(newDictionary)
+	detail, err := s.sysDictionaryRepository.Create(newDictionary)
+	if err != nil {
+		return nil, err
+	}
+	s.dictionaryCache.Invalidate()
+	return detail, nil
 }
 
 func (s *SysDictionaryUseCase) Delete(ids []int) error {
 	s.Logger.Info("Deleting dictionary_detail", zap.String("ids", fmt.Sprintf("%v", ids)))
-	return s.sysDictionaryRepository.Delete(ids)
+	if err := s.sysDictionaryRepository.Delete(ids); err != nil {
+		return err
+	}
+	s.dictionaryCache.Invalidate()
+	return nil
 }
 
 func (s *SysDictionaryUseCase) Update(id int, userMap map[string]interface{}) (*dictionaryDomain.DictionaryDetail, error) {
 	s.Logger.Info("Updating dictionary", zap.Int("id", id))
-	return s.sysDictionaryRepository.Update(id, userMap)
+	detail, err := s.sysDictionaryRepository.Update(id, userMap)
+	if err != nil {
+		return nil, err
+	}
+	s.dictionaryCache.Invalidate()
+	return detail, nil
 }
 
 func (s *SysDictionaryUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[dictionaryDomain.DictionaryDetail], error) {
@@ -0,0 +1,170 @@
+package invitation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainInvitation "github.com/gbrayhan/microservices-go/src/domain/sys/invitation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	invitationRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/invitation"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidInvitation 邀请不存在、已使用、已撤销、已过期或邮箱不匹配
+var ErrInvalidInvitation = errors.New("invalid or expired invitation")
+
+type ISysInvitationService interface {
+	Create(invitedBy int64, request CreateInvitationRequest) (*domainInvitation.CreatedInvitation, error)
+	GetAll() (*[]domainInvitation.Invitation, error)
+	Revoke(id int64) error
+	// Redeem 校验邀请并占用，注册完成后调用 Complete，失败时调用 Release
+	Redeem(token, email string) (*domainInvitation.Invitation, error)
+	Complete(id int64, userId int64) error
+	Release(id int64) error
+}
+
+type CreateInvitationRequest struct {
+	Email      string `json:"email" binding:"required"`
+	RoleID     int64  `json:"role_id" binding:"required"`
+	ExpireDays int    `json:"expire_days"`
+}
+
+type SysInvitationUseCase struct {
+	invitationRepository invitationRepo.ISysInvitationRepository
+	roleRepository       role.ISysRolesRepository
+	userRepository       user.UserRepositoryInterface
+	Logger               *logger.Logger
+}
+
+func NewSysInvitationUseCase(
+	invitationRepository invitationRepo.ISysInvitationRepository,
+	roleRepository role.ISysRolesRepository,
+	userRepository user.UserRepositoryInterface,
+	loggerInstance *logger.Logger,
+) ISysInvitationService {
+	return &SysInvitationUseCase{
+		invitationRepository: invitationRepository,
+		roleRepository:       roleRepository,
+		userRepository:       userRepository,
+		Logger:               loggerInstance,
+	}
+}
+
+// Create 为邮箱创建邀请，邮箱已注册时拒绝
+func (s *SysInvitationUseCase) Create(invitedBy int64, request CreateInvitationRequest) (*domainInvitation.CreatedInvitation, error) {
+	email := normalizeEmail(request.Email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, domainErrors.NewAppError(errors.New("invalid email address"), domainErrors.ValidationError)
+	}
+	s.Logger.Info("Creating invitation", zap.String("email", email), zap.Int64("invitedBy", invitedBy))
+
+	role, err := s.roleRepository.GetByID(int(request.RoleID))
+	if err != nil {
+		return nil, err
+	}
+	if role.IsSuper {
+		return nil, domainErrors.NewAppError(errors.New("super administrator role cannot be granted by invitation"), domainErrors.ValidationError)
+	}
+	existing, err := s.userRepository.GetOneByMap(map[string]interface{}{"email": email})
+	if err != nil {
+		return nil, err
+	}
+	if existing.ID != 0 {
+		return nil, domainErrors.NewAppError(errors.New("email is already registered"), domainErrors.ResourceAlreadyExists)
+	}
+
+	expireDays := request.ExpireDays
+	if expireDays <= 0 {
+		expireDays = sharedUtil.GetEnvAsInt("REGISTER_INVITATION_EXPIRE_DAYS", 7)
+	}
+	token, err := generateToken()
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+
+	created, err := s.invitationRepository.Create(&domainInvitation.Invitation{
+		Email:     email,
+		RoleID:    request.RoleID,
+		InvitedBy: invitedBy,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().AddDate(0, 0, expireDays),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domainInvitation.CreatedInvitation{
+		Invitation: *created,
+		Token:      token,
+		Link:       fmt.Sprintf("%s/#/auth/register?invitation=%s", sharedUtil.GetEnv("SERVER_FRONTEND_URL", ""), token),
+	}, nil
+}
+
+func (s *SysInvitationUseCase) GetAll() (*[]domainInvitation.Invitation, error) {
+	s.Logger.Info("Getting all invitations")
+	return s.invitationRepository.GetAll()
+}
+
+func (s *SysInvitationUseCase) Revoke(id int64) error {
+	s.Logger.Info("Revoking invitation", zap.Int64("id", id))
+	return s.invitationRepository.Revoke(id)
+}
+
+func (s *SysInvitationUseCase) Redeem(token, email string) (*domainInvitation.Invitation, error) {
+	invitation, err := s.invitationRepository.GetByTokenHash(hashToken(token))
+	if err != nil {
+		var appErr *domainErrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == domainErrors.NotFound {
+			return nil, domainErrors.NewAppError(ErrInvalidInvitation, domainErrors.NotAuthorized)
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !invitation.IsUsable(now) || !strings.EqualFold(invitation.Email, normalizeEmail(email)) {
+		s.Logger.Warn("Invitation rejected", zap.Int64("id", invitation.ID), zap.String("email", email))
+		return nil, domainErrors.NewAppError(ErrInvalidInvitation, domainErrors.NotAuthorized)
+	}
+	claimed, err := s.invitationRepository.Claim(invitation.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, domainErrors.NewAppError(ErrInvalidInvitation, domainErrors.NotAuthorized)
+	}
+	return invitation, nil
+}
+
+func (s *SysInvitationUseCase) Complete(id int64, userId int64) error {
+	return s.invitationRepository.SetAcceptedUser(id, userId)
+}
+
+func (s *SysInvitationUseCase) Release(id int64) error {
+	return s.invitationRepository.Release(id)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken 数据库只保存令牌的 sha256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,16 +1,24 @@
 package menu
 
 import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
 	"github.com/gbrayhan/microservices-go/src/domain"
 	menuDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
 	menuBtnDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu_btn"
+	translationDomain "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 
 	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
 	menuGroupRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_group"
+	menuApiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
 	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
 	userRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
 	"go.uber.org/zap"
 )
 
@@ -23,7 +31,11 @@ type ISysMenuService interface {
 	SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[menuDomain.Menu], error)
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*menuDomain.Menu, error)
-	GetUserMenus(roleId int64) ([]*menuDomain.MenuGroup, error)
+	GetUserMenus(roleIds []int64) ([]*menuDomain.MenuGroup, error)
+	GetCachedUserMenus(roleIds []int64, locale string) (*menuDomain.UserMenus, error)
+	Move(request menuDomain.MenuMoveRequest) ([]*menuDomain.Menu, error)
+	GetMenuApis(menuId int64) (*menuDomain.MenuApis, error)
+	UpdateMenuApis(menuApis *menuDomain.MenuApis) error
 }
 
 type SysMenuUseCase struct {
@@ -32,6 +44,11 @@ type SysMenuUseCase struct {
 	sysRoleMenuRepository  roleMenuRepo.ISysRoleMenuRepository
 	sysMenuGroupRepository menuGroupRepo.MenuGroupRepositoryInterface
 	sysRoleBtnRepository   roleBtnRepo.ISysRoleBtnRepository
+	sysRoleRepository      roleRepo.ISysRolesRepository
+	menuApiRepository      menuApiRepo.IMenuApiRepository
+	enforcer               *casbin.Enforcer
+	userMenuCache          *cache.UserMenuCache
+	translationService     translationDomain.ITranslationService
 	Logger                 *logger.Logger
 }
 
@@ -41,6 +58,11 @@ func NewSysMenuUseCase(
 	userRepository userRepo.UserRepositoryInterface,
 	sysMenuGroupRepository menuGroupRepo.MenuGroupRepositoryInterface,
 	sysRoleBtnRepository roleBtnRepo.ISysRoleBtnRepository,
+	sysRoleRepository roleRepo.ISysRolesRepository,
+	menuApiRepository menuApiRepo.IMenuApiRepository,
+	enforcer *casbin.Enforcer,
+	userMenuCache *cache.UserMenuCache,
+	translationService translationDomain.ITranslationService,
 	loggerInstance *logger.Logger,
 ) ISysMenuService {
 	return &SysMenuUseCase{
@@ -49,6 +71,11 @@ func NewSysMenuUseCase(
 		sysRoleMenuRepository:  sysRoleMenuRepository,
 		sysMenuGroupRepository: sysMenuGroupRepository,
 		sysRoleBtnRepository:   sysRoleBtnRepository,
+		sysRoleRepository:      sysRoleRepository,
+		menuApiRepository:      menuApiRepository,
+		enforcer:               enforcer,
+		userMenuCache:          userMenuCache,
+		translationService:     translationService,
 		Logger:                 loggerInstance,
 	}
 }
@@ -69,17 +96,46 @@ func (s *SysMenuUseCase) GetByID(id int) (*menuDomain.Menu, error) {
 
 func (s *SysMenuUseCase) Create(newMenu *menuDomain.Menu) (*menuDomain.Menu, error) {
 	s.Logger.Info("Creating new menu", zap.String("path", newMenu.Path))
-	return s.sysMenuRepository.Create(newMenu)
+	menu, err := s.sysMenuRepository.Create(newMenu)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return menu, nil
 }
 
 func (s *SysMenuUseCase) Delete(id int) error {
 	s.Logger.Info("Deleting menu", zap.Int("id", id))
-	return s.sysMenuRepository.Delete(id)
+	if err := s.sysMenuRepository.Delete(id); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	// 回收该菜单及其按钮带来的接口授权
+	if err := s.menuApiRepository.SyncMenuRoles(int64(id)); err != nil {
+		return err
+	}
+	return sharedUtil.ReloadCasbinPolicy(s.enforcer)
 }
 
 func (s *SysMenuUseCase) Update(id int, userMap map[string]interface{}) (*menuDomain.Menu, error) {
 	s.Logger.Info("Updating menu", zap.Int("id", id))
-	return s.sysMenuRepository.Update(id, userMap)
+	parentId, parentChanged := menuIdFromMap(userMap, "parent_id")
+	groupId, groupChanged := menuIdFromMap(userMap, "menu_group_id")
+	var menu *menuDomain.Menu
+	var err error
+	if parentChanged || groupChanged {
+		// 父级或分组变化时校验、更新和层级校正在同一事务中完成
+		menu, err = s.sysMenuRepository.UpdateInTree(id, userMap, func(menus []menuDomain.Menu) ([]menuDomain.MenuPosition, error) {
+			return planMenuUpdate(menus, id, parentId, parentChanged, groupId, groupChanged)
+		})
+	} else {
+		menu, err = s.sysMenuRepository.Update(id, userMap)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return menu, nil
 }
 
 func (s *SysMenuUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[menuDomain.Menu], error) {
@@ -100,23 +156,48 @@ func (s *SysMenuUseCase) GetOneByMap(userMap map[string]interface{}) (*menuDomai
 	return s.sysMenuRepository.GetOneByMap(userMap)
 }
 
-// GetUserMenus
-func (s *SysMenuUseCase) GetUserMenus(roleId int64) ([]*menuDomain.MenuGroup, error) {
-	s.Logger.Info("Getting user menus", zap.Int64("roleId", roleId))
-	var roleMenuIds []int
+// GetUserMenus 返回多个角色菜单和按钮的并集，roleIds 为空时返回全部菜单
+func (s *SysMenuUseCase) GetUserMenus(roleIds []int64) ([]*menuDomain.MenuGroup, error) {
+	s.Logger.Info("Getting user menus", zap.Int64s("roleIds", roleIds))
+	// 超级管理员角色与全部菜单列表相同，拥有所有菜单和按钮
+	isSuper, err := s.sysRoleRepository.IsSuperRole(roleIds...)
+	if err != nil {
+		return nil, err
+	}
+	var roleId int64
+	if !isSuper && len(roleIds) > 0 {
+		roleId = roleIds[0]
+	}
+	roleMenuIds := []int{}
 	// role bind menu list
 	var roleBtns []*roleBtnRepo.SysRoleBtn
-	var err error
-	if roleId == 0 { // role setting list
-		roleMenuIds = []int{}
-	} else { // get user menu
-		roleMenuIds, err = s.sysRoleMenuRepository.GetByRoleId(roleId)
-		if err != nil {
-			return nil, err
-		}
-		roleBtns, err = s.sysRoleBtnRepository.GetByRoleId(roleId)
-		if err != nil {
-			return nil, err
+	// 任一角色未限制按钮的菜单保留全部按钮
+	unrestrictedMenus := make(map[int64]bool)
+	if roleId != 0 { // get user menu, role setting list otherwise
+		seenMenus := make(map[int]bool)
+		for _, id := range roleIds {
+			menuIds, err := s.sysRoleMenuRepository.GetByRoleId(id)
+			if err != nil {
+				return nil, err
+			}
+			btns, err := s.sysRoleBtnRepository.GetByRoleId(id)
+			if err != nil {
+				return nil, err
+			}
+			restricted := make(map[int64]bool)
+			for _, btn := range btns {
+				restricted[btn.SysMenuID] = true
+			}
+			for _, menuId := range menuIds {
+				if !restricted[int64(menuId)] {
+					unrestrictedMenus[int64(menuId)] = true
+				}
+				if !seenMenus[menuId] {
+					seenMenus[menuId] = true
+					roleMenuIds = append(roleMenuIds, menuId)
+				}
+			}
+			roleBtns = append(roleBtns, btns...)
 		}
 	}
 	s.Logger.Info("getting role btns ", zap.Int("roleBtnsCount", len(roleBtns)))
@@ -139,7 +220,7 @@ func (s *SysMenuUseCase) GetUserMenus(roleId int64) ([]*menuDomain.MenuGroup, er
 		filteredMenuItems := make([]menuDomain.Menu, 0)
 		for _, menuItem := range *group.MenuItems {
 			filteredMenuItem := menuItem
-			if btnIds, exists := roleBtnMap[int64(menuItem.ID)]; exists && roleId != 0 {
+			if btnIds, exists := roleBtnMap[int64(menuItem.ID)]; exists && roleId != 0 && !unrestrictedMenus[int64(menuItem.ID)] {
 				// 过滤菜单按钮，只保留角色有权访问的按钮
 				filteredButtons := make([]menuBtnDomain.MenuBtn, 0)
 				btnSlices := make([]string, 0)
@@ -0,0 +1,20 @@
+package menu
+
+import (
+	menuDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+func (s *SysMenuUseCase) GetMenuApis(menuId int64) (*menuDomain.MenuApis, error) {
+	return s.menuApiRepository.GetByMenuId(menuId)
+}
+
+// UpdateMenuApis 替换菜单及其按钮依赖的接口，已拥有该菜单或按钮的角色立即获得或失去对应的接口策略
+func (s *SysMenuUseCase) UpdateMenuApis(menuApis *menuDomain.MenuApis) error {
+	s.Logger.Info("Updating menu apis", zap.Int64("menuId", menuApis.MenuID))
+	if err := s.menuApiRepository.Replace(menuApis); err != nil {
+		return err
+	}
+	return sharedUtil.ReloadCasbinPolicy(s.enforcer)
+}
@@ -0,0 +1,222 @@
+package menu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	menuDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	"go.uber.org/zap"
+)
+
+var errMenuCycle = errors.New("menu cannot be moved under itself or one of its descendants")
+
+// Move 按请求中的树结构在一个事务中调整菜单的 parent_id、sort 和 menu_level，返回调整后的菜单树
+func (s *SysMenuUseCase) Move(request menuDomain.MenuMoveRequest) ([]*menuDomain.Menu, error) {
+	s.Logger.Info("Moving menus", zap.Int("groupId", request.MenuGroupId), zap.Int("parentId", request.ParentID))
+	menus, err := s.sysMenuRepository.GetAll(0)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := planMenuMoves(*menus, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(positions) > 0 {
+		if err := s.sysMenuRepository.UpdatePositions(positions); err != nil {
+			return nil, err
+		}
+		s.userMenuCache.Invalidate(context.Background())
+	}
+	return s.GetAll(request.MenuGroupId)
+}
+
+// planMenuUpdate 校验单个菜单修改父级或分组后的树，返回需要校正父级和层级的菜单。
+// 分组变化时父菜单必须属于新分组，有子菜单的菜单不能单独改分组
+func planMenuUpdate(menus []menuDomain.Menu, id int, parentId int, parentChanged bool, groupId int, groupChanged bool) ([]menuDomain.MenuPosition, error) {
+	byId := make(map[int]menuDomain.Menu, len(menus))
+	parents := make(map[int]int, len(menus))
+	sorts := make(map[int]int8, len(menus))
+	for _, menu := range menus {
+		byId[menu.ID] = menu
+		parents[menu.ID] = menu.ParentID
+		sorts[menu.ID] = menu.Sort
+	}
+	menu, exists := byId[id]
+	if !exists {
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	if !parentChanged {
+		parentId = menu.ParentID
+	}
+	if !groupChanged {
+		groupId = menu.MenuGroupId
+	}
+	if groupId != menu.MenuGroupId {
+		for _, child := range menus {
+			if child.ParentID == id {
+				return nil, validationError("menu %d has children and cannot be moved to another group", id)
+			}
+		}
+	}
+	if parentId != 0 {
+		parent, exists := byId[parentId]
+		if !exists {
+			return nil, validationError("parent menu %d not found", parentId)
+		}
+		if parent.MenuGroupId != groupId {
+			return nil, validationError("menu %d cannot be placed under menu %d of another group", id, parentId)
+		}
+	}
+	parents[id] = parentId
+	if hasMenuCycle(parents, id) {
+		return nil, domainErrors.NewAppError(errMenuCycle, domainErrors.ValidationError)
+	}
+	return menuPositions(menus, parents, sorts), nil
+}
+
+// siblingKey 同一父菜单下的兄弟菜单，顶层菜单按分组区分
+type siblingKey struct {
+	parentId int
+	groupId  int
+}
+
+// planMenuMoves 校验请求并返回位置发生变化的菜单。未出现在请求中的菜单保持原父级，
+// 受影响父菜单下的全部子菜单重新编号：请求中的菜单按请求顺序在前，其余按原排序在后
+func planMenuMoves(menus []menuDomain.Menu, request menuDomain.MenuMoveRequest) ([]menuDomain.MenuPosition, error) {
+	byId := make(map[int]menuDomain.Menu, len(menus))
+	parents := make(map[int]int, len(menus))
+	sorts := make(map[int]int8, len(menus))
+	for _, menu := range menus {
+		byId[menu.ID] = menu
+		parents[menu.ID] = menu.ParentID
+		sorts[menu.ID] = menu.Sort
+	}
+	if request.ParentID != 0 {
+		parent, ok := byId[request.ParentID]
+		if !ok {
+			return nil, validationError("parent menu %d not found", request.ParentID)
+		}
+		if request.MenuGroupId != 0 && parent.MenuGroupId != request.MenuGroupId {
+			return nil, validationError("menu %d does not belong to menu group %d", parent.ID, request.MenuGroupId)
+		}
+	}
+
+	seen := make(map[int]bool)
+	placed := make(map[siblingKey][]int)
+	affected := make(map[siblingKey]bool)
+	var place func(nodes []menuDomain.MenuMoveNode, parentId int) error
+	place = func(nodes []menuDomain.MenuMoveNode, parentId int) error {
+		for _, node := range nodes {
+			menu, ok := byId[node.ID]
+			if !ok {
+				return validationError("menu %d not found", node.ID)
+			}
+			if seen[node.ID] {
+				return validationError("menu %d appears more than once", node.ID)
+			}
+			seen[node.ID] = true
+			if request.MenuGroupId != 0 && menu.MenuGroupId != request.MenuGroupId {
+				return validationError("menu %d does not belong to menu group %d", node.ID, request.MenuGroupId)
+			}
+			if parentId != 0 && byId[parentId].MenuGroupId != menu.MenuGroupId {
+				return validationError("menu %d cannot be moved under menu %d of another group", node.ID, parentId)
+			}
+			key := siblingKey{parentId: parentId, groupId: menu.MenuGroupId}
+			placed[key] = append(placed[key], node.ID)
+			affected[key] = true
+			affected[siblingKey{parentId: menu.ParentID, groupId: menu.MenuGroupId}] = true
+			parents[node.ID] = parentId
+			if err := place(node.Children, node.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := place(request.Items, request.ParentID); err != nil {
+		return nil, err
+	}
+	for id := range seen {
+		if hasMenuCycle(parents, id) {
+			return nil, domainErrors.NewAppError(errMenuCycle, domainErrors.ValidationError)
+		}
+	}
+
+	rest := make(map[siblingKey][]menuDomain.Menu)
+	for _, menu := range menus {
+		key := siblingKey{parentId: parents[menu.ID], groupId: menu.MenuGroupId}
+		if affected[key] && !seen[menu.ID] {
+			rest[key] = append(rest[key], menu)
+		}
+	}
+	for key := range affected {
+		children := rest[key]
+		sort.SliceStable(children, func(i, j int) bool {
+			if children[i].Sort != children[j].Sort {
+				return children[i].Sort < children[j].Sort
+			}
+			return children[i].ID < children[j].ID
+		})
+		ids := placed[key]
+		for _, child := range children {
+			ids = append(ids, child.ID)
+		}
+		if len(ids) > math.MaxInt8 {
+			return nil, validationError("a menu can have at most %d children", math.MaxInt8)
+		}
+		for i, id := range ids {
+			sorts[id] = int8(i + 1)
+		}
+	}
+	return menuPositions(menus, parents, sorts), nil
+}
+
+// menuPositions 返回父级、排序或层级与数据库不一致的菜单，父菜单不存在的菜单视为顶层
+func menuPositions(menus []menuDomain.Menu, parents map[int]int, sorts map[int]int8) []menuDomain.MenuPosition {
+	positions := make([]menuDomain.MenuPosition, 0)
+	for _, menu := range menus {
+		level := 0
+		for current := parents[menu.ID]; current != 0 && level <= len(parents); current = parents[current] {
+			if _, exists := parents[current]; !exists {
+				break
+			}
+			level++
+		}
+		position := menuDomain.MenuPosition{ID: menu.ID, ParentID: parents[menu.ID], Sort: sorts[menu.ID], MenuLevel: level}
+		if position.ParentID != menu.ParentID || position.Sort != menu.Sort || position.MenuLevel != menu.MenuLevel {
+			positions = append(positions, position)
+		}
+	}
+	return positions
+}
+
+func hasMenuCycle(parents map[int]int, id int) bool {
+	for current, depth := parents[id], 0; current != 0 && depth <= len(parents); current, depth = parents[current], depth+1 {
+		if current == id {
+			return true
+		}
+	}
+	return false
+}
+
+// menuIdFromMap 解析更新请求中的 parent_id、menu_group_id 等编号字段，JSON 数字为 float64
+func menuIdFromMap(updateMap map[string]interface{}, key string) (int, bool) {
+	switch value := updateMap[key].(type) {
+	case float64:
+		return int(value), true
+	case int:
+		return value, true
+	case string:
+		id, err := strconv.Atoi(value)
+		return id, err == nil
+	}
+	return 0, false
+}
+
+func validationError(format string, args ...interface{}) error {
+	return domainErrors.NewAppError(fmt.Errorf(format, args...), domainErrors.ValidationError)
+}
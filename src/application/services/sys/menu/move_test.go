@@ -0,0 +1,116 @@
+package menu
+
+import (
+	"testing"
+
+	menuDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanMenuMoves(t *testing.T) {
+	menus := []menuDomain.Menu{
+		{ID: 1, ParentID: 0, Sort: 1, MenuLevel: 0, MenuGroupId: 1},
+		{ID: 2, ParentID: 1, Sort: 1, MenuLevel: 1, MenuGroupId: 1},
+		{ID: 3, ParentID: 2, Sort: 1, MenuLevel: 2, MenuGroupId: 1},
+		{ID: 4, ParentID: 0, Sort: 2, MenuLevel: 0, MenuGroupId: 1},
+		{ID: 5, ParentID: 0, Sort: 1, MenuLevel: 0, MenuGroupId: 2},
+	}
+
+	// 2 移到顶层并排在 1 前面，子菜单 3 的层级随之变化
+	positions, err := planMenuMoves(menus, menuDomain.MenuMoveRequest{
+		Items: []menuDomain.MenuMoveNode{{ID: 2, Children: []menuDomain.MenuMoveNode{{ID: 3}}}, {ID: 1}, {ID: 4}},
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []menuDomain.MenuPosition{
+		{ID: 1, ParentID: 0, Sort: 2, MenuLevel: 0},
+		{ID: 2, ParentID: 0, Sort: 1, MenuLevel: 0},
+		{ID: 3, ParentID: 2, Sort: 1, MenuLevel: 1},
+		{ID: 4, ParentID: 0, Sort: 3, MenuLevel: 0},
+	}, positions)
+
+	positions, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{ParentID: 1, Items: []menuDomain.MenuMoveNode{{ID: 2}}})
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+
+	_, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{ParentID: 3, Items: []menuDomain.MenuMoveNode{{ID: 1}}})
+	assert.EqualError(t, err, errMenuCycle.Error())
+
+	_, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{ParentID: 1, Items: []menuDomain.MenuMoveNode{{ID: 5}}})
+	assert.Error(t, err)
+
+	_, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{MenuGroupId: 2, Items: []menuDomain.MenuMoveNode{{ID: 4}}})
+	assert.Error(t, err)
+
+	_, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{Items: []menuDomain.MenuMoveNode{{ID: 4, Children: []menuDomain.MenuMoveNode{{ID: 4}}}}})
+	assert.Error(t, err)
+
+	_, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{Items: []menuDomain.MenuMoveNode{{ID: 9}}})
+	assert.Error(t, err)
+}
+
+func TestPlanMenuMovesRenumbersSiblings(t *testing.T) {
+	menus := []menuDomain.Menu{
+		{ID: 1, ParentID: 0, Sort: 1, MenuGroupId: 1},
+		{ID: 2, ParentID: 1, Sort: 1, MenuLevel: 1, MenuGroupId: 1},
+		{ID: 3, ParentID: 1, Sort: 2, MenuLevel: 1, MenuGroupId: 1},
+		{ID: 4, ParentID: 1, Sort: 3, MenuLevel: 1, MenuGroupId: 1},
+		{ID: 5, ParentID: 0, Sort: 2, MenuGroupId: 1},
+		{ID: 6, ParentID: 0, Sort: 1, MenuGroupId: 2},
+	}
+
+	// 只提交 4 排到 1 下第一位，未提交的 2、3 依次后移
+	positions, err := planMenuMoves(menus, menuDomain.MenuMoveRequest{ParentID: 1, Items: []menuDomain.MenuMoveNode{{ID: 4}}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []menuDomain.MenuPosition{
+		{ID: 2, ParentID: 1, Sort: 2, MenuLevel: 1},
+		{ID: 3, ParentID: 1, Sort: 3, MenuLevel: 1},
+		{ID: 4, ParentID: 1, Sort: 1, MenuLevel: 1},
+	}, positions)
+
+	// 3 移到顶层首位，原父菜单下的 4 补位，顶层的 1、5 后移，其他分组不受影响
+	positions, err = planMenuMoves(menus, menuDomain.MenuMoveRequest{Items: []menuDomain.MenuMoveNode{{ID: 3}}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []menuDomain.MenuPosition{
+		{ID: 1, ParentID: 0, Sort: 2, MenuLevel: 0},
+		{ID: 3, ParentID: 0, Sort: 1, MenuLevel: 0},
+		{ID: 4, ParentID: 1, Sort: 2, MenuLevel: 1},
+		{ID: 5, ParentID: 0, Sort: 3, MenuLevel: 0},
+	}, positions)
+}
+
+func TestPlanMenuUpdate(t *testing.T) {
+	menus := []menuDomain.Menu{
+		{ID: 1, ParentID: 0, Sort: 1, MenuLevel: 0, MenuGroupId: 1},
+		{ID: 2, ParentID: 1, Sort: 1, MenuLevel: 1, MenuGroupId: 1},
+		{ID: 3, ParentID: 2, Sort: 1, MenuLevel: 2, MenuGroupId: 1},
+		{ID: 4, ParentID: 0, Sort: 1, MenuLevel: 0, MenuGroupId: 2},
+	}
+
+	positions, err := planMenuUpdate(menus, 2, 0, true, 0, false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []menuDomain.MenuPosition{
+		{ID: 2, ParentID: 0, Sort: 1, MenuLevel: 0},
+		{ID: 3, ParentID: 2, Sort: 1, MenuLevel: 1},
+	}, positions)
+
+	_, err = planMenuUpdate(menus, 1, 3, true, 0, false)
+	assert.EqualError(t, err, errMenuCycle.Error())
+
+	_, err = planMenuUpdate(menus, 2, 4, true, 0, false)
+	assert.Error(t, err)
+
+	// 只改分组时父菜单仍在原分组
+	_, err = planMenuUpdate(menus, 3, 0, false, 2, true)
+	assert.Error(t, err)
+
+	// 有子菜单的菜单不能单独改分组
+	_, err = planMenuUpdate(menus, 2, 0, true, 2, true)
+	assert.Error(t, err)
+
+	positions, err = planMenuUpdate(menus, 3, 4, true, 2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []menuDomain.MenuPosition{{ID: 3, ParentID: 4, Sort: 1, MenuLevel: 1}}, positions)
+
+	_, err = planMenuUpdate(menus, 9, 0, true, 0, false)
+	assert.Error(t, err)
+}
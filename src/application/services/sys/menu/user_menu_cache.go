@@ -0,0 +1,68 @@
+package menu
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	menuDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	translationDomain "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
+)
+
+// GetCachedUserMenus 优先读取 Redis 中按角色组合缓存的菜单，未命中时计算并写入缓存。
+// 缓存中是原文，翻译在读取后进行，ETag 按翻译后的内容重新计算
+func (s *SysMenuUseCase) GetCachedUserMenus(roleIds []int64, locale string) (*menuDomain.UserMenus, error) {
+	userMenus, err := s.getCachedUserMenus(roleIds)
+	if err != nil || locale == "" || s.translationService == nil {
+		return userMenus, err
+	}
+	for _, group := range userMenus.Groups {
+		s.translateMenus(group.Items, locale)
+	}
+	etag, err := menuETag(userMenus.Groups)
+	if err != nil {
+		return nil, err
+	}
+	userMenus.ETag = etag
+	return userMenus, nil
+}
+
+func (s *SysMenuUseCase) getCachedUserMenus(roleIds []int64) (*menuDomain.UserMenus, error) {
+	ctx := context.Background()
+	if cached := s.userMenuCache.Get(ctx, roleIds); cached != nil {
+		return cached, nil
+	}
+	groups, err := s.GetUserMenus(roleIds)
+	if err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		groups = []*menuDomain.MenuGroup{}
+	}
+	etag, err := menuETag(groups)
+	if err != nil {
+		return nil, err
+	}
+	userMenus := &menuDomain.UserMenus{ETag: etag, Groups: groups}
+	s.userMenuCache.Set(ctx, roleIds, userMenus)
+	return userMenus, nil
+}
+
+// translateMenus 按菜单 name 翻译标题
+func (s *SysMenuUseCase) translateMenus(menus []*menuDomain.Menu, locale string) {
+	for _, menu := range menus {
+		menu.Title = s.translationService.Translate(locale, translationDomain.ScopeMenu, menu.Name, menu.Title)
+		s.translateMenus(menu.Children, locale)
+	}
+}
+
+// menuETag 菜单内容的强校验 ETag
+func menuETag(groups []*menuDomain.MenuGroup) (string, error) {
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
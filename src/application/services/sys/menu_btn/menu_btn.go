@@ -1,8 +1,14 @@
 package menu_btn
 
 import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	menuApiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
 	menuBtnDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu_btn"
@@ -22,12 +28,18 @@ type IMenuBtnService interface {
 
 type MenuBtnUseCase struct {
 	sysMenuBtnRepository menuBtnRepo.MenuBtnRepositoryInterface
+	menuApiRepository    menuApiRepo.IMenuApiRepository
+	enforcer             *casbin.Enforcer
+	userMenuCache        *cache.UserMenuCache
 	Logger               *logger.Logger
 }
 
-func NewMenuBtnUseCase(sysMenuBtnRepository menuBtnRepo.MenuBtnRepositoryInterface, loggerInstance *logger.Logger) IMenuBtnService {
+func NewMenuBtnUseCase(sysMenuBtnRepository menuBtnRepo.MenuBtnRepositoryInterface, menuApiRepository menuApiRepo.IMenuApiRepository, enforcer *casbin.Enforcer, userMenuCache *cache.UserMenuCache, loggerInstance *logger.Logger) IMenuBtnService {
 	return &MenuBtnUseCase{
 		sysMenuBtnRepository: sysMenuBtnRepository,
+		menuApiRepository:    menuApiRepository,
+		enforcer:             enforcer,
+		userMenuCache:        userMenuCache,
 		Logger:               loggerInstance,
 	}
 }
@@ -44,17 +56,39 @@ func (s *MenuBtnUseCase) GetByID(id int) (*menuBtnDomain.MenuBtn, error) {
 
 func (s *MenuBtnUseCase) Create(newMenuBtn *menuBtnDomain.MenuBtn) (*menuBtnDomain.MenuBtn, error) {
 	s.Logger.Info("Creating new menuBtn", zap.String("Name", newMenuBtn.Name))
-	return s.sysMenuBtnRepository.Create(newMenuBtn)
+	result, err := s.sysMenuBtnRepository.Create(newMenuBtn)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return result, nil
 }
 
 func (s *MenuBtnUseCase) Delete(id int) error {
 	s.Logger.Info("Deleting menuBtn", zap.Int("id", id))
-	return s.sysMenuBtnRepository.Delete(id)
+	menuBtn, err := s.sysMenuBtnRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.sysMenuBtnRepository.Delete(id); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	// 回收该按钮带来的接口授权
+	if err := s.menuApiRepository.SyncMenuRoles(menuBtn.SysBaseMenuID); err != nil {
+		return err
+	}
+	return sharedUtil.ReloadCasbinPolicy(s.enforcer)
 }
 
 func (s *MenuBtnUseCase) Update(id int, userMap map[string]interface{}) (*menuBtnDomain.MenuBtn, error) {
 	s.Logger.Info("Updating menuBtn", zap.Int("id", id))
-	return s.sysMenuBtnRepository.Update(id, userMap)
+	result, err := s.sysMenuBtnRepository.Update(id, userMap)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return result, nil
 }
 
 func (s *MenuBtnUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[menuBtnDomain.MenuBtn], error) {
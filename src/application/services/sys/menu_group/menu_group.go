@@ -1,10 +1,12 @@
 package menu_group
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
 	menuGroupDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu_group"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	menuGroupRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_group"
 	"go.uber.org/zap"
@@ -23,12 +25,14 @@ type ISysMenuGroupService interface {
 
 type SysMenuGroupUseCase struct {
 	sysMenuGroupRepository menuGroupRepo.MenuGroupRepositoryInterface
+	userMenuCache          *cache.UserMenuCache
 	Logger                 *logger.Logger
 }
 
-func NewSysMenuGroupUseCase(sysMenuGroupRepository menuGroupRepo.MenuGroupRepositoryInterface, loggerInstance *logger.Logger) ISysMenuGroupService {
+func NewSysMenuGroupUseCase(sysMenuGroupRepository menuGroupRepo.MenuGroupRepositoryInterface, userMenuCache *cache.UserMenuCache, loggerInstance *logger.Logger) ISysMenuGroupService {
 	return &SysMenuGroupUseCase{
 		sysMenuGroupRepository: sysMenuGroupRepository,
+		userMenuCache:          userMenuCache,
 		Logger:                 loggerInstance,
 	}
 }
@@ -45,17 +49,31 @@ func (s *SysMenuGroupUseCase) GetByID(id int) (*menuGroupDomain.MenuGroup, error
 
 func (s *SysMenuGroupUseCase) Create(newMenuGroup *menuGroupDomain.MenuGroup) (*menuGroupDomain.MenuGroup, error) {
 	s.Logger.Info("Creating new menuGroup", zap.String("Name", newMenuGroup.Name))
-	return s.sysMenuGroupRepository.Create(newMenuGroup)
+	result, err := s.sysMenuGroupRepository.Create(newMenuGroup)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return result, nil
 }
 
 func (s *SysMenuGroupUseCase) Delete(ids []int) error {
 	s.Logger.Info("Deleting menuGroup", zap.String("ids", fmt.Sprintf("%v", ids)))
-	return s.sysMenuGroupRepository.Delete(ids)
+	if err := s.sysMenuGroupRepository.Delete(ids); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return nil
 }
 
 func (s *SysMenuGroupUseCase) Update(id int, userMap map[string]interface{}) (*menuGroupDomain.MenuGroup, error) {
 	s.Logger.Info("Updating menuGroup", zap.Int("id", id))
-	return s.sysMenuGroupRepository.Update(id, userMap)
+	result, err := s.sysMenuGroupRepository.Update(id, userMap)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return result, nil
 }
 
 func (s *SysMenuGroupUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[menuGroupDomain.MenuGroup], error) {
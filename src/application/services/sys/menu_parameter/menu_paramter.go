@@ -1,6 +1,9 @@
 package menu_parameter
 
 import (
+	"context"
+
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	menuParameterRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_parameter"
 
@@ -22,12 +25,14 @@ type IMenuParameterService interface {
 
 type MenuParameterUseCase struct {
 	menuParameterRepository menuParameterRepo.MenuParameterRepositoryInterface
+	userMenuCache           *cache.UserMenuCache
 	Logger                  *logger.Logger
 }
 
-func NewMenuParameterUseCase(menuParameterRepository menuParameterRepo.MenuParameterRepositoryInterface, loggerInstance *logger.Logger) IMenuParameterService {
+func NewMenuParameterUseCase(menuParameterRepository menuParameterRepo.MenuParameterRepositoryInterface, userMenuCache *cache.UserMenuCache, loggerInstance *logger.Logger) IMenuParameterService {
 	return &MenuParameterUseCase{
 		menuParameterRepository: menuParameterRepository,
+		userMenuCache:           userMenuCache,
 		Logger:                  loggerInstance,
 	}
 }
@@ -44,17 +49,31 @@ func (s *MenuParameterUseCase) GetByID(id int) (*menuParameterDomain.MenuParamet
 
 func (s *MenuParameterUseCase) Create(newMenuParameter *menuParameterDomain.MenuParameter) (*menuParameterDomain.MenuParameter, error) {
 	s.Logger.Info("Creating new menuParameter", zap.String("Key", newMenuParameter.Key))
-	return s.menuParameterRepository.Create(newMenuParameter)
+	result, err := s.menuParameterRepository.Create(newMenuParameter)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return result, nil
 }
 
 func (s *MenuParameterUseCase) Delete(id int) error {
 	s.Logger.Info("Deleting menuParameter", zap.Int("id", id))
-	return s.menuParameterRepository.Delete(id)
+	if err := s.menuParameterRepository.Delete(id); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return nil
 }
 
 func (s *MenuParameterUseCase) Update(id int, userMap map[string]interface{}) (*menuParameterDomain.MenuParameter, error) {
 	s.Logger.Info("Updating menuParameter", zap.Int("id", id))
-	return s.menuParameterRepository.Update(id, userMap)
+	result, err := s.menuParameterRepository.Update(id, userMap)
+	if err != nil {
+		return nil, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return result, nil
 }
 
 func (s *MenuParameterUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[menuParameterDomain.MenuParameter], error) {
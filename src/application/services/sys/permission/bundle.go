@@ -0,0 +1,47 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// ExportBundle 导出菜单、接口、角色及其绑定，可以导入到其他环境
+func (s *PermissionUseCase) ExportBundle() (*domainPermission.Bundle, error) {
+	bundle, err := s.bundleRepository.Export()
+	if err != nil {
+		return nil, err
+	}
+	bundle.Version = domainPermission.BundleVersion
+	bundle.ExportedAt = time.Now()
+	s.Logger.Info("Permission bundle exported",
+		zap.Int("menus", len(bundle.Menus)),
+		zap.Int("apis", len(bundle.Apis)),
+		zap.Int("roles", len(bundle.Roles)))
+	return bundle, nil
+}
+
+// ImportBundle 重复导入同一个权限包不会产生变化；dryRun 时只返回差异。只有超级管理员可以修改角色的 is_super
+func (s *PermissionUseCase) ImportBundle(bundle *domainPermission.Bundle, dryRun bool, operatorRoleIds []int64) (*domainPermission.BundleImportResult, error) {
+	if bundle.Version < 1 || bundle.Version > domainPermission.BundleVersion {
+		return nil, domainErrors.NewAppError(fmt.Errorf("unsupported bundle version %d", bundle.Version), domainErrors.ValidationError)
+	}
+	isSuper, err := s.roleRepository.IsSuperRole(operatorRoleIds...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.bundleRepository.Import(bundle, dryRun, isSuper)
+	if err != nil || dryRun || len(result.Changes) == 0 {
+		return result, err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	if err := sharedUtil.ReloadCasbinPolicy(s.enforcer); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
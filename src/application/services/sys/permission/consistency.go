@@ -0,0 +1,134 @@
+package permission
+
+import (
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// CheckConsistency 列出角色或接口已不存在、以及重复的 casbin_rule 记录
+func (s *PermissionUseCase) CheckConsistency() (*domainPermission.ConsistencyReport, error) {
+	rules, err := s.casbinRuleRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepository.GetAll(0)
+	if err != nil {
+		return nil, err
+	}
+	apis, err := s.apiRepository.GetAll("")
+	if err != nil {
+		return nil, err
+	}
+	roleIds := make(map[string]bool, len(*roles))
+	for _, role := range *roles {
+		roleIds[strconv.FormatInt(role.ID, 10)] = true
+	}
+	apiKeys := make(map[[2]string]bool, len(*apis))
+	for _, api := range *apis {
+		apiKeys[[2]string{api.Path, api.Method}] = true
+	}
+
+	report := &domainPermission.ConsistencyReport{Drifts: []domainPermission.PolicyDrift{}}
+	seen := make(map[[4]string]bool, len(rules))
+	for _, rule := range rules {
+		reason := ""
+		key := [4]string{rule.PType, rule.V0, rule.V1, rule.V2}
+		switch {
+		case seen[key]:
+			reason = domainPermission.DriftDuplicate
+		case rule.PType == "p" && !roleIds[rule.V0]:
+			reason = domainPermission.DriftRoleMissing
+		case rule.PType == "p" && !apiKeys[[2]string{rule.V1, rule.V2}]:
+			reason = domainPermission.DriftApiMissing
+		case rule.PType == "g" && (!roleIds[rule.V0] || !roleIds[rule.V1]):
+			reason = domainPermission.DriftRoleMissing
+		}
+		seen[key] = true
+		if reason != "" {
+			report.Drifts = append(report.Drifts, driftOf(rule, reason))
+		}
+	}
+	report.EnforcerInSync, err = s.enforcerInSync(rules)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// RepairConsistency 删除 CheckConsistency 列出的规则，同时清理对应的菜单关联接口标记
+func (s *PermissionUseCase) RepairConsistency() (*domainPermission.ConsistencyReport, error) {
+	report, err := s.CheckConsistency()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(report.Drifts))
+	for _, drift := range report.Drifts {
+		ids = append(ids, drift.ID)
+	}
+	if err := s.menuApiRepository.DeletePolicies(ids); err != nil {
+		return nil, err
+	}
+	if err := sharedUtil.ReloadCasbinPolicy(s.enforcer); err != nil {
+		return nil, err
+	}
+	s.Logger.Info("Repaired casbin policy drift", zap.Int("count", len(ids)))
+	report.EnforcerInSync = true
+	report.Repaired = true
+	return report, nil
+}
+
+// enforcerInSync 比较内存中的 p、g 规则与数据库记录
+func (s *PermissionUseCase) enforcerInSync(rules []casbinRepo.CasbinRule) (bool, error) {
+	stored := make(map[[4]string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.PType == "g" {
+			stored[[4]string{"g", rule.V0, rule.V1}] = true
+		} else {
+			stored[[4]string{rule.PType, rule.V0, rule.V1, rule.V2}] = true
+		}
+	}
+	loaded := make(map[[4]string]bool, len(stored))
+	policies, err := s.enforcer.GetPolicy()
+	if err != nil {
+		return false, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	for _, policy := range policies {
+		if len(policy) >= 3 {
+			loaded[[4]string{"p", policy[0], policy[1], policy[2]}] = true
+		}
+	}
+	groupings, err := s.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return false, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	for _, grouping := range groupings {
+		if len(grouping) >= 2 {
+			loaded[[4]string{"g", grouping[0], grouping[1]}] = true
+		}
+	}
+	if len(loaded) != len(stored) {
+		return false, nil
+	}
+	for key := range loaded {
+		if !stored[key] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func driftOf(rule casbinRepo.CasbinRule, reason string) domainPermission.PolicyDrift {
+	return domainPermission.PolicyDrift{
+		ID:     rule.ID,
+		PType:  rule.PType,
+		V0:     rule.V0,
+		V1:     rule.V1,
+		V2:     rule.V2,
+		Reason: reason,
+	}
+}
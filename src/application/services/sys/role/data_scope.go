@@ -0,0 +1,130 @@
+package role
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	deptDomain "github.com/gbrayhan/microservices-go/src/domain/sys/dept"
+	roleDomain "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+)
+
+var errInvalidDataScope = errors.New("data_scope must be one of all, self, dept, custom")
+
+// GetDataScope 合并用户全部角色的数据权限，超级管理员或任一角色为 all 时不限制
+func (s *SysRoleUseCase) GetDataScope(userId int64, roleIds []int64) (*domain.DataScope, error) {
+	roles, err := s.sysRoleRepository.GetByIDs(roleIds)
+	if err != nil {
+		return nil, err
+	}
+	scope := &domain.DataScope{UserID: userId}
+	for _, role := range *roles {
+		if role.IsSuper {
+			scope.All = true
+			return scope, nil
+		}
+		switch role.DataScope {
+		case domain.DataScopeAll, "":
+			scope.All = true
+			return scope, nil
+		case domain.DataScopeSelf:
+			scope.Self = true
+		case domain.DataScopeDept:
+			scope.Self = true
+			if scope.DeptUserIDs == nil {
+				if scope.DeptUserIDs, err = s.deptUserIDs(userId); err != nil {
+					return nil, err
+				}
+			}
+		case domain.DataScopeCustom:
+			if len(role.DataScopeFilter) > 0 {
+				scope.Custom = append(scope.Custom, role.DataScopeFilter)
+			}
+		}
+	}
+	return scope, nil
+}
+
+// deptUserIDs 用户所在部门的成员，负责人还能看到下级部门的成员
+func (s *SysRoleUseCase) deptUserIDs(userId int64) ([]int64, error) {
+	memberships, err := s.sysDeptRepository.GetUserDepts(userId)
+	if err != nil {
+		return nil, err
+	}
+	deptIds := make([]int64, 0, len(memberships))
+	var leadIds []int64
+	for _, membership := range memberships {
+		if membership.IsLeader {
+			leadIds = append(leadIds, membership.DeptID)
+		} else {
+			deptIds = append(deptIds, membership.DeptID)
+		}
+	}
+	if len(leadIds) > 0 {
+		depts, err := s.sysDeptRepository.GetAll(0)
+		if err != nil {
+			return nil, err
+		}
+		deptIds = append(deptIds, deptDomain.WithDescendants(*depts, leadIds)...)
+	}
+	members, err := s.sysDeptRepository.GetMembers(deptIds...)
+	if err != nil {
+		return nil, err
+	}
+	userIds := []int64{userId}
+	seen := map[int64]bool{userId: true}
+	for _, member := range members {
+		if !seen[member.UserID] {
+			seen[member.UserID] = true
+			userIds = append(userIds, member.UserID)
+		}
+	}
+	return userIds, nil
+}
+
+// normalizeDataScope 校验新建角色的数据权限，未设置时为 all
+func normalizeDataScope(role *roleDomain.Role) error {
+	if role.DataScope == "" {
+		role.DataScope = domain.DataScopeAll
+	}
+	if !validDataScope(role.DataScope) {
+		return domainErrors.NewAppError(errInvalidDataScope, domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// normalizeDataScopeUpdate 校验更新请求中的数据权限，并把自定义条件转换为保存的 JSON
+func normalizeDataScopeUpdate(updateMap map[string]interface{}) error {
+	if value, ok := updateMap["data_scope"]; ok {
+		dataScope, isString := value.(string)
+		if !isString || !validDataScope(dataScope) {
+			return domainErrors.NewAppError(errInvalidDataScope, domainErrors.ValidationError)
+		}
+	}
+	value, ok := updateMap["data_scope_filter"]
+	if !ok {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	var filter map[string][]string
+	if value != nil {
+		if err := json.Unmarshal(raw, &filter); err != nil {
+			return domainErrors.NewAppError(errors.New("data_scope_filter must map fields to value lists"), domainErrors.ValidationError)
+		}
+	}
+	updateMap["data_scope_filter"] = roleRepo.FormatDataScopeFilter(filter)
+	return nil
+}
+
+func validDataScope(dataScope string) bool {
+	switch dataScope {
+	case domain.DataScopeAll, domain.DataScopeSelf, domain.DataScopeDept, domain.DataScopeCustom:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+)
+
+// GetRoleFields 角色在各资源上隐藏的字段
+func (s *SysRoleUseCase) GetRoleFields(roleId int64) (map[string][]string, error) {
+	roleFields, err := s.sysRoleFieldRepo.GetByRoleIds([]int64{roleId})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]string)
+	for _, v := range roleFields {
+		result[v.Resource] = append(result[v.Resource], v.Field)
+	}
+	return result, nil
+}
+
+// BindRoleFields 设置角色在某个资源上隐藏的字段，请求格式 {"resource": "user", "fields": ["phone"]}
+func (s *SysRoleUseCase) BindRoleFields(roleId int64, updateMap map[string]interface{}) error {
+	resource, ok := updateMap["resource"].(string)
+	allowed, known := domain.MaskableFields[resource]
+	if !ok || !known {
+		return domainErrors.NewAppError(errors.New("unknown field resource"), domainErrors.ValidationError)
+	}
+	values, ok := updateMap["fields"].([]interface{})
+	if !ok && updateMap["fields"] != nil {
+		return domainErrors.NewAppError(errors.New("fields must be an array"), domainErrors.ValidationError)
+	}
+	fields := make([]string, 0, len(values))
+	for _, value := range values {
+		field, isString := value.(string)
+		if !isString || !containsField(allowed, field) {
+			return domainErrors.NewAppError(fmt.Errorf("field %v cannot be hidden on %s", value, resource), domainErrors.ValidationError)
+		}
+		fields = append(fields, field)
+	}
+	return s.sysRoleFieldRepo.Replace(roleId, resource, fields)
+}
+
+// GetHiddenFields 与按钮权限一致取并集，只有全部角色都隐藏的字段才会被隐藏，超级管理员不隐藏任何字段
+func (s *SysRoleUseCase) GetHiddenFields(roleIds []int64, resource string) (domain.FieldMask, error) {
+	mask := domain.FieldMask{}
+	if len(roleIds) == 0 {
+		return mask, nil
+	}
+	isSuper, err := s.IsSuperRole(roleIds...)
+	if err != nil || isSuper {
+		return mask, err
+	}
+	roleFields, err := s.sysRoleFieldRepo.GetByRoleIds(roleIds)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]map[int64]bool)
+	for _, v := range roleFields {
+		if v.Resource != resource {
+			continue
+		}
+		if counts[v.Field] == nil {
+			counts[v.Field] = make(map[int64]bool)
+		}
+		counts[v.Field][v.RoleID] = true
+	}
+	roles := make(map[int64]bool, len(roleIds))
+	for _, id := range roleIds {
+		roles[id] = true
+	}
+	for field, hiddenBy := range counts {
+		if len(hiddenBy) == len(roles) {
+			mask[field] = true
+		}
+	}
+	return mask, nil
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	"go.uber.org/zap"
+)
+
+// 角色继承使用 casbin g 规则：g, 子角色ID, 父角色ID，子角色继承父角色的全部接口权限
+
+var errRoleCycle = errors.New("parent role cannot be the role itself or one of its descendants")
+
+// syncRoleParent 重写角色的父级 g 规则
+func (s *SysRoleUseCase) syncRoleParent(roleId int64, parentId int64) error {
+	if s.enforcer == nil {
+		return nil
+	}
+	sub := strconv.FormatInt(roleId, 10)
+	if _, err := s.enforcer.RemoveFilteredGroupingPolicy(0, sub); err != nil {
+		s.Logger.Error("Error removing role inheritance", zap.Error(err), zap.Int64("roleId", roleId))
+		return domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	if parentId == 0 {
+		return nil
+	}
+	if _, err := s.enforcer.AddGroupingPolicy(sub, strconv.FormatInt(parentId, 10)); err != nil {
+		s.Logger.Error("Error adding role inheritance", zap.Error(err), zap.Int64("roleId", roleId), zap.Int64("parentId", parentId))
+		return domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	return nil
+}
+
+// SyncRoleInheritance 按 sys_roles.parent_id 全量校正 g 规则，启动时调用
+func (s *SysRoleUseCase) SyncRoleInheritance() error {
+	if s.enforcer == nil {
+		return nil
+	}
+	roles, err := s.sysRoleRepository.GetAll(0)
+	if err != nil {
+		return err
+	}
+	expected := make(map[[2]string]bool)
+	for _, role := range *roles {
+		if role.ParentID != 0 {
+			expected[[2]string{strconv.FormatInt(role.ID, 10), strconv.FormatInt(role.ParentID, 10)}] = true
+		}
+	}
+
+	current, err := s.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	removed, added := 0, 0
+	for _, rule := range current {
+		if len(rule) < 2 {
+			continue
+		}
+		key := [2]string{rule[0], rule[1]}
+		if expected[key] {
+			delete(expected, key)
+			continue
+		}
+		if _, err := s.enforcer.RemoveGroupingPolicy(rule[0], rule[1]); err != nil {
+			return domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		removed++
+	}
+	for key := range expected {
+		if _, err := s.enforcer.AddGroupingPolicy(key[0], key[1]); err != nil {
+			return domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		added++
+	}
+	s.Logger.Info("Role inheritance synchronized", zap.Int("added", added), zap.Int("removed", removed))
+	return nil
+}
+
+// checkParent 校验新的父角色存在且不会形成环
+func (s *SysRoleUseCase) checkParent(roleId int64, parentId int64) error {
+	if parentId == 0 {
+		return nil
+	}
+	if parentId == roleId {
+		return domainErrors.NewAppError(errRoleCycle, domainErrors.ValidationError)
+	}
+	roles, err := s.sysRoleRepository.GetAll(0)
+	if err != nil {
+		return err
+	}
+	parents := make(map[int64]int64, len(*roles))
+	for _, role := range *roles {
+		parents[role.ID] = role.ParentID
+	}
+	if _, exists := parents[parentId]; !exists {
+		return domainErrors.NewAppError(fmt.Errorf("parent role %d not found", parentId), domainErrors.ValidationError)
+	}
+	for current, depth := parentId, 0; current != 0 && depth <= len(parents); current, depth = parents[current], depth+1 {
+		if current == roleId {
+			return domainErrors.NewAppError(errRoleCycle, domainErrors.ValidationError)
+		}
+	}
+	return nil
+}
+
+// GetEffectiveApiRules 角色自身及所有祖先角色的接口权限，格式与 GetApiRuleList 相同
+func (s *SysRoleUseCase) GetEffectiveApiRules(roleId int) ([]string, error) {
+	roleIds := []int{roleId}
+	if s.enforcer != nil {
+		ancestors, err := s.enforcer.GetImplicitRolesForUser(strconv.Itoa(roleId))
+		if err != nil {
+			return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		for _, ancestor := range ancestors {
+			if id, err := strconv.Atoi(ancestor); err == nil {
+				roleIds = append(roleIds, id)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	rules := make([]string, 0)
+	for _, id := range roleIds {
+		roleRules, err := s.casbinRuleRepo.GetByRoleId(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range roleRules {
+			if !seen[rule] {
+				seen[rule] = true
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// parentIdFromMap 解析更新请求中的 parent_id，JSON 数字为 float64
+func parentIdFromMap(updateMap map[string]interface{}) (int64, bool) {
+	switch value := updateMap["parent_id"].(type) {
+	case float64:
+		return int64(value), true
+	case int64:
+		return value, true
+	case int:
+		return int64(value), true
+	case string:
+		id, err := strconv.ParseInt(value, 10, 64)
+		return id, err == nil
+	}
+	return 0, false
+}
@@ -1,17 +1,30 @@
 package role
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
 
+	"github.com/casbin/casbin/v2"
+
 	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
+	"github.com/gbrayhan/microservices-go/src/domain/constants"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	roleDomain "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	deptRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dept"
+	menuApiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	templateRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/permission_template"
 	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleFieldRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_field"
 	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
 	"go.uber.org/zap"
 )
 
@@ -31,16 +44,43 @@ type ISysRoleService interface {
 	UpdateRoleMenuIds(id int, updateMap map[string]any) error
 
 	GetApiRuleList(roleId int) ([]string, error)
+	GetEffectiveApiRules(roleId int) ([]string, error)
+	GetApiGrants(roleId int64) ([]roleDomain.ApiGrant, error)
 	BindApiRule(roleId int, updateMap map[string]interface{}) error
 	BindRoleMenuBtns(roleId int64, updateMap map[string]interface{}) error
+	GetRoleFields(roleId int64) (map[string][]string, error)
+	BindRoleFields(roleId int64, updateMap map[string]interface{}) error
+	GetHiddenFields(roleIds []int64, resource string) (domain.FieldMask, error)
+
+	IsSuperRole(roleIds ...int64) (bool, error)
+	GetDataScope(userId int64, roleIds []int64) (*domain.DataScope, error)
+	SyncRoleInheritance() error
+
+	CloneRole(sourceId int64, newRole *roleDomain.Role) (*roleDomain.Role, error)
+	GetTemplates() ([]roleDomain.PermissionTemplate, error)
+	GetTemplate(id int64) (*roleDomain.PermissionTemplate, error)
+	CreateTemplate(template *roleDomain.PermissionTemplate, fromRoleId int64) (*roleDomain.PermissionTemplate, error)
+	UpdateTemplate(id int64, template *roleDomain.PermissionTemplate) (*roleDomain.PermissionTemplate, error)
+	DeleteTemplate(id int64) error
+	DiffTemplate(roleId int64, templateId int64, mode string) (*roleDomain.PermissionDiff, error)
+	ApplyTemplate(roleId int64, templateId int64, mode string) (*roleDomain.PermissionDiff, error)
 }
 
+// ErrLastSuperAdmin 至少保留一个拥有超级管理员角色的用户
+var ErrLastSuperAdmin = errors.New("at least one super administrator must remain")
+
 type SysRoleUseCase struct {
 	sysRoleRepository     roleRepo.ISysRolesRepository
 	sysRoleMenuRepository roleMenuRepo.ISysRoleMenuRepository
 	sysMenuRepository     menuRepo.MenuRepositoryInterface
 	casbinRuleRepo        casbinRepo.ICasbinRuleRepository
 	sysRoleBtnRepo        roleBtnRepo.ISysRoleBtnRepository
+	sysDeptRepository     deptRepo.ISysDeptRepository
+	sysRoleFieldRepo      roleFieldRepo.ISysRoleFieldRepository
+	templateRepo          templateRepo.IPermissionTemplateRepository
+	menuApiRepo           menuApiRepo.IMenuApiRepository
+	userMenuCache         *cache.UserMenuCache
+	enforcer              *casbin.Enforcer
 
 	Logger *logger.Logger
 }
@@ -51,6 +91,12 @@ func NewSysRoleUseCase(
 	casbinRuleRepo casbinRepo.ICasbinRuleRepository,
 	sysMenuRepository menuRepo.MenuRepositoryInterface,
 	sysRoleBtnRepo roleBtnRepo.ISysRoleBtnRepository,
+	sysDeptRepository deptRepo.ISysDeptRepository,
+	sysRoleFieldRepo roleFieldRepo.ISysRoleFieldRepository,
+	templateRepository templateRepo.IPermissionTemplateRepository,
+	menuApiRepository menuApiRepo.IMenuApiRepository,
+	userMenuCache *cache.UserMenuCache,
+	enforcer *casbin.Enforcer,
 	loggerInstance *logger.Logger) ISysRoleService {
 	return &SysRoleUseCase{
 		sysRoleRepository:     sysRoleRepository,
@@ -58,6 +104,12 @@ func NewSysRoleUseCase(
 		sysMenuRepository:     sysMenuRepository,
 		sysRoleBtnRepo:        sysRoleBtnRepo,
 		casbinRuleRepo:        casbinRuleRepo,
+		sysDeptRepository:     sysDeptRepository,
+		sysRoleFieldRepo:      sysRoleFieldRepo,
+		templateRepo:          templateRepository,
+		menuApiRepo:           menuApiRepository,
+		userMenuCache:         userMenuCache,
+		enforcer:              enforcer,
 		Logger:                loggerInstance,
 	}
 }
@@ -80,18 +132,21 @@ func BuildRoleTree(roles *[]roleDomain.Role) []*roleDomain.RoleTree {
 	for _, role := range *roles {
 
 		node := &roleDomain.RoleTree{
-			ID:            role.ID,
-			Name:          role.Name,
-			ParentID:      role.ParentID,
-			DefaultRouter: role.DefaultRouter,
-			Status:        role.Status,
-			Order:         role.Order,
-			Label:         role.Label,
-			Description:   role.Description,
-			CreatedAt:     role.CreatedAt,
-			UpdatedAt:     role.UpdatedAt,
-			Path:          []int64{role.ID},
-			Children:      []*roleDomain.RoleTree{},
+			ID:              role.ID,
+			Name:            role.Name,
+			ParentID:        role.ParentID,
+			DefaultRouter:   role.DefaultRouter,
+			Status:          role.Status,
+			Order:           role.Order,
+			Label:           role.Label,
+			Description:     role.Description,
+			IsSuper:         role.IsSuper,
+			DataScope:       role.DataScope,
+			DataScopeFilter: role.DataScopeFilter,
+			CreatedAt:       role.CreatedAt,
+			UpdatedAt:       role.UpdatedAt,
+			Path:            []int64{role.ID},
+			Children:        []*roleDomain.RoleTree{},
 		}
 		roleMap[role.ID] = node
 	}
@@ -126,17 +181,82 @@ func (s *SysRoleUseCase) GetByName(name string) (*roleDomain.Role, error) {
 
 func (s *SysRoleUseCase) Create(newRole *roleDomain.Role) (*roleDomain.Role, error) {
 	s.Logger.Info("Creating new role", zap.String("name", newRole.Name))
-	return s.sysRoleRepository.Create(newRole)
+	if err := s.checkParent(0, newRole.ParentID); err != nil {
+		return nil, err
+	}
+	if err := normalizeDataScope(newRole); err != nil {
+		return nil, err
+	}
+	role, err := s.sysRoleRepository.Create(newRole)
+	if err != nil {
+		return role, err
+	}
+	return role, s.syncRoleParent(role.ID, role.ParentID)
 }
 
 func (s *SysRoleUseCase) Delete(id int) error {
 	s.Logger.Info("Deleting role", zap.Int("id", id))
-	return s.sysRoleRepository.Delete(id)
+	if err := s.ensureSuperAdminRemains(int64(id)); err != nil {
+		return err
+	}
+	// 仓储在同一事务中删除角色的接口策略和继承规则
+	if err := s.sysRoleRepository.Delete(id); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return sharedUtil.ReloadCasbinPolicy(s.enforcer)
 }
 
 func (s *SysRoleUseCase) Update(id int, userMap map[string]interface{}) (*roleDomain.Role, error) {
 	s.Logger.Info("Updating role", zap.Int("id", id))
-	return s.sysRoleRepository.Update(id, userMap)
+	isSuper, superChanged := userMap["is_super"].(bool)
+	status, statusChanged := userMap["status"]
+	if (superChanged && !isSuper) || (statusChanged && fmt.Sprint(status) != constants.StatusEnabled) {
+		if err := s.ensureSuperAdminRemains(int64(id)); err != nil {
+			return nil, err
+		}
+	}
+	if err := normalizeDataScopeUpdate(userMap); err != nil {
+		return nil, err
+	}
+	parentId, parentChanged := parentIdFromMap(userMap)
+	if parentChanged {
+		if err := s.checkParent(int64(id), parentId); err != nil {
+			return nil, err
+		}
+	}
+	role, err := s.sysRoleRepository.Update(id, userMap)
+	if err != nil {
+		return nil, err
+	}
+	// is_super 决定用户菜单是否为全部菜单
+	s.userMenuCache.Invalidate(context.Background())
+	if !parentChanged {
+		return role, nil
+	}
+	return role, s.syncRoleParent(role.ID, role.ParentID)
+}
+
+// ensureSuperAdminRemains 取消或删除超级管理员角色前，确认其他角色仍有超级管理员用户
+func (s *SysRoleUseCase) ensureSuperAdminRemains(roleId int64) error {
+	isSuper, err := s.sysRoleRepository.IsSuperRole(roleId)
+	if err != nil || !isSuper {
+		return err
+	}
+	count, err := s.sysRoleRepository.CountSuperUsers(roleId, 0)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		s.Logger.Warn("Refusing to remove the last super administrator role", zap.Int64("roleId", roleId))
+		return domainErrors.NewAppError(ErrLastSuperAdmin, domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// IsSuperRole 供 CasbinMiddleware 和菜单查询判断超级管理员角色，任一角色为超级管理员即返回 true
+func (s *SysRoleUseCase) IsSuperRole(roleIds ...int64) (bool, error) {
+	return s.sysRoleRepository.IsSuperRole(roleIds...)
 }
 
 func (s *SysRoleUseCase) SearchPaginated(filters domain.DataFilters) (*roleDomain.SearchResultRole, error) {
@@ -219,16 +339,57 @@ func (s *SysRoleUseCase) GetRoleMenuIds(id int64) (map[int][]int, map[int64][]in
 }
 
 func (s *SysRoleUseCase) UpdateRoleMenuIds(id int, updateMap map[string]any) error {
-	return s.sysRoleMenuRepository.Insert(id, updateMap)
+	if err := s.sysRoleMenuRepository.Insert(id, updateMap); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return s.syncImpliedApis(int64(id))
 }
 func (s *SysRoleUseCase) GetApiRuleList(roleId int) ([]string, error) {
 	return s.casbinRuleRepo.GetByRoleId(roleId)
 
 }
 func (s *SysRoleUseCase) BindApiRule(roleId int, updateMap map[string]interface{}) error {
-	return s.casbinRuleRepo.Insert(roleId, updateMap)
+	if err := s.casbinRuleRepo.Insert(roleId, updateMap); err != nil {
+		return err
+	}
+	// 提交的接口均为直接绑定，之后回收菜单时保留
+	if err := s.menuApiRepo.MarkExplicit(int64(roleId), apiKeysFromMap(updateMap)); err != nil {
+		return err
+	}
+	// 已授予菜单和按钮关联的接口不会因重新绑定而丢失
+	return s.syncImpliedApis(int64(roleId))
+}
+
+// apiKeysFromMap 解析绑定请求中的 path---method，格式校验由 casbin_rule 仓储完成
+func apiKeysFromMap(updateMap map[string]interface{}) []string {
+	items, _ := updateMap["apiPaths"].([]interface{})
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		if key, ok := item.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 func (s *SysRoleUseCase) BindRoleMenuBtns(roleId int64, updateMap map[string]interface{}) error {
-	return s.sysRoleBtnRepo.Insert(roleId, updateMap)
+	if err := s.sysRoleBtnRepo.Insert(roleId, updateMap); err != nil {
+		return err
+	}
+	s.userMenuCache.Invalidate(context.Background())
+	return s.syncImpliedApis(roleId)
+}
+
+// GetApiGrants 角色自身的接口策略，区分直接绑定和由菜单、按钮带来的授权
+func (s *SysRoleUseCase) GetApiGrants(roleId int64) ([]roleDomain.ApiGrant, error) {
+	return s.menuApiRepo.GetRoleGrants(roleId)
+}
+
+// syncImpliedApis 角色的菜单、按钮或接口绑定变化后补齐关联接口的策略
+func (s *SysRoleUseCase) syncImpliedApis(roleId int64) error {
+	if err := s.menuApiRepo.SyncRoles(roleId); err != nil {
+		return err
+	}
+	return sharedUtil.ReloadCasbinPolicy(s.enforcer)
 }
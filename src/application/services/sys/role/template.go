@@ -0,0 +1,295 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	roleDomain "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+func (s *SysRoleUseCase) GetTemplates() ([]roleDomain.PermissionTemplate, error) {
+	return s.templateRepo.GetAll()
+}
+
+func (s *SysRoleUseCase) GetTemplate(id int64) (*roleDomain.PermissionTemplate, error) {
+	return s.templateRepo.GetByID(id)
+}
+
+// CreateTemplate fromRoleId 不为 0 时以该角色当前的权限作为模板内容
+func (s *SysRoleUseCase) CreateTemplate(template *roleDomain.PermissionTemplate, fromRoleId int64) (*roleDomain.PermissionTemplate, error) {
+	s.Logger.Info("Creating permission template", zap.String("name", template.Name), zap.Int64("fromRoleId", fromRoleId))
+	if fromRoleId != 0 {
+		permissions, err := s.permissionSet(fromRoleId)
+		if err != nil {
+			return nil, err
+		}
+		template.Permissions = *permissions
+	}
+	if err := normalizePermissionSet(&template.Permissions); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.Create(template)
+}
+
+func (s *SysRoleUseCase) UpdateTemplate(id int64, template *roleDomain.PermissionTemplate) (*roleDomain.PermissionTemplate, error) {
+	s.Logger.Info("Updating permission template", zap.Int64("id", id))
+	if err := normalizePermissionSet(&template.Permissions); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.Update(id, template)
+}
+
+func (s *SysRoleUseCase) DeleteTemplate(id int64) error {
+	s.Logger.Info("Deleting permission template", zap.Int64("id", id))
+	return s.templateRepo.Delete(id)
+}
+
+// DiffTemplate 预览角色套用模板后的变化，不修改数据
+func (s *SysRoleUseCase) DiffTemplate(roleId int64, templateId int64, mode string) (*roleDomain.PermissionDiff, error) {
+	_, diff, err := s.templateTarget(roleId, templateId, mode)
+	return diff, err
+}
+
+// ApplyTemplate 按 mode 把模板写入角色，返回实际发生的变化
+func (s *SysRoleUseCase) ApplyTemplate(roleId int64, templateId int64, mode string) (*roleDomain.PermissionDiff, error) {
+	target, diff, err := s.templateTarget(roleId, templateId, mode)
+	if err != nil || diff.Empty() {
+		return diff, err
+	}
+	if err := s.sysRoleRepository.ReplacePermissions(roleId, target); err != nil {
+		return nil, err
+	}
+	s.Logger.Info("Permission template applied", zap.Int64("roleId", roleId), zap.Int64("templateId", templateId), zap.String("mode", mode))
+	s.userMenuCache.Invalidate(context.Background())
+	// 菜单和按钮变化也可能带来关联接口的策略变化
+	return diff, sharedUtil.ReloadCasbinPolicy(s.enforcer)
+}
+
+// templateTarget 计算角色套用模板后的权限以及与当前权限的差异
+func (s *SysRoleUseCase) templateTarget(roleId int64, templateId int64, mode string) (*roleDomain.PermissionSet, *roleDomain.PermissionDiff, error) {
+	if mode == "" {
+		mode = roleDomain.TemplateApplyReplace
+	}
+	if mode != roleDomain.TemplateApplyReplace && mode != roleDomain.TemplateApplyMerge {
+		return nil, nil, domainErrors.NewAppError(fmt.Errorf("unknown apply mode %q", mode), domainErrors.ValidationError)
+	}
+	template, err := s.templateRepo.GetByID(templateId)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := s.permissionSet(roleId)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := template.Permissions
+	if err := normalizePermissionSet(&target); err != nil {
+		return nil, nil, err
+	}
+	if mode == roleDomain.TemplateApplyMerge {
+		target = mergePermissions(current, &target)
+	}
+	return &target, diffPermissions(current, &target), nil
+}
+
+// permissionSet 读取角色自身的菜单、按钮、直接绑定的接口和字段权限，不含继承和菜单带出的接口
+func (s *SysRoleUseCase) permissionSet(roleId int64) (*roleDomain.PermissionSet, error) {
+	if _, err := s.sysRoleRepository.GetByID(int(roleId)); err != nil {
+		return nil, err
+	}
+	menuIds, err := s.sysRoleMenuRepository.GetByRoleId(roleId)
+	if err != nil {
+		return nil, err
+	}
+	roleBtns, err := s.sysRoleBtnRepo.GetByRoleId(roleId)
+	if err != nil {
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	grants, err := s.menuApiRepo.GetRoleGrants(roleId)
+	if err != nil {
+		return nil, err
+	}
+	// 仅由菜单或按钮带出的接口不属于直接授权，写入时由 menu_api.SyncRolePolicies 按菜单重新推导
+	apis := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		if grant.Explicit {
+			apis = append(apis, grant.Path+"---"+grant.Method)
+		}
+	}
+	fields, err := s.GetRoleFields(roleId)
+	if err != nil {
+		return nil, err
+	}
+	set := &roleDomain.PermissionSet{
+		Menus:  make([]int64, 0, len(menuIds)),
+		Btns:   make(map[int64][]int64),
+		Apis:   apis,
+		Fields: fields,
+	}
+	for _, id := range menuIds {
+		set.Menus = append(set.Menus, int64(id))
+	}
+	for _, v := range roleBtns {
+		set.Btns[v.SysMenuID] = append(set.Btns[v.SysMenuID], v.SysBaseMenuBtnID)
+	}
+	if err := normalizePermissionSet(set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// normalizePermissionSet 去重排序，并校验接口格式和可隐藏字段
+func normalizePermissionSet(set *roleDomain.PermissionSet) error {
+	set.Menus = uniqueInt64(set.Menus)
+	btns := make(map[int64][]int64, len(set.Btns))
+	for menuId, btnIds := range set.Btns {
+		if ids := uniqueInt64(btnIds); len(ids) > 0 {
+			btns[menuId] = ids
+		}
+	}
+	set.Btns = btns
+
+	for _, api := range set.Apis {
+		if path, method, ok := strings.Cut(api, "---"); !ok || path == "" || method == "" {
+			return domainErrors.NewAppError(fmt.Errorf("invalid api rule %q, expected path---method", api), domainErrors.ValidationError)
+		}
+	}
+	set.Apis = uniqueStrings(set.Apis)
+
+	fields := make(map[string][]string, len(set.Fields))
+	for resource, names := range set.Fields {
+		allowed, known := domain.MaskableFields[resource]
+		if !known {
+			return domainErrors.NewAppError(errors.New("unknown field resource"), domainErrors.ValidationError)
+		}
+		for _, field := range names {
+			if !containsField(allowed, field) {
+				return domainErrors.NewAppError(fmt.Errorf("field %s cannot be hidden on %s", field, resource), domainErrors.ValidationError)
+			}
+		}
+		if names = uniqueStrings(names); len(names) > 0 {
+			fields[resource] = names
+		}
+	}
+	set.Fields = fields
+	return nil
+}
+
+// mergePermissions 菜单、按钮和接口取并集；隐藏字段取交集，只有两边都隐藏的字段继续隐藏
+func mergePermissions(current *roleDomain.PermissionSet, template *roleDomain.PermissionSet) roleDomain.PermissionSet {
+	merged := roleDomain.PermissionSet{
+		Menus:  uniqueInt64(append(append([]int64{}, current.Menus...), template.Menus...)),
+		Btns:   make(map[int64][]int64),
+		Apis:   uniqueStrings(append(append([]string{}, current.Apis...), template.Apis...)),
+		Fields: make(map[string][]string),
+	}
+	for _, btns := range []map[int64][]int64{current.Btns, template.Btns} {
+		for menuId, btnIds := range btns {
+			merged.Btns[menuId] = uniqueInt64(append(merged.Btns[menuId], btnIds...))
+		}
+	}
+	for resource, names := range current.Fields {
+		var kept []string
+		for _, field := range names {
+			if containsField(template.Fields[resource], field) {
+				kept = append(kept, field)
+			}
+		}
+		if len(kept) > 0 {
+			merged.Fields[resource] = kept
+		}
+	}
+	return merged
+}
+
+// diffPermissions target 相对 current 新增和移除的权限
+func diffPermissions(current *roleDomain.PermissionSet, target *roleDomain.PermissionSet) *roleDomain.PermissionDiff {
+	diff := &roleDomain.PermissionDiff{
+		Added:   roleDomain.PermissionSet{Btns: map[int64][]int64{}, Fields: map[string][]string{}},
+		Removed: roleDomain.PermissionSet{Btns: map[int64][]int64{}, Fields: map[string][]string{}},
+	}
+	diff.Added.Menus = subtractInt64(target.Menus, current.Menus)
+	diff.Removed.Menus = subtractInt64(current.Menus, target.Menus)
+	diff.Added.Apis = subtractStrings(target.Apis, current.Apis)
+	diff.Removed.Apis = subtractStrings(current.Apis, target.Apis)
+	for menuId, btnIds := range target.Btns {
+		if added := subtractInt64(btnIds, current.Btns[menuId]); len(added) > 0 {
+			diff.Added.Btns[menuId] = added
+		}
+	}
+	for menuId, btnIds := range current.Btns {
+		if removed := subtractInt64(btnIds, target.Btns[menuId]); len(removed) > 0 {
+			diff.Removed.Btns[menuId] = removed
+		}
+	}
+	for resource, names := range target.Fields {
+		if added := subtractStrings(names, current.Fields[resource]); len(added) > 0 {
+			diff.Added.Fields[resource] = added
+		}
+	}
+	for resource, names := range current.Fields {
+		if removed := subtractStrings(names, target.Fields[resource]); len(removed) > 0 {
+			diff.Removed.Fields[resource] = removed
+		}
+	}
+	return diff
+}
+
+func uniqueInt64(values []int64) []int64 {
+	seen := make(map[int64]bool, len(values))
+	result := make([]int64, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func subtractInt64(values []int64, remove []int64) []int64 {
+	result := make([]int64, 0)
+	for _, v := range values {
+		found := false
+		for _, r := range remove {
+			if r == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func subtractStrings(values []string, remove []string) []string {
+	result := make([]string, 0)
+	for _, v := range values {
+		if !containsField(remove, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
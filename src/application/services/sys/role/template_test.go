@@ -0,0 +1,106 @@
+package role
+
+import (
+	"testing"
+
+	roleDomain "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	menuApiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleFieldRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_field"
+	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateMergeAndDiff(t *testing.T) {
+	current := &roleDomain.PermissionSet{
+		Menus:  []int64{1, 2},
+		Btns:   map[int64][]int64{1: {10}},
+		Apis:   []string{"/v1/user---GET"},
+		Fields: map[string][]string{"user": {"email", "phone"}},
+	}
+	template := &roleDomain.PermissionSet{
+		Menus:  []int64{2, 3, 3},
+		Btns:   map[int64][]int64{1: {11}},
+		Apis:   []string{"/v1/role---GET"},
+		Fields: map[string][]string{"user": {"phone"}},
+	}
+	assert.NoError(t, normalizePermissionSet(template))
+	assert.Equal(t, []int64{2, 3}, template.Menus)
+
+	replace := diffPermissions(current, template)
+	assert.Equal(t, []int64{3}, replace.Added.Menus)
+	assert.Equal(t, []int64{1}, replace.Removed.Menus)
+	assert.Equal(t, []string{"/v1/user---GET"}, replace.Removed.Apis)
+	assert.Equal(t, map[string][]string{"user": {"email"}}, replace.Removed.Fields)
+
+	merged := mergePermissions(current, template)
+	assert.Equal(t, []int64{1, 2, 3}, merged.Menus)
+	assert.Equal(t, []int64{10, 11}, merged.Btns[1])
+	assert.Equal(t, []string{"/v1/role---GET", "/v1/user---GET"}, merged.Apis)
+	assert.Equal(t, map[string][]string{"user": {"phone"}}, merged.Fields)
+
+	merge := diffPermissions(current, &merged)
+	assert.Empty(t, merge.Removed.Menus)
+	assert.Empty(t, merge.Removed.Apis)
+	assert.Equal(t, []string{"/v1/role---GET"}, merge.Added.Apis)
+	assert.False(t, merge.Empty())
+	assert.True(t, diffPermissions(&merged, &merged).Empty())
+}
+
+func TestNormalizePermissionSetRejectsInvalid(t *testing.T) {
+	assert.Error(t, normalizePermissionSet(&roleDomain.PermissionSet{Apis: []string{"/v1/user"}}))
+	assert.Error(t, normalizePermissionSet(&roleDomain.PermissionSet{Fields: map[string][]string{"user": {"password"}}}))
+}
+
+type fakeRoleRepo struct{ roleRepo.ISysRolesRepository }
+
+func (fakeRoleRepo) GetByID(id int) (*roleDomain.Role, error) {
+	return &roleDomain.Role{ID: int64(id)}, nil
+}
+
+type fakeRoleMenuRepo struct {
+	roleMenuRepo.ISysRoleMenuRepository
+}
+
+func (fakeRoleMenuRepo) GetByRoleId(int64) ([]int, error) { return []int{3}, nil }
+
+type fakeRoleBtnRepo struct {
+	roleBtnRepo.ISysRoleBtnRepository
+}
+
+func (fakeRoleBtnRepo) GetByRoleId(int64) ([]*roleBtnRepo.SysRoleBtn, error) { return nil, nil }
+
+type fakeRoleFieldRepo struct {
+	roleFieldRepo.ISysRoleFieldRepository
+}
+
+func (fakeRoleFieldRepo) GetByRoleIds([]int64) ([]*roleFieldRepo.SysRoleField, error) {
+	return nil, nil
+}
+
+type fakeMenuApiRepo struct {
+	menuApiRepo.IMenuApiRepository
+	grants []roleDomain.ApiGrant
+}
+
+func (f fakeMenuApiRepo) GetRoleGrants(int64) ([]roleDomain.ApiGrant, error) { return f.grants, nil }
+
+func TestPermissionSetSkipsImpliedOnlyApis(t *testing.T) {
+	s := &SysRoleUseCase{
+		sysRoleRepository:     fakeRoleRepo{},
+		sysRoleMenuRepository: fakeRoleMenuRepo{},
+		sysRoleBtnRepo:        fakeRoleBtnRepo{},
+		sysRoleFieldRepo:      fakeRoleFieldRepo{},
+		menuApiRepo: fakeMenuApiRepo{grants: []roleDomain.ApiGrant{
+			{Path: "/v1/user", Method: "GET", Explicit: true, ImpliedBy: []string{"menu:3"}},
+			{Path: "/v1/user/:id", Method: "GET", Explicit: false, ImpliedBy: []string{"menu:3"}},
+			{Path: "/v1/dept", Method: "POST", Explicit: true, ImpliedBy: []string{}},
+		}},
+	}
+
+	set, err := s.permissionSet(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, set.Menus)
+	assert.Equal(t, []string{"/v1/dept---POST", "/v1/user---GET"}, set.Apis)
+}
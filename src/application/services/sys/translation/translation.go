@@ -0,0 +1,245 @@
+package translation
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	translationDomain "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	translationRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/translation"
+	"go.uber.org/zap"
+)
+
+// translations locale -> scope -> key -> value
+type translations map[string]map[string]map[string]string
+
+// TranslationUseCase 翻译在内存中按 ttl 缓存，本实例修改后立即重新加载，其他实例最迟 ttl 后生效。
+// defaultLocale 为原文的语言，请求该语言时没有翻译就返回原文
+type TranslationUseCase struct {
+	translationRepository translationRepo.ITranslationRepository
+	defaultLocale         string
+	ttl                   time.Duration
+	mu                    sync.RWMutex
+	cached                translations
+	loadedAt              time.Time
+	Logger                *logger.Logger
+}
+
+func NewTranslationUseCase(translationRepository translationRepo.ITranslationRepository, defaultLocale string, ttl time.Duration, loggerInstance *logger.Logger) translationDomain.ITranslationService {
+	return &TranslationUseCase{
+		translationRepository: translationRepository,
+		defaultLocale:         normalizeLocale(defaultLocale),
+		ttl:                   ttl,
+		Logger:                loggerInstance,
+	}
+}
+
+func (s *TranslationUseCase) GetAll(locale string, scope string) ([]translationDomain.Translation, error) {
+	s.Logger.Info("Getting translations", zap.String("locale", locale), zap.String("scope", scope))
+	return s.translationRepository.GetAll(locale, scope)
+}
+
+func (s *TranslationUseCase) Save(items []translationDomain.Translation) ([]translationDomain.Translation, error) {
+	s.Logger.Info("Saving translations", zap.Int("count", len(items)))
+	seen := make(map[string]bool, len(items))
+	for i := range items {
+		items[i].Locale = normalizeLocale(items[i].Locale)
+		item := items[i]
+		if item.Locale == "" || item.Key == "" || item.Value == "" {
+			return nil, domainErrors.NewAppError(errors.New("locale, key and value are required"), domainErrors.ValidationError)
+		}
+		switch item.Scope {
+		case translationDomain.ScopeMenu, translationDomain.ScopeError:
+		case translationDomain.ScopeDictionaryDetail:
+			if _, err := strconv.ParseInt(item.Key, 10, 64); err != nil {
+				return nil, domainErrors.NewAppError(errors.New("dictionary_detail key must be a dictionary detail id"), domainErrors.ValidationError)
+			}
+		default:
+			return nil, domainErrors.NewAppError(errors.New("unknown translation scope "+item.Scope), domainErrors.ValidationError)
+		}
+		// 同一批次中重复的键会让 ON CONFLICT 在一条语句中更新同一行两次
+		id := item.Locale + "\x00" + item.Scope + "\x00" + item.Key
+		if seen[id] {
+			return nil, domainErrors.NewAppError(errors.New("duplicate translation "+item.Locale+" "+item.Scope+" "+item.Key), domainErrors.ValidationError)
+		}
+		seen[id] = true
+	}
+	saved, err := s.translationRepository.Save(items)
+	if err != nil {
+		return nil, err
+	}
+	s.reload(true)
+	return saved, nil
+}
+
+func (s *TranslationUseCase) Delete(id int64) error {
+	s.Logger.Info("Deleting translation", zap.Int64("id", id))
+	if err := s.translationRepository.Delete(id); err != nil {
+		return err
+	}
+	s.reload(true)
+	return nil
+}
+
+func (s *TranslationUseCase) Locales() []string {
+	set := make(map[string]bool)
+	if s.defaultLocale != "" {
+		set[s.defaultLocale] = true
+	}
+	for locale := range s.snapshot() {
+		set[locale] = true
+	}
+	for _, locale := range domainErrors.MessageLocales() {
+		set[locale] = true
+	}
+	locales := make([]string, 0, len(set))
+	for locale := range set {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func (s *TranslationUseCase) ResolveLocale(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return ""
+	}
+	return matchLocale(parseAcceptLanguage(acceptLanguage), s.Locales())
+}
+
+// Translate 错误消息先查数据库，再查内置翻译
+func (s *TranslationUseCase) Translate(locale string, scope string, key string, fallback string) string {
+	if locale == "" || key == "" {
+		return fallback
+	}
+	if value, ok := s.snapshot()[locale][scope][key]; ok {
+		return value
+	}
+	if scope == translationDomain.ScopeError {
+		if value, ok := domainErrors.LocalizedMessage(locale, key); ok {
+			return value
+		}
+	}
+	return fallback
+}
+
+// snapshot 过期时重新加载，加载失败时继续使用旧数据
+func (s *TranslationUseCase) snapshot() translations {
+	s.mu.RLock()
+	cached, loadedAt := s.cached, s.loadedAt
+	s.mu.RUnlock()
+	if cached != nil && time.Since(loadedAt) < s.ttl {
+		return cached
+	}
+	return s.reload(false)
+}
+
+// reload force 为 false 时只在其他请求尚未重新加载时查询数据库
+func (s *TranslationUseCase) reload(force bool) translations {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !force && s.cached != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.cached
+	}
+	all, err := s.translationRepository.GetAll("", "")
+	s.loadedAt = time.Now()
+	if err != nil {
+		s.Logger.Error("Error loading translations", zap.Error(err))
+		if s.cached == nil {
+			s.cached = translations{}
+		}
+		return s.cached
+	}
+	loaded := make(translations)
+	for _, item := range all {
+		if loaded[item.Locale] == nil {
+			loaded[item.Locale] = make(map[string]map[string]string)
+		}
+		if loaded[item.Locale][item.Scope] == nil {
+			loaded[item.Locale][item.Scope] = make(map[string]string)
+		}
+		loaded[item.Locale][item.Scope][item.Key] = item.Value
+	}
+	s.cached = loaded
+	return loaded
+}
+
+// parseAcceptLanguage 按 q 值从高到低返回语言，忽略 * 和 q=0
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	parsed := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := normalizeLocale(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					value = 0
+				}
+				q = value
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		parsed = append(parsed, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(parsed, func(i, j int) bool { return parsed[i].q > parsed[j].q })
+	tags := make([]string, 0, len(parsed))
+	for _, item := range parsed {
+		tags = append(tags, item.tag)
+	}
+	return tags
+}
+
+// matchLocale 按优先级先精确匹配，再按主语言匹配（zh、zh-TW 可以匹配 zh-CN）
+func matchLocale(preferred []string, available []string) string {
+	for _, tag := range preferred {
+		for _, locale := range available {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		base := baseLanguage(tag)
+		for _, locale := range available {
+			if strings.EqualFold(base, baseLanguage(locale)) {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
+func baseLanguage(tag string) string {
+	if i := strings.Index(tag, "-"); i > 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// normalizeLocale zh_cn -> zh-CN
+func normalizeLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	for i := range parts {
+		if i == 0 {
+			parts[i] = strings.ToLower(parts[i])
+		} else if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
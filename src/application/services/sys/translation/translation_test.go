@@ -0,0 +1,22 @@
+package translation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"zh-CN", "zh", "en"}, parseAcceptLanguage("en;q=0.5, zh_cn ,zh;q=0.8"))
+	assert.Equal(t, []string{"fr"}, parseAcceptLanguage("*, fr, de;q=0"))
+	assert.Empty(t, parseAcceptLanguage(""))
+}
+
+func TestMatchLocale(t *testing.T) {
+	available := []string{"en", "zh-CN"}
+	assert.Equal(t, "zh-CN", matchLocale([]string{"zh-cn"}, available))
+	// 主语言匹配
+	assert.Equal(t, "zh-CN", matchLocale([]string{"zh-TW", "en"}, available))
+	assert.Equal(t, "en", matchLocale([]string{"fr", "en-US"}, available))
+	assert.Equal(t, "", matchLocale([]string{"fr"}, available))
+}
@@ -2,14 +2,20 @@ package user
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	emailUseCase "github.com/gbrayhan/microservices-go/src/application/services/email"
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	jwtBlacklistDomain "github.com/gbrayhan/microservices-go/src/domain/jwt_blacklist"
 	userDomain "github.com/gbrayhan/microservices-go/src/domain/user"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	passwordLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/password"
+	passwordHistoryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/password_history"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	userRoleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
 	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
@@ -18,53 +24,69 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrSuperAdminRequired 非超级管理员授予超级角色或修改超级管理员
+var ErrSuperAdminRequired = errors.New("only a super administrator can manage super administrators")
+
 type IUserUseCase interface {
-	GetAll() (*[]userDomain.User, error)
-	GetByID(id int) (*userDomain.User, error)
+	GetAll(scope *domain.DataScope) (*[]userDomain.User, error)
+	GetByID(id int, scope *domain.DataScope) (*userDomain.User, error)
 	GetByEmail(email string) (*userDomain.User, error)
 	Create(newUser *userDomain.User) (*userDomain.User, error)
-	Delete(id int) error
-	Update(id int64, userMap map[string]interface{}) (*userDomain.User, error)
+	Delete(id int, operatorRoleIds []int64, scope *domain.DataScope) error
+	Update(id int64, userMap map[string]interface{}, operatorRoleIds []int64, scope *domain.DataScope) (*userDomain.User, error)
 	SearchPaginated(filters domain.DataFilters) (*userDomain.SearchResultUser, error)
-	SearchByProperty(property string, searchText string) (*[]string, error)
+	SearchByProperty(property string, searchText string, scope *domain.DataScope) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*userDomain.User, error)
-	UserBindRoles(userId int64, updateMap map[string]interface{}) error
-	ResetPassword(userId int64) (*userDomain.User, error)
-	EditPassword(userId int64, data userDomain.PasswordEditRequest) (*userDomain.User, error)
+	// operatorRoleIds 为操作者的角色，授予超级角色或管理超级管理员(修改、删除、改密)时要求操作者也是超级管理员
+	UserBindRoles(userId int64, updateMap map[string]interface{}, operatorRoleIds []int64, scope *domain.DataScope) error
+	ResetPassword(userId int64, method string, operatorRoleIds []int64, scope *domain.DataScope) (*userDomain.ResetPasswordResult, error)
+	EditPassword(userId int64, data userDomain.PasswordEditRequest, operatorRoleIds []int64) (*userDomain.User, error)
 	ChangePasswordById(userId int64, password string, jwtToken string) (*userDomain.User, error)
 }
 
 type UserUseCase struct {
-	userRepository         user.UserRepositoryInterface
-	userRoleRepository     userRoleRepo.ISysUserRoleRepository
-	Logger                 *logger.Logger
-	eventBus               bus.EventBus
-	jwtBlacklistRepository jwtBlacklistDomain.IJwtBlacklistService
+	userRepository            user.UserRepositoryInterface
+	userRoleRepository        userRoleRepo.ISysUserRoleRepository
+	roleRepository            roleRepo.ISysRolesRepository
+	Logger                    *logger.Logger
+	eventBus                  bus.EventBus
+	jwtBlacklistRepository    jwtBlacklistDomain.IJwtBlacklistService
+	passwordHistoryRepository passwordHistoryRepo.IPasswordHistoryRepository
+	passwordPolicy            *passwordLib.Policy
+	emailService              emailUseCase.IEmailService
 }
 
 func NewUserUseCase(
 	userRepository user.UserRepositoryInterface,
 	userRoleRepository userRoleRepo.ISysUserRoleRepository,
+	roleRepository roleRepo.ISysRolesRepository,
 	eventBus bus.EventBus,
 	jwtBlacklistRepository jwtBlacklistDomain.IJwtBlacklistService,
+	passwordHistoryRepository passwordHistoryRepo.IPasswordHistoryRepository,
+	passwordPolicy *passwordLib.Policy,
+	emailService emailUseCase.IEmailService,
 	logger *logger.Logger) IUserUseCase {
 	return &UserUseCase{
-		userRepository:         userRepository,
-		userRoleRepository:     userRoleRepository,
-		eventBus:               eventBus,
-		Logger:                 logger,
-		jwtBlacklistRepository: jwtBlacklistRepository,
+		userRepository:            userRepository,
+		userRoleRepository:        userRoleRepository,
+		roleRepository:            roleRepository,
+		eventBus:                  eventBus,
+		Logger:                    logger,
+		jwtBlacklistRepository:    jwtBlacklistRepository,
+		passwordHistoryRepository: passwordHistoryRepository,
+		passwordPolicy:            passwordPolicy,
+		emailService:              emailService,
 	}
 }
 
-func (s *UserUseCase) GetAll() (*[]userDomain.User, error) {
+func (s *UserUseCase) GetAll(scope *domain.DataScope) (*[]userDomain.User, error) {
 	s.Logger.Info("Getting all users")
-	return s.userRepository.GetAll()
+	return s.userRepository.GetAll(scope)
 }
 
-func (s *UserUseCase) GetByID(id int) (*userDomain.User, error) {
+func (s *UserUseCase) GetByID(id int, scope *domain.DataScope) (*userDomain.User, error) {
 	s.Logger.Info("Getting user by ID", zap.Int("id", id))
-	return s.userRepository.GetByID(id)
+	return s.userRepository.GetByIDInScope(id, scope)
 }
 
 func (s *UserUseCase) GetByEmail(email string) (*userDomain.User, error) {
@@ -72,8 +94,21 @@ func (s *UserUseCase) GetByEmail(email string) (*userDomain.User, error) {
 	return s.userRepository.GetByEmail(email)
 }
 
+// Create 未指定密码时生成一次性密码，通过返回用户的 Password 交给管理员，用户首次登录时必须修改
 func (s *UserUseCase) Create(newUser *userDomain.User) (*userDomain.User, error) {
 	s.Logger.Info("Creating new user", zap.String("email", newUser.Email))
+	generated := ""
+	if newUser.Password == "" {
+		password, err := s.passwordPolicy.Generate()
+		if err != nil {
+			return &userDomain.User{}, domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		generated = password
+		newUser.Password = password
+		newUser.MustChangePassword = true
+	} else if err := s.passwordPolicy.Validate(newUser.Password, newUser.UserName); err != nil {
+		return &userDomain.User{}, domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
 	if err != nil {
 		s.Logger.Error("Error hashing password", zap.Error(err))
@@ -82,17 +117,45 @@ func (s *UserUseCase) Create(newUser *userDomain.User) (*userDomain.User, error)
 	newUser.HashPassword = string(hash)
 	newUser.UUID = uuid.New().String()
 	newUser.Status = 1
-	return s.userRepository.Create(newUser)
+	created, err := s.userRepository.Create(newUser)
+	if err != nil {
+		return created, err
+	}
+	if err := s.passwordHistoryRepository.Add(created.ID, newUser.HashPassword, s.passwordPolicy.Config().HistoryCount); err != nil {
+		s.Logger.Warn("Error recording password history", zap.Error(err), zap.Int64("id", created.ID))
+	}
+	created.Password = generated
+	return created, nil
 }
 
-func (s *UserUseCase) Delete(id int) error {
+func (s *UserUseCase) Delete(id int, operatorRoleIds []int64, scope *domain.DataScope) error {
 	s.Logger.Info("Deleting user", zap.Int("id", id))
+	if err := s.ensureInScope(int64(id), scope); err != nil {
+		return err
+	}
+	if err := s.ensureCanManageUser(int64(id), operatorRoleIds); err != nil {
+		return err
+	}
+	if err := s.ensureSuperAdminRemains(int64(id)); err != nil {
+		return err
+	}
 	return s.userRepository.Delete(id)
 }
 
-func (s *UserUseCase) Update(id int64, userMap map[string]interface{}) (*userDomain.User, error) {
+// Update 禁用用户时同样要确认仍有其他可用的超级管理员
+func (s *UserUseCase) Update(id int64, userMap map[string]interface{}, operatorRoleIds []int64, scope *domain.DataScope) (*userDomain.User, error) {
 	s.Logger.Info("Updating user", zap.Int64("id", id))
-
+	if err := s.ensureInScope(id, scope); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCanManageUser(id, operatorRoleIds); err != nil {
+		return nil, err
+	}
+	if status, ok := userMap["status"]; ok && fmt.Sprint(status) != strconv.Itoa(int(userDomain.UserStatusEnabled)) {
+		if err := s.ensureSuperAdminRemains(id); err != nil {
+			return nil, err
+		}
+	}
 	return s.userRepository.Update(id, userMap)
 }
 
@@ -103,33 +166,153 @@ func (s *UserUseCase) SearchPaginated(filters domain.DataFilters) (*userDomain.S
 	return s.userRepository.SearchPaginated(filters)
 }
 
-func (s *UserUseCase) SearchByProperty(property string, searchText string) (*[]string, error) {
+func (s *UserUseCase) SearchByProperty(property string, searchText string, scope *domain.DataScope) (*[]string, error) {
 	s.Logger.Info("Searching users by property",
 		zap.String("property", property),
 		zap.String("searchText", searchText))
-	return s.userRepository.SearchByProperty(property, searchText)
+	return s.userRepository.SearchByProperty(property, searchText, scope)
 }
 
 func (s *UserUseCase) GetOneByMap(userMap map[string]interface{}) (*userDomain.User, error) {
 	return s.userRepository.GetOneByMap(userMap)
 }
-func (s *UserUseCase) UserBindRoles(userId int64, updateMap map[string]interface{}) error {
+func (s *UserUseCase) UserBindRoles(userId int64, updateMap map[string]interface{}, operatorRoleIds []int64, scope *domain.DataScope) error {
+	if err := s.ensureInScope(userId, scope); err != nil {
+		return err
+	}
+	keepsSuper, err := s.roleRepository.ContainsSuperRole(roleIdsFromMap(updateMap)...)
+	if err != nil {
+		return err
+	}
+	if keepsSuper {
+		if err := s.ensureOperatorIsSuper(operatorRoleIds); err != nil {
+			return err
+		}
+	} else {
+		if err := s.ensureCanManageUser(userId, operatorRoleIds); err != nil {
+			return err
+		}
+		if err := s.ensureSuperAdminRemains(userId); err != nil {
+			return err
+		}
+	}
 	return s.userRoleRepository.Insert(userId, updateMap)
 }
 
-func (s *UserUseCase) ResetPassword(userId int64) (*userDomain.User, error) {
-	updateMap := make(map[string]interface{})
-	password := os.Getenv("RESET_USER_PASSWORD")
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// ensureInScope 目标用户不在操作者的数据权限范围内时与不存在一样返回 NotFound
+func (s *UserUseCase) ensureInScope(userId int64, scope *domain.DataScope) error {
+	_, err := s.userRepository.GetByIDInScope(int(userId), scope)
+	return err
+}
+
+// ensureCanManageUser 目标用户是超级管理员时，只有超级管理员可以修改其角色和密码
+func (s *UserUseCase) ensureCanManageUser(userId int64, operatorRoleIds []int64) error {
+	isSuper, err := s.roleRepository.IsSuperUser(userId)
+	if err != nil || !isSuper {
+		return err
+	}
+	return s.ensureOperatorIsSuper(operatorRoleIds)
+}
+
+// ensureOperatorIsSuper 操作者的角色中必须包含超级管理员角色
+func (s *UserUseCase) ensureOperatorIsSuper(operatorRoleIds []int64) error {
+	isSuper, err := s.roleRepository.IsSuperRole(operatorRoleIds...)
 	if err != nil {
-		s.Logger.Error("Error hashing password", zap.Error(err))
+		return err
+	}
+	if !isSuper {
+		s.Logger.Warn("Refusing super administrator change by non-super operator", zap.Int64s("operatorRoleIds", operatorRoleIds))
+		return domainErrors.NewAppError(ErrSuperAdminRequired, domainErrors.NotAuthorized)
+	}
+	return nil
+}
+
+// ensureSuperAdminRemains 用户失去超级管理员身份前，确认仍有其他超级管理员
+func (s *UserUseCase) ensureSuperAdminRemains(userId int64) error {
+	isSuper, err := s.roleRepository.IsSuperUser(userId)
+	if err != nil || !isSuper {
+		return err
+	}
+	count, err := s.roleRepository.CountSuperUsers(0, userId)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		s.Logger.Warn("Refusing to remove the last super administrator", zap.Int64("userId", userId))
+		return domainErrors.NewAppError(errors.New("at least one super administrator must remain"), domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// roleIdsFromMap 解析绑定请求中的角色ID，格式校验由 user_role 仓储完成
+func roleIdsFromMap(updateMap map[string]interface{}) []int64 {
+	items, _ := updateMap["roleIds"].([]interface{})
+	roleIds := make([]int64, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			if id, err := strconv.ParseInt(str, 10, 64); err == nil {
+				roleIds = append(roleIds, id)
+			}
+		}
+	}
+	return roleIds
+}
+
+// ResetPassword 不再使用统一的重置密码：生成一次性密码(下次登录强制修改，同时注销用户已有的会话)或向用户邮箱发送重置链接
+func (s *UserUseCase) ResetPassword(userId int64, method string, operatorRoleIds []int64, scope *domain.DataScope) (*userDomain.ResetPasswordResult, error) {
+	s.Logger.Info("Reset password", zap.Int64("id", userId), zap.String("method", method))
+	userInfo, err := s.userRepository.GetByIDInScope(int(userId), scope)
+	if err != nil {
+		s.Logger.Error("Error getting user info", zap.Error(err))
+		return nil, err
+	}
+	if err := s.ensureCanManageUser(userId, operatorRoleIds); err != nil {
 		return nil, err
 	}
-	updateMap["hash_password"] = hash
-	return s.userRepository.Update(userId, updateMap)
+
+	switch method {
+	case userDomain.ResetMethodLink:
+		if userInfo.Email == "" {
+			return nil, domainErrors.NewAppError(errors.New("user has no email address"), domainErrors.ValidationError)
+		}
+		if err := s.emailService.SendForgetPasswordEmail(userInfo.Email); err != nil {
+			s.Logger.Error("Error sending reset password email", zap.Error(err), zap.Int64("id", userId))
+			return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		return &userDomain.ResetPasswordResult{Method: method, Email: userInfo.Email}, nil
+	case "", userDomain.ResetMethodPassword:
+		password, err := s.passwordPolicy.Generate()
+		if err != nil {
+			s.Logger.Error("Error generating one-time password", zap.Error(err))
+			return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			s.Logger.Error("Error hashing password", zap.Error(err))
+			return nil, err
+		}
+		// 先注销会话，失败时不修改密码
+		if err := s.jwtBlacklistRepository.RevokeUserTokens(userId); err != nil {
+			return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+		updateMap := map[string]interface{}{
+			"hash_password":        string(hash),
+			"password_changed_at":  time.Now(),
+			"must_change_password": true,
+		}
+		if _, err := s.userRepository.Update(userId, updateMap); err != nil {
+			return nil, err
+		}
+		return &userDomain.ResetPasswordResult{Method: userDomain.ResetMethodPassword, Password: password}, nil
+	default:
+		return nil, domainErrors.NewAppError(errors.New("unsupported reset method: "+method), domainErrors.ValidationError)
+	}
 }
 
-func (s *UserUseCase) EditPassword(userId int64, data userDomain.PasswordEditRequest) (*userDomain.User, error) {
+func (s *UserUseCase) EditPassword(userId int64, data userDomain.PasswordEditRequest, operatorRoleIds []int64) (*userDomain.User, error) {
+	if err := s.ensureCanManageUser(userId, operatorRoleIds); err != nil {
+		return nil, err
+	}
 	userInfo, err := s.userRepository.GetByID(int(userId))
 	if err != nil {
 		s.Logger.Error("Error getting user info", zap.Error(err))
@@ -140,15 +323,7 @@ func (s *UserUseCase) EditPassword(userId int64, data userDomain.PasswordEditReq
 		s.Logger.Warn("EditPassword failed: invalid password", zap.String("username", userInfo.UserName))
 		return nil, domainErrors.NewAppError(errors.New("old password is incorrect"), domainErrors.NotAuthorized)
 	}
-
-	hash, err := bcrypt.GenerateFromPassword([]byte(data.NewPasswd), bcrypt.DefaultCost)
-	if err != nil {
-		s.Logger.Error("Error hashing password", zap.Error(err))
-		return nil, err
-	}
-	updateMap := make(map[string]interface{})
-	updateMap["hash_password"] = hash
-	return s.userRepository.Update(userId, updateMap)
+	return s.changePassword(userInfo, data.NewPasswd)
 }
 
 // ChangePasswordById implements IUserUseCase.
@@ -159,19 +334,54 @@ func (s *UserUseCase) ChangePasswordById(userId int64, password string, jwtToken
 		s.Logger.Error("Error getting user info", zap.Error(err))
 		return nil, err
 	}
-	updateMap := make(map[string]interface{})
+	res, err := s.changePassword(userInfo, password)
+	if err != nil {
+		s.Logger.Error("Error updating user info", zap.Error(err))
+		return nil, err
+	}
+	// invalidate jwt
+	s.jwtBlacklistRepository.AddToBlacklist(jwtToken)
+	return res, nil
+}
+
+// changePassword 校验密码策略和历史密码，更新后清除强制修改标记并记录历史
+func (s *UserUseCase) changePassword(userInfo *userDomain.User, password string) (*userDomain.User, error) {
+	if err := s.passwordPolicy.Validate(password, userInfo.UserName); err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	historyCount := s.passwordPolicy.Config().HistoryCount
+	if historyCount > 0 {
+		hashes, err := s.passwordHistoryRepository.GetRecentHashes(userInfo.ID, historyCount)
+		if err != nil {
+			return nil, err
+		}
+		// 当前密码同样不能重复使用
+		hashes = append(hashes, userInfo.HashPassword)
+		for _, hash := range hashes {
+			if sharedUtil.CheckPasswordHash(password, hash) {
+				return nil, domainErrors.NewAppError(
+					errors.New("password must differ from the last "+strconv.Itoa(historyCount)+" passwords"),
+					domainErrors.ValidationError)
+			}
+		}
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		s.Logger.Error("Error hashing password", zap.Error(err))
 		return nil, err
 	}
-	updateMap["hash_password"] = hash
+	updateMap := map[string]interface{}{
+		"hash_password":        string(hash),
+		"password_changed_at":  time.Now(),
+		"must_change_password": false,
+	}
 	res, err := s.userRepository.Update(userInfo.ID, updateMap)
 	if err != nil {
-		s.Logger.Error("Error updating user info", zap.Error(err))
 		return nil, err
 	}
-	// invalidate jwt
-	s.jwtBlacklistRepository.AddToBlacklist(jwtToken)
+	if err := s.passwordHistoryRepository.Add(userInfo.ID, string(hash), historyCount); err != nil {
+		s.Logger.Warn("Error recording password history", zap.Error(err), zap.Int64("id", userInfo.ID))
+	}
 	return res, nil
 }
@@ -0,0 +1,252 @@
+package user
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	userDomain "github.com/gbrayhan/microservices-go/src/domain/user"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	passwordLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/password"
+	passwordHistoryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/password_history"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	userRoleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var allScope = &domain.DataScope{All: true}
+
+const (
+	superRoleId  int64 = 1
+	normalRoleId int64 = 2
+	superUserId  int64 = 10
+	normalUserId int64 = 20
+)
+
+type fakeRoleRepository struct {
+	roleRepo.ISysRolesRepository
+	lastSuper bool
+}
+
+func (f *fakeRoleRepository) IsSuperRole(roleIds ...int64) (bool, error) {
+	for _, id := range roleIds {
+		if id == superRoleId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRoleRepository) ContainsSuperRole(roleIds ...int64) (bool, error) {
+	return f.IsSuperRole(roleIds...)
+}
+
+func (f *fakeRoleRepository) IsSuperUser(userId int64) (bool, error) {
+	return userId == superUserId, nil
+}
+
+func (f *fakeRoleRepository) CountSuperUsers(excludeRoleId int64, excludeUserId int64) (int64, error) {
+	if f.lastSuper {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+type fakeUserRepository struct {
+	user.UserRepositoryInterface
+	updated map[int64]map[string]interface{}
+	deleted []int
+	created int64
+}
+
+func (f *fakeUserRepository) Update(id int64, userMap map[string]interface{}) (*userDomain.User, error) {
+	f.updated[id] = userMap
+	return &userDomain.User{ID: id}, nil
+}
+
+// GetByIDInScope 只按 All、Self 和 DeptUserIDs 判断可见范围
+func (f *fakeUserRepository) GetByIDInScope(id int, scope *domain.DataScope) (*userDomain.User, error) {
+	if scope == nil || scope.All || (scope.Self && scope.UserID == int64(id)) {
+		return &userDomain.User{ID: int64(id)}, nil
+	}
+	for _, userId := range scope.DeptUserIDs {
+		if userId == int64(id) {
+			return &userDomain.User{ID: int64(id)}, nil
+		}
+	}
+	return nil, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+}
+
+func (f *fakeUserRepository) Create(newUser *userDomain.User) (*userDomain.User, error) {
+	f.created++
+	newUser.ID = 100 + f.created
+	return newUser, nil
+}
+
+func (f *fakeUserRepository) Delete(id int) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+type fakeUserRoleRepository struct {
+	userRoleRepo.ISysUserRoleRepository
+	inserted map[int64]map[string]any
+}
+
+func (f *fakeUserRoleRepository) Insert(userId int64, updateMap map[string]any) error {
+	f.inserted[userId] = updateMap
+	return nil
+}
+
+type fakePasswordHistoryRepository struct {
+	passwordHistoryRepo.IPasswordHistoryRepository
+	hashes map[int64][]string
+}
+
+func (f *fakePasswordHistoryRepository) Add(userId int64, hashPassword string, keep int) error {
+	f.hashes[userId] = append(f.hashes[userId], hashPassword)
+	return nil
+}
+
+func newTestUserUseCase() (*UserUseCase, *fakeUserRoleRepository) {
+	userRoles := &fakeUserRoleRepository{inserted: map[int64]map[string]any{}}
+	return &UserUseCase{
+		roleRepository:     &fakeRoleRepository{},
+		userRepository:     &fakeUserRepository{updated: map[int64]map[string]interface{}{}},
+		userRoleRepository: userRoles,
+		Logger:             &logger.Logger{Log: zap.NewNop()},
+	}, userRoles
+}
+
+func newTestUserUseCaseWithUsers() (*UserUseCase, *fakeRoleRepository, *fakeUserRepository) {
+	s, _ := newTestUserUseCase()
+	roles := &fakeRoleRepository{}
+	users := &fakeUserRepository{updated: map[int64]map[string]interface{}{}}
+	s.roleRepository = roles
+	s.userRepository = users
+	return s, roles, users
+}
+
+func bindMap(roleIds ...string) map[string]interface{} {
+	items := make([]interface{}, 0, len(roleIds))
+	for _, id := range roleIds {
+		items = append(items, id)
+	}
+	return map[string]interface{}{"roleIds": items}
+}
+
+func isSuperAdminRequired(err error) bool {
+	var appErr *domainErrors.AppError
+	return errors.As(err, &appErr) && appErr.Err == ErrSuperAdminRequired
+}
+
+func TestUserBindRoles_SuperRoleRequiresSuperOperator(t *testing.T) {
+	s, userRoles := newTestUserUseCase()
+
+	err := s.UserBindRoles(normalUserId, bindMap("1"), []int64{normalRoleId}, allScope)
+	assert.True(t, isSuperAdminRequired(err))
+	assert.Empty(t, userRoles.inserted)
+
+	err = s.UserBindRoles(normalUserId, bindMap("1", "2"), []int64{normalRoleId, superRoleId}, allScope)
+	assert.NoError(t, err)
+	assert.Contains(t, userRoles.inserted, normalUserId)
+}
+
+func TestUserBindRoles_SuperUserRequiresSuperOperator(t *testing.T) {
+	s, userRoles := newTestUserUseCase()
+
+	err := s.UserBindRoles(superUserId, bindMap("2"), []int64{normalRoleId}, allScope)
+	assert.True(t, isSuperAdminRequired(err))
+
+	err = s.UserBindRoles(normalUserId, bindMap("2"), []int64{normalRoleId}, allScope)
+	assert.NoError(t, err)
+	assert.NotContains(t, userRoles.inserted, superUserId)
+	assert.Contains(t, userRoles.inserted, normalUserId)
+}
+
+func TestPasswordChanges_SuperUserRequiresSuperOperator(t *testing.T) {
+	s, _ := newTestUserUseCase()
+
+	_, err := s.ResetPassword(superUserId, userDomain.ResetMethodPassword, []int64{normalRoleId}, allScope)
+	assert.True(t, isSuperAdminRequired(err))
+
+	_, err = s.EditPassword(superUserId, userDomain.PasswordEditRequest{OldPassword: "old", NewPasswd: "new"}, nil)
+	assert.True(t, isSuperAdminRequired(err))
+}
+
+func TestUpdateAndDelete_SuperUserRequiresSuperOperator(t *testing.T) {
+	s, _, users := newTestUserUseCaseWithUsers()
+
+	_, err := s.Update(superUserId, map[string]interface{}{"email": "new@example.com"}, []int64{normalRoleId}, allScope)
+	assert.True(t, isSuperAdminRequired(err))
+	err = s.Delete(int(superUserId), []int64{normalRoleId}, allScope)
+	assert.True(t, isSuperAdminRequired(err))
+	assert.Empty(t, users.updated)
+	assert.Empty(t, users.deleted)
+
+	_, err = s.Update(superUserId, map[string]interface{}{"email": "new@example.com"}, []int64{superRoleId}, allScope)
+	assert.NoError(t, err)
+	_, err = s.Update(normalUserId, map[string]interface{}{"status": float64(2)}, []int64{normalRoleId}, allScope)
+	assert.NoError(t, err)
+}
+
+func TestUpdate_KeepsLastSuperUserEnabled(t *testing.T) {
+	s, roles, users := newTestUserUseCaseWithUsers()
+	roles.lastSuper = true
+
+	_, err := s.Update(superUserId, map[string]interface{}{"status": float64(2)}, []int64{superRoleId}, allScope)
+	assert.Error(t, err)
+	assert.NotContains(t, users.updated, superUserId)
+
+	_, err = s.Update(superUserId, map[string]interface{}{"status": float64(1)}, []int64{superRoleId}, allScope)
+	assert.NoError(t, err)
+}
+
+func TestWritesOutsideDataScopeAreNotFound(t *testing.T) {
+	s, _, users := newTestUserUseCaseWithUsers()
+	userRoles := &fakeUserRoleRepository{inserted: map[int64]map[string]any{}}
+	s.userRoleRepository = userRoles
+	deptScope := &domain.DataScope{UserID: 30, Self: true, DeptUserIDs: []int64{31}}
+
+	isNotFound := func(err error) bool {
+		var appErr *domainErrors.AppError
+		return errors.As(err, &appErr) && appErr.Type == domainErrors.NotFound
+	}
+
+	_, err := s.Update(normalUserId, map[string]interface{}{"email": "new@example.com"}, []int64{normalRoleId}, deptScope)
+	assert.True(t, isNotFound(err))
+	err = s.Delete(int(normalUserId), []int64{normalRoleId}, deptScope)
+	assert.True(t, isNotFound(err))
+	err = s.UserBindRoles(normalUserId, bindMap("2"), []int64{normalRoleId}, deptScope)
+	assert.True(t, isNotFound(err))
+	result, err := s.ResetPassword(normalUserId, userDomain.ResetMethodPassword, []int64{normalRoleId}, deptScope)
+	assert.True(t, isNotFound(err))
+	assert.Nil(t, result)
+	assert.Empty(t, users.updated)
+	assert.Empty(t, users.deleted)
+	assert.Empty(t, userRoles.inserted)
+
+	_, err = s.Update(31, map[string]interface{}{"email": "new@example.com"}, []int64{normalRoleId}, deptScope)
+	assert.NoError(t, err)
+}
+
+func TestCreate_RecordsInitialPasswordHistory(t *testing.T) {
+	s, _ := newTestUserUseCase()
+	history := &fakePasswordHistoryRepository{hashes: map[int64][]string{}}
+	s.passwordHistoryRepository = history
+	s.passwordPolicy = passwordLib.New(passwordLib.Config{MinLength: 8, MaxLength: 72, RequireDigit: true, HistoryCount: 5}, s.Logger)
+
+	created, err := s.Create(&userDomain.User{UserName: "alice", Password: "Secret123"})
+	assert.NoError(t, err)
+	assert.Len(t, history.hashes[created.ID], 1)
+	assert.True(t, sharedUtil.CheckPasswordHash("Secret123", history.hashes[created.ID][0]))
+
+	generated, err := s.Create(&userDomain.User{UserName: "bob"})
+	assert.NoError(t, err)
+	assert.Len(t, history.hashes[generated.ID], 1)
+	assert.True(t, sharedUtil.CheckPasswordHash(generated.Password, history.hashes[generated.ID][0]))
+}
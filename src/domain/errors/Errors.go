@@ -123,6 +123,6 @@ func AppErrorToHTTP(appErr *AppError) (int, string) {
 	case CaptchaError:
 		return http.StatusBadRequest, appErr.Error()
 	default:
-		return http.StatusInternalServerError, "Internal Server Error"
+		return http.StatusInternalServerError, InternalServerErrorMessage
 	}
 }
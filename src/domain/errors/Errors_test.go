@@ -182,3 +182,14 @@ func TestErrorTypeConstants(t *testing.T) {
 	assert.Equal(t, ErrorType("TokenGeneratorError"), TokenGeneratorError)
 	assert.Equal(t, ErrorType("UnknownError"), UnknownError)
 }
+
+func TestLocalizedMessage(t *testing.T) {
+	message, ok := LocalizedMessage("zh-CN", NewAppErrorWithType(NotFound).Error())
+	assert.True(t, ok)
+	assert.Equal(t, "记录不存在", message)
+
+	_, ok = LocalizedMessage("zh-CN", "menu 3 not found")
+	assert.False(t, ok)
+	_, ok = LocalizedMessage("fr", InternalServerErrorMessage)
+	assert.False(t, ok)
+}
@@ -0,0 +1,38 @@
+package errors
+
+// InternalServerErrorMessage 未知错误对外统一返回的消息
+const InternalServerErrorMessage = "Internal Server Error"
+
+// localizedMessages 内置错误消息的翻译，按语言区分，键为英文原文；数据库中的翻译优先
+var localizedMessages = map[string]map[string]string{
+	"zh-CN": {
+		string(notFoundMessage):              "记录不存在",
+		string(validationErrorMessage):       "参数校验失败",
+		string(alreadyExistsErrorMessage):    "资源已存在",
+		string(repositoryErrorMessage):       "数据操作失败",
+		string(notAuthenticatedErrorMessage): "未登录",
+		string(tokenGeneratorErrorMessage):   "令牌生成失败",
+		string(notAuthorizedErrorMessage):    "没有权限",
+		string(unknownErrorMessage):          "服务异常",
+		string(TokenErrorMessage):            "令牌无效",
+		string(TokenExpiredMessage):          "令牌已过期",
+		string(UploadErrorMessage):           "上传失败",
+		string(CaptchaErrorMessage):          "验证码错误",
+		InternalServerErrorMessage:           "服务器内部错误",
+	},
+}
+
+// LocalizedMessage 返回内置错误消息在指定语言下的翻译
+func LocalizedMessage(locale string, message string) (string, bool) {
+	translated, ok := localizedMessages[locale][message]
+	return translated, ok
+}
+
+// MessageLocales 内置翻译覆盖的语言
+func MessageLocales() []string {
+	locales := make([]string, 0, len(localizedMessages))
+	for locale := range localizedMessages {
+		locales = append(locales, locale)
+	}
+	return locales
+}
@@ -3,4 +3,6 @@ package jwt_blacklist
 type IJwtBlacklistService interface {
 	AddToBlacklist(jwtToken string) error
 	IsJwtInBlacklist(token string) (bool, error)
+	// RevokeUserTokens 注销用户此前签发的全部令牌
+	RevokeUserTokens(userId int64) error
 }
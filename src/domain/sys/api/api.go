@@ -10,13 +10,15 @@ import (
 )
 
 type Api struct {
-	ID          int       `json:"id"`
-	Path        string    `json:"path"`
-	ApiGroup    string    `json:"api_group"`
-	Method      string    `json:"method"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int    `json:"id"`
+	Path        string `json:"path"`
+	ApiGroup    string `json:"api_group"`
+	Method      string `json:"method"`
+	Description string `json:"description"`
+	// Stale 路由已不存在，由同步接口标记
+	Stale     bool      `json:"stale"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type IApiService interface {
@@ -29,12 +31,40 @@ type IApiService interface {
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*Api, error)
 	GetApisGroup(path string) (*[]GroupApiItem, error)
-	SynchronizeRouterToApi(router gin.RoutesInfo) (*int, error)
+	SynchronizeRouterToApi(router gin.RoutesInfo, options SyncOptions) (*SyncResult, error)
 	GenerateTemplate() (*bytes.Buffer, error)
 	Export() (*bytes.Buffer, error)
 	Import(src multipart.File) (*[]Api, *int, *int, error)
 }
 
+// 同步时对已不存在的路由的处理方式
+const (
+	SyncStaleKeep   = "keep"
+	SyncStaleFlag   = "flag"
+	SyncStaleDelete = "delete"
+)
+
+type SyncOptions struct {
+	// DryRun 只返回差异，不写入数据库
+	DryRun bool
+	Stale  string
+}
+
+type RenamedApi struct {
+	From Api `json:"from"`
+	To   Api `json:"to"`
+}
+
+// SyncResult 路由与 sys_apis 的差异，Removed 为已不存在的路由。
+// Suggested 为同分组下描述相同的新旧路由，只供人工确认，新旧路由仍分别按 Added 和 Removed 处理
+type SyncResult struct {
+	Added     []Api        `json:"added"`
+	Removed   []Api        `json:"removed"`
+	Renamed   []RenamedApi `json:"renamed"`
+	Suggested []RenamedApi `json:"suggested"`
+	Updated   []Api        `json:"updated"`
+}
+
 type GroupApiItem struct {
 	GroupName       string          `json:"title"`
 	GroupKey        string          `json:"key"`
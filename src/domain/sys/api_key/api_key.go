@@ -0,0 +1,38 @@
+package api_key
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+)
+
+// ApiKey 用户个人API密钥，密钥明文只在创建时返回一次
+type ApiKey struct {
+	ID         int64             `json:"id"`
+	UserID     int64             `json:"user_id"`
+	RoleID     int64             `json:"role_id"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+	KeyHash    string            `json:"-"`
+	Paths      []string          `json:"paths"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	LastUsedIP string            `json:"last_used_ip"`
+	RevokedAt  *time.Time        `json:"revoked_at"`
+	CreatedAt  domain.CustomTime `json:"created_at"`
+	UpdatedAt  domain.CustomTime `json:"updated_at"`
+}
+
+// CreatedApiKey 创建结果，Key 为完整密钥明文
+type CreatedApiKey struct {
+	ApiKey
+	Key string `json:"key"`
+}
+
+// IsActive 未吊销且未过期
+func (k *ApiKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,78 @@
+package dept
+
+import (
+	"github.com/gbrayhan/microservices-go/src/domain"
+)
+
+// Dept 组织部门，ParentID 为 0 表示顶级部门
+type Dept struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	ParentID  int64             `json:"parent_id"`
+	Order     int64             `json:"order"`
+	Status    int16             `json:"status"`
+	CreatedAt domain.CustomTime `json:"created_at"`
+	UpdatedAt domain.CustomTime `json:"updated_at"`
+}
+
+type DeptNode struct {
+	ID       string      `json:"value"`
+	Name     string      `json:"title"`
+	Key      string      `json:"key"`
+	Path     []int64     `json:"path"`
+	Children []*DeptNode `json:"children"`
+}
+
+type DeptTree struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	ParentID  int64             `json:"parent_id"`
+	Order     int64             `json:"order"`
+	Status    int16             `json:"status"`
+	LeaderIDs []int64           `json:"leader_ids"`
+	CreatedAt domain.CustomTime `json:"created_at"`
+	UpdatedAt domain.CustomTime `json:"updated_at"`
+	Path      []int64           `json:"path"`
+	Children  []*DeptTree       `json:"children"`
+}
+
+// Member 部门成员，IsLeader 为部门负责人
+type Member struct {
+	DeptID   int64 `json:"dept_id"`
+	UserID   int64 `json:"user_id"`
+	IsLeader bool  `json:"is_leader"`
+}
+
+type IDeptService interface {
+	GetAll(status int) ([]*DeptTree, error)
+	GetByID(id int64) (*Dept, error)
+	Create(newDept *Dept) (*Dept, error)
+	Update(id int64, deptMap map[string]interface{}) (*Dept, error)
+	Delete(id int64) error
+	GetTreeDepts(status int) (*DeptNode, error)
+	GetMembers(deptId int64) ([]Member, error)
+	SetMembers(deptId int64, userIds []int64, leaderIds []int64) error
+	GetUserDepts(userId int64) ([]Member, error)
+}
+
+// WithDescendants 返回给定部门及其全部下级部门的ID
+func WithDescendants(depts []Dept, ids []int64) []int64 {
+	children := make(map[int64][]int64)
+	for _, d := range depts {
+		children[d.ParentID] = append(children[d.ParentID], d.ID)
+	}
+	seen := make(map[int64]bool)
+	result := make([]int64, 0, len(ids))
+	queue := append([]int64{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+	return result
+}
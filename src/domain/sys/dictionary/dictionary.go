@@ -29,4 +29,6 @@ type IDictionaryService interface {
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*Dictionary, error)
 	GetByType(typeText string) (*Dictionary, error)
+	// GetByTypes 按 type 批量获取启用的字典，不存在的类型不出现在结果中
+	GetByTypes(types []string) (map[string]*Dictionary, error)
 }
@@ -1,9 +1,11 @@
 package dictionary_detail
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
+	"github.com/gbrayhan/microservices-go/src/domain/sys/translation"
 )
 
 type DictionaryDetail struct {
@@ -29,3 +31,13 @@ type IDictionaryDetailService interface {
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*DictionaryDetail, error)
 }
+
+// TranslateLabels 按字典详情 ID 将展示值翻译为指定语言，locale 为空时不处理
+func TranslateLabels(details []DictionaryDetail, translationService translation.ITranslationService, locale string) {
+	if locale == "" || translationService == nil {
+		return
+	}
+	for i := range details {
+		details[i].Label = translationService.Translate(locale, translation.ScopeDictionaryDetail, strconv.Itoa(details[i].ID), details[i].Label)
+	}
+}
@@ -0,0 +1,34 @@
+package invitation
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+)
+
+// Invitation 管理员发出的注册邀请，绑定邮箱和注册后授予的角色
+type Invitation struct {
+	ID             int64             `json:"id"`
+	Email          string            `json:"email"`
+	RoleID         int64             `json:"role_id"`
+	InvitedBy      int64             `json:"invited_by"`
+	TokenHash      string            `json:"-"`
+	ExpiresAt      time.Time         `json:"expires_at"`
+	AcceptedAt     *time.Time        `json:"accepted_at"`
+	AcceptedUserID int64             `json:"accepted_user_id"`
+	RevokedAt      *time.Time        `json:"revoked_at"`
+	CreatedAt      domain.CustomTime `json:"created_at"`
+	UpdatedAt      domain.CustomTime `json:"updated_at"`
+}
+
+// CreatedInvitation 创建结果，Token 明文只返回一次
+type CreatedInvitation struct {
+	Invitation
+	Token string `json:"token"`
+	Link  string `json:"link"`
+}
+
+// IsUsable 未使用、未撤销且未过期
+func (i *Invitation) IsUsable(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -43,6 +43,34 @@ type MenuGroup struct {
 	Items []*Menu `json:"items"`
 }
 
+// MenuMoveNode 新菜单树中的一个节点，Children 的顺序即 sort
+type MenuMoveNode struct {
+	ID       int            `json:"id" binding:"required"`
+	Children []MenuMoveNode `json:"children"`
+}
+
+// MenuMoveRequest 只需包含要调整的子树，ParentID 为 Items 的父菜单，0 表示顶层；
+// MenuGroupId 不为 0 时所有节点必须属于该分组
+type MenuMoveRequest struct {
+	MenuGroupId int            `json:"menu_group_id"`
+	ParentID    int            `json:"parent_id"`
+	Items       []MenuMoveNode `json:"items" binding:"required"`
+}
+
+// MenuPosition 菜单在树中的位置，MenuLevel 为深度，顶层为 0
+type MenuPosition struct {
+	ID        int
+	ParentID  int
+	Sort      int8
+	MenuLevel int
+}
+
+// UserMenus 用户菜单及其 ETag，ETag 由菜单内容计算，内容不变时保持不变
+type UserMenus struct {
+	ETag   string       `json:"etag"`
+	Groups []*MenuGroup `json:"groups"`
+}
+
 type IMenuService interface {
 	GetAll(groupId int) ([]*Menu, error)
 	GetByID(id int) (*Menu, error)
@@ -50,5 +78,10 @@ type IMenuService interface {
 	Delete(id int) error
 	Update(id int, userMap map[string]interface{}) (*Menu, error)
 	GetOneByMap(userMap map[string]interface{}) (*Menu, error)
-	GetUserMenus(roleId int64) ([]*MenuGroup, error)
+	GetUserMenus(roleIds []int64) ([]*MenuGroup, error)
+	// GetCachedUserMenus locale 不为空时菜单标题按该语言翻译
+	GetCachedUserMenus(roleIds []int64, locale string) (*UserMenus, error)
+	Move(request MenuMoveRequest) ([]*Menu, error)
+	GetMenuApis(menuId int64) (*MenuApis, error)
+	UpdateMenuApis(menuApis *MenuApis) error
 }
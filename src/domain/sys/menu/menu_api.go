@@ -0,0 +1,9 @@
+package menu
+
+// MenuApis 菜单及其按钮依赖的接口，角色被授予菜单或按钮时自动获得这些接口的策略
+type MenuApis struct {
+	MenuID int64   `json:"menu_id"`
+	Apis   []int64 `json:"apis"`
+	// Buttons 按钮ID -> 接口ID
+	Buttons map[int64][]int64 `json:"buttons"`
+}
@@ -18,9 +18,11 @@ type SysOperationRecord struct {
 	Body         string
 	Resp         string
 	UserID       int64
-	CreatedAt    domain.CustomTime
-	UpdatedAt    domain.CustomTime
-	DeletedAt    time.Time
+	// ImpersonatorID 模拟登录期间产生的记录保存真实管理员ID
+	ImpersonatorID int64
+	CreatedAt      domain.CustomTime
+	UpdatedAt      domain.CustomTime
+	DeletedAt      time.Time
 }
 type ISysOperationRecordService interface {
 	GetAll() (*[]SysOperationRecord, error)
@@ -0,0 +1,105 @@
+package permission
+
+import "time"
+
+// BundleVersion 当前权限包格式的版本，导入时拒绝更高的版本
+const BundleVersion = 1
+
+// Bundle 一个环境的完整授权配置。导入按自然键匹配：菜单分组和菜单按名称，按钮按菜单+名称，
+// 参数按菜单+类型+键，接口按路径+方法，角色按名称
+type Bundle struct {
+	Version    int               `json:"version" yaml:"version"`
+	ExportedAt time.Time         `json:"exported_at" yaml:"exported_at"`
+	MenuGroups []BundleMenuGroup `json:"menu_groups" yaml:"menu_groups"`
+	Menus      []BundleMenu      `json:"menus" yaml:"menus"`
+	Apis       []BundleApi       `json:"apis" yaml:"apis"`
+	Roles      []BundleRole      `json:"roles" yaml:"roles"`
+}
+
+type BundleMenuGroup struct {
+	Name   string `json:"name" yaml:"name"`
+	Path   string `json:"path" yaml:"path"`
+	Sort   int8   `json:"sort" yaml:"sort"`
+	Status int16  `json:"status" yaml:"status"`
+}
+
+// BundleMenu 菜单树节点，Group 为菜单分组名称
+type BundleMenu struct {
+	Name       string            `json:"name" yaml:"name"`
+	Path       string            `json:"path" yaml:"path"`
+	Title      string            `json:"title" yaml:"title"`
+	Icon       string            `json:"icon" yaml:"icon"`
+	Component  string            `json:"component" yaml:"component"`
+	Hidden     bool              `json:"hidden" yaml:"hidden"`
+	Sort       int8              `json:"sort" yaml:"sort"`
+	KeepAlive  int16             `json:"keep_alive" yaml:"keep_alive"`
+	MenuLevel  int               `json:"menu_level" yaml:"menu_level"`
+	Group      string            `json:"group" yaml:"group"`
+	Buttons    []BundleButton    `json:"buttons,omitempty" yaml:"buttons,omitempty"`
+	Parameters []BundleParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Children   []BundleMenu      `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+type BundleButton struct {
+	Name string `json:"name" yaml:"name"`
+	Desc string `json:"desc" yaml:"desc"`
+}
+
+type BundleParameter struct {
+	Type  string `json:"type" yaml:"type"`
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type BundleApi struct {
+	Path        string `json:"path" yaml:"path"`
+	Method      string `json:"method" yaml:"method"`
+	Description string `json:"description" yaml:"description"`
+	ApiGroup    string `json:"api_group" yaml:"api_group"`
+}
+
+type BundleApiRef struct {
+	Path   string `json:"path" yaml:"path"`
+	Method string `json:"method" yaml:"method"`
+}
+
+// BundleRole 角色及其绑定，Parent 为父角色名称，Buttons 为菜单名称 -> 按钮名称
+type BundleRole struct {
+	Name            string              `json:"name" yaml:"name"`
+	Parent          string              `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Label           string              `json:"label" yaml:"label"`
+	Description     string              `json:"description" yaml:"description"`
+	DefaultRouter   string              `json:"default_router" yaml:"default_router"`
+	Status          int16               `json:"status" yaml:"status"`
+	Order           int64               `json:"order" yaml:"order"`
+	IsSuper         bool                `json:"is_super" yaml:"is_super"`
+	DataScope       string              `json:"data_scope" yaml:"data_scope"`
+	DataScopeFilter map[string][]string `json:"data_scope_filter,omitempty" yaml:"data_scope_filter,omitempty"`
+	Menus           []string            `json:"menus" yaml:"menus"`
+	Buttons         map[string][]string `json:"buttons,omitempty" yaml:"buttons,omitempty"`
+	Apis            []BundleApiRef      `json:"apis" yaml:"apis"`
+}
+
+// 导入时对单个对象执行的操作
+const (
+	BundleActionCreate = "create"
+	BundleActionUpdate = "update"
+	BundleActionAdd    = "add"
+	BundleActionRemove = "remove"
+)
+
+// BundleChange 导入产生的一项变化，Kind 为 menu_group、menu、button、parameter、api、role、
+// role_parent、role_menu、role_button、role_api，Fields 为 update 时变化的字段
+type BundleChange struct {
+	Kind   string   `json:"kind"`
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// BundleImportResult DryRun 时 Changes 为实际导入会产生的变化，数据不会被修改
+type BundleImportResult struct {
+	DryRun    bool           `json:"dry_run"`
+	Changes   []BundleChange `json:"changes"`
+	Unchanged int            `json:"unchanged"`
+}
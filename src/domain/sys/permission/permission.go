@@ -0,0 +1,70 @@
+package permission
+
+// CheckRequest 模拟一次接口调用，RoleID 与 UserID 二选一，指定用户时使用其全部角色
+type CheckRequest struct {
+	RoleID int64  `json:"role_id"`
+	UserID int64  `json:"user_id"`
+	Path   string `json:"path" binding:"required"`
+	Method string `json:"method" binding:"required"`
+}
+
+// RoleDecision 单个角色的判定结果，Policy 为命中的策略行 [角色, 路径, 方法]
+type RoleDecision struct {
+	RoleID   int64    `json:"role_id"`
+	RoleName string   `json:"role_name"`
+	Allowed  bool     `json:"allowed"`
+	Super    bool     `json:"super"`
+	Policy   []string `json:"policy,omitempty"`
+	// Inherited 命中的策略来自父角色
+	Inherited bool `json:"inherited"`
+}
+
+// CheckResult 任一角色允许即允许，与 CasbinMiddleware 一致
+type CheckResult struct {
+	Allowed bool           `json:"allowed"`
+	Path    string         `json:"path"`
+	Method  string         `json:"method"`
+	Roles   []RoleDecision `json:"roles"`
+}
+
+// ApiAccess 可以访问某个接口的角色
+type ApiAccess struct {
+	ApiID  int            `json:"api_id"`
+	Path   string         `json:"path"`
+	Method string         `json:"method"`
+	Roles  []RoleDecision `json:"roles"`
+}
+
+// casbin_rule 与 sys_apis、sys_roles 不一致的原因
+const (
+	DriftRoleMissing = "role_missing"
+	DriftApiMissing  = "api_missing"
+	DriftDuplicate   = "duplicate"
+)
+
+// PolicyDrift 一条不一致的 casbin_rule 记录
+type PolicyDrift struct {
+	ID     int64  `json:"id"`
+	PType  string `json:"ptype"`
+	V0     string `json:"v0"`
+	V1     string `json:"v1"`
+	V2     string `json:"v2"`
+	Reason string `json:"reason"`
+}
+
+// ConsistencyReport EnforcerInSync 为内存中的策略与 casbin_rule 表一致
+type ConsistencyReport struct {
+	Drifts         []PolicyDrift `json:"drifts"`
+	EnforcerInSync bool          `json:"enforcer_in_sync"`
+	Repaired       bool          `json:"repaired"`
+}
+
+type IPermissionService interface {
+	Check(request CheckRequest) (*CheckResult, error)
+	GetApiRoles(apiId int) (*ApiAccess, error)
+	CheckConsistency() (*ConsistencyReport, error)
+	// RepairConsistency 删除不一致的记录并重新加载 enforcer
+	RepairConsistency() (*ConsistencyReport, error)
+	ExportBundle() (*Bundle, error)
+	ImportBundle(bundle *Bundle, dryRun bool, operatorRoleIds []int64) (*BundleImportResult, error)
+}
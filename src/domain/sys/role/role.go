@@ -5,16 +5,20 @@ import (
 )
 
 type Role struct {
-	ID            int64             `json:"id"`
-	Name          string            `json:"name"`
-	ParentID      int64             `json:"parent_id"`
-	DefaultRouter string            `json:"default_router"`
-	Status        int16             `json:"status"`
-	Order         int64             `json:"order"`
-	Label         string            `json:"label"`
-	Description   string            `json:"description"`
-	CreatedAt     domain.CustomTime `json:"created_at"`
-	UpdatedAt     domain.CustomTime `json:"updated_at"`
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	ParentID      int64  `json:"parent_id"`
+	DefaultRouter string `json:"default_router"`
+	Status        int16  `json:"status"`
+	Order         int64  `json:"order"`
+	Label         string `json:"label"`
+	Description   string `json:"description"`
+	IsSuper       bool   `json:"is_super"`
+	// DataScope 数据权限范围：all、self、dept、custom
+	DataScope       string              `json:"data_scope"`
+	DataScopeFilter map[string][]string `json:"data_scope_filter,omitempty"`
+	CreatedAt       domain.CustomTime   `json:"created_at"`
+	UpdatedAt       domain.CustomTime   `json:"updated_at"`
 }
 type SearchResultRole struct {
 	Data       *[]Role `json:"data"`
@@ -33,18 +37,30 @@ type RoleNode struct {
 }
 
 type RoleTree struct {
-	ID            int64             `json:"id"`
-	Name          string            `json:"name"`
-	ParentID      int64             `json:"parent_id"`
-	DefaultRouter string            `json:"default_router"`
-	Status        int16             `json:"status"`
-	Order         int64             `json:"order"`
-	Label         string            `json:"label"`
-	Description   string            `json:"description"`
-	CreatedAt     domain.CustomTime `json:"created_at"`
-	UpdatedAt     domain.CustomTime `json:"updated_at"`
-	Path          []int64           `json:"path"`
-	Children      []*RoleTree       `json:"children"`
+	ID              int64               `json:"id"`
+	Name            string              `json:"name"`
+	ParentID        int64               `json:"parent_id"`
+	DefaultRouter   string              `json:"default_router"`
+	Status          int16               `json:"status"`
+	Order           int64               `json:"order"`
+	Label           string              `json:"label"`
+	Description     string              `json:"description"`
+	IsSuper         bool                `json:"is_super"`
+	DataScope       string              `json:"data_scope"`
+	DataScopeFilter map[string][]string `json:"data_scope_filter,omitempty"`
+	CreatedAt       domain.CustomTime   `json:"created_at"`
+	UpdatedAt       domain.CustomTime   `json:"updated_at"`
+	Path            []int64             `json:"path"`
+	Children        []*RoleTree         `json:"children"`
+}
+
+// ApiGrant 角色自身的一条接口策略。Explicit 为直接绑定的接口，
+// ImpliedBy 为关联了该接口且已授予角色的菜单或按钮（菜单名/按钮名）
+type ApiGrant struct {
+	Path      string   `json:"path"`
+	Method    string   `json:"method"`
+	Explicit  bool     `json:"explicit"`
+	ImpliedBy []string `json:"implied_by"`
 }
 
 type IRoleService interface {
@@ -60,6 +76,21 @@ type IRoleService interface {
 	GetRoleMenuIds(id int64) (map[int][]int, map[int64][]int64, error)
 	UpdateRoleMenuIds(id int, updateMap map[string]any) error
 	GetApiRuleList(roleId int) ([]string, error)
+	GetEffectiveApiRules(roleId int) ([]string, error)
+	GetApiGrants(roleId int64) ([]ApiGrant, error)
 	BindApiRule(roleId int, updateMap map[string]interface{}) error
 	BindRoleMenuBtns(roleId int64, updateMap map[string]interface{}) error
+	GetRoleFields(roleId int64) (map[string][]string, error)
+	BindRoleFields(roleId int64, updateMap map[string]interface{}) error
+	GetHiddenFields(roleIds []int64, resource string) (domain.FieldMask, error)
+	IsSuperRole(roleIds ...int64) (bool, error)
+	GetDataScope(userId int64, roleIds []int64) (*domain.DataScope, error)
+	CloneRole(sourceId int64, newRole *Role) (*Role, error)
+	GetTemplates() ([]PermissionTemplate, error)
+	GetTemplate(id int64) (*PermissionTemplate, error)
+	CreateTemplate(template *PermissionTemplate, fromRoleId int64) (*PermissionTemplate, error)
+	UpdateTemplate(id int64, template *PermissionTemplate) (*PermissionTemplate, error)
+	DeleteTemplate(id int64) error
+	DiffTemplate(roleId int64, templateId int64, mode string) (*PermissionDiff, error)
+	ApplyTemplate(roleId int64, templateId int64, mode string) (*PermissionDiff, error)
 }
@@ -0,0 +1,46 @@
+package role
+
+import (
+	"github.com/gbrayhan/microservices-go/src/domain"
+)
+
+// 模板应用方式：replace 用模板覆盖角色权限，merge 在角色现有权限上追加模板权限
+const (
+	TemplateApplyReplace = "replace"
+	TemplateApplyMerge   = "merge"
+)
+
+// PermissionSet 角色的菜单、按钮、接口和隐藏字段
+type PermissionSet struct {
+	Menus []int64 `json:"menus"`
+	// Btns 菜单ID -> 按钮ID
+	Btns map[int64][]int64 `json:"btns"`
+	// Apis 格式与 GetApiRuleList 相同：path---method
+	Apis []string `json:"apis"`
+	// Fields 资源 -> 隐藏字段
+	Fields map[string][]string `json:"fields"`
+}
+
+// PermissionTemplate 可以套用到任意角色的命名权限模板
+type PermissionTemplate struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Permissions PermissionSet     `json:"permissions"`
+	CreatedAt   domain.CustomTime `json:"created_at"`
+	UpdatedAt   domain.CustomTime `json:"updated_at"`
+}
+
+// PermissionDiff 角色套用模板后会新增和移除的权限
+type PermissionDiff struct {
+	Added   PermissionSet `json:"added"`
+	Removed PermissionSet `json:"removed"`
+}
+
+// Empty 套用模板不会改变角色权限
+func (d *PermissionDiff) Empty() bool {
+	return len(d.Added.Menus) == 0 && len(d.Removed.Menus) == 0 &&
+		len(d.Added.Btns) == 0 && len(d.Removed.Btns) == 0 &&
+		len(d.Added.Apis) == 0 && len(d.Removed.Apis) == 0 &&
+		len(d.Added.Fields) == 0 && len(d.Removed.Fields) == 0
+}
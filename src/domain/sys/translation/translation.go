@@ -0,0 +1,39 @@
+package translation
+
+import (
+	"github.com/gbrayhan/microservices-go/src/domain"
+)
+
+// 翻译范围，不同范围下 Key 的含义不同
+const (
+	// ScopeMenu Key 为菜单 name
+	ScopeMenu = "menu"
+	// ScopeDictionaryDetail Key 为字典详情 ID
+	ScopeDictionaryDetail = "dictionary_detail"
+	// ScopeError Key 为错误消息原文
+	ScopeError = "error"
+)
+
+// Translation 某个语言下一段文本的翻译，Locale 如 zh-CN、en
+type Translation struct {
+	ID        int64             `json:"id"`
+	Locale    string            `json:"locale"`
+	Scope     string            `json:"scope"`
+	Key       string            `json:"key"`
+	Value     string            `json:"value"`
+	CreatedAt domain.CustomTime `json:"created_at"`
+	UpdatedAt domain.CustomTime `json:"updated_at"`
+}
+
+type ITranslationService interface {
+	GetAll(locale string, scope string) ([]Translation, error)
+	// Save 按 locale、scope、key 新增或覆盖翻译
+	Save(translations []Translation) ([]Translation, error)
+	Delete(id int64) error
+	// Locales 已有翻译的语言
+	Locales() []string
+	// ResolveLocale 按 Accept-Language 的优先级返回第一个有翻译的语言，都没有时返回空字符串
+	ResolveLocale(acceptLanguage string) string
+	// Translate 没有对应翻译时返回 fallback
+	Translate(locale string, scope string, key string, fallback string) string
+}
@@ -31,6 +31,41 @@ type DataFilters struct {
 	SortDirection    SortDirection       `json:"sortDirection"`
 	Page             int                 `json:"page"`
 	PageSize         int                 `json:"pageSize"`
+	// DataScope 请求用户的数据权限，为 nil 时不限制
+	DataScope *DataScope `json:"-"`
+}
+
+const (
+	DataScopeAll    = "all"
+	DataScopeSelf   = "self"
+	DataScopeDept   = "dept"
+	DataScopeCustom = "custom"
+)
+
+// DataScope 由用户全部角色的数据权限合并而来，满足任一条件的记录可见
+type DataScope struct {
+	All    bool
+	UserID int64
+	Self   bool
+	// DeptUserIDs 同部门及所负责下级部门的成员
+	DeptUserIDs []int64
+	// Custom 每个过滤条件内字段之间为与，条件之间为或
+	Custom []map[string][]string
+}
+
+// FieldResourceUser 字段权限的资源名，对应 /user 接口的响应
+const FieldResourceUser = "user"
+
+// MaskableFields 各资源允许按角色隐藏的响应字段，供角色设置页面选择
+var MaskableFields = map[string][]string{
+	FieldResourceUser: {"phone", "email"},
+}
+
+// FieldMask 对请求用户隐藏的响应字段
+type FieldMask map[string]bool
+
+func (m FieldMask) Hidden(field string) bool {
+	return m[field]
 }
 
 type CommonResponse[T interface{}] struct {
@@ -7,21 +7,30 @@ import (
 	roleDomain "github.com/gbrayhan/microservices-go/src/domain/sys/role"
 )
 
+const (
+	UserStatusEnabled  int16 = 1
+	UserStatusDisabled int16 = 2
+	// UserStatusPending 自助注册后等待邮箱验证，验证前不能登录
+	UserStatusPending int16 = 3
+)
+
 type User struct {
-	ID            int64             `json:"id"`
-	UUID          string            `json:"uuid"`
-	UserName      string            `json:"user_name"`
-	NickName      string            `json:"nick_name"`
-	Email         string            `json:"email"`
-	Status        int16             `json:"status"`
-	HashPassword  string            `json:"hash_password"`
-	HeaderImg     string            `json:"header_img"`
-	Phone         string            `json:"phone"`
-	OriginSetting string            `json:"origin_setting"`
-	Password      string            `json:"password"`
-	CreatedAt     time.Time         `json:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at"`
-	Roles         []roleDomain.Role `json:"roles"`
+	ID                 int64             `json:"id"`
+	UUID               string            `json:"uuid"`
+	UserName           string            `json:"user_name"`
+	NickName           string            `json:"nick_name"`
+	Email              string            `json:"email"`
+	Status             int16             `json:"status"`
+	HashPassword       string            `json:"hash_password"`
+	HeaderImg          string            `json:"header_img"`
+	Phone              string            `json:"phone"`
+	OriginSetting      string            `json:"origin_setting"`
+	Password           string            `json:"password"`
+	PasswordChangedAt  *time.Time        `json:"password_changed_at"`
+	MustChangePassword bool              `json:"must_change_password"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	Roles              []roleDomain.Role `json:"roles"`
 }
 type SearchResultUser struct {
 	Data       *[]User `json:"data"`
@@ -39,17 +48,31 @@ type PasswordEditRequest struct {
 type ChangePasswordRequest struct {
 	NewPasswd string `json:"new_password"`
 }
+
+const (
+	// ResetMethodPassword 生成一次性密码，用户下次登录时必须修改
+	ResetMethodPassword = "password"
+	// ResetMethodLink 向用户邮箱发送重置链接
+	ResetMethodLink = "link"
+)
+
+// ResetPasswordResult 一次性密码只在重置时返回一次
+type ResetPasswordResult struct {
+	Method   string `json:"method"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
 type IUserService interface {
-	GetAll() (*[]User, error)
-	GetByID(id int) (*User, error)
+	GetAll(scope *domain.DataScope) (*[]User, error)
+	GetByID(id int, scope *domain.DataScope) (*User, error)
 	Create(newUser *User) (*User, error)
-	Delete(id int) error
-	Update(id int64, userMap map[string]interface{}) (*User, error)
+	Delete(id int, operatorRoleIds []int64, scope *domain.DataScope) error
+	Update(id int64, userMap map[string]interface{}, operatorRoleIds []int64, scope *domain.DataScope) (*User, error)
 	SearchPaginated(filters domain.DataFilters) (*SearchResultUser, error)
-	SearchByProperty(property string, searchText string) (*[]string, error)
+	SearchByProperty(property string, searchText string, scope *domain.DataScope) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*User, error)
-	UserBindRoles(userId int64, updateMap map[string]interface{}) error
-	ResetPassword(userId int64) (*User, error)
-	EditPassword(userId int64, data PasswordEditRequest) (*User, error)
+	UserBindRoles(userId int64, updateMap map[string]interface{}, operatorRoleIds []int64, scope *domain.DataScope) error
+	ResetPassword(userId int64, method string, operatorRoleIds []int64, scope *domain.DataScope) (*ResetPasswordResult, error)
+	EditPassword(userId int64, data PasswordEditRequest, operatorRoleIds []int64) (*User, error)
 	ChangePasswordById(userId int64, password string, jwtToken string) (*User, error)
 }
@@ -0,0 +1,25 @@
+package di
+
+import (
+	apiKeyUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/api_key"
+	apiKeyController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/api_key"
+)
+
+type ApiKeyModule struct {
+	Controller apiKeyController.IApiKeyController
+	UseCase    apiKeyUseCase.ISysApiKeyService
+}
+
+func setupApiKeyModule(appContext *ApplicationContext) error {
+	// the use case is shared with the auth middleware
+	apiKeyUC := appContext.MiddlewareProvider.ApiKeyService
+
+	// Initialize controllers
+	apiKeyController := apiKeyController.NewApiKeyController(apiKeyUC, appContext.Logger)
+
+	appContext.ApiKeyModule = ApiKeyModule{
+		Controller: apiKeyController,
+		UseCase:    apiKeyUC,
+	}
+	return nil
+}
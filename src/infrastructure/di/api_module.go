@@ -20,6 +20,7 @@ func setupApiModule(appContext *ApplicationContext) error {
 	apiUC := apiUseCase.NewSysApiUseCase(
 		apiRepository,
 		appContext.Repositories.DictionaryRepository,
+		appContext.Enforcer,
 		appContext.Logger)
 	// Initialize controllers
 	apiController := apiController.NewApiController(apiUC, appContext.Logger)
@@ -3,6 +3,10 @@ package di
 
 import (
 	authUseCase "github.com/gbrayhan/microservices-go/src/application/services/auth"
+	invitationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/invitation"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	ldapLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/ldap"
+	oidcLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/oidc"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
@@ -20,6 +24,12 @@ type AuthModule struct {
 }
 
 func setupAuthModule(appContext *ApplicationContext) error {
+	// register executor
+	appContext.FunctionExecutor.RegisterFunction(jwt_blacklist.FUNCTION_TYPE_SYNC_JWT_BLACKLIST,
+		func(*domainScheduledTask.ScheduledTask) error {
+			_, err := appContext.Repositories.JwtBlacklistRepository.SyncToRedis()
+			return err
+		})
 
 	// Initialize websocket handler
 	wsHandler := wsHandler.NewUserStatusHandler(appContext.SessionManager, appContext.Logger)
@@ -28,12 +38,36 @@ func setupAuthModule(appContext *ApplicationContext) error {
 	authUC := authUseCase.NewAuthUseCase(
 		appContext.Repositories.UserRepository,
 		appContext.Repositories.RoleRepository,
+		appContext.Repositories.UserRoleRepository,
+		appContext.Repositories.UserIdentityRepository,
 		appContext.JWTService,
 		appContext.Logger,
 		appContext.Repositories.JwtBlacklistRepository,
 		appContext.RedisClient,
 		appContext.SessionManager,
-		appContext.CaptchaHandler)
+		appContext.CaptchaHandler,
+		oidcLib.New(oidcLib.DefaultConfig(appContext.Logger), appContext.Logger),
+		ldapLib.New(ldapLib.DefaultConfig(appContext.Logger), appContext.Logger),
+		appContext.PasswordPolicy,
+		appContext.Repositories.PasswordHistoryRepository,
+		appContext.EventBus,
+		invitationUseCase.NewSysInvitationUseCase(
+			appContext.Repositories.InvitationRepository,
+			appContext.Repositories.RoleRepository,
+			appContext.Repositories.UserRepository,
+			appContext.Logger))
+
+	appContext.FunctionExecutor.RegisterFunction(ldapLib.FUNCTION_TYPE_SYNC_LDAP_USERS,
+		func(*domainScheduledTask.ScheduledTask) error {
+			_, err := authUC.SyncLDAPUsers()
+			return err
+		})
+
+	appContext.FunctionExecutor.RegisterFunction(authUseCase.FUNCTION_TYPE_CLEAN_UNVERIFIED_USERS,
+		func(*domainScheduledTask.ScheduledTask) error {
+			_, err := authUC.CleanUnverifiedUsers()
+			return err
+		})
 
 	// Initialize controllers
 	authController := authController.NewAuthController(authUC, appContext.Logger)
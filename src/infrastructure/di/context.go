@@ -4,35 +4,50 @@ package di
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gbrayhan/microservices-go/src/application/event/bus"
 	"github.com/gbrayhan/microservices-go/src/application/event/factory"
+	apiKeyUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/api_key"
 	taskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/cache"
 	captchaLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/captcha"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	passwordLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/password"
 	redisLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/redis"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/scheduler"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/watcher"
 	ws "github.com/gbrayhan/microservices-go/src/infrastructure/lib/websocket"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/password_history"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api_key"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_group"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_parameter"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/bundle"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dept"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary_detail"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/files"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/invitation"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/permission_template"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_field"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/translation"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user_identity"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
 	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
@@ -62,6 +77,10 @@ type ApplicationContext struct {
 	Limiter            *redisLib.RedisLuaRateLimiter
 	WsRouter           *ws.WebSocketRouter
 	Enforcer           *casbin.Enforcer
+	PolicyWatcher      *watcher.RedisWatcher
+	UserMenuCache      *cache.UserMenuCache
+	DictionaryNotifier *watcher.RedisNotifier
+	DictionaryCache    *cache.DictionaryCache
 	JWTService         security.IJWTService
 	Repositories       RepositoryContainer
 	TaskExecutor       *executor.TaskExecutorManager
@@ -71,6 +90,7 @@ type ApplicationContext struct {
 	MiddlewareProvider *middlewares.MiddlewareProvider
 	SessionManager     *ws.SessionManager
 	CaptchaHandler     *captchaLib.Captcha
+	PasswordPolicy     *passwordLib.Policy
 
 	UserModule             UserModule
 	AuthModule             AuthModule
@@ -90,25 +110,40 @@ type ApplicationContext struct {
 	ConfigModule           ConfigModule
 	EmailModule            EmailModule
 	CaptchaModule          CaptchaModule
+	ApiKeyModule           ApiKeyModule
+	InvitationModule       InvitationModule
+	DeptModule             DeptModule
+	PermissionModule       PermissionModule
+	TranslationModule      TranslationModule
 }
 type RepositoryContainer struct {
-	RoleMenuRepository         role_menu.ISysRoleMenuRepository
-	CasBinRepository           casbin_rule.ICasbinRuleRepository
-	MenuRepository             base_menu.MenuRepositoryInterface
-	RoleBtnRepository          role_btn.ISysRoleBtnRepository
-	UserRoleRepository         user_role.ISysUserRoleRepository
-	JwtBlacklistRepository     jwt_blacklist.JwtBlacklistRepository
-	ApiRepository              api.ApiRepositoryInterface
-	DictionaryDetailRepository dictionary_detail.DictionaryRepositoryInterface
-	DictionaryRepository       dictionary.DictionaryRepositoryInterface
-	MenuGroupRepository        base_menu_group.MenuGroupRepositoryInterface
-	MenuBtnRepository          base_menu_btn.MenuBtnRepositoryInterface
-	MenuParameterRepository    base_menu_parameter.MenuParameterRepositoryInterface
-	RoleRepository             role.ISysRolesRepository
-	UserRepository             user.UserRepositoryInterface
-	FileRepository             files.ISysFilesRepository
-	ScheduledTaskRepository    scheduled_task.IScheduledTaskRepository
-	TaskExecutionLogRepository task_execution_log.ITaskExecutionLogRepository
+	RoleMenuRepository           role_menu.ISysRoleMenuRepository
+	CasBinRepository             casbin_rule.ICasbinRuleRepository
+	MenuRepository               base_menu.MenuRepositoryInterface
+	RoleBtnRepository            role_btn.ISysRoleBtnRepository
+	RoleFieldRepository          role_field.ISysRoleFieldRepository
+	PermissionTemplateRepository permission_template.IPermissionTemplateRepository
+	UserRoleRepository           user_role.ISysUserRoleRepository
+	JwtBlacklistRepository       jwt_blacklist.JwtBlacklistRepository
+	ApiRepository                api.ApiRepositoryInterface
+	DictionaryDetailRepository   dictionary_detail.DictionaryRepositoryInterface
+	DictionaryRepository         dictionary.DictionaryRepositoryInterface
+	MenuGroupRepository          base_menu_group.MenuGroupRepositoryInterface
+	MenuBtnRepository            base_menu_btn.MenuBtnRepositoryInterface
+	MenuParameterRepository      base_menu_parameter.MenuParameterRepositoryInterface
+	RoleRepository               role.ISysRolesRepository
+	UserRepository               user.UserRepositoryInterface
+	FileRepository               files.ISysFilesRepository
+	ScheduledTaskRepository      scheduled_task.IScheduledTaskRepository
+	TaskExecutionLogRepository   task_execution_log.ITaskExecutionLogRepository
+	ApiKeyRepository             api_key.ISysApiKeyRepository
+	PasswordHistoryRepository    password_history.IPasswordHistoryRepository
+	InvitationRepository         invitation.ISysInvitationRepository
+	DeptRepository               dept.ISysDeptRepository
+	BundleRepository             bundle.IBundleRepository
+	MenuApiRepository            menu_api.IMenuApiRepository
+	TranslationRepository        translation.ITranslationRepository
+	UserIdentityRepository       user_identity.IUserIdentityRepository
 }
 
 // SetupDependencies creates a new application context with all dependencies
@@ -119,34 +154,49 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 		return nil, err
 	}
 
+	// Initialize Redis client
+	redisClientInstance, err := redisLib.InitRedisClient(loggerInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Redis client: %w", err)
+	}
+
 	// share repositories
 	repositories := RepositoryContainer{
-		RoleMenuRepository:         role_menu.NewSysRoleMenuRepository(db, loggerInstance),
-		CasBinRepository:           casbin_rule.NewCasbinRuleRepository(db, loggerInstance),
-		MenuRepository:             base_menu.NewMenuRepository(db, loggerInstance),
-		RoleBtnRepository:          role_btn.NewRoleBtnRepository(db, loggerInstance),
-		UserRoleRepository:         user_role.NewSysUserRoleRepository(db, loggerInstance),
-		JwtBlacklistRepository:     jwt_blacklist.NewUJwtBlacklistRepository(db),
-		DictionaryRepository:       dictionary.NewDictionaryRepository(db, loggerInstance),
-		MenuBtnRepository:          base_menu_btn.NewMenuBtnRepository(db, loggerInstance),
-		MenuGroupRepository:        base_menu_group.NewMenuGroupRepository(db, loggerInstance),
-		MenuParameterRepository:    base_menu_parameter.NewMenuParameterRepository(db, loggerInstance),
-		RoleRepository:             role.NewSysRolesRepository(db, loggerInstance),
-		UserRepository:             user.NewUserRepository(db, loggerInstance),
-		FileRepository:             files.NewSysFilesRepository(db, loggerInstance),
-		ScheduledTaskRepository:    scheduled_task.NewScheduledTaskRepository(db, loggerInstance),
-		TaskExecutionLogRepository: task_execution_log.NewTaskExecutionLogRepository(db, loggerInstance),
+		RoleMenuRepository:           role_menu.NewSysRoleMenuRepository(db, loggerInstance),
+		CasBinRepository:             casbin_rule.NewCasbinRuleRepository(db, loggerInstance),
+		MenuRepository:               base_menu.NewMenuRepository(db, loggerInstance),
+		RoleBtnRepository:            role_btn.NewRoleBtnRepository(db, loggerInstance),
+		RoleFieldRepository:          role_field.NewRoleFieldRepository(db, loggerInstance),
+		PermissionTemplateRepository: permission_template.NewPermissionTemplateRepository(db, loggerInstance),
+		UserRoleRepository:           user_role.NewSysUserRoleRepository(db, loggerInstance),
+		JwtBlacklistRepository:       jwt_blacklist.NewUJwtBlacklistRepository(db, redisClientInstance, loggerInstance),
+		DictionaryRepository:         dictionary.NewDictionaryRepository(db, loggerInstance),
+		MenuBtnRepository:            base_menu_btn.NewMenuBtnRepository(db, loggerInstance),
+		MenuGroupRepository:          base_menu_group.NewMenuGroupRepository(db, loggerInstance),
+		MenuParameterRepository:      base_menu_parameter.NewMenuParameterRepository(db, loggerInstance),
+		RoleRepository:               role.NewSysRolesRepository(db, loggerInstance),
+		UserRepository:               user.NewUserRepository(db, loggerInstance),
+		FileRepository:               files.NewSysFilesRepository(db, loggerInstance),
+		ScheduledTaskRepository:      scheduled_task.NewScheduledTaskRepository(db, loggerInstance),
+		TaskExecutionLogRepository:   task_execution_log.NewTaskExecutionLogRepository(db, loggerInstance),
+		ApiKeyRepository:             api_key.NewSysApiKeyRepository(db, loggerInstance),
+		PasswordHistoryRepository:    password_history.NewPasswordHistoryRepository(db, loggerInstance),
+		InvitationRepository:         invitation.NewSysInvitationRepository(db, loggerInstance),
+		DeptRepository:               dept.NewSysDeptRepository(db, loggerInstance),
+		BundleRepository:             bundle.NewBundleRepository(db, loggerInstance),
+		MenuApiRepository:            menu_api.NewMenuApiRepository(db, loggerInstance),
+		TranslationRepository:        translation.NewTranslationRepository(db, loggerInstance),
+		UserIdentityRepository:       user_identity.NewUserIdentityRepository(db, loggerInstance),
+	}
+
+	// move revoked tokens left in postgres into redis
+	if _, err := repositories.JwtBlacklistRepository.SyncToRedis(); err != nil {
+		loggerInstance.Error("Error synchronizing jwt blacklist to redis", zap.Error(err))
 	}
 
 	// create event bus
 	eventBus := factory.CreateEventBus(loggerInstance)
 
-	// Initialize Redis client
-	redisClientInstance, err := redisLib.InitRedisClient(loggerInstance)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Redis client: %w", err)
-	}
-
 	// Initialize limiter
 	limiter := redisLib.NewRedisLuaRateLimiter(redisClientInstance)
 
@@ -155,6 +205,33 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 	if err != nil {
 		return nil, err
 	}
+	// 多实例之间通过 Redis 同步 casbin 策略
+	var policyWatcher *watcher.RedisWatcher
+	if sharedUtil.GetEnv("SERVER_POLICY_WATCHER", "true") == "true" {
+		policyWatcher, err = watcher.NewRedisWatcher(redisClientInstance, sharedUtil.GetEnv("REDIS_POLICY_CHANNEL", watcher.DefaultChannel), loggerInstance)
+		if err != nil {
+			loggerInstance.Error("Error creating casbin policy watcher", zap.Error(err))
+			return nil, err
+		}
+		policyWatcher.SetEnforcer(enforcer)
+		if err := sharedUtil.SetCasbinWatcher(enforcer, policyWatcher); err != nil {
+			return nil, err
+		}
+	}
+
+	// 用户菜单按角色缓存在 Redis 中
+	userMenuCache := cache.NewUserMenuCache(redisClientInstance,
+		time.Duration(sharedUtil.GetEnvAsInt("REDIS_USER_MENU_TTL", 30))*time.Minute, loggerInstance)
+
+	// 字典缓存在各实例内存中，变更通过 Redis 通知其他实例；无法订阅时不启用缓存
+	var dictionaryCache *cache.DictionaryCache
+	dictionaryNotifier, notifierErr := watcher.NewRedisNotifier(redisClientInstance, sharedUtil.GetEnv("REDIS_DICTIONARY_CHANNEL", watcher.DictionaryChannel), loggerInstance)
+	if notifierErr != nil {
+		loggerInstance.Error("Error subscribing dictionary notifications, dictionary cache disabled", zap.Error(notifierErr))
+	} else {
+		dictionaryCache = cache.NewDictionaryCache(dictionaryNotifier,
+			time.Duration(sharedUtil.GetEnvAsInt("DICTIONARY_CACHE_TTL", 10))*time.Minute, loggerInstance)
+	}
 
 	// init websocket instance
 	wsRouter := ws.NewWebSocketRouter()
@@ -173,37 +250,46 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 	jwtService := security.NewJWTService()
 
 	// Initialize MiddleWare
-	middlewareProvider := middlewares.NewMiddlewareProvider(redisClientInstance, db)
+	apiKeyService := apiKeyUseCase.NewSysApiKeyUseCase(repositories.ApiKeyRepository, repositories.UserRepository, loggerInstance)
+	middlewareProvider := middlewares.NewMiddlewareProvider(redisClientInstance, db, repositories.JwtBlacklistRepository, apiKeyService)
 
 	// Initialize CaptchaHandler
 	captchaHandler := captchaLib.New(captchaLib.DefaultConfig(loggerInstance))
+	// Initialize password policy
+	passwordPolicy := passwordLib.New(passwordLib.DefaultConfig(), loggerInstance)
 	// initialize task scheduler
 	taskScheduler := scheduler.NewTaskScheduler(
 		repositories.ScheduledTaskRepository, loggerInstance, taskExecutor, repositories.TaskExecutionLogRepository)
 
 	// create context
 	appContext := &ApplicationContext{
-		DB:            db,
-		RedisClient:   redisClientInstance,
-		EventBus:      eventBus,
-		Logger:        loggerInstance,
-		Limiter:       limiter,
-		WsRouter:      wsRouter,
-		Enforcer:      enforcer,
-		JWTService:    jwtService,
-		Repositories:  repositories,
-		TaskExecutor:  taskExecutor,
-		TaskScheduler: taskScheduler,
+		DB:                 db,
+		RedisClient:        redisClientInstance,
+		EventBus:           eventBus,
+		Logger:             loggerInstance,
+		Limiter:            limiter,
+		WsRouter:           wsRouter,
+		Enforcer:           enforcer,
+		PolicyWatcher:      policyWatcher,
+		UserMenuCache:      userMenuCache,
+		DictionaryNotifier: dictionaryNotifier,
+		DictionaryCache:    dictionaryCache,
+		JWTService:         jwtService,
+		Repositories:       repositories,
+		TaskExecutor:       taskExecutor,
+		TaskScheduler:      taskScheduler,
 
 		FunctionExecutor:   functionExecutor,
 		HttpExecutor:       httpCallExecutor,
 		MiddlewareProvider: middlewareProvider,
 		SessionManager:     sessionManager,
 		CaptchaHandler:     captchaHandler,
+		PasswordPolicy:     passwordPolicy,
 	}
 
 	// module slice
 	moduleSetupFuncs := []func(*ApplicationContext) error{
+		setupTranslationModule,
 		setupUserModule,
 		setupAuthModule,
 		setupApiModule,
@@ -222,6 +308,10 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 		setupTaskExecutionLogModule,
 		setupEmailModule,
 		setupCaptchaModule,
+		setupApiKeyModule,
+		setupInvitationModule,
+		setupDeptModule,
+		setupPermissionModule,
 	}
 
 	for _, setupFunc := range moduleSetupFuncs {
@@ -245,6 +335,14 @@ func (appContext *ApplicationContext) Close() error {
 		}
 	}
 
+	// stop policy watcher before redis client
+	if appContext.PolicyWatcher != nil {
+		appContext.PolicyWatcher.Close()
+	}
+	if appContext.DictionaryNotifier != nil {
+		appContext.DictionaryNotifier.Close()
+	}
+
 	// down redis client
 	if appContext.RedisClient != nil {
 		if err := appContext.RedisClient.Close(); err != nil {
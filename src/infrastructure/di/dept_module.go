@@ -0,0 +1,26 @@
+package di
+
+import (
+	deptUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/dept"
+	domainDept "github.com/gbrayhan/microservices-go/src/domain/sys/dept"
+	deptController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/dept"
+)
+
+type DeptModule struct {
+	Controller deptController.IDeptController
+	UseCase    domainDept.IDeptService
+}
+
+func setupDeptModule(appContext *ApplicationContext) error {
+	// Initialize use cases
+	deptUC := deptUseCase.NewSysDeptUseCase(appContext.Repositories.DeptRepository, appContext.Logger)
+
+	// Initialize controllers
+	deptController := deptController.NewDeptController(deptUC, appContext.Logger)
+
+	appContext.DeptModule = DeptModule{
+		Controller: deptController,
+		UseCase:    deptUC,
+	}
+	return nil
+}
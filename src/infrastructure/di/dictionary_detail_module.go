@@ -17,10 +17,10 @@ func setupDictionaryDetailModule(appContext *ApplicationContext) error {
 	dictionaryDetailRepo := dictionary_detail.NewDictionaryRepository(appContext.DB, appContext.Logger)
 
 	// Initialize use cases
-	dictionaryDetailUC := dictionaryDetailUseCase.NewSysDictionaryUseCase(dictionaryDetailRepo, appContext.Logger)
+	dictionaryDetailUC := dictionaryDetailUseCase.NewSysDictionaryUseCase(dictionaryDetailRepo, appContext.DictionaryCache, appContext.Logger)
 
 	// Initialize controllers
-	dictionaryDetailController := dictionaryDetailController.NewIDictionaryDetailController(dictionaryDetailUC, appContext.Logger)
+	dictionaryDetailController := dictionaryDetailController.NewIDictionaryDetailController(dictionaryDetailUC, appContext.TranslationModule.UseCase, appContext.Logger)
 
 	appContext.DictionaryDetailModule = DictionaryDetailModule{
 		Controller: dictionaryDetailController,
@@ -4,6 +4,7 @@ import (
 	dictionaryUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/dictionary"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary"
 	dictionaryController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/dictionary"
+	"go.uber.org/zap"
 )
 
 type DictionaryModule struct {
@@ -17,10 +18,15 @@ func setupDictionaryModule(appContext *ApplicationContext) error {
 	dictionaryRepo := dictionary.NewDictionaryRepository(appContext.DB, appContext.Logger)
 
 	// Initialize use cases
-	dictionaryUC := dictionaryUseCase.NewSysDictionaryUseCase(dictionaryRepo, appContext.Logger)
+	dictionaryUC := dictionaryUseCase.NewSysDictionaryUseCase(dictionaryRepo, appContext.DictionaryCache, appContext.Logger)
+	if appContext.DictionaryCache != nil {
+		if err := dictionaryUC.WarmCache(); err != nil {
+			appContext.Logger.Warn("Error warming dictionary cache", zap.Error(err))
+		}
+	}
 
 	// Initialize controllers
-	dictionaryController := dictionaryController.NewDictionaryController(dictionaryUC, appContext.Logger)
+	dictionaryController := dictionaryController.NewDictionaryController(dictionaryUC, appContext.TranslationModule.UseCase, appContext.Logger)
 	appContext.DictionaryModule = DictionaryModule{
 		Controller: dictionaryController,
 		UseCase:    dictionaryUC,
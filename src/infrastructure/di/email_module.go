@@ -14,7 +14,10 @@ type EmailModule struct {
 
 func setupEmailModule(appContext *ApplicationContext) error {
 	// Initialize event
-	appContext.EventBus.Subscribe(eventModel.ForgetPasswordEventType, eventHandler.NewEmailEventHandler())
+	emailHandler := eventHandler.NewEmailEventHandler()
+	appContext.EventBus.Subscribe(eventModel.ForgetPasswordEventType, emailHandler)
+	appContext.EventBus.Subscribe(eventModel.UserRegisteredEventType, emailHandler)
+	appContext.EventBus.Subscribe(eventModel.EmailVerificationEventType, emailHandler)
 
 	// Initialize use cases
 	service := emailUseCase.NewEmailUseCase(
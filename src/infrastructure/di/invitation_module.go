@@ -0,0 +1,29 @@
+package di
+
+import (
+	invitationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/invitation"
+	invitationController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/invitation"
+)
+
+type InvitationModule struct {
+	Controller invitationController.IInvitationController
+	UseCase    invitationUseCase.ISysInvitationService
+}
+
+func setupInvitationModule(appContext *ApplicationContext) error {
+	// Initialize use cases
+	invitationUC := invitationUseCase.NewSysInvitationUseCase(
+		appContext.Repositories.InvitationRepository,
+		appContext.Repositories.RoleRepository,
+		appContext.Repositories.UserRepository,
+		appContext.Logger)
+
+	// Initialize controllers
+	invitationController := invitationController.NewInvitationController(invitationUC, appContext.Logger)
+
+	appContext.InvitationModule = InvitationModule{
+		Controller: invitationController,
+		UseCase:    invitationUC,
+	}
+	return nil
+}
@@ -17,7 +17,7 @@ func setupMenuBtnModule(appContext *ApplicationContext) error {
 	menuBtnRepo := base_menu_btn.NewMenuBtnRepository(appContext.DB, appContext.Logger)
 
 	// Initialize use cases
-	menuBtnUC := menuBtnUseCase.NewMenuBtnUseCase(menuBtnRepo, appContext.Logger)
+	menuBtnUC := menuBtnUseCase.NewMenuBtnUseCase(menuBtnRepo, appContext.Repositories.MenuApiRepository, appContext.Enforcer, appContext.UserMenuCache, appContext.Logger)
 
 	// Initialize controllers
 	menuBtnController := menuBtnController.NewMenuBtnController(menuBtnUC, appContext.Logger)
@@ -16,7 +16,7 @@ func setupMenuGroupModule(appContext *ApplicationContext) error {
 
 	// Initialize use cases
 	menuGroupUC := menuGroupUseCase.NewSysMenuGroupUseCase(
-		appContext.Repositories.MenuGroupRepository, appContext.Logger)
+		appContext.Repositories.MenuGroupRepository, appContext.UserMenuCache, appContext.Logger)
 
 	// Initialize controllers
 	menuGroupController := menuGroupController.NewMenuGroupController(menuGroupUC, appContext.Logger)
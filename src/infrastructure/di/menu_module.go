@@ -27,10 +27,16 @@ func setupMenuModule(appContext *ApplicationContext) error {
 		appContext.Repositories.RoleMenuRepository,
 		appContext.Repositories.UserRepository,
 		appContext.Repositories.MenuGroupRepository,
-		appContext.Repositories.RoleBtnRepository, appContext.Logger)
+		appContext.Repositories.RoleBtnRepository,
+		appContext.Repositories.RoleRepository,
+		appContext.Repositories.MenuApiRepository,
+		appContext.Enforcer,
+		appContext.UserMenuCache,
+		appContext.TranslationModule.UseCase,
+		appContext.Logger)
 
 	// Initialize controllers
-	menuController := menuController.NewMenuController(menuUC, appContext.Logger)
+	menuController := menuController.NewMenuController(menuUC, appContext.TranslationModule.UseCase, appContext.Logger)
 	appContext.MenuModule = MenuModule{
 		Controller:          menuController,
 		UseCase:             menuUC,
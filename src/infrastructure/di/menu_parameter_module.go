@@ -16,6 +16,7 @@ func setupMenuParameterModule(appContext *ApplicationContext) error {
 	// Initialize use cases
 	menuParameterUC := menuParameterUseCase.NewMenuParameterUseCase(
 		appContext.Repositories.MenuParameterRepository,
+		appContext.UserMenuCache,
 		appContext.Logger)
 
 	// Initialize controllers
@@ -0,0 +1,35 @@
+package di
+
+import (
+	permissionUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/permission"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	permissionController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/permission"
+)
+
+type PermissionModule struct {
+	Controller permissionController.IPermissionController
+	UseCase    domainPermission.IPermissionService
+}
+
+func setupPermissionModule(appContext *ApplicationContext) error {
+	// Initialize use cases
+	permissionUC := permissionUseCase.NewPermissionUseCase(
+		appContext.Enforcer,
+		appContext.Repositories.RoleRepository,
+		appContext.Repositories.UserRoleRepository,
+		appContext.ApiModule.Repository,
+		appContext.Repositories.CasBinRepository,
+		appContext.Repositories.MenuApiRepository,
+		appContext.Repositories.BundleRepository,
+		appContext.UserMenuCache,
+		appContext.Logger)
+
+	// Initialize controllers
+	permissionController := permissionController.NewPermissionController(permissionUC, appContext.Logger)
+
+	appContext.PermissionModule = PermissionModule{
+		Controller: permissionController,
+		UseCase:    permissionUC,
+	}
+	return nil
+}
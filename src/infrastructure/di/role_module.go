@@ -4,20 +4,25 @@ import (
 	roleUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/permission_template"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_field"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
 	roleController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/role"
+	"go.uber.org/zap"
 )
 
 type RoleModule struct {
-	Controller         roleController.IRoleController
-	UseCase            roleUseCase.ISysRoleService
-	Repository         role.ISysRolesRepository
-	RoleMenuRepository role_menu.ISysRoleMenuRepository
-	CasBinRepository   casbin_rule.ICasbinRuleRepository
-	MenuRepository     base_menu.MenuRepositoryInterface
-	RoleBtnRepository  role_btn.ISysRoleBtnRepository
+	Controller          roleController.IRoleController
+	UseCase             roleUseCase.ISysRoleService
+	Repository          role.ISysRolesRepository
+	RoleMenuRepository  role_menu.ISysRoleMenuRepository
+	CasBinRepository    casbin_rule.ICasbinRuleRepository
+	MenuRepository      base_menu.MenuRepositoryInterface
+	RoleBtnRepository   role_btn.ISysRoleBtnRepository
+	RoleFieldRepository role_field.ISysRoleFieldRepository
+	TemplateRepository  permission_template.IPermissionTemplateRepository
 }
 
 func setupRoleModule(appContext *ApplicationContext) error {
@@ -29,18 +34,32 @@ func setupRoleModule(appContext *ApplicationContext) error {
 		appContext.Repositories.CasBinRepository,
 		appContext.Repositories.MenuRepository,
 		appContext.Repositories.RoleBtnRepository,
+		appContext.Repositories.DeptRepository,
+		appContext.Repositories.RoleFieldRepository,
+		appContext.Repositories.PermissionTemplateRepository,
+		appContext.Repositories.MenuApiRepository,
+		appContext.UserMenuCache,
+		appContext.Enforcer,
 		appContext.Logger)
 
+	// 按角色树校正 casbin 继承规则
+	if err := roleUC.SyncRoleInheritance(); err != nil {
+		appContext.Logger.Error("Error synchronizing role inheritance", zap.Error(err))
+		return err
+	}
+
 	// Initialize controllers
 	roleController := roleController.NewRoleController(roleUC, appContext.Logger)
 	appContext.RoleModule = RoleModule{
-		Controller:         roleController,
-		UseCase:            roleUC,
-		Repository:         appContext.Repositories.RoleRepository,
-		RoleMenuRepository: appContext.Repositories.RoleMenuRepository,
-		RoleBtnRepository:  appContext.Repositories.RoleBtnRepository,
-		CasBinRepository:   appContext.Repositories.CasBinRepository,
-		MenuRepository:     appContext.Repositories.MenuRepository,
+		Controller:          roleController,
+		UseCase:             roleUC,
+		Repository:          appContext.Repositories.RoleRepository,
+		RoleMenuRepository:  appContext.Repositories.RoleMenuRepository,
+		RoleBtnRepository:   appContext.Repositories.RoleBtnRepository,
+		CasBinRepository:    appContext.Repositories.CasBinRepository,
+		MenuRepository:      appContext.Repositories.MenuRepository,
+		RoleFieldRepository: appContext.Repositories.RoleFieldRepository,
+		TemplateRepository:  appContext.Repositories.PermissionTemplateRepository,
 	}
 	return nil
 }
@@ -0,0 +1,37 @@
+package di
+
+import (
+	"time"
+
+	translationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/translation"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/translation"
+	translationController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/translation"
+	sharedUtil "github.com/gbrayhan/microservices-go/src/shared/utils"
+)
+
+type TranslationModule struct {
+	Controller translationController.ITranslationController
+	UseCase    domainTranslation.ITranslationService
+	Repository translation.ITranslationRepository
+}
+
+// setupTranslationModule 需在菜单、字典模块之前初始化
+func setupTranslationModule(appContext *ApplicationContext) error {
+	// Initialize use cases
+	translationUC := translationUseCase.NewTranslationUseCase(
+		appContext.Repositories.TranslationRepository,
+		sharedUtil.GetEnv("I18N_DEFAULT_LOCALE", "en"),
+		time.Duration(sharedUtil.GetEnvAsInt("I18N_CACHE_TTL", 60))*time.Second,
+		appContext.Logger)
+
+	// Initialize controllers
+	translationController := translationController.NewTranslationController(translationUC, appContext.Logger)
+
+	appContext.TranslationModule = TranslationModule{
+		Controller: translationController,
+		UseCase:    translationUC,
+		Repository: appContext.Repositories.TranslationRepository,
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package di
 
 import (
+	emailUseCase "github.com/gbrayhan/microservices-go/src/application/services/email"
 	userUseCase "github.com/gbrayhan/microservices-go/src/application/services/user"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/job"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
@@ -18,12 +19,24 @@ func setupUserModule(appContext *ApplicationContext) error {
 	// register executor
 	appContext.FunctionExecutor.RegisterFunction(job.FUNCTION_TYPE_CLEAN_UP_OLD_DATA, job.CleanOldData)
 
+	// reset links are sent through the email use case
+	emailUC := emailUseCase.NewEmailUseCase(
+		appContext.Repositories.UserRepository,
+		appContext.JWTService,
+		appContext.EventBus,
+		appContext.RedisClient,
+		appContext.Logger)
+
 	// Initialize use cases
 	userUC := userUseCase.NewUserUseCase(
 		appContext.Repositories.UserRepository,
 		appContext.Repositories.UserRoleRepository,
+		appContext.Repositories.RoleRepository,
 		appContext.EventBus,
 		appContext.Repositories.JwtBlacklistRepository,
+		appContext.Repositories.PasswordHistoryRepository,
+		appContext.PasswordPolicy,
+		emailUC,
 		appContext.Logger)
 
 	// Initialize controllers
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	dictionaryDomain "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary"
+	dictionaryDetailDomain "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary_detail"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/watcher"
+	"go.uber.org/zap"
+)
+
+// DictionaryCache 启用的字典及其启用的详情按 type 缓存在内存中，字典或详情变化时整体失效，
+// 并通过 notifier 通知其他实例一起失效。通知可能在断线期间丢失，因此重新订阅时整体失效，
+// 条目也在 ttl 后过期，ttl 不大于 0 时不过期。nil 时所有方法为空操作
+type DictionaryCache struct {
+	mu         sync.RWMutex
+	entries    map[string]dictionaryEntry
+	generation uint64
+	ttl        time.Duration
+	notifier   *watcher.RedisNotifier
+	Logger     *logger.Logger
+}
+
+type dictionaryEntry struct {
+	dictionary *dictionaryDomain.Dictionary
+	expiresAt  time.Time
+}
+
+func NewDictionaryCache(notifier *watcher.RedisNotifier, ttl time.Duration, loggerInstance *logger.Logger) *DictionaryCache {
+	c := &DictionaryCache{
+		entries:  make(map[string]dictionaryEntry),
+		ttl:      ttl,
+		notifier: notifier,
+		Logger:   loggerInstance,
+	}
+	if notifier != nil {
+		notifier.SetHandler(func(string) {
+			c.clear()
+			c.Logger.Debug("Dictionary cache invalidated by another instance")
+		})
+		notifier.SetReconnectHandler(func() {
+			c.clear()
+			c.Logger.Info("Dictionary cache cleared after resubscribing")
+		})
+	}
+	return c
+}
+
+// Get 返回缓存的副本，调用方可以修改
+func (c *DictionaryCache) Get(typeText string) (*dictionaryDomain.Dictionary, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[typeText]
+	if !ok || (!entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)) {
+		return nil, false
+	}
+	return copyDictionary(entry.dictionary), true
+}
+
+// Generation 从数据库读取前获取，写入时用于丢弃读取期间已经失效的数据
+func (c *DictionaryCache) Generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// Set 读取期间缓存已失效时放弃写入
+func (c *DictionaryCache) Set(generation uint64, dictionaries ...dictionaryDomain.Dictionary) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+	for i := range dictionaries {
+		c.entries[dictionaries[i].Type] = dictionaryEntry{dictionary: copyDictionary(&dictionaries[i]), expiresAt: expiresAt}
+	}
+}
+
+// Invalidate 清空本实例的缓存并通知其他实例
+func (c *DictionaryCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.clear()
+	if c.notifier != nil {
+		if err := c.notifier.Publish("invalidate"); err != nil {
+			c.Logger.Error("Error notifying dictionary cache invalidation", zap.Error(err))
+		}
+	}
+}
+
+func (c *DictionaryCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]dictionaryEntry)
+	c.generation++
+}
+
+func copyDictionary(dictionary *dictionaryDomain.Dictionary) *dictionaryDomain.Dictionary {
+	copied := *dictionary
+	if dictionary.Details != nil {
+		details := append([]dictionaryDetailDomain.DictionaryDetail{}, *dictionary.Details...)
+		copied.Details = &details
+	}
+	return &copied
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	dictionaryDomain "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary"
+	dictionaryDetailDomain "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary_detail"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDictionaryCache(t *testing.T) {
+	c := NewDictionaryCache(nil, 0, nil)
+	details := []dictionaryDetailDomain.DictionaryDetail{{ID: 1, Label: "男", Value: "1"}}
+	c.Set(c.Generation(), dictionaryDomain.Dictionary{ID: 1, Type: "gender", Details: &details})
+
+	// 修改返回值不影响缓存
+	cached, ok := c.Get("gender")
+	assert.True(t, ok)
+	(*cached.Details)[0].Label = "Male"
+	cached, _ = c.Get("gender")
+	assert.Equal(t, "男", (*cached.Details)[0].Label)
+
+	// 读取期间失效的数据不会写入
+	generation := c.Generation()
+	c.Invalidate()
+	c.Set(generation, dictionaryDomain.Dictionary{ID: 1, Type: "gender", Details: &details})
+	_, ok = c.Get("gender")
+	assert.False(t, ok)
+
+	var disabled *DictionaryCache
+	disabled.Set(0, dictionaryDomain.Dictionary{Type: "gender"})
+	_, ok = disabled.Get("gender")
+	assert.False(t, ok)
+}
+
+func TestDictionaryCacheExpires(t *testing.T) {
+	c := NewDictionaryCache(nil, time.Millisecond, nil)
+	c.Set(c.Generation(), dictionaryDomain.Dictionary{ID: 1, Type: "gender"})
+	_, ok := c.Get("gender")
+	assert.True(t, ok)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok = c.Get("gender")
+	assert.False(t, ok)
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	menuDomain "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// userMenuVersionKey 递增后所有实例上旧版本的缓存都不再命中，由 TTL 清理
+	userMenuVersionKey = "user_menus:version"
+	userMenuKeyPrefix  = "user_menus:%s:%s"
+)
+
+// UserMenuCache 按角色组合缓存 /menu/user 的结果，菜单、分组、按钮、参数或角色菜单变化时整体失效。
+// nil 时所有方法为空操作
+type UserMenuCache struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+	Logger      *logger.Logger
+}
+
+func NewUserMenuCache(redisClient *redis.Client, ttl time.Duration, loggerInstance *logger.Logger) *UserMenuCache {
+	return &UserMenuCache{redisClient: redisClient, ttl: ttl, Logger: loggerInstance}
+}
+
+// Get 未命中或读取失败时返回 nil
+func (c *UserMenuCache) Get(ctx context.Context, roleIds []int64) *menuDomain.UserMenus {
+	if c == nil {
+		return nil
+	}
+	key, err := c.key(ctx, roleIds)
+	if err != nil {
+		c.Logger.Warn("Error reading user menu cache version", zap.Error(err))
+		return nil
+	}
+	data, err := c.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.Logger.Warn("Error reading user menu cache", zap.Error(err), zap.String("key", key))
+		}
+		return nil
+	}
+	var menus menuDomain.UserMenus
+	if err := json.Unmarshal(data, &menus); err != nil {
+		c.Logger.Warn("Error decoding user menu cache", zap.Error(err), zap.String("key", key))
+		return nil
+	}
+	return &menus
+}
+
+func (c *UserMenuCache) Set(ctx context.Context, roleIds []int64, menus *menuDomain.UserMenus) {
+	if c == nil {
+		return
+	}
+	key, err := c.key(ctx, roleIds)
+	if err != nil {
+		c.Logger.Warn("Error reading user menu cache version", zap.Error(err))
+		return
+	}
+	data, err := json.Marshal(menus)
+	if err != nil {
+		c.Logger.Warn("Error encoding user menu cache", zap.Error(err))
+		return
+	}
+	if err := c.redisClient.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		c.Logger.Warn("Error writing user menu cache", zap.Error(err), zap.String("key", key))
+	}
+}
+
+// Invalidate 使所有角色的菜单缓存失效
+func (c *UserMenuCache) Invalidate(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	if err := c.redisClient.Incr(ctx, userMenuVersionKey).Err(); err != nil {
+		c.Logger.Error("Error invalidating user menu cache", zap.Error(err))
+	}
+}
+
+func (c *UserMenuCache) key(ctx context.Context, roleIds []int64) (string, error) {
+	version, err := c.redisClient.Get(ctx, userMenuVersionKey).Result()
+	if errors.Is(err, redis.Nil) {
+		version, err = "0", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(userMenuKeyPrefix, version, roleKey(roleIds)), nil
+}
+
+// roleKey 角色组合与顺序无关，未传角色（全部菜单）时为 all
+func roleKey(roleIds []int64) string {
+	if len(roleIds) == 0 {
+		return "all"
+	}
+	sorted := append([]int64{}, roleIds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	parts := make([]string, 0, len(sorted))
+	for i, id := range sorted {
+		if i > 0 && id == sorted[i-1] {
+			continue
+		}
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,14 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleKey(t *testing.T) {
+	assert.Equal(t, "all", roleKey(nil))
+	assert.Equal(t, "1,2,5", roleKey([]int64{5, 1, 2}))
+	// 重复角色与顺序不影响缓存键
+	assert.Equal(t, roleKey([]int64{2, 1}), roleKey([]int64{1, 2, 2, 1}))
+}
@@ -0,0 +1,275 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	goldap "github.com/go-ldap/ldap/v3"
+	"go.uber.org/zap"
+)
+
+const FUNCTION_TYPE_SYNC_LDAP_USERS = "sync_ldap_users"
+
+// ErrInvalidCredentials 用户不存在或密码错误
+var ErrInvalidCredentials = errors.New("invalid ldap credentials")
+
+// Config LDAP/AD认证配置
+type Config struct {
+	Enable             bool
+	URL                string // ldap://host:389 或 ldaps://host:636
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string // 用于查找用户的服务账号
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string // 登录查找过滤器，%s 替换为用户名
+	SyncFilter         string // 定时同步时的用户过滤器
+	UsernameAttr       string
+	EmailAttr          string
+	NameAttr           string
+	GroupAttr          string
+	IDAttr             string            // 绑定本地用户的不变标识，如 objectGUID、entryUUID；为空时使用DN
+	RoleMapping        map[string]string // 分组DN或CN -> sys_roles.name
+	DefaultRole        string
+	AutoProvision      bool
+}
+
+// Entry 目录中的用户条目
+type Entry struct {
+	DN       string
+	ID       string // IDAttr 的值，二进制值按十六进制编码
+	Username string
+	Email    string
+	NickName string
+	Groups   []string
+}
+
+// Client LDAP客户端，每次操作使用独立连接
+type Client struct {
+	config Config
+	logger *logger.Logger
+}
+
+// DefaultConfig 从环境变量读取LDAP配置
+func DefaultConfig(loggerInstance *logger.Logger) Config {
+	roleMapping := make(map[string]string)
+	if raw := utils.GetEnv("LDAP_ROLE_MAPPING", ""); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &roleMapping); err != nil {
+			loggerInstance.Error("Invalid LDAP_ROLE_MAPPING, ignoring", zap.Error(err))
+		}
+	}
+
+	return Config{
+		Enable:             utils.GetEnv("LDAP_ENABLE", "false") == "true",
+		URL:                utils.GetEnv("LDAP_URL", "ldap://localhost:389"),
+		StartTLS:           utils.GetEnv("LDAP_START_TLS", "false") == "true",
+		InsecureSkipVerify: utils.GetEnv("LDAP_INSECURE_SKIP_VERIFY", "false") == "true",
+		BindDN:             utils.GetEnv("LDAP_BIND_DN", ""),
+		BindPassword:       utils.GetEnv("LDAP_BIND_PASSWORD", ""),
+		BaseDN:             utils.GetEnv("LDAP_BASE_DN", ""),
+		UserFilter:         utils.GetEnv("LDAP_USER_FILTER", "(uid=%s)"),
+		SyncFilter:         utils.GetEnv("LDAP_SYNC_FILTER", "(objectClass=person)"),
+		UsernameAttr:       utils.GetEnv("LDAP_USERNAME_ATTR", "uid"),
+		EmailAttr:          utils.GetEnv("LDAP_EMAIL_ATTR", "mail"),
+		NameAttr:           utils.GetEnv("LDAP_NAME_ATTR", "cn"),
+		GroupAttr:          utils.GetEnv("LDAP_GROUP_ATTR", "memberOf"),
+		IDAttr:             utils.GetEnv("LDAP_ID_ATTR", ""),
+		RoleMapping:        roleMapping,
+		DefaultRole:        utils.GetEnv("LDAP_DEFAULT_ROLE", ""),
+		AutoProvision:      utils.GetEnv("LDAP_AUTO_PROVISION", "true") == "true",
+	}
+}
+
+// New 创建LDAP客户端，未启用时返回nil
+func New(config Config, loggerInstance *logger.Logger) *Client {
+	if !config.Enable {
+		return nil
+	}
+	return &Client{config: config, logger: loggerInstance}
+}
+
+// Config 返回当前配置
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// Authenticate 以服务账号查找用户DN，再以用户DN和密码绑定校验
+func (c *Client) Authenticate(username, password string) (*Entry, error) {
+	// 空密码会被多数目录视为匿名绑定而成功
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf(c.config.UserFilter, goldap.EscapeFilter(username))
+	entries, err := c.search(conn, filter, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		c.logger.Warn("LDAP user lookup did not return exactly one entry",
+			zap.String("username", username), zap.Int("count", len(entries)))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := conn.Bind(entries[0].DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// SearchUsers 返回同步过滤器匹配的全部用户
+func (c *Client) SearchUsers() ([]Entry, error) {
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return c.search(conn, c.config.SyncFilter, 0)
+}
+
+// MapRoles 将用户分组映射为角色名，分组可按完整DN或CN配置
+func (c *Client) MapRoles(groups []string) []string {
+	roleNames := make([]string, 0)
+	for _, group := range groups {
+		roleName, ok := c.config.RoleMapping[group]
+		if !ok {
+			roleName, ok = c.config.RoleMapping[groupCommonName(group)]
+		}
+		if ok && !contains(roleNames, roleName) {
+			roleNames = append(roleNames, roleName)
+		}
+	}
+	return roleNames
+}
+
+func (c *Client) connect() (*goldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.config.InsecureSkipVerify}
+	conn, err := goldap.DialURL(c.config.URL, goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		c.logger.Error("Error connecting to LDAP server", zap.Error(err), zap.String("url", c.config.URL))
+		return nil, err
+	}
+	if c.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.config.BindDN != "" {
+		if err := conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+			conn.Close()
+			c.logger.Error("Error binding LDAP service account", zap.Error(err))
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c *Client) search(conn *goldap.Conn, filter string, sizeLimit int) ([]Entry, error) {
+	request := goldap.NewSearchRequest(
+		c.config.BaseDN,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, sizeLimit, 0, false,
+		filter,
+		c.searchAttributes(),
+		nil,
+	)
+	result, err := conn.SearchWithPaging(request, 500)
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, err
+	}
+	if result == nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(result.Entries))
+	for _, item := range result.Entries {
+		entries = append(entries, Entry{
+			DN:       item.DN,
+			ID:       c.entryID(item),
+			Username: item.GetAttributeValue(c.config.UsernameAttr),
+			Email:    item.GetAttributeValue(c.config.EmailAttr),
+			NickName: item.GetAttributeValue(c.config.NameAttr),
+			Groups:   item.GetAttributeValues(c.config.GroupAttr),
+		})
+	}
+	return entries, nil
+}
+
+// Identifier 绑定本地用户使用的标识，未配置 IDAttr 时为DN
+func (e *Entry) Identifier() string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return e.DN
+}
+
+func (c *Client) searchAttributes() []string {
+	attributes := []string{"dn", c.config.UsernameAttr, c.config.EmailAttr, c.config.NameAttr, c.config.GroupAttr}
+	if c.config.IDAttr != "" {
+		attributes = append(attributes, c.config.IDAttr)
+	}
+	return attributes
+}
+
+func (c *Client) entryID(item *goldap.Entry) string {
+	if c.config.IDAttr == "" {
+		return ""
+	}
+	return encodeID(item.GetRawAttributeValue(c.config.IDAttr))
+}
+
+// encodeID objectGUID 等二进制值按十六进制编码，entryUUID 等文本值原样返回
+func encodeID(raw []byte) string {
+	if utf8.Valid(raw) {
+		printable := true
+		for _, r := range string(raw) {
+			if !unicode.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(raw)
+		}
+	}
+	return hex.EncodeToString(raw)
+}
+
+// groupCommonName 从 cn=admins,ou=groups,dc=example,dc=com 中取出 admins
+func groupCommonName(group string) string {
+	dn, err := goldap.ParseDN(group)
+	if err != nil || len(dn.RDNs) == 0 {
+		return group
+	}
+	for _, attribute := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, "cn") {
+			return attribute.Value
+		}
+	}
+	return group
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
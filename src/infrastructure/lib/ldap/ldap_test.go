@@ -0,0 +1,48 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapRoles_MatchesDNAndCommonName(t *testing.T) {
+	client := &Client{config: Config{RoleMapping: map[string]string{
+		"cn=admins,ou=groups,dc=example,dc=com": "admin",
+		"developers":                            "developer",
+		"ops":                                   "admin",
+	}}}
+
+	roles := client.MapRoles([]string{
+		"cn=admins,ou=groups,dc=example,dc=com",
+		"CN=developers,OU=groups,DC=example,DC=com",
+		"cn=ops,ou=groups,dc=example,dc=com",
+		"cn=unmapped,ou=groups,dc=example,dc=com",
+	})
+
+	assert.Equal(t, []string{"admin", "developer"}, roles)
+}
+
+func TestGroupCommonName(t *testing.T) {
+	assert.Equal(t, "admins", groupCommonName("cn=admins,ou=groups,dc=example,dc=com"))
+	assert.Equal(t, "ou=groups,dc=example", groupCommonName("ou=groups,dc=example"))
+	assert.Equal(t, "plain", groupCommonName("plain"))
+}
+
+func TestAuthenticate_RejectsEmptyPassword(t *testing.T) {
+	client := &Client{config: Config{URL: "ldap://127.0.0.1:1"}}
+
+	_, err := client.Authenticate("alice", "")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestEntryIdentifier(t *testing.T) {
+	assert.Equal(t, "cn=alice,dc=example", (&Entry{DN: "cn=alice,dc=example"}).Identifier())
+	assert.Equal(t, "guid", (&Entry{DN: "cn=alice,dc=example", ID: "guid"}).Identifier())
+}
+
+func TestEncodeID(t *testing.T) {
+	assert.Equal(t, "6f1c2d4e-0000-4000-8000-000000000001", encodeID([]byte("6f1c2d4e-0000-4000-8000-000000000001")))
+	assert.Equal(t, "00ff10", encodeID([]byte{0x00, 0xff, 0x10}))
+}
@@ -0,0 +1,263 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// Config OIDC单点登录配置
+type Config struct {
+	Enable        bool
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string
+	EmailClaim    string
+	NameClaim     string
+	GroupsClaim   string
+	RoleMapping   map[string]string // IdP group -> sys_roles.name
+	DefaultRole   string
+	AutoProvision bool
+}
+
+// Identity 从IdP声明中解析出的用户身份，Issuer + Subject 唯一标识IdP账号
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	NickName      string
+	Groups        []string
+}
+
+// Provider OIDC授权码+PKCE登录提供者
+type Provider struct {
+	config Config
+	logger *logger.Logger
+
+	mu       sync.Mutex
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+	oauth2   *oauth2.Config
+}
+
+// DefaultConfig 从环境变量读取OIDC配置
+func DefaultConfig(loggerInstance *logger.Logger) Config {
+	scopes := splitAndTrim(utils.GetEnv("OIDC_SCOPES", "openid,profile,email"))
+	if !contains(scopes, gooidc.ScopeOpenID) {
+		scopes = append([]string{gooidc.ScopeOpenID}, scopes...)
+	}
+
+	roleMapping := make(map[string]string)
+	if raw := utils.GetEnv("OIDC_ROLE_MAPPING", ""); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &roleMapping); err != nil {
+			loggerInstance.Error("Invalid OIDC_ROLE_MAPPING, ignoring", zap.Error(err))
+		}
+	}
+
+	return Config{
+		Enable:        utils.GetEnv("OIDC_ENABLE", "false") == "true",
+		Issuer:        utils.GetEnv("OIDC_ISSUER", ""),
+		ClientID:      utils.GetEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret:  utils.GetEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:   utils.GetEnv("OIDC_REDIRECT_URL", ""),
+		Scopes:        scopes,
+		UsernameClaim: utils.GetEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		EmailClaim:    utils.GetEnv("OIDC_EMAIL_CLAIM", "email"),
+		NameClaim:     utils.GetEnv("OIDC_NAME_CLAIM", "name"),
+		GroupsClaim:   utils.GetEnv("OIDC_GROUPS_CLAIM", "groups"),
+		RoleMapping:   roleMapping,
+		DefaultRole:   utils.GetEnv("OIDC_DEFAULT_ROLE", ""),
+		AutoProvision: utils.GetEnv("OIDC_AUTO_PROVISION", "true") == "true",
+	}
+}
+
+// New 创建OIDC提供者，未启用时返回nil
+func New(config Config, loggerInstance *logger.Logger) *Provider {
+	if !config.Enable {
+		return nil
+	}
+	return &Provider{config: config, logger: loggerInstance}
+}
+
+// Config 返回当前配置
+func (p *Provider) Config() Config {
+	return p.config
+}
+
+// discover 延迟加载IdP元数据，IdP不可用时不影响服务启动
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return nil
+	}
+
+	provider, err := gooidc.NewProvider(ctx, p.config.Issuer)
+	if err != nil {
+		p.logger.Error("Error discovering OIDC provider", zap.Error(err), zap.String("issuer", p.config.Issuer))
+		return err
+	}
+	p.provider = provider
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.config.ClientID})
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.config.Scopes,
+	}
+	return nil
+}
+
+// AuthCodeURL 生成携带state、nonce和PKCE challenge的授权地址
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	return p.oauth2.AuthCodeURL(state,
+		gooidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchange 使用授权码和PKCE verifier换取令牌并校验ID Token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response missing id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	claims := make(map[string]any)
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	// userinfo 补充 ID Token 中缺失的声明（如部分IdP不在ID Token中下发groups）
+	if p.provider.UserInfoEndpoint() != "" {
+		userInfo, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			p.logger.Warn("Error fetching OIDC userinfo", zap.Error(err))
+		} else if userInfo.Subject == idToken.Subject {
+			extra := make(map[string]any)
+			if err := userInfo.Claims(&extra); err == nil {
+				for key, value := range extra {
+					if _, exists := claims[key]; !exists {
+						claims[key] = value
+					}
+				}
+			}
+		}
+	}
+
+	identity := p.identityFromClaims(idToken.Subject, claims)
+	identity.Issuer = idToken.Issuer
+	return identity, nil
+}
+
+// MapRoles 将IdP分组按RoleMapping映射为角色名，未配置映射的分组被忽略
+func (p *Provider) MapRoles(groups []string) []string {
+	roleNames := make([]string, 0)
+	for _, group := range groups {
+		if roleName, ok := p.config.RoleMapping[group]; ok && !contains(roleNames, roleName) {
+			roleNames = append(roleNames, roleName)
+		}
+	}
+	return roleNames
+}
+
+// NewCodeVerifier 生成PKCE code verifier
+func NewCodeVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+func (p *Provider) identityFromClaims(subject string, claims map[string]any) *Identity {
+	identity := &Identity{
+		Subject:  subject,
+		Username: stringClaim(claims, p.config.UsernameClaim),
+		Email:    stringClaim(claims, p.config.EmailClaim),
+		NickName: stringClaim(claims, p.config.NameClaim),
+		Groups:   stringSliceClaim(claims, p.config.GroupsClaim),
+		// 部分IdP以字符串下发 email_verified
+		EmailVerified: claims["email_verified"] == true || claims["email_verified"] == "true",
+	}
+	if identity.Username == "" {
+		identity.Username = identity.Email
+	}
+	if identity.Username == "" {
+		identity.Username = subject
+	}
+	return identity
+}
+
+func stringClaim(claims map[string]any, key string) string {
+	if value, ok := claims[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func stringSliceClaim(claims map[string]any, key string) []string {
+	switch value := claims[key].(type) {
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	case string:
+		return splitAndTrim(value)
+	default:
+		return nil
+	}
+}
+
+func splitAndTrim(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	testClientID = "test-client"
+	testCode     = "test-code"
+	testKeyID    = "test-key"
+)
+
+// mockIdP 本地模拟的OIDC身份提供者，校验PKCE并签发ID Token
+type mockIdP struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	nonce         string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	idp := &mockIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": testKeyID,
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != testCode ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != idp.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   testClientID,
+			"sub":   "user-1",
+			"nonce": idp.nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+		for key, value := range idp.claims {
+			claims[key] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = testKeyID
+		idToken, _ := token.SignedString(key)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize 模拟用户在IdP登录后，IdP记录授权请求中的challenge和nonce
+func (idp *mockIdP) authorize(t *testing.T, authURL string) {
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	idp.codeChallenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+}
+
+func newTestProvider(idp *mockIdP) *Provider {
+	return New(Config{
+		Enable:        true,
+		Issuer:        idp.server.URL,
+		ClientID:      testClientID,
+		RedirectURL:   "http://localhost/callback",
+		Scopes:        []string{"openid", "profile", "email"},
+		UsernameClaim: "preferred_username",
+		EmailClaim:    "email",
+		NameClaim:     "name",
+		GroupsClaim:   "groups",
+		RoleMapping:   map[string]string{"admins": "admin", "devs": "developer"},
+	}, &logger.Logger{Log: zap.NewNop()})
+}
+
+func TestExchange_MapsClaimsFromMockIdP(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.claims = jwt.MapClaims{
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"name":               "Alice",
+		"groups":             []string{"admins", "unmapped"},
+	}
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+	verifier := NewCodeVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-1", verifier)
+	assert.NoError(t, err)
+	idp.authorize(t, authURL)
+
+	identity, err := provider.Exchange(ctx, testCode, verifier, "nonce-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, idp.server.URL, identity.Issuer)
+	assert.Equal(t, "user-1", identity.Subject)
+	assert.Equal(t, "alice", identity.Username)
+	assert.Equal(t, "alice@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Alice", identity.NickName)
+	assert.Equal(t, []string{"admin"}, provider.MapRoles(identity.Groups))
+}
+
+func TestExchange_RejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-1", NewCodeVerifier())
+	assert.NoError(t, err)
+	idp.authorize(t, authURL)
+
+	_, err = provider.Exchange(ctx, testCode, NewCodeVerifier(), "nonce-1")
+
+	assert.Error(t, err)
+}
+
+func TestExchange_RejectsNonceMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+	verifier := NewCodeVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-1", verifier)
+	assert.NoError(t, err)
+	idp.authorize(t, authURL)
+
+	_, err = provider.Exchange(ctx, testCode, verifier, "nonce-2")
+
+	assert.Error(t, err)
+}
+
+func TestIdentityFromClaims_FallsBackToEmailAndSubject(t *testing.T) {
+	provider := &Provider{config: Config{UsernameClaim: "preferred_username", EmailClaim: "email", GroupsClaim: "groups"}}
+
+	identity := provider.identityFromClaims("sub-1", map[string]any{"email": "bob@example.com", "groups": "a, b"})
+	assert.Equal(t, "bob@example.com", identity.Username)
+	assert.Equal(t, []string{"a", "b"}, identity.Groups)
+
+	identity = provider.identityFromClaims("sub-2", map[string]any{})
+	assert.Equal(t, "sub-2", identity.Username)
+}
+
+func TestIdentityFromClaims_EmailVerified(t *testing.T) {
+	provider := &Provider{config: Config{EmailClaim: "email"}}
+
+	assert.True(t, provider.identityFromClaims("sub", map[string]any{"email_verified": true}).EmailVerified)
+	assert.True(t, provider.identityFromClaims("sub", map[string]any{"email_verified": "true"}).EmailVerified)
+	assert.False(t, provider.identityFromClaims("sub", map[string]any{"email_verified": false}).EmailVerified)
+	assert.False(t, provider.identityFromClaims("sub", map[string]any{}).EmailVerified)
+}
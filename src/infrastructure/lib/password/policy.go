@@ -0,0 +1,247 @@
+package password
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	lowerChars  = "abcdefghijkmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	digitChars  = "23456789"
+	symbolChars = "!@#$%^&*-_=+?"
+)
+
+// Config 密码策略配置
+type Config struct {
+	MinLength     int
+	MaxLength     int // bcrypt 只使用前72字节
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	BreachedFile  string // 泄露密码列表，每行一个明文或SHA1(十六进制)，可带 :count 后缀
+	HistoryCount  int    // 不能与最近N次使用过的密码相同，0 表示不限制
+	MaxAgeDays    int    // 密码最长有效天数，0 表示不过期
+}
+
+// Policy 密码策略
+type Policy struct {
+	config Config
+	logger *logger.Logger
+
+	breachedOnce  sync.Once
+	breachedPlain map[string]struct{}
+	breachedSHA1  map[string]struct{}
+}
+
+// ViolationError 密码不满足策略时返回，Violations 为全部未满足的规则
+type ViolationError struct {
+	Violations []string
+}
+
+func (e *ViolationError) Error() string {
+	return "password does not meet policy: " + strings.Join(e.Violations, "; ")
+}
+
+// DefaultConfig 从环境变量读取密码策略
+func DefaultConfig() Config {
+	return Config{
+		MinLength:     utils.GetEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:     utils.GetEnvAsInt("PASSWORD_MAX_LENGTH", 72),
+		RequireUpper:  utils.GetEnv("PASSWORD_REQUIRE_UPPER", "true") == "true",
+		RequireLower:  utils.GetEnv("PASSWORD_REQUIRE_LOWER", "true") == "true",
+		RequireDigit:  utils.GetEnv("PASSWORD_REQUIRE_DIGIT", "true") == "true",
+		RequireSymbol: utils.GetEnv("PASSWORD_REQUIRE_SYMBOL", "false") == "true",
+		BreachedFile:  utils.GetEnv("PASSWORD_BREACHED_FILE", ""),
+		HistoryCount:  utils.GetEnvAsInt("PASSWORD_HISTORY_COUNT", 5),
+		MaxAgeDays:    utils.GetEnvAsInt("PASSWORD_MAX_AGE_DAYS", 0),
+	}
+}
+
+// New 创建密码策略，泄露密码列表在第一次校验时加载
+func New(config Config, loggerInstance *logger.Logger) *Policy {
+	return &Policy{config: config, logger: loggerInstance}
+}
+
+func (p *Policy) Config() Config {
+	return p.config
+}
+
+// Validate 校验密码强度，username 不为空时禁止密码包含用户名
+func (p *Policy) Validate(password, username string) error {
+	var violations []string
+	length := len([]rune(password))
+	if length < p.config.MinLength {
+		violations = append(violations, "at least "+strconv.Itoa(p.config.MinLength)+" characters")
+	}
+	if p.config.MaxLength > 0 && len(password) > p.config.MaxLength {
+		violations = append(violations, "at most "+strconv.Itoa(p.config.MaxLength)+" bytes")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.config.RequireUpper && !hasUpper {
+		violations = append(violations, "an uppercase letter")
+	}
+	if p.config.RequireLower && !hasLower {
+		violations = append(violations, "a lowercase letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		violations = append(violations, "a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		violations = append(violations, "a symbol")
+	}
+	if username != "" && len(username) >= 3 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violations = append(violations, "must not contain the username")
+	}
+	if p.isBreached(password) {
+		violations = append(violations, "appears in a list of breached passwords")
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// IsExpired 超过最长有效天数时需要强制修改
+func (p *Policy) IsExpired(changedAt time.Time, now time.Time) bool {
+	if p.config.MaxAgeDays <= 0 || changedAt.IsZero() {
+		return false
+	}
+	return now.After(changedAt.AddDate(0, 0, p.config.MaxAgeDays))
+}
+
+// Generate 生成满足策略的一次性随机密码
+func (p *Policy) Generate() (string, error) {
+	length := p.config.MinLength + 4
+	if length < 12 {
+		length = 12
+	}
+	classes := []string{lowerChars, upperChars, digitChars}
+	if p.config.RequireSymbol {
+		classes = append(classes, symbolChars)
+	}
+	all := strings.Join(classes, "")
+
+	chars := make([]byte, 0, length)
+	// 每类字符至少一个
+	for _, class := range classes {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+	for len(chars) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+	// 打乱顺序
+	for i := len(chars) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		chars[i], chars[j.Int64()] = chars[j.Int64()], chars[i]
+	}
+	return string(chars), nil
+}
+
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
+
+func (p *Policy) isBreached(password string) bool {
+	p.breachedOnce.Do(p.loadBreached)
+	if len(p.breachedPlain) == 0 && len(p.breachedSHA1) == 0 {
+		return false
+	}
+	if _, ok := p.breachedPlain[strings.ToLower(password)]; ok {
+		return true
+	}
+	sum := sha1.Sum([]byte(password))
+	_, ok := p.breachedSHA1[strings.ToUpper(hex.EncodeToString(sum[:]))]
+	return ok
+}
+
+func (p *Policy) loadBreached() {
+	p.breachedPlain = make(map[string]struct{})
+	p.breachedSHA1 = make(map[string]struct{})
+	if p.config.BreachedFile == "" {
+		return
+	}
+	file, err := os.Open(p.config.BreachedFile)
+	if err != nil {
+		p.logger.Error("Error opening breached password list", zap.Error(err), zap.String("file", p.config.BreachedFile))
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if isSHA1Line(line) {
+			p.breachedSHA1[strings.ToUpper(line[:40])] = struct{}{}
+			continue
+		}
+		p.breachedPlain[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		p.logger.Error("Error reading breached password list", zap.Error(err))
+	}
+	p.logger.Info("Breached password list loaded",
+		zap.Int("plain", len(p.breachedPlain)), zap.Int("sha1", len(p.breachedSHA1)))
+}
+
+// isSHA1Line 40位十六进制，或 HIBP 格式的 HASH:count
+func isSHA1Line(line string) bool {
+	if len(line) != 40 && (len(line) < 42 || line[40] != ':') {
+		return false
+	}
+	_, err := hex.DecodeString(line[:40])
+	return err == nil
+}
+
+// IsViolation 判断错误是否为策略校验失败
+func IsViolation(err error) bool {
+	var violation *ViolationError
+	return errors.As(err, &violation)
+}
@@ -0,0 +1,65 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() Config {
+	return Config{MinLength: 8, MaxLength: 72, RequireUpper: true, RequireLower: true, RequireDigit: true}
+}
+
+func TestValidate_ReportsAllViolations(t *testing.T) {
+	policy := New(testConfig(), nil)
+
+	err := policy.Validate("abc", "")
+
+	var violation *ViolationError
+	assert.ErrorAs(t, err, &violation)
+	assert.Equal(t, []string{"at least 8 characters", "an uppercase letter", "a digit"}, violation.Violations)
+	assert.NoError(t, policy.Validate("Str0ngPassw0rd", "alice"))
+	assert.True(t, IsViolation(policy.Validate("Alice12345", "alice")))
+}
+
+func TestValidate_BreachedList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "breached.txt")
+	// Password123 的SHA1，HIBP 格式
+	content := "# comment\nSummer2024!\nB2E98AD6F6EB8508DD6A14CFA704BAD7F05F6FB1:100\n"
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	config := testConfig()
+	config.BreachedFile = file
+	loggerInstance, _ := logger.NewLogger()
+	policy := New(config, loggerInstance)
+
+	assert.NoError(t, policy.Validate("Summer2024!A1", ""))
+	assert.True(t, IsViolation(policy.Validate("Summer2024!", "")))
+	assert.True(t, IsViolation(policy.Validate("Password123", "")))
+}
+
+func TestIsExpired(t *testing.T) {
+	config := testConfig()
+	config.MaxAgeDays = 90
+	policy := New(config, nil)
+	now := time.Now()
+
+	assert.False(t, policy.IsExpired(now.AddDate(0, 0, -30), now))
+	assert.True(t, policy.IsExpired(now.AddDate(0, 0, -91), now))
+	assert.False(t, New(testConfig(), nil).IsExpired(now.AddDate(-5, 0, 0), now))
+}
+
+func TestGenerate_SatisfiesPolicy(t *testing.T) {
+	config := testConfig()
+	config.RequireSymbol = true
+	policy := New(config, nil)
+
+	for i := 0; i < 20; i++ {
+		password, err := policy.Generate()
+		assert.NoError(t, err)
+		assert.NoError(t, policy.Validate(password, ""))
+	}
+}
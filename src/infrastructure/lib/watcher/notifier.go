@@ -0,0 +1,161 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// DictionaryChannel 字典变更通知使用的 Redis 频道
+const DictionaryChannel = "dictionary:changed"
+
+const (
+	// notifierPingInterval 订阅连接长时间没有消息时发送 PING 检查连接，断开的连接在下一次读取时重连
+	notifierPingInterval = 30 * time.Second
+	notifierRetryDelay   = time.Second
+)
+
+type notification struct {
+	Instance string `json:"instance"`
+	Payload  string `json:"payload"`
+}
+
+// RedisNotifier 通过 Redis pub/sub 向其他实例广播变更通知，本实例发出的通知不会回调自身
+type RedisNotifier struct {
+	client   *redis.Client
+	channel  string
+	instance string
+	pubsub   *redis.PubSub
+	cancel   context.CancelFunc
+	Logger   *logger.Logger
+
+	mu          sync.RWMutex
+	handler     func(payload string)
+	onReconnect func()
+}
+
+func NewRedisNotifier(client *redis.Client, channel string, loggerInstance *logger.Logger) (*RedisNotifier, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		cancel()
+		_ = pubsub.Close()
+		return nil, err
+	}
+	n := &RedisNotifier{
+		client:   client,
+		channel:  channel,
+		instance: uuid.NewString(),
+		pubsub:   pubsub,
+		cancel:   cancel,
+		Logger:   loggerInstance,
+	}
+	go n.listen(ctx)
+	return n, nil
+}
+
+// SetHandler 收到其他实例的通知时调用
+func (n *RedisNotifier) SetHandler(handler func(payload string)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.handler = handler
+}
+
+// SetReconnectHandler 断线重连并重新订阅后调用，断线期间的通知已经丢失
+func (n *RedisNotifier) SetReconnectHandler(handler func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onReconnect = handler
+}
+
+func (n *RedisNotifier) Publish(payload string) error {
+	data, err := json.Marshal(notification{Instance: n.instance, Payload: payload})
+	if err != nil {
+		return err
+	}
+	if err := n.client.Publish(context.Background(), n.channel, data).Err(); err != nil {
+		n.Logger.Error("Error publishing notification", zap.Error(err), zap.String("channel", n.channel))
+		return err
+	}
+	return nil
+}
+
+func (n *RedisNotifier) Close() {
+	n.cancel()
+	if err := n.pubsub.Close(); err != nil {
+		n.Logger.Error("Error closing notifier", zap.Error(err), zap.String("channel", n.channel))
+	}
+}
+
+func (n *RedisNotifier) listen(ctx context.Context) {
+	listenPubSub(ctx, n.pubsub, n.channel, n.Logger, n.dispatch, n.resubscribed)
+}
+
+func (n *RedisNotifier) resubscribed() {
+	n.mu.RLock()
+	onReconnect := n.onReconnect
+	n.mu.RUnlock()
+	if onReconnect != nil {
+		onReconnect()
+	}
+}
+
+func (n *RedisNotifier) dispatch(payload string) {
+	var msg notification
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		n.Logger.Warn("Invalid notification", zap.Error(err), zap.String("channel", n.channel))
+		return
+	}
+	if msg.Instance == n.instance {
+		return
+	}
+	n.mu.RLock()
+	handler := n.handler
+	n.mu.RUnlock()
+	if handler != nil {
+		handler(msg.Payload)
+	}
+}
+
+// listenPubSub 直接读取订阅连接而不使用 Channel()，以便感知 go-redis 断线重连后的重新订阅。
+// 首次订阅的确认已由调用方读取，之后收到的订阅确认都是重连后的重新订阅，断线期间的消息已经丢失
+func listenPubSub(ctx context.Context, pubsub *redis.PubSub, channel string, log *logger.Logger, onMessage func(payload string), onResubscribe func()) {
+	for {
+		received, err := pubsub.ReceiveTimeout(ctx, notifierPingInterval)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				_ = pubsub.Ping(ctx)
+				continue
+			}
+			log.Warn("Error receiving pub/sub message", zap.Error(err), zap.String("channel", channel))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(notifierRetryDelay):
+			}
+			continue
+		}
+		switch received := received.(type) {
+		case *redis.Subscription:
+			if received.Kind != "subscribe" {
+				continue
+			}
+			log.Info("Pub/sub channel resubscribed", zap.String("channel", channel))
+			onResubscribe()
+		case *redis.Message:
+			onMessage(received.Payload)
+		}
+	}
+}
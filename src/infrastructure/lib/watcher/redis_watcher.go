@@ -0,0 +1,206 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// DefaultChannel 策略变更通知使用的 Redis 频道
+const DefaultChannel = "casbin:policy"
+
+// 通知类型，reload 表示其他实例需要全量重新加载
+const (
+	methodReload         = "reload"
+	methodAddPolicy      = "add_policy"
+	methodRemovePolicy   = "remove_policy"
+	methodRemoveFiltered = "remove_filtered_policy"
+	methodAddPolicies    = "add_policies"
+	methodRemovePolicies = "remove_policies"
+)
+
+type message struct {
+	Instance   string     `json:"instance"`
+	Method     string     `json:"method"`
+	Sec        string     `json:"sec,omitempty"`
+	PType      string     `json:"ptype,omitempty"`
+	FieldIndex int        `json:"field_index,omitempty"`
+	Rules      [][]string `json:"rules,omitempty"`
+}
+
+// RedisWatcher 通过 Redis pub/sub 在多个实例间同步 casbin 策略，增量变更直接应用到内存模型，失败时全量重新加载
+type RedisWatcher struct {
+	client   *redis.Client
+	channel  string
+	instance string
+	pubsub   *redis.PubSub
+	cancel   context.CancelFunc
+	Logger   *logger.Logger
+
+	mu       sync.RWMutex
+	enforcer *casbin.Enforcer
+	callback func(string)
+}
+
+var _ persist.WatcherEx = (*RedisWatcher)(nil)
+
+func NewRedisWatcher(client *redis.Client, channel string, loggerInstance *logger.Logger) (*RedisWatcher, error) {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		cancel()
+		_ = pubsub.Close()
+		return nil, err
+	}
+	w := &RedisWatcher{
+		client:   client,
+		channel:  channel,
+		instance: uuid.NewString(),
+		pubsub:   pubsub,
+		cancel:   cancel,
+		Logger:   loggerInstance,
+	}
+	go w.listen(ctx)
+	return w, nil
+}
+
+// SetEnforcer 收到其他实例的通知后更新该 enforcer，watcher 本身通过 enforcer.SetWatcher 设置
+func (w *RedisWatcher) SetEnforcer(enforcer *casbin.Enforcer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.enforcer = enforcer
+}
+
+func (w *RedisWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+func (w *RedisWatcher) Update() error {
+	return w.publish(message{Method: methodReload})
+}
+
+func (w *RedisWatcher) UpdateForAddPolicy(sec, ptype string, params ...string) error {
+	return w.publish(message{Method: methodAddPolicy, Sec: sec, PType: ptype, Rules: [][]string{params}})
+}
+
+func (w *RedisWatcher) UpdateForRemovePolicy(sec, ptype string, params ...string) error {
+	return w.publish(message{Method: methodRemovePolicy, Sec: sec, PType: ptype, Rules: [][]string{params}})
+}
+
+func (w *RedisWatcher) UpdateForRemoveFilteredPolicy(sec, ptype string, fieldIndex int, fieldValues ...string) error {
+	return w.publish(message{Method: methodRemoveFiltered, Sec: sec, PType: ptype, FieldIndex: fieldIndex, Rules: [][]string{fieldValues}})
+}
+
+func (w *RedisWatcher) UpdateForSavePolicy(model model.Model) error {
+	return w.Update()
+}
+
+func (w *RedisWatcher) UpdateForAddPolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(message{Method: methodAddPolicies, Sec: sec, PType: ptype, Rules: rules})
+}
+
+func (w *RedisWatcher) UpdateForRemovePolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(message{Method: methodRemovePolicies, Sec: sec, PType: ptype, Rules: rules})
+}
+
+func (w *RedisWatcher) Close() {
+	w.cancel()
+	if err := w.pubsub.Close(); err != nil {
+		w.Logger.Error("Error closing policy watcher", zap.Error(err))
+	}
+}
+
+func (w *RedisWatcher) publish(msg message) error {
+	msg.Instance = w.instance
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := w.client.Publish(context.Background(), w.channel, payload).Err(); err != nil {
+		w.Logger.Error("Error publishing policy update", zap.Error(err), zap.String("method", msg.Method))
+		return err
+	}
+	return nil
+}
+
+func (w *RedisWatcher) listen(ctx context.Context) {
+	listenPubSub(ctx, w.pubsub, w.channel, w.Logger, w.receive, w.resubscribed)
+}
+
+func (w *RedisWatcher) receive(payload string) {
+	var msg message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		w.Logger.Warn("Invalid policy update message", zap.Error(err))
+		return
+	}
+	// 本实例的变更已经应用
+	if msg.Instance == w.instance {
+		return
+	}
+	w.apply(msg, payload)
+}
+
+// resubscribed 断线期间其他实例的策略变更已经丢失，重新订阅后全量重新加载
+func (w *RedisWatcher) resubscribed() {
+	w.mu.RLock()
+	enforcer := w.enforcer
+	w.mu.RUnlock()
+	if enforcer == nil {
+		return
+	}
+	if err := enforcer.LoadPolicy(); err != nil {
+		w.Logger.Error("Error reloading policy after resubscribing", zap.Error(err))
+	}
+}
+
+func (w *RedisWatcher) apply(msg message, payload string) {
+	w.mu.RLock()
+	enforcer, callback := w.enforcer, w.callback
+	w.mu.RUnlock()
+	if enforcer == nil {
+		return
+	}
+	// 新增时忽略已存在的规则；删除时有规则不存在说明已经不一致，直接全量重新加载
+	applied := true
+	var err error
+	switch msg.Method {
+	case methodAddPolicy, methodAddPolicies:
+		_, err = enforcer.SelfAddPoliciesEx(msg.Sec, msg.PType, msg.Rules)
+	case methodRemovePolicy, methodRemovePolicies:
+		applied, err = enforcer.SelfRemovePolicies(msg.Sec, msg.PType, msg.Rules)
+	case methodRemoveFiltered:
+		if len(msg.Rules) == 1 {
+			_, err = enforcer.SelfRemoveFilteredPolicy(msg.Sec, msg.PType, msg.FieldIndex, msg.Rules[0]...)
+		}
+	default:
+		if callback != nil {
+			callback(payload)
+			return
+		}
+		applied = false
+	}
+	if applied && err == nil {
+		w.Logger.Debug("Applied policy update", zap.String("method", msg.Method))
+		return
+	}
+	if err != nil {
+		w.Logger.Warn("Incremental policy update failed, reloading", zap.Error(err), zap.String("method", msg.Method))
+	}
+	if err := enforcer.LoadPolicy(); err != nil {
+		w.Logger.Error("Error reloading policy", zap.Error(err))
+	}
+}
@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTestEnforcer(t *testing.T, params ...interface{}) *casbin.Enforcer {
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+[policy_definition]
+p = sub, obj, act
+[role_definition]
+g = _, _
+[policy_effect]
+e = some(where (p.eft == allow))
+[matchers]
+m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && regexMatch(r.act, p.act)`)
+	assert.NoError(t, err)
+	enforcer, err := casbin.NewEnforcer(append([]interface{}{m}, params...)...)
+	assert.NoError(t, err)
+	return enforcer
+}
+
+func TestRedisWatcherApply(t *testing.T) {
+	enforcer := newTestEnforcer(t)
+	w := &RedisWatcher{instance: "local", Logger: &logger.Logger{Log: zap.NewNop()}}
+	w.SetEnforcer(enforcer)
+
+	w.apply(message{Method: methodAddPolicies, Sec: "p", PType: "p", Rules: [][]string{{"2", "/v1/user", "GET"}}}, "")
+	w.apply(message{Method: methodAddPolicy, Sec: "g", PType: "g", Rules: [][]string{{"3", "2"}}}, "")
+	allowed, _ := enforcer.Enforce("3", "/v1/user", "GET")
+	assert.True(t, allowed)
+
+	// 重复的规则不影响其余规则
+	w.apply(message{Method: methodAddPolicies, Sec: "p", PType: "p", Rules: [][]string{{"2", "/v1/user", "GET"}, {"2", "/v1/role", "GET"}}}, "")
+	allowed, _ = enforcer.Enforce("2", "/v1/role", "GET")
+	assert.True(t, allowed)
+
+	w.apply(message{Method: methodRemoveFiltered, Sec: "g", PType: "g", FieldIndex: 0, Rules: [][]string{{"3"}}}, "")
+	allowed, _ = enforcer.Enforce("3", "/v1/user", "GET")
+	assert.False(t, allowed)
+
+	w.apply(message{Method: methodRemovePolicy, Sec: "p", PType: "p", Rules: [][]string{{"2", "/v1/user", "GET"}}}, "")
+	allowed, _ = enforcer.Enforce("2", "/v1/user", "GET")
+	assert.False(t, allowed)
+}
+
+func TestRedisWatcherReloadsAfterResubscribe(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.csv")
+	assert.NoError(t, os.WriteFile(policyFile, []byte("p, 2, /v1/user, GET\n"), 0o600))
+	enforcer := newTestEnforcer(t, fileadapter.NewAdapter(policyFile))
+	w := &RedisWatcher{instance: "local", Logger: &logger.Logger{Log: zap.NewNop()}}
+	w.SetEnforcer(enforcer)
+
+	// 断线期间其他实例新增的策略只存在于存储中
+	assert.NoError(t, os.WriteFile(policyFile, []byte("p, 2, /v1/user, GET\np, 2, /v1/role, GET\n"), 0o600))
+	allowed, _ := enforcer.Enforce("2", "/v1/role", "GET")
+	assert.False(t, allowed)
+
+	w.resubscribed()
+	allowed, _ = enforcer.Enforce("2", "/v1/role", "GET")
+	assert.True(t, allowed)
+}
@@ -1,18 +1,45 @@
 package jwt_blacklist
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+const (
+	// JwtBlacklistKeyPrefix redis key of a revoked token, suffixed with its jti
+	JwtBlacklistKeyPrefix = "jwt_blacklist:%s"
+	// UserTokensRevokedKeyPrefix unix time before which every token of the user is revoked
+	UserTokensRevokedKeyPrefix = "jwt_revoked_before:%d"
+
+	FUNCTION_TYPE_SYNC_JWT_BLACKLIST = "sync_jwt_blacklist"
+
+	// pendingCheckInterval how often postgres is polled for rows another
+	// instance wrote while redis was down
+	pendingCheckInterval = time.Minute
+)
+
 type JwtBlacklist struct {
 	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	CreatedAt *time.Time     `gorm:"column:created_at" json:"createdAt,omitempty"`
 	UpdatedAt *time.Time     `gorm:"column:updated_at" json:"updatedAt,omitempty"`
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at" json:"deletedAt,omitempty"`
 
-	Jwt string `gorm:"column:jwt;type:text;uniqueIndex" json:"jwt"`
+	Jwt       string     `gorm:"column:jwt;type:text;uniqueIndex" json:"jwt"`
+	Jti       string     `gorm:"column:jti;type:varchar(64);index" json:"jti"`
+	ExpiresAt *time.Time `gorm:"column:expires_at;index" json:"expiresAt,omitempty"`
 }
 
 func (*JwtBlacklist) TableName() string {
@@ -22,27 +49,230 @@ func (*JwtBlacklist) TableName() string {
 type JwtBlacklistRepository interface {
 	AddToBlacklist(jwtToken string) error
 	IsJwtInBlacklist(token string) (bool, error)
+	SyncToRedis() (int, error)
+	RevokeUserTokens(userId int64) error
 }
 
+// Repository keeps revoked tokens in redis with a TTL equal to the remaining
+// token life. Postgres is written while redis is unavailable and is read until
+// those rows have been moved back into redis.
 type Repository struct {
-	DB *gorm.DB
+	DB          *gorm.DB
+	RedisClient *redis.Client
+	Logger      *logger.Logger
+
+	mu        sync.Mutex
+	pending   bool
+	checkedAt time.Time
+	syncing   atomic.Bool
+}
+
+func NewUJwtBlacklistRepository(db *gorm.DB, redisClient *redis.Client, loggerInstance *logger.Logger) JwtBlacklistRepository {
+	return &Repository{DB: db, RedisClient: redisClient, Logger: loggerInstance}
+}
+
+func GetJwtBlacklistKey(jti string) string {
+	return fmt.Sprintf(JwtBlacklistKeyPrefix, jti)
 }
 
-func NewUJwtBlacklistRepository(db *gorm.DB) JwtBlacklistRepository {
-	return &Repository{DB: db}
+func GetUserTokensRevokedKey(userId int64) string {
+	return fmt.Sprintf(UserTokensRevokedKeyPrefix, userId)
 }
 
 // AddToBlacklist implements JwtBlacklistRepository.
 func (r *Repository) AddToBlacklist(jwtToken string) error {
-	result := r.DB.Create(&JwtBlacklist{
-		Jwt: jwtToken,
-	})
-	return result.Error
+	jti, expiresAt, err := parseRevocationClaims(jwtToken)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// an expired token is rejected anyway, nothing to remember
+		return nil
+	}
+
+	err = r.RedisClient.Set(context.Background(), GetJwtBlacklistKey(jti), 1, ttl).Err()
+	if err == nil {
+		return nil
+	}
+	r.Logger.Warn("Redis unavailable, storing revoked token in postgres", zap.Error(err))
+	r.setPending()
+	return r.DB.Create(&JwtBlacklist{
+		Jwt:       jwtToken,
+		Jti:       jti,
+		ExpiresAt: &expiresAt,
+	}).Error
 }
 
 // IsJwtInBlacklist implements JwtBlacklistRepository.
 func (r *Repository) IsJwtInBlacklist(jwtToken string) (bool, error) {
-	var count int64
-	r.DB.Model(&JwtBlacklist{}).Where("jwt = ?", jwtToken).Count(&count)
-	return count > 0, nil
+	jti, _, err := parseRevocationClaims(jwtToken)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := context.Background()
+	var exists *redis.IntCmd
+	var revokedBefore *redis.StringCmd
+	userId, issuedAt := parseUserClaims(jwtToken)
+	_, err = r.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, GetJwtBlacklistKey(jti))
+		if userId != 0 {
+			revokedBefore = pipe.Get(ctx, GetUserTokensRevokedKey(userId))
+		}
+		return nil
+	})
+	if err == nil || errors.Is(err, redis.Nil) {
+		if exists.Val() > 0 || isRevokedBefore(revokedBefore, issuedAt) {
+			return true, nil
+		}
+		if !r.hasPendingRows() {
+			return false, nil
+		}
+		// redis is back but rows written during the outage are not synced yet
+		go r.syncPending()
+	} else {
+		r.Logger.Warn("Redis unavailable, checking revoked token in postgres", zap.Error(err))
+		// other instances may be writing to postgres during the same outage
+		r.setPending()
+	}
+
+	var total int64
+	if err := r.DB.Model(&JwtBlacklist{}).
+		Where("jti = ? OR jwt = ?", jti, jwtToken).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// SyncToRedis moves revoked tokens stored in postgres (legacy rows and rows
+// written while redis was down) into redis and purges the table.
+func (r *Repository) SyncToRedis() (int, error) {
+	var rows []JwtBlacklist
+	if err := r.DB.Find(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	synced := 0
+	for _, row := range rows {
+		jti, expiresAt, err := parseRevocationClaims(row.Jwt)
+		if err != nil {
+			r.Logger.Warn("Dropping unparsable blacklisted token", zap.Uint("id", row.ID), zap.Error(err))
+		} else if ttl := time.Until(expiresAt); ttl > 0 {
+			if err := r.RedisClient.Set(ctx, GetJwtBlacklistKey(jti), 1, ttl).Err(); err != nil {
+				return synced, err
+			}
+			synced++
+		}
+		if err := r.DB.Unscoped().Delete(&JwtBlacklist{}, row.ID).Error; err != nil {
+			return synced, err
+		}
+	}
+
+	// rows written by other instances during the sync keep postgres in use
+	var remaining int64
+	if err := r.DB.Model(&JwtBlacklist{}).Count(&remaining).Error; err != nil {
+		return synced, err
+	}
+	r.mu.Lock()
+	r.pending = remaining > 0
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+
+	r.Logger.Info("Synchronized jwt blacklist to redis", zap.Int("synced", synced), zap.Int("rows", len(rows)))
+	return synced, nil
+}
+
+// RevokeUserTokens revokes every token issued to the user so far, e.g. after
+// an administrator reset the password. The marker lives as long as the
+// longest token lifetime.
+func (r *Repository) RevokeUserTokens(userId int64) error {
+	ttl := time.Duration(utils.GetEnvAsInt("JWT_REFRESH_TIME_HOUR", 24)) * time.Hour
+	err := r.RedisClient.Set(context.Background(), GetUserTokensRevokedKey(userId), time.Now().Unix(), ttl).Err()
+	if err != nil {
+		r.Logger.Error("Error revoking user tokens", zap.Error(err), zap.Int64("userId", userId))
+		return err
+	}
+	r.Logger.Info("Revoked all tokens of user", zap.Int64("userId", userId))
+	return nil
+}
+
+func (r *Repository) setPending() {
+	r.mu.Lock()
+	r.pending = true
+	r.mu.Unlock()
+}
+
+// hasPendingRows reports whether postgres may hold revoked tokens missing in
+// redis. Without a local hint the table is polled every pendingCheckInterval.
+func (r *Repository) hasPendingRows() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending || time.Since(r.checkedAt) < pendingCheckInterval {
+		return r.pending
+	}
+	var total int64
+	if err := r.DB.Model(&JwtBlacklist{}).Count(&total).Error; err != nil {
+		r.Logger.Warn("Error counting blacklisted tokens in postgres", zap.Error(err))
+		return true
+	}
+	r.checkedAt = time.Now()
+	r.pending = total > 0
+	return r.pending
+}
+
+// syncPending moves rows written during a redis outage back into redis once
+// redis answers again. Only one sync runs at a time.
+func (r *Repository) syncPending() {
+	if !r.syncing.CompareAndSwap(false, true) {
+		return
+	}
+	defer r.syncing.Store(false)
+	if _, err := r.SyncToRedis(); err != nil {
+		r.Logger.Warn("Error synchronizing jwt blacklist to redis", zap.Error(err))
+	}
+}
+
+// parseUserClaims returns the user id and issue time of a token, zero when absent.
+func parseUserClaims(jwtToken string) (int64, int64) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(jwtToken, claims); err != nil {
+		return 0, 0
+	}
+	userId, _ := claims["id"].(float64)
+	issuedAt, _ := claims["iat"].(float64)
+	return int64(userId), int64(issuedAt)
+}
+
+// isRevokedBefore tokens issued in the same second as the revocation are
+// revoked too, the user simply signs in again.
+func isRevokedBefore(revokedBefore *redis.StringCmd, issuedAt int64) bool {
+	if revokedBefore == nil {
+		return false
+	}
+	before, err := revokedBefore.Int64()
+	return err == nil && issuedAt <= before
+}
+
+// parseRevocationClaims returns the revocation id and expiration of a token.
+// Tokens issued before the jti claim existed are identified by their digest.
+func parseRevocationClaims(jwtToken string) (string, time.Time, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(jwtToken, claims); err != nil {
+		return "", time.Time{}, err
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return "", time.Time{}, errors.New("token missing expiration (exp) claim")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		sum := sha256.Sum256([]byte(jwtToken))
+		jti = hex.EncodeToString(sum[:])
+	}
+	return jti, time.Unix(int64(exp), 0), nil
 }
@@ -1 +1,103 @@
 package jwt_blacklist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestParseRevocationClaims_UsesJti(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	token := signToken(t, jwt.MapClaims{"id": 1, "jti": "abc", "exp": exp})
+
+	jti, expiresAt, err := parseRevocationClaims(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", jti)
+	assert.Equal(t, exp, expiresAt.Unix())
+}
+
+func TestParseRevocationClaims_LegacyTokenWithoutJti(t *testing.T) {
+	token := signToken(t, jwt.MapClaims{"id": 1, "exp": time.Now().Add(time.Hour).Unix()})
+
+	jti, _, err := parseRevocationClaims(token)
+
+	sum := sha256.Sum256([]byte(token))
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), jti)
+}
+
+func TestParseRevocationClaims_MissingExpiration(t *testing.T) {
+	token := signToken(t, jwt.MapClaims{"id": 1, "jti": "abc"})
+
+	_, _, err := parseRevocationClaims(token)
+
+	assert.Error(t, err)
+}
+
+func TestParseRevocationClaims_InvalidToken(t *testing.T) {
+	_, _, err := parseRevocationClaims("not-a-token")
+
+	assert.Error(t, err)
+}
+
+func TestHasPendingRows_PollsRowsWrittenByOtherInstances(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&JwtBlacklist{}))
+	loggerInstance, _ := logger.NewLogger()
+	r := &Repository{DB: db, Logger: loggerInstance}
+
+	assert.False(t, r.hasPendingRows())
+
+	expiresAt := time.Now().Add(time.Hour)
+	assert.NoError(t, db.Create(&JwtBlacklist{Jwt: "token", Jti: "abc", ExpiresAt: &expiresAt}).Error)
+	// 轮询间隔内沿用上次结果
+	assert.False(t, r.hasPendingRows())
+
+	r.checkedAt = time.Now().Add(-pendingCheckInterval)
+	assert.True(t, r.hasPendingRows())
+	// 发现待同步记录后每次都查询 postgres，直到同步完成
+	assert.True(t, r.hasPendingRows())
+}
+
+func TestSetPending_KeepsPostgresInUse(t *testing.T) {
+	r := &Repository{checkedAt: time.Now()}
+
+	r.setPending()
+
+	assert.True(t, r.hasPendingRows())
+}
+
+func TestParseUserClaims(t *testing.T) {
+	token := signToken(t, jwt.MapClaims{"id": 7, "iat": 1700000000, "exp": time.Now().Add(time.Hour).Unix()})
+
+	userId, issuedAt := parseUserClaims(token)
+
+	assert.Equal(t, int64(7), userId)
+	assert.Equal(t, int64(1700000000), issuedAt)
+}
+
+func TestIsRevokedBefore(t *testing.T) {
+	revokedAt := redis.NewStringResult("1700000000", nil)
+
+	assert.True(t, isRevokedBefore(revokedAt, 1699999999))
+	assert.True(t, isRevokedBefore(revokedAt, 1700000000))
+	assert.False(t, isRevokedBefore(revokedAt, 1700000001))
+	assert.False(t, isRevokedBefore(redis.NewStringResult("", redis.Nil), 1))
+	assert.False(t, isRevokedBefore(nil, 1))
+}
@@ -0,0 +1,71 @@
+package password_history
+
+import (
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory 用户历史密码哈希，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	ID           int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID       int64     `gorm:"column:user_id;index;not null" json:"userId"`
+	HashPassword string    `gorm:"column:hash_password;type:text;not null" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (*PasswordHistory) TableName() string {
+	return "sys_user_password_histories"
+}
+
+type IPasswordHistoryRepository interface {
+	// Add 记录新的密码哈希，只保留最近 keep 条
+	Add(userId int64, hashPassword string, keep int) error
+	// GetRecentHashes 最近 limit 条密码哈希，按时间倒序
+	GetRecentHashes(userId int64, limit int) ([]string, error)
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB, loggerInstance *logger.Logger) IPasswordHistoryRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) Add(userId int64, hashPassword string, keep int) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&PasswordHistory{UserID: userId, HashPassword: hashPassword}).Error; err != nil {
+			return err
+		}
+		if keep <= 0 {
+			return nil
+		}
+		keepIds := tx.Model(&PasswordHistory{}).Select("id").
+			Where("user_id = ?", userId).Order("id desc").Limit(keep)
+		return tx.Where("user_id = ? AND id NOT IN (?)", userId, keepIds).Delete(&PasswordHistory{}).Error
+	})
+	if err != nil {
+		r.Logger.Error("Error saving password history", zap.Error(err), zap.Int64("userId", userId))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) GetRecentHashes(userId int64, limit int) ([]string, error) {
+	var hashes []string
+	if limit <= 0 {
+		return hashes, nil
+	}
+	err := r.DB.Model(&PasswordHistory{}).Where("user_id = ?", userId).
+		Order("id desc").Limit(limit).Pluck("hash_password", &hashes).Error
+	if err != nil {
+		r.Logger.Error("Error getting password history", zap.Error(err), zap.Int64("userId", userId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return hashes, nil
+}
@@ -1,15 +1,32 @@
 package psql
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	taskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/password_history"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api_key"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dept"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/invitation"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/operation_records"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/permission_template"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_field"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/translation"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user_identity"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
@@ -140,6 +157,18 @@ func (r *PSQLRepository) InitDatabase() error {
 		return err
 	}
 
+	err = r.SeedSuperRole()
+	if err != nil {
+		r.Logger.Error("Error seeding super role", zap.Error(err))
+		return err
+	}
+
+	err = r.SeedScheduledTasks()
+	if err != nil {
+		r.Logger.Error("Error seeding scheduled tasks", zap.Error(err))
+		return err
+	}
+
 	r.Logger.Info("Database connection and migrations successful")
 	return nil
 }
@@ -148,9 +177,23 @@ func (r *PSQLRepository) MigrateEntitiesGORM() error {
 	// Import the models to register them with GORM
 	userModel := &user.User{}
 	apiModal := &api.SysApi{}
+	jwtBlacklistModel := &jwt_blacklist.JwtBlacklist{}
+	apiKeyModel := &api_key.SysApiKey{}
+	passwordHistoryModel := &password_history.PasswordHistory{}
+	invitationModel := &invitation.SysInvitation{}
+	operationRecordModel := &operation_records.SysOperationRecord{}
+	roleModel := &role.SysRole{}
+	deptModel := &dept.SysDept{}
+	userDeptModel := &dept.SysUserDept{}
+	roleFieldModel := &role_field.SysRoleField{}
+	permissionTemplateModel := &permission_template.SysPermissionTemplate{}
+	menuApiModel := &menu_api.SysBaseMenuApi{}
+	roleImpliedApiModel := &menu_api.SysRoleImpliedApi{}
+	translationModel := &translation.SysTranslation{}
+	userIdentityModel := &user_identity.UserIdentity{}
 
 	// Auto migrate the models to create/update tables
-	err := r.DB.AutoMigrate(userModel, apiModal)
+	err := r.DB.AutoMigrate(userModel, apiModal, jwtBlacklistModel, apiKeyModel, passwordHistoryModel, invitationModel, operationRecordModel, roleModel, deptModel, userDeptModel, roleFieldModel, permissionTemplateModel, menuApiModel, roleImpliedApiModel, translationModel, userIdentityModel)
 	if err != nil {
 		r.Logger.Error("Error migrating database entities", zap.Error(err))
 		return err
@@ -198,6 +241,75 @@ func (r *PSQLRepository) SeedInitialUser() error {
 	return nil
 }
 
+// SeedSuperRole 没有超级管理员角色时创建 super_admin 角色并授予ID为1的用户，
+// 兼容原先 CasbinMiddleware 中对该用户的硬编码跳过
+func (r *PSQLRepository) SeedSuperRole() error {
+	var count int64
+	if err := r.DB.Model(&role.SysRole{}).Where("is_super = ?", true).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var initialUser user.User
+	if err := r.DB.Where("id = ?", 1).First(&initialUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Logger.Warn("No super role and no user with id 1, set sys_roles.is_super manually")
+			return nil
+		}
+		return err
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		superRole := role.SysRole{Name: "super_admin", Label: "超级管理员", Status: 1}
+		if err := tx.Where("name = ?", superRole.Name).FirstOrCreate(&superRole).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&superRole).Update("is_super", true).Error; err != nil {
+			return err
+		}
+		userRole := user_role.SysUserRole{SysUserID: initialUser.ID, SysRoleID: superRole.ID}
+		if err := tx.Where(&userRole).FirstOrCreate(&userRole).Error; err != nil {
+			return err
+		}
+		r.Logger.Info("Super role seeded", zap.Int64("roleId", superRole.ID), zap.Int64("userId", initialUser.ID))
+		return nil
+	})
+}
+
+// SeedScheduledTasks 创建内置函数任务，已存在的任务保持不变以免覆盖管理员的修改
+func (r *PSQLRepository) SeedScheduledTasks() error {
+	tasks := []map[string]interface{}{
+		{
+			"task_name":        jwt_blacklist.FUNCTION_TYPE_SYNC_JWT_BLACKLIST,
+			"task_description": "Move revoked tokens written to postgres during a redis outage back into redis",
+			"cron_expression":  "0 */5 * * * *",
+			"task_type":        taskConstants.TaskTypeFunction,
+			"task_params":      datatypes.JSON(`{"function_name":"` + jwt_blacklist.FUNCTION_TYPE_SYNC_JWT_BLACKLIST + `"}`),
+			"exec_type":        taskConstants.TaskExecRecurring,
+			"status":           taskConstants.TaskStatusEnabled,
+		},
+	}
+
+	for _, task := range tasks {
+		var count int64
+		if err := r.DB.Model(&scheduled_task.ScheduledTask{}).
+			Where("task_name = ?", task["task_name"]).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		// map 写入，避免 status 零值被 gorm 默认值替换
+		if err := r.DB.Model(&scheduled_task.ScheduledTask{}).Create(task).Error; err != nil {
+			return err
+		}
+		r.Logger.Info("Scheduled task seeded", zap.Any("taskName", task["task_name"]))
+	}
+	return nil
+}
+
 // InitPSQLDB initializes the database connection with logger
 func InitPSQLDB(loggerInstance *logger.Logger) (*gorm.DB, error) {
 	repo := &PSQLRepository{
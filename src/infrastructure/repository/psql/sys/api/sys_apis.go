@@ -11,6 +11,7 @@ import (
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	domainApi "github.com/gbrayhan/microservices-go/src/domain/sys/api"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/utils"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -23,10 +24,11 @@ type SysApi struct {
 	CreatedAt   time.Time      `gorm:"column:created_at" json:"createdAt,omitempty"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt,omitempty"`
 	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index:idx_sys_apis_deleted_at" json:"deletedAt,omitempty"`
-	Path        string         `gorm:"column:path" json:"path,omitempty"`               // api路径
-	Description string         `gorm:"column:description" json:"description,omitempty"` // api中文描述
-	ApiGroup    string         `gorm:"column:api_group" json:"apiGroup,omitempty"`      // api组
-	Method      string         `gorm:"column:method" json:"method,omitempty"`           // 方法
+	Path        string         `gorm:"column:path" json:"path,omitempty"`                // api路径
+	Description string         `gorm:"column:description" json:"description,omitempty"`  // api中文描述
+	ApiGroup    string         `gorm:"column:api_group" json:"apiGroup,omitempty"`       // api组
+	Method      string         `gorm:"column:method" json:"method,omitempty"`            // 方法
+	Stale       bool           `gorm:"column:stale;not null;default:false" json:"stale"` // 路由已不存在
 }
 
 func (SysApi) TableName() string {
@@ -40,6 +42,7 @@ var ColumnsApiMapping = map[string]string{
 	"description": "description",
 	"apiGroup":    "api_group",
 	"method":      "method",
+	"stale":       "stale",
 	"createdAt":   "created_at",
 	"updatedAt":   "updated_at",
 }
@@ -123,11 +126,31 @@ func (r *Repository) GetByID(id int) (*domainApi.Api, error) {
 	return api.toDomainMapper(), nil
 }
 
+// Update 路径或方法变化时在同一事务中改写 casbin_rule 中的接口策略
 func (r *Repository) Update(id int, apiMap map[string]interface{}) (*domainApi.Api, error) {
 	var apiObj SysApi
 	apiObj.ID = id
 	delete(apiMap, "updated_at")
-	err := r.DB.Model(&apiObj).Updates(apiMap).Error
+	var previous SysApi
+	if err := r.DB.Where("id = ?", id).First(&previous).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &domainApi.Api{}, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+		}
+		r.Logger.Error("Error getting api for update", zap.Error(err), zap.Int("id", id))
+		return &domainApi.Api{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&apiObj).Updates(apiMap).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).First(&apiObj).Error; err != nil {
+			return err
+		}
+		if previous.Path == apiObj.Path && previous.Method == apiObj.Method {
+			return nil
+		}
+		return casbin_rule.RenameApiPolicies(tx, previous.Path, previous.Method, apiObj.Path, apiObj.Method)
+	})
 	if err != nil {
 		r.Logger.Error("Error updating api", zap.Error(err), zap.Int("id", id))
 		byteErr, _ := json.Marshal(err)
@@ -143,22 +166,35 @@ func (r *Repository) Update(id int, apiMap map[string]interface{}) (*domainApi.A
 			return &domainApi.Api{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
 		}
 	}
-	if err := r.DB.Where("id = ?", id).First(&apiObj).Error; err != nil {
-		r.Logger.Error("Error retrieving updated api", zap.Error(err), zap.Int("id", id))
-		return &domainApi.Api{}, err
-	}
 	r.Logger.Info("Successfully updated api", zap.Int("id", id))
 	return apiObj.toDomainMapper(), nil
 }
 
+// Delete 在同一事务中删除接口的 casbin 策略
 func (r *Repository) Delete(ids []int) error {
-	tx := r.DB.Where("id IN ?", ids).Delete(&SysApi{})
-
-	if tx.Error != nil {
-		r.Logger.Error("Error deleting api", zap.Error(tx.Error), zap.String("ids", fmt.Sprintf("%v", ids)))
+	var apis []SysApi
+	var rowsAffected int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ?", ids).Find(&apis).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN ?", ids).Delete(&SysApi{})
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		for _, api := range apis {
+			if err := casbin_rule.DeleteApiPolicies(tx, api.Path, api.Method); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.Logger.Error("Error deleting api", zap.Error(err), zap.String("ids", fmt.Sprintf("%v", ids)))
 		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
 	}
-	if tx.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		r.Logger.Warn("Api not found for deletion", zap.String("ids", fmt.Sprintf("%v", ids)))
 		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
 	}
@@ -329,6 +365,7 @@ func (u *SysApi) toDomainMapper() *domainApi.Api {
 		ApiGroup:    u.ApiGroup,
 		Method:      u.Method,
 		Description: u.Description,
+		Stale:       u.Stale,
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
 	}
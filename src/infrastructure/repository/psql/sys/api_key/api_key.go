@@ -0,0 +1,162 @@
+package api_key
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainApiKey "github.com/gbrayhan/microservices-go/src/domain/sys/api_key"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SysApiKey represents the sys_api_keys table structure.
+type SysApiKey struct {
+	ID         int64          `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	CreatedAt  time.Time      `gorm:"column:created_at" json:"createdAt,omitempty"`
+	UpdatedAt  time.Time      `gorm:"column:updated_at" json:"updatedAt,omitempty"`
+	DeletedAt  gorm.DeletedAt `gorm:"column:deleted_at;index" json:"deletedAt,omitempty"`
+	UserID     int64          `gorm:"column:user_id;index;not null" json:"userId"`
+	RoleID     int64          `gorm:"column:role_id;not null" json:"roleId"`
+	Name       string         `gorm:"column:name;type:varchar(100)" json:"name"`
+	Prefix     string         `gorm:"column:prefix;type:varchar(32);uniqueIndex" json:"prefix"`
+	KeyHash    string         `gorm:"column:key_hash;type:varchar(64);not null" json:"-"`
+	Paths      []string       `gorm:"column:paths;type:text;serializer:json" json:"paths"`
+	ExpiresAt  *time.Time     `gorm:"column:expires_at" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time     `gorm:"column:last_used_at" json:"lastUsedAt,omitempty"`
+	LastUsedIP string         `gorm:"column:last_used_ip;type:varchar(64)" json:"lastUsedIp"`
+	RevokedAt  *time.Time     `gorm:"column:revoked_at" json:"revokedAt,omitempty"`
+}
+
+func (*SysApiKey) TableName() string {
+	return "sys_api_keys"
+}
+
+type ISysApiKeyRepository interface {
+	Create(apiKey *domainApiKey.ApiKey) (*domainApiKey.ApiKey, error)
+	GetByID(id int64) (*domainApiKey.ApiKey, error)
+	GetByPrefix(prefix string) (*domainApiKey.ApiKey, error)
+	GetByUserID(userId int64) (*[]domainApiKey.ApiKey, error)
+	Revoke(id int64) error
+	TouchLastUsed(id int64, ip string, usedAt time.Time) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewSysApiKeyRepository(db *gorm.DB, loggerInstance *logger.Logger) ISysApiKeyRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) Create(apiKey *domainApiKey.ApiKey) (*domainApiKey.ApiKey, error) {
+	model := fromDomainMapper(apiKey)
+	if err := r.DB.Create(model).Error; err != nil {
+		r.Logger.Error("Error creating api key", zap.Error(err), zap.Int64("userId", apiKey.UserID))
+		return &domainApiKey.ApiKey{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully created api key", zap.Int64("id", model.ID), zap.String("prefix", model.Prefix))
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) GetByID(id int64) (*domainApiKey.ApiKey, error) {
+	var model SysApiKey
+	if err := r.DB.Where("id = ?", id).First(&model).Error; err != nil {
+		return &domainApiKey.ApiKey{}, r.notFoundOrUnknown(err, "id", id)
+	}
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) GetByPrefix(prefix string) (*domainApiKey.ApiKey, error) {
+	var model SysApiKey
+	if err := r.DB.Where("prefix = ?", prefix).First(&model).Error; err != nil {
+		return &domainApiKey.ApiKey{}, r.notFoundOrUnknown(err, "prefix", prefix)
+	}
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) GetByUserID(userId int64) (*[]domainApiKey.ApiKey, error) {
+	var models []SysApiKey
+	if err := r.DB.Where("user_id = ?", userId).Order("id desc").Find(&models).Error; err != nil {
+		r.Logger.Error("Error getting api keys by user", zap.Error(err), zap.Int64("userId", userId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&models), nil
+}
+
+func (r *Repository) Revoke(id int64) error {
+	tx := r.DB.Model(&SysApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if tx.Error != nil {
+		r.Logger.Error("Error revoking api key", zap.Error(tx.Error), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	if tx.RowsAffected == 0 {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	r.Logger.Info("Successfully revoked api key", zap.Int64("id", id))
+	return nil
+}
+
+func (r *Repository) TouchLastUsed(id int64, ip string, usedAt time.Time) error {
+	// UpdateColumns 不刷新 updated_at
+	err := r.DB.Model(&SysApiKey{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": usedAt, "last_used_ip": ip}).Error
+	if err != nil {
+		r.Logger.Error("Error updating api key last used", zap.Error(err), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) notFoundOrUnknown(err error, field string, value any) error {
+	if err == gorm.ErrRecordNotFound {
+		r.Logger.Warn("Api key not found", zap.Any(field, value))
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	r.Logger.Error("Error getting api key", zap.Error(err), zap.Any(field, value))
+	return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+}
+
+func (m *SysApiKey) toDomainMapper() *domainApiKey.ApiKey {
+	return &domainApiKey.ApiKey{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		RoleID:     m.RoleID,
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		KeyHash:    m.KeyHash,
+		Paths:      m.Paths,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		LastUsedIP: m.LastUsedIP,
+		RevokedAt:  m.RevokedAt,
+		CreatedAt:  domain.CustomTime{Time: m.CreatedAt},
+		UpdatedAt:  domain.CustomTime{Time: m.UpdatedAt},
+	}
+}
+
+func fromDomainMapper(k *domainApiKey.ApiKey) *SysApiKey {
+	return &SysApiKey{
+		ID:        k.ID,
+		UserID:    k.UserID,
+		RoleID:    k.RoleID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		KeyHash:   k.KeyHash,
+		Paths:     k.Paths,
+		ExpiresAt: k.ExpiresAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
+
+func arrayToDomainMapper(models *[]SysApiKey) *[]domainApiKey.ApiKey {
+	apiKeys := make([]domainApiKey.ApiKey, len(*models))
+	for i, model := range *models {
+		apiKeys[i] = *model.toDomainMapper()
+	}
+	return &apiKeys
+}
@@ -64,6 +64,11 @@ type MenuRepositoryInterface interface {
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(menuMap map[string]interface{}) (*domainMenu.Menu, error)
 	GetByIDs(ids []int) (*[]domainMenu.Menu, error)
+	// UpdatePositions 在同一事务中更新菜单的 parent_id、sort 和 menu_level
+	UpdatePositions(positions []domainMenu.MenuPosition) error
+	// UpdateInTree 在同一事务中读取全部菜单交给 plan 校验并计算位置变化，再更新菜单本身和受影响菜单的位置，
+	// plan 返回的错误原样返回
+	UpdateInTree(id int, menuMap map[string]interface{}, plan func(menus []domainMenu.Menu) ([]domainMenu.MenuPosition, error)) (*domainMenu.Menu, error)
 }
 
 type Repository struct {
@@ -135,23 +140,10 @@ func (r *Repository) Update(id int, menuMap map[string]interface{}) (*domainMenu
 	var menuObj SysBaseMenu
 	menuObj.ID = id
 	delete(menuMap, "updated_at")
-	err := r.DB.Model(&menuObj).
-		Select("parent_id", "menu_level", "name", "path", "component", "hidden", "sort", "icon", "title", "keep_alive").
-		Updates(menuMap).Error
+	err := updateMenu(r.DB, id, menuMap)
 	if err != nil {
 		r.Logger.Error("Error updating menu", zap.Error(err), zap.Int("id", id))
-		byteErr, _ := json.Marshal(err)
-		var newError domainErrors.GormErr
-		errUnmarshal := json.Unmarshal(byteErr, &newError)
-		if errUnmarshal != nil {
-			return nil, errUnmarshal
-		}
-		switch newError.Number {
-		case 1062:
-			return nil, domainErrors.NewAppErrorWithType(domainErrors.ResourceAlreadyExists)
-		default:
-			return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
-		}
+		return nil, updateError(err)
 	}
 	if err := r.DB.Where("id = ?", id).First(&menuObj).Error; err != nil {
 		r.Logger.Error("Error retrieving updated menu", zap.Error(err), zap.Int("id", id))
@@ -161,6 +153,59 @@ func (r *Repository) Update(id int, menuMap map[string]interface{}) (*domainMenu
 	return menuObj.toDomainMapper(), nil
 }
 
+func (r *Repository) UpdateInTree(id int, menuMap map[string]interface{}, plan func(menus []domainMenu.Menu) ([]domainMenu.MenuPosition, error)) (*domainMenu.Menu, error) {
+	var menuObj SysBaseMenu
+	delete(menuMap, "updated_at")
+	var planErr error
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var menus []SysBaseMenu
+		if err := tx.Find(&menus).Error; err != nil {
+			return err
+		}
+		positions, err := plan(*ArrayToDomainMapper(&menus))
+		if err != nil {
+			planErr = err
+			return err
+		}
+		if err := updateMenu(tx, id, menuMap); err != nil {
+			return err
+		}
+		if err := updatePositions(tx, positions); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(&menuObj).Error
+	})
+	if planErr != nil {
+		return nil, planErr
+	}
+	if err != nil {
+		r.Logger.Error("Error updating menu", zap.Error(err), zap.Int("id", id))
+		return nil, updateError(err)
+	}
+	r.Logger.Info("Successfully updated menu", zap.Int("id", id))
+	return menuObj.toDomainMapper(), nil
+}
+
+func updateMenu(tx *gorm.DB, id int, menuMap map[string]interface{}) error {
+	return tx.Model(&SysBaseMenu{ID: id}).
+		Select("parent_id", "menu_level", "name", "path", "component", "hidden", "sort", "icon", "title", "keep_alive", "menu_group_id").
+		Updates(menuMap).Error
+}
+
+func updateError(err error) error {
+	byteErr, _ := json.Marshal(err)
+	var newError domainErrors.GormErr
+	if errUnmarshal := json.Unmarshal(byteErr, &newError); errUnmarshal != nil {
+		return errUnmarshal
+	}
+	switch newError.Number {
+	case 1062:
+		return domainErrors.NewAppErrorWithType(domainErrors.ResourceAlreadyExists)
+	default:
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+}
+
 func (r *Repository) Delete(id int) error {
 	tx := r.DB.Delete(&SysBaseMenu{}, id)
 	if tx.Error != nil {
@@ -175,6 +220,18 @@ func (r *Repository) Delete(id int) error {
 	return nil
 }
 
+func (r *Repository) UpdatePositions(positions []domainMenu.MenuPosition) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		return updatePositions(tx, positions)
+	})
+	if err != nil {
+		r.Logger.Error("Error updating menu positions", zap.Error(err))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully updated menu positions", zap.Int("count", len(positions)))
+	return nil
+}
+
 func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainMenu.Menu], error) {
 	query := r.DB.Model(&SysBaseMenu{})
 
@@ -356,3 +413,16 @@ func (r *Repository) GetOneByMap(menuMap map[string]interface{}) (*domainMenu.Me
 	}
 	return menuRepository.toDomainMapper(), nil
 }
+
+func updatePositions(tx *gorm.DB, positions []domainMenu.MenuPosition) error {
+	for _, position := range positions {
+		if err := tx.Model(&SysBaseMenu{}).Where("id = ?", position.ID).Updates(map[string]interface{}{
+			"parent_id":  position.ParentID,
+			"sort":       position.Sort,
+			"menu_level": position.MenuLevel,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
+	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	menuGroupRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_group"
+	menuParamRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_parameter"
+	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errDryRun 试运行结束时返回，使导入事务回滚
+var errDryRun = errors.New("bundle import dry run")
+
+// errNoSuperUser 导入会取消最后一个超级管理员用户的超级角色
+var errNoSuperUser = errors.New("bundle import would leave no super administrator")
+
+// IBundleRepository 菜单、接口、角色及其绑定的整体导出和导入
+type IBundleRepository interface {
+	Export() (*domainPermission.Bundle, error)
+	// Import 在一个事务中按自然键新增或更新，不删除权限包之外的对象；包内角色的菜单、按钮和接口绑定被整体替换。
+	// allowSuper 为 false 时拒绝新增超级角色或修改 is_super；导入后没有超级管理员用户时整体回滚
+	Import(bundle *domainPermission.Bundle, dryRun bool, allowSuper bool) (*domainPermission.BundleImportResult, error)
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewBundleRepository(db *gorm.DB, loggerInstance *logger.Logger) IBundleRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+// snapshot 授权配置相关表的全部记录
+type snapshot struct {
+	groups    []menuGroupRepo.SysBaseMenuGroups
+	menus     []menuRepo.SysBaseMenu
+	btns      []menuBtnRepo.SysBaseMenuBtn
+	params    []menuParamRepo.SysBaseMenuParameter
+	apis      []apiRepo.SysApi
+	roles     []roleRepo.SysRole
+	roleMenus []roleMenuRepo.SysRoleMenu
+	roleBtns  []roleBtnRepo.SysRoleBtn
+	rules     []casbinRepo.CasbinRule
+}
+
+func loadSnapshot(tx *gorm.DB) (*snapshot, error) {
+	s := &snapshot{}
+	queries := []struct {
+		dest  interface{}
+		order string
+	}{
+		{&s.groups, "sort, id"},
+		{&s.menus, "sort, id"},
+		{&s.btns, "id"},
+		{&s.params, "id"},
+		{&s.apis, "api_group, path, method"},
+		{&s.roles, "id"},
+		{&s.roleMenus, "sys_role_id, sys_base_menu_id"},
+		{&s.roleBtns, "role_id, sys_menu_id, sys_base_menu_btn_id"},
+		{&s.rules, "id"},
+	}
+	for _, query := range queries {
+		if err := tx.Order(query.order).Find(query.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (r *Repository) Export() (*domainPermission.Bundle, error) {
+	s, err := loadSnapshot(r.DB)
+	if err != nil {
+		r.Logger.Error("Error loading authorization configuration", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+
+	bundle := &domainPermission.Bundle{
+		MenuGroups: make([]domainPermission.BundleMenuGroup, 0, len(s.groups)),
+		Apis:       make([]domainPermission.BundleApi, 0, len(s.apis)),
+		Roles:      make([]domainPermission.BundleRole, 0, len(s.roles)),
+	}
+	groupNames := make(map[int]string, len(s.groups))
+	for _, group := range s.groups {
+		groupNames[group.ID] = group.Name
+		bundle.MenuGroups = append(bundle.MenuGroups, domainPermission.BundleMenuGroup{
+			Name: group.Name, Path: group.Path, Sort: group.Sort, Status: group.Status,
+		})
+	}
+	bundle.Menus = exportMenus(s, groupNames)
+	for _, api := range s.apis {
+		bundle.Apis = append(bundle.Apis, domainPermission.BundleApi{
+			Path: api.Path, Method: api.Method, Description: api.Description, ApiGroup: api.ApiGroup,
+		})
+	}
+
+	menuNames := make(map[int64]string, len(s.menus))
+	for _, menu := range s.menus {
+		menuNames[int64(menu.ID)] = menu.Name
+	}
+	btnNames := make(map[int64]string, len(s.btns))
+	for _, btn := range s.btns {
+		btnNames[int64(btn.ID)] = btn.Name
+	}
+	roleNames := make(map[int64]string, len(s.roles))
+	for _, role := range s.roles {
+		roleNames[role.ID] = role.Name
+	}
+	for _, role := range s.roles {
+		exported := domainPermission.BundleRole{
+			Name:          role.Name,
+			Parent:        roleNames[role.ParentID],
+			Label:         role.Label,
+			Description:   role.Description,
+			DefaultRouter: role.DefaultRouter,
+			Status:        role.Status,
+			Order:         role.Order,
+			IsSuper:       role.IsSuper,
+			DataScope:     role.DataScope,
+			Menus:         []string{},
+			Buttons:       map[string][]string{},
+			Apis:          []domainPermission.BundleApiRef{},
+		}
+		if role.DataScopeFilter != "" {
+			_ = json.Unmarshal([]byte(role.DataScopeFilter), &exported.DataScopeFilter)
+		}
+		for _, roleMenu := range s.roleMenus {
+			if int64(roleMenu.SysRoleID) == role.ID && menuNames[int64(roleMenu.SysBaseMenuID)] != "" {
+				exported.Menus = append(exported.Menus, menuNames[int64(roleMenu.SysBaseMenuID)])
+			}
+		}
+		for _, roleBtn := range s.roleBtns {
+			menuName, btnName := menuNames[roleBtn.SysMenuID], btnNames[roleBtn.SysBaseMenuBtnID]
+			if roleBtn.RoleID == role.ID && menuName != "" && btnName != "" {
+				exported.Buttons[menuName] = append(exported.Buttons[menuName], btnName)
+			}
+		}
+		sub := strconv.FormatInt(role.ID, 10)
+		for _, rule := range s.rules {
+			if rule.PType == "p" && rule.V0 == sub {
+				exported.Apis = append(exported.Apis, domainPermission.BundleApiRef{Path: rule.V1, Method: rule.V2})
+			}
+		}
+		bundle.Roles = append(bundle.Roles, exported)
+	}
+	return bundle, nil
+}
+
+// exportMenus 按 parent_id 组装菜单树，父菜单不存在的菜单作为根节点
+func exportMenus(s *snapshot, groupNames map[int]string) []domainPermission.BundleMenu {
+	exists := make(map[int]bool, len(s.menus))
+	for _, menu := range s.menus {
+		exists[menu.ID] = true
+	}
+	children := make(map[int][]menuRepo.SysBaseMenu)
+	var roots []menuRepo.SysBaseMenu
+	for _, menu := range s.menus {
+		if menu.ParentID == 0 || !exists[menu.ParentID] || menu.ParentID == menu.ID {
+			roots = append(roots, menu)
+			continue
+		}
+		children[menu.ParentID] = append(children[menu.ParentID], menu)
+	}
+	btns := make(map[int64][]domainPermission.BundleButton)
+	for _, btn := range s.btns {
+		btns[btn.SysBaseMenuID] = append(btns[btn.SysBaseMenuID], domainPermission.BundleButton{Name: btn.Name, Desc: btn.Desc})
+	}
+	params := make(map[int64][]domainPermission.BundleParameter)
+	for _, param := range s.params {
+		params[param.SysBaseMenuID] = append(params[param.SysBaseMenuID], domainPermission.BundleParameter{Type: param.Type, Key: param.Key, Value: param.Value})
+	}
+
+	visited := make(map[int]bool, len(s.menus))
+	var build func(menu menuRepo.SysBaseMenu) domainPermission.BundleMenu
+	build = func(menu menuRepo.SysBaseMenu) domainPermission.BundleMenu {
+		visited[menu.ID] = true
+		node := domainPermission.BundleMenu{
+			Name:       menu.Name,
+			Path:       menu.Path,
+			Title:      menu.Title,
+			Icon:       menu.Icon,
+			Component:  menu.Component,
+			Hidden:     menu.Hidden,
+			Sort:       menu.Sort,
+			KeepAlive:  menu.KeepAlive,
+			MenuLevel:  menu.MenuLevel,
+			Group:      groupNames[menu.MenuGroupId],
+			Buttons:    btns[int64(menu.ID)],
+			Parameters: params[int64(menu.ID)],
+		}
+		for _, child := range children[menu.ID] {
+			if !visited[child.ID] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+	result := make([]domainPermission.BundleMenu, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, build(root))
+	}
+	return result
+}
+
+func (r *Repository) Import(bundle *domainPermission.Bundle, dryRun bool, allowSuper bool) (*domainPermission.BundleImportResult, error) {
+	result := &domainPermission.BundleImportResult{DryRun: dryRun, Changes: []domainPermission.BundleChange{}}
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		s, err := loadSnapshot(tx)
+		if err != nil {
+			return err
+		}
+		superUsers, err := countSuperUsers(tx)
+		if err != nil {
+			return err
+		}
+		if err := newImporter(tx, s, result, allowSuper).run(bundle); err != nil {
+			return err
+		}
+		if superUsers > 0 {
+			remaining, err := countSuperUsers(tx)
+			if err != nil {
+				return err
+			}
+			if remaining == 0 {
+				return domainErrors.NewAppError(errNoSuperUser, domainErrors.ValidationError)
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		var appErr *domainErrors.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		r.Logger.Error("Error importing permission bundle", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Permission bundle imported",
+		zap.Bool("dryRun", dryRun),
+		zap.Int("changes", len(result.Changes)),
+		zap.Int("unchanged", result.Unchanged))
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package bundle
+
+import (
+	"testing"
+
+	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
+	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportMenusRoundTripsThroughFlatten(t *testing.T) {
+	s := &snapshot{
+		menus: []menuRepo.SysBaseMenu{
+			{ID: 1, Name: "system", MenuGroupId: 7},
+			{ID: 2, Name: "user", ParentID: 1},
+			{ID: 3, Name: "role", ParentID: 1},
+			{ID: 4, Name: "orphan", ParentID: 99},
+		},
+		btns: []menuBtnRepo.SysBaseMenuBtn{{ID: 10, Name: "add", SysBaseMenuID: 2}},
+	}
+	menus := exportMenus(s, map[int]string{7: "admin"})
+
+	assert.Len(t, menus, 2)
+	assert.Equal(t, "admin", menus[0].Group)
+	assert.Len(t, menus[0].Children, 2)
+	assert.Equal(t, "add", menus[0].Children[0].Buttons[0].Name)
+	assert.Equal(t, "orphan", menus[1].Name)
+
+	flat := flattenMenus(menus, "", nil)
+	var order, parents []string
+	for _, item := range flat {
+		order = append(order, item.menu.Name)
+		parents = append(parents, item.parent)
+	}
+	assert.Equal(t, []string{"system", "user", "role", "orphan"}, order)
+	assert.Equal(t, []string{"", "system", "system", ""}, parents)
+}
@@ -0,0 +1,624 @@
+package bundle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
+	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	menuGroupRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_group"
+	menuParamRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_parameter"
+	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	menuApiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	"gorm.io/gorm"
+)
+
+// importer 在导入事务中维护自然键到数据库ID的映射，并记录每一项变化
+type importer struct {
+	tx       *gorm.DB
+	snapshot *snapshot
+	result   *domainPermission.BundleImportResult
+	// allowSuper 操作者是超级管理员时才允许新增超级角色或修改 is_super
+	allowSuper bool
+
+	groupIds  map[string]int
+	menuIds   map[string]int
+	menuNames map[int64]string
+	// btnIds 菜单名称 -> 按钮名称 -> 按钮ID
+	btnIds   map[string]map[string]int
+	btnNames map[int64]string
+	apiKeys  map[string]bool
+	roles    map[string]*roleRepo.SysRole
+}
+
+func newImporter(tx *gorm.DB, s *snapshot, result *domainPermission.BundleImportResult, allowSuper bool) *importer {
+	return &importer{
+		tx:         tx,
+		snapshot:   s,
+		result:     result,
+		allowSuper: allowSuper,
+		groupIds:   make(map[string]int),
+		menuIds:    make(map[string]int),
+		menuNames:  make(map[int64]string),
+		btnIds:     make(map[string]map[string]int),
+		btnNames:   make(map[int64]string),
+		apiKeys:    make(map[string]bool),
+		roles:      make(map[string]*roleRepo.SysRole),
+	}
+}
+
+func (i *importer) run(bundle *domainPermission.Bundle) error {
+	steps := []func(*domainPermission.Bundle) error{
+		i.importGroups,
+		i.importMenus,
+		i.importApis,
+		i.importRoles,
+		i.importRoleParents,
+		i.importRoleBindings,
+	}
+	for _, step := range steps {
+		if err := step(bundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *importer) importGroups(bundle *domainPermission.Bundle) error {
+	existing := make(map[string]*menuGroupRepo.SysBaseMenuGroups)
+	for idx := range i.snapshot.groups {
+		group := &i.snapshot.groups[idx]
+		if _, ok := existing[group.Name]; !ok {
+			existing[group.Name] = group
+			i.groupIds[group.Name] = group.ID
+		}
+	}
+	seen := make(map[string]bool)
+	for _, group := range bundle.MenuGroups {
+		if err := requireUnique(seen, "menu group", group.Name); err != nil {
+			return err
+		}
+		current, ok := existing[group.Name]
+		if !ok {
+			model := &menuGroupRepo.SysBaseMenuGroups{Name: group.Name, Path: group.Path, Sort: group.Sort, Status: group.Status}
+			if err := i.tx.Create(model).Error; err != nil {
+				return err
+			}
+			i.groupIds[group.Name] = model.ID
+			i.record("menu_group", group.Name, domainPermission.BundleActionCreate)
+			continue
+		}
+		if err := i.update(&menuGroupRepo.SysBaseMenuGroups{}, current.ID, "menu_group", group.Name,
+			map[string]interface{}{"path": current.Path, "sort": current.Sort, "status": current.Status},
+			map[string]interface{}{"path": group.Path, "sort": group.Sort, "status": group.Status}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatMenu 先序展开的菜单，保证父菜单先于子菜单导入
+type flatMenu struct {
+	menu   domainPermission.BundleMenu
+	parent string
+}
+
+func flattenMenus(menus []domainPermission.BundleMenu, parent string, out []flatMenu) []flatMenu {
+	for _, menu := range menus {
+		out = append(out, flatMenu{menu: menu, parent: parent})
+		out = flattenMenus(menu.Children, menu.Name, out)
+	}
+	return out
+}
+
+func (i *importer) importMenus(bundle *domainPermission.Bundle) error {
+	existing := make(map[string]*menuRepo.SysBaseMenu)
+	for idx := range i.snapshot.menus {
+		menu := &i.snapshot.menus[idx]
+		i.menuNames[int64(menu.ID)] = menu.Name
+		if _, ok := existing[menu.Name]; !ok {
+			existing[menu.Name] = menu
+			i.menuIds[menu.Name] = menu.ID
+		}
+	}
+	btns := make(map[int64][]menuBtnRepo.SysBaseMenuBtn)
+	for _, btn := range i.snapshot.btns {
+		btns[btn.SysBaseMenuID] = append(btns[btn.SysBaseMenuID], btn)
+		i.btnNames[int64(btn.ID)] = btn.Name
+		if menuName := i.menuNames[btn.SysBaseMenuID]; menuName != "" {
+			i.addButton(menuName, btn.Name, btn.ID)
+		}
+	}
+	params := make(map[int64][]menuParamRepo.SysBaseMenuParameter)
+	for _, param := range i.snapshot.params {
+		params[param.SysBaseMenuID] = append(params[param.SysBaseMenuID], param)
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range flattenMenus(bundle.Menus, "", nil) {
+		menu := item.menu
+		if err := requireUnique(seen, "menu", menu.Name); err != nil {
+			return err
+		}
+		groupId := 0
+		if menu.Group != "" {
+			id, ok := i.groupIds[menu.Group]
+			if !ok {
+				return validationError("menu %s references unknown menu group %s", menu.Name, menu.Group)
+			}
+			groupId = id
+		}
+		parentId := 0
+		if item.parent != "" {
+			parentId = i.menuIds[item.parent]
+		}
+		desired := map[string]interface{}{
+			"parent_id": parentId, "path": menu.Path, "title": menu.Title, "icon": menu.Icon,
+			"component": menu.Component, "hidden": menu.Hidden, "sort": menu.Sort,
+			"keep_alive": menu.KeepAlive, "menu_level": menu.MenuLevel, "menu_group_id": groupId,
+		}
+		current, ok := existing[menu.Name]
+		if !ok {
+			model := &menuRepo.SysBaseMenu{
+				ParentID: parentId, Path: menu.Path, Name: menu.Name, Title: menu.Title, Icon: menu.Icon,
+				Component: menu.Component, Hidden: menu.Hidden, Sort: menu.Sort, KeepAlive: menu.KeepAlive,
+				MenuLevel: menu.MenuLevel, MenuGroupId: groupId,
+			}
+			if err := i.tx.Omit("MenuBtns", "MenuParameters").Create(model).Error; err != nil {
+				return err
+			}
+			i.menuIds[menu.Name] = model.ID
+			i.menuNames[int64(model.ID)] = menu.Name
+			i.record("menu", menu.Name, domainPermission.BundleActionCreate)
+		} else if err := i.update(&menuRepo.SysBaseMenu{}, current.ID, "menu", menu.Name, map[string]interface{}{
+			"parent_id": current.ParentID, "path": current.Path, "title": current.Title, "icon": current.Icon,
+			"component": current.Component, "hidden": current.Hidden, "sort": current.Sort,
+			"keep_alive": current.KeepAlive, "menu_level": current.MenuLevel, "menu_group_id": current.MenuGroupId,
+		}, desired); err != nil {
+			return err
+		}
+		menuId := int64(i.menuIds[menu.Name])
+		if err := i.importButtons(menu, menuId, btns[menuId]); err != nil {
+			return err
+		}
+		if err := i.importParameters(menu, menuId, params[menuId]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *importer) importButtons(menu domainPermission.BundleMenu, menuId int64, existing []menuBtnRepo.SysBaseMenuBtn) error {
+	seen := make(map[string]bool)
+	for _, btn := range menu.Buttons {
+		key := menu.Name + "/" + btn.Name
+		if err := requireUnique(seen, "button", btn.Name); err != nil {
+			return err
+		}
+		current := findButton(existing, btn.Name)
+		if current == nil {
+			model := &menuBtnRepo.SysBaseMenuBtn{Name: btn.Name, Desc: btn.Desc, SysBaseMenuID: menuId}
+			if err := i.tx.Create(model).Error; err != nil {
+				return err
+			}
+			i.addButton(menu.Name, btn.Name, model.ID)
+			i.btnNames[int64(model.ID)] = btn.Name
+			i.record("button", key, domainPermission.BundleActionCreate)
+			continue
+		}
+		if err := i.update(&menuBtnRepo.SysBaseMenuBtn{}, current.ID, "button", key,
+			map[string]interface{}{"desc": current.Desc},
+			map[string]interface{}{"desc": btn.Desc}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *importer) importParameters(menu domainPermission.BundleMenu, menuId int64, existing []menuParamRepo.SysBaseMenuParameter) error {
+	seen := make(map[string]bool)
+	for _, param := range menu.Parameters {
+		key := menu.Name + "/" + param.Type + ":" + param.Key
+		if err := requireUnique(seen, "parameter", param.Type+":"+param.Key); err != nil {
+			return err
+		}
+		var current *menuParamRepo.SysBaseMenuParameter
+		for idx := range existing {
+			if existing[idx].Type == param.Type && existing[idx].Key == param.Key {
+				current = &existing[idx]
+				break
+			}
+		}
+		if current == nil {
+			model := &menuParamRepo.SysBaseMenuParameter{SysBaseMenuID: menuId, Type: param.Type, Key: param.Key, Value: param.Value}
+			if err := i.tx.Create(model).Error; err != nil {
+				return err
+			}
+			i.record("parameter", key, domainPermission.BundleActionCreate)
+			continue
+		}
+		if err := i.update(&menuParamRepo.SysBaseMenuParameter{}, current.ID, "parameter", key,
+			map[string]interface{}{"value": current.Value},
+			map[string]interface{}{"value": param.Value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *importer) importApis(bundle *domainPermission.Bundle) error {
+	existing := make(map[string]*apiRepo.SysApi)
+	for idx := range i.snapshot.apis {
+		api := &i.snapshot.apis[idx]
+		key := apiKey(api.Method, api.Path)
+		i.apiKeys[key] = true
+		if _, ok := existing[key]; !ok {
+			existing[key] = api
+		}
+	}
+	seen := make(map[string]bool)
+	for _, api := range bundle.Apis {
+		if api.Path == "" || api.Method == "" {
+			return validationError("api path and method are required")
+		}
+		key := apiKey(api.Method, api.Path)
+		if err := requireUnique(seen, "api", key); err != nil {
+			return err
+		}
+		current, ok := existing[key]
+		if !ok {
+			model := &apiRepo.SysApi{Path: api.Path, Method: strings.ToUpper(api.Method), Description: api.Description, ApiGroup: api.ApiGroup}
+			if err := i.tx.Create(model).Error; err != nil {
+				return err
+			}
+			i.apiKeys[key] = true
+			i.record("api", key, domainPermission.BundleActionCreate)
+			continue
+		}
+		if err := i.update(&apiRepo.SysApi{}, current.ID, "api", key,
+			map[string]interface{}{"description": current.Description, "api_group": current.ApiGroup},
+			map[string]interface{}{"description": api.Description, "api_group": api.ApiGroup}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *importer) importRoles(bundle *domainPermission.Bundle) error {
+	for idx := range i.snapshot.roles {
+		role := &i.snapshot.roles[idx]
+		if _, ok := i.roles[role.Name]; !ok {
+			i.roles[role.Name] = role
+		}
+	}
+	seen := make(map[string]bool)
+	for _, role := range bundle.Roles {
+		if err := requireUnique(seen, "role", role.Name); err != nil {
+			return err
+		}
+		dataScope := role.DataScope
+		if dataScope == "" {
+			dataScope = "all"
+		}
+		filter := roleRepo.FormatDataScopeFilter(role.DataScopeFilter)
+		current, ok := i.roles[role.Name]
+		if !i.allowSuper && (ok && current.IsSuper != role.IsSuper || !ok && role.IsSuper) {
+			return domainErrors.NewAppError(fmt.Errorf("role %s: only a super administrator can change is_super", role.Name), domainErrors.NotAuthorized)
+		}
+		if !ok {
+			model := &roleRepo.SysRole{
+				Name: role.Name, Label: role.Label, Description: role.Description, DefaultRouter: role.DefaultRouter,
+				Status: role.Status, Order: role.Order, IsSuper: role.IsSuper, DataScope: dataScope, DataScopeFilter: filter,
+			}
+			if err := i.tx.Create(model).Error; err != nil {
+				return err
+			}
+			i.roles[role.Name] = model
+			i.record("role", role.Name, domainPermission.BundleActionCreate)
+			continue
+		}
+		if err := i.update(&roleRepo.SysRole{}, current.ID, "role", role.Name, map[string]interface{}{
+			"label": current.Label, "description": current.Description, "default_router": current.DefaultRouter,
+			"status": current.Status, "order": current.Order, "is_super": current.IsSuper,
+			"data_scope": current.DataScope, "data_scope_filter": current.DataScopeFilter,
+		}, map[string]interface{}{
+			"label": role.Label, "description": role.Description, "default_router": role.DefaultRouter,
+			"status": role.Status, "order": role.Order, "is_super": role.IsSuper,
+			"data_scope": dataScope, "data_scope_filter": filter,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRoleParents 写入 parent_id 和对应的 casbin g 规则，拒绝形成环的继承关系
+func (i *importer) importRoleParents(bundle *domainPermission.Bundle) error {
+	roleNames := make(map[int64]string, len(i.roles))
+	for name, role := range i.roles {
+		roleNames[role.ID] = name
+	}
+	parents := make(map[string]string, len(i.roles))
+	for name, role := range i.roles {
+		parents[name] = roleNames[role.ParentID]
+	}
+	for _, role := range bundle.Roles {
+		if role.Parent != "" {
+			if _, ok := i.roles[role.Parent]; !ok {
+				return validationError("role %s references unknown parent role %s", role.Name, role.Parent)
+			}
+		}
+		parents[role.Name] = role.Parent
+	}
+	for _, role := range bundle.Roles {
+		for current, depth := parents[role.Name], 0; current != ""; current, depth = parents[current], depth+1 {
+			if current == role.Name || depth > len(parents) {
+				return validationError("role %s inherits from itself", role.Name)
+			}
+		}
+	}
+
+	for _, role := range bundle.Roles {
+		model := i.roles[role.Name]
+		sub := strconv.FormatInt(model.ID, 10)
+		var parentId int64
+		if role.Parent != "" {
+			parentId = i.roles[role.Parent].ID
+		}
+		var current []string
+		for _, rule := range i.snapshot.rules {
+			if rule.PType == "g" && rule.V0 == sub {
+				current = append(current, rule.V1)
+			}
+		}
+		inSync := len(current) == 0 && parentId == 0 ||
+			len(current) == 1 && current[0] == strconv.FormatInt(parentId, 10)
+		if model.ParentID == parentId && inSync {
+			i.result.Unchanged++
+			continue
+		}
+		if err := i.tx.Model(&roleRepo.SysRole{}).Where("id = ?", model.ID).Update("parent_id", parentId).Error; err != nil {
+			return err
+		}
+		if err := i.tx.Where("ptype = ? AND v0 = ?", "g", sub).Delete(&casbinRepo.CasbinRule{}).Error; err != nil {
+			return err
+		}
+		if parentId != 0 {
+			if err := i.tx.Create(&casbinRepo.CasbinRule{PType: "g", V0: sub, V1: strconv.FormatInt(parentId, 10)}).Error; err != nil {
+				return err
+			}
+		}
+		model.ParentID = parentId
+		i.record("role_parent", role.Name, domainPermission.BundleActionUpdate, "parent")
+	}
+	return nil
+}
+
+// importRoleBindings 用权限包中的菜单、按钮和接口替换角色现有的绑定
+func (i *importer) importRoleBindings(bundle *domainPermission.Bundle) error {
+	for _, role := range bundle.Roles {
+		model := i.roles[role.Name]
+
+		desiredMenus := make(map[string]int64, len(role.Menus))
+		for _, name := range role.Menus {
+			id, ok := i.menuIds[name]
+			if !ok {
+				return validationError("role %s references unknown menu %s", role.Name, name)
+			}
+			desiredMenus[name] = int64(id)
+		}
+		currentMenus := make(map[string]int64)
+		for _, roleMenu := range i.snapshot.roleMenus {
+			if int64(roleMenu.SysRoleID) == model.ID {
+				currentMenus[i.menuNameOrID(int64(roleMenu.SysBaseMenuID))] = int64(roleMenu.SysBaseMenuID)
+			}
+		}
+		changed := false
+		if i.diffBinding("role_menu", role.Name, currentMenus, desiredMenus) {
+			changed = true
+			if err := roleMenuRepo.ReplaceRoleMenus(i.tx, model.ID, mapValues(desiredMenus)); err != nil {
+				return err
+			}
+		}
+
+		desiredBtns := make(map[string]int64)
+		btnMenus := make(map[string]int64)
+		for menuName, btnNames := range role.Buttons {
+			for _, btnName := range btnNames {
+				btnId, ok := i.btnIds[menuName][btnName]
+				if !ok {
+					return validationError("role %s references unknown button %s/%s", role.Name, menuName, btnName)
+				}
+				key := menuName + "/" + btnName
+				desiredBtns[key] = int64(btnId)
+				btnMenus[key] = int64(i.menuIds[menuName])
+			}
+		}
+		currentBtns := make(map[string]int64)
+		for _, roleBtn := range i.snapshot.roleBtns {
+			if roleBtn.RoleID == model.ID {
+				key := i.menuNameOrID(roleBtn.SysMenuID) + "/" + i.btnNameOrID(roleBtn.SysBaseMenuBtnID)
+				currentBtns[key] = roleBtn.SysBaseMenuBtnID
+			}
+		}
+		if i.diffBinding("role_button", role.Name, currentBtns, desiredBtns) {
+			changed = true
+			btns := make(map[int64][]int64)
+			for key, btnId := range desiredBtns {
+				btns[btnMenus[key]] = append(btns[btnMenus[key]], btnId)
+			}
+			if err := roleBtnRepo.ReplaceRoleBtns(i.tx, model.ID, btns); err != nil {
+				return err
+			}
+		}
+
+		desiredApis := make(map[string]int64, len(role.Apis))
+		for _, api := range role.Apis {
+			key := apiKey(api.Method, api.Path)
+			if !i.apiKeys[key] {
+				return validationError("role %s references unknown api %s", role.Name, key)
+			}
+			desiredApis[key] = 0
+		}
+		currentApis := make(map[string]int64)
+		sub := strconv.FormatInt(model.ID, 10)
+		for _, rule := range i.snapshot.rules {
+			if rule.PType == "p" && rule.V0 == sub {
+				currentApis[apiKey(rule.V2, rule.V1)] = 0
+			}
+		}
+		if i.diffBinding("role_api", role.Name, currentApis, desiredApis) {
+			changed = true
+			apis := make([]string, 0, len(role.Apis))
+			for _, api := range role.Apis {
+				apis = append(apis, api.Path+"---"+strings.ToUpper(api.Method))
+			}
+			if err := casbinRepo.ReplaceRolePolicies(i.tx, model.ID, apis); err != nil {
+				return err
+			}
+		}
+		// 目标环境中菜单和按钮关联的接口仍然自动授予
+		if changed {
+			if err := menuApiRepo.SyncRolePolicies(i.tx, model.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffBinding 记录角色绑定的增减，返回是否需要重写
+func (i *importer) diffBinding(kind, roleName string, current, desired map[string]int64) bool {
+	var added, removed []string
+	for key := range desired {
+		if _, ok := current[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		i.result.Unchanged++
+		return false
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	for _, key := range added {
+		i.record(kind, roleName+": "+key, domainPermission.BundleActionAdd)
+	}
+	for _, key := range removed {
+		i.record(kind, roleName+": "+key, domainPermission.BundleActionRemove)
+	}
+	return true
+}
+
+// update 只写入变化的字段，没有变化时计入 Unchanged
+func (i *importer) update(model interface{}, id interface{}, kind, key string, current, desired map[string]interface{}) error {
+	var fields []string
+	for field, value := range desired {
+		if current[field] != value {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		i.result.Unchanged++
+		return nil
+	}
+	sort.Strings(fields)
+	updates := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		updates[field] = desired[field]
+	}
+	if err := i.tx.Model(model).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+	i.record(kind, key, domainPermission.BundleActionUpdate, fields...)
+	return nil
+}
+
+func (i *importer) record(kind, key, action string, fields ...string) {
+	i.result.Changes = append(i.result.Changes, domainPermission.BundleChange{Kind: kind, Key: key, Action: action, Fields: fields})
+}
+
+func (i *importer) addButton(menuName, btnName string, id int) {
+	if i.btnIds[menuName] == nil {
+		i.btnIds[menuName] = make(map[string]int)
+	}
+	if _, ok := i.btnIds[menuName][btnName]; !ok {
+		i.btnIds[menuName][btnName] = id
+	}
+}
+
+// menuNameOrID 已删除菜单的绑定没有名称，使用 #ID 作为键
+func (i *importer) menuNameOrID(id int64) string {
+	if name, ok := i.menuNames[id]; ok {
+		return name
+	}
+	return "#" + strconv.FormatInt(id, 10)
+}
+
+func (i *importer) btnNameOrID(id int64) string {
+	if name, ok := i.btnNames[id]; ok {
+		return name
+	}
+	return "#" + strconv.FormatInt(id, 10)
+}
+
+func findButton(btns []menuBtnRepo.SysBaseMenuBtn, name string) *menuBtnRepo.SysBaseMenuBtn {
+	for idx := range btns {
+		if btns[idx].Name == name {
+			return &btns[idx]
+		}
+	}
+	return nil
+}
+
+func requireUnique(seen map[string]bool, kind, key string) error {
+	if key == "" {
+		return validationError("%s name is required", kind)
+	}
+	if seen[key] {
+		return validationError("duplicate %s %s in bundle", kind, key)
+	}
+	seen[key] = true
+	return nil
+}
+
+func apiKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+func mapValues(values map[string]int64) []int64 {
+	result := make([]int64, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+	}
+	return result
+}
+
+// countSuperUsers 统计未删除且拥有超级管理员角色的用户数
+func countSuperUsers(tx *gorm.DB) (int64, error) {
+	var count int64
+	err := tx.Model(&roleRepo.SysRole{}).
+		Joins("JOIN sys_user_roles ON sys_user_roles.sys_role_id = sys_roles.id").
+		Joins("JOIN sys_users ON sys_users.id = sys_user_roles.sys_user_id AND sys_users.deleted_at IS NULL").
+		Where("sys_roles.is_super = ?", true).
+		Distinct("sys_users.id").
+		Count(&count).Error
+	return count, err
+}
+
+func validationError(format string, args ...interface{}) error {
+	return domainErrors.NewAppError(fmt.Errorf(format, args...), domainErrors.ValidationError)
+}
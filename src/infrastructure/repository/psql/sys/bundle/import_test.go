@@ -0,0 +1,177 @@
+package bundle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
+	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	menuGroupRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_group"
+	menuParamRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_parameter"
+	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	menuApiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	userRoleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
+	userRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestRepository 使用内存 SQLite，预置一个超级管理员角色和用户
+func newTestRepository(t *testing.T) (*Repository, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// 部分表名带 public. 前缀，附加同名库；内存库按连接隔离，只保留一个连接
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.Exec("ATTACH DATABASE ':memory:' AS public").Error)
+	// sys_base_menu_btns 与 sys_apis 的 deleted_at 索引同名，SQLite 中索引名全局唯一
+	require.NoError(t, db.AutoMigrate(&menuBtnRepo.SysBaseMenuBtn{}))
+	require.NoError(t, db.Exec("DROP INDEX idx_sys_apis_deleted_at").Error)
+	require.NoError(t, db.AutoMigrate(
+		&menuGroupRepo.SysBaseMenuGroups{}, &menuRepo.SysBaseMenu{},
+		&menuParamRepo.SysBaseMenuParameter{}, &apiRepo.SysApi{}, &roleRepo.SysRole{},
+		&roleMenuRepo.SysRoleMenu{}, &roleBtnRepo.SysRoleBtn{}, &casbinRepo.CasbinRule{},
+		&menuApiRepo.SysBaseMenuApi{}, &menuApiRepo.SysRoleImpliedApi{},
+		&userRepo.User{}, &userRoleRepo.SysUserRole{},
+	))
+	// numeric 主键在 SQLite 中不会自增，改为整数主键重建
+	for _, table := range []string{"sys_base_menu_groups", "sys_base_menus", "sys_base_menu_btns", "casbin_rule", "sys_users"} {
+		var ddl string
+		require.NoError(t, db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&ddl).Error)
+		require.NoError(t, db.Exec("DROP TABLE "+table).Error)
+		require.NoError(t, db.Exec(strings.Replace(ddl, "`id` numeric(20,0)", "`id` integer", 1)).Error)
+	}
+	require.NoError(t, db.Create(&roleRepo.SysRole{ID: 1, Name: "admin", IsSuper: true, DataScope: "all"}).Error)
+	require.NoError(t, db.Create(&userRepo.User{ID: 1, UserName: "admin", Email: "admin@example.com"}).Error)
+	require.NoError(t, db.Create(&userRoleRepo.SysUserRole{SysUserID: 1, SysRoleID: 1}).Error)
+	return &Repository{DB: db, Logger: &logger.Logger{Log: zap.NewNop()}}, db
+}
+
+func testBundle() *domainPermission.Bundle {
+	return &domainPermission.Bundle{
+		Version:    domainPermission.BundleVersion,
+		MenuGroups: []domainPermission.BundleMenuGroup{{Name: "admin", Path: "/admin", Status: 1}},
+		Menus: []domainPermission.BundleMenu{{
+			Name: "system", Path: "/system", Group: "admin",
+			Children: []domainPermission.BundleMenu{{
+				Name: "user", Path: "/system/user",
+				Buttons:    []domainPermission.BundleButton{{Name: "add", Desc: "add user"}},
+				Parameters: []domainPermission.BundleParameter{{Type: "query", Key: "tab", Value: "all"}},
+			}},
+		}},
+		Apis: []domainPermission.BundleApi{
+			{Path: "/v1/user", Method: "GET", ApiGroup: "user"},
+			{Path: "/v1/user", Method: "POST", ApiGroup: "user"},
+		},
+		Roles: []domainPermission.BundleRole{
+			{Name: "admin", IsSuper: true, Status: 1, DataScope: "all", Menus: []string{}, Buttons: map[string][]string{}},
+			{
+				Name: "operator", Parent: "viewer", Status: 1, DataScope: "self",
+				Menus:   []string{"system", "user"},
+				Buttons: map[string][]string{"user": {"add"}},
+				Apis:    []domainPermission.BundleApiRef{{Path: "/v1/user", Method: "POST"}},
+			},
+			{
+				Name: "viewer", Status: 1, DataScope: "self",
+				Menus: []string{"system", "user"},
+				Apis:  []domainPermission.BundleApiRef{{Path: "/v1/user", Method: "GET"}},
+			},
+		},
+	}
+}
+
+func errorType(err error) domainErrors.ErrorType {
+	var appErr *domainErrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Type
+	}
+	return ""
+}
+
+func TestImport_IsIdempotent(t *testing.T) {
+	r, db := newTestRepository(t)
+
+	first, err := r.Import(testBundle(), false, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Changes)
+
+	second, err := r.Import(testBundle(), false, true)
+	require.NoError(t, err)
+	assert.Empty(t, second.Changes)
+	assert.Positive(t, second.Unchanged)
+
+	var menus, roles, rules int64
+	db.Model(&menuRepo.SysBaseMenu{}).Count(&menus)
+	db.Model(&roleRepo.SysRole{}).Count(&roles)
+	db.Model(&casbinRepo.CasbinRule{}).Count(&rules)
+	assert.Equal(t, int64(2), menus)
+	assert.Equal(t, int64(3), roles)
+	// 两个角色各一条接口策略，加一条继承规则
+	assert.Equal(t, int64(3), rules)
+
+	// 导出后再导入同样没有变化
+	exported, err := r.Export()
+	require.NoError(t, err)
+	exported.Version = domainPermission.BundleVersion
+	third, err := r.Import(exported, false, true)
+	require.NoError(t, err)
+	assert.Empty(t, third.Changes)
+}
+
+func TestImport_DryRunDoesNotWrite(t *testing.T) {
+	r, db := newTestRepository(t)
+
+	result, err := r.Import(testBundle(), true, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Changes)
+
+	var roles int64
+	db.Model(&roleRepo.SysRole{}).Count(&roles)
+	assert.Equal(t, int64(1), roles)
+}
+
+func TestImport_RejectsSuperChangesFromNonSuperOperator(t *testing.T) {
+	r, db := newTestRepository(t)
+
+	_, err := r.Import(testBundle(), false, false)
+	require.NoError(t, err)
+
+	bundle := testBundle()
+	bundle.Roles[1].IsSuper = true
+	_, err = r.Import(bundle, false, false)
+	assert.Equal(t, domainErrors.NotAuthorized, errorType(err))
+
+	bundle = testBundle()
+	bundle.Roles = append(bundle.Roles, domainPermission.BundleRole{Name: "root", IsSuper: true})
+	_, err = r.Import(bundle, false, false)
+	assert.Equal(t, domainErrors.NotAuthorized, errorType(err))
+
+	var superRoles int64
+	db.Model(&roleRepo.SysRole{}).Where("is_super = ?", true).Count(&superRoles)
+	assert.Equal(t, int64(1), superRoles)
+}
+
+func TestImport_RefusesToRemoveLastSuperUser(t *testing.T) {
+	r, db := newTestRepository(t)
+
+	bundle := testBundle()
+	bundle.Roles[0].IsSuper = false
+	_, err := r.Import(bundle, false, true)
+	assert.Equal(t, domainErrors.ValidationError, errorType(err))
+
+	var role roleRepo.SysRole
+	require.NoError(t, db.First(&role, 1).Error)
+	assert.True(t, role.IsSuper)
+}
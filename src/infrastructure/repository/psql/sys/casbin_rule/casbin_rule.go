@@ -37,6 +37,7 @@ var ColumnsRoleMapping = map[string]string{
 type ICasbinRuleRepository interface {
 	Insert(roleId int, UpdateMap map[string]any) error
 	GetByRoleId(roleId int) ([]string, error)
+	GetAll() ([]CasbinRule, error)
 }
 
 type Repository struct {
@@ -47,7 +48,7 @@ type Repository struct {
 // GetByRoleId implements ISysRoleMenuRepository.
 func (r *Repository) GetByRoleId(roleId int) ([]string, error) {
 	var casbinRules []CasbinRule
-	err := r.DB.Where(&CasbinRule{V0: strconv.Itoa(roleId)}).Find(&casbinRules).Error
+	err := r.DB.Where(&CasbinRule{PType: "p", V0: strconv.Itoa(roleId)}).Find(&casbinRules).Error
 	if err != nil {
 		r.Logger.Error("Error getting all casbin_rule", zap.Error(err))
 		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
@@ -89,14 +90,73 @@ func (r *Repository) Insert(roleId int, UpdateMap map[string]any) error {
 		}
 		casbinMenus = append(casbinMenus, roleMenu)
 	}
-	if err := r.DB.Where(&CasbinRule{V0: strconv.Itoa(roleId)}).
-		Delete(&CasbinRule{}).Error; err != nil {
-		return err
+	// 只替换接口策略，保留角色继承的 g 规则
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&CasbinRule{PType: "p", V0: strconv.Itoa(roleId)}).
+			Delete(&CasbinRule{}).Error; err != nil {
+			return err
+		}
+		if len(casbinMenus) == 0 {
+			return nil
+		}
+		return tx.Model(&CasbinRule{}).Create(&casbinMenus).Error
+	})
+}
+
+func (r *Repository) GetAll() ([]CasbinRule, error) {
+	var casbinRules []CasbinRule
+	if err := r.DB.Order("id").Find(&casbinRules).Error; err != nil {
+		r.Logger.Error("Error getting all casbin_rule", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return casbinRules, nil
+}
+
+// DeleteRules 在调用方的事务中按ID删除规则
+func DeleteRules(tx *gorm.DB, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
 	}
-	if err := r.DB.Model(&CasbinRule{}).Create(&casbinMenus).Error; err != nil {
+	return tx.Where("id IN ?", ids).Delete(&CasbinRule{}).Error
+}
+
+// RenameApiPolicies 在调用方的事务中把接口策略迁移到新的路径和方法
+func RenameApiPolicies(tx *gorm.DB, oldPath, oldMethod, newPath, newMethod string) error {
+	return tx.Model(&CasbinRule{}).
+		Where("ptype = ? AND v1 = ? AND v2 = ?", "p", oldPath, oldMethod).
+		Updates(map[string]interface{}{"v1": newPath, "v2": newMethod}).Error
+}
+
+// DeleteApiPolicies 在调用方的事务中删除接口的全部策略
+func DeleteApiPolicies(tx *gorm.DB, path, method string) error {
+	return tx.Where("ptype = ? AND v1 = ? AND v2 = ?", "p", path, method).Delete(&CasbinRule{}).Error
+}
+
+// DeleteRolePolicies 在调用方的事务中删除角色的接口策略以及它作为子角色和父角色的 g 规则
+func DeleteRolePolicies(tx *gorm.DB, roleId int64) error {
+	sub := strconv.FormatInt(roleId, 10)
+	return tx.Where("(ptype = ? AND v0 = ?) OR (ptype = ? AND (v0 = ? OR v1 = ?))", "p", sub, "g", sub, sub).
+		Delete(&CasbinRule{}).Error
+}
+
+// ReplaceRolePolicies 在调用方的事务中替换角色的接口策略，apis 格式为 path---method，保留 g 规则
+func ReplaceRolePolicies(tx *gorm.DB, roleId int64, apis []string) error {
+	sub := strconv.FormatInt(roleId, 10)
+	if err := tx.Where(&CasbinRule{PType: "p", V0: sub}).Delete(&CasbinRule{}).Error; err != nil {
 		return err
 	}
-	return nil
+	rules := make([]CasbinRule, 0, len(apis))
+	for _, api := range apis {
+		path, method, ok := strings.Cut(api, "---")
+		if !ok {
+			return fmt.Errorf("invalid api rule %q", api)
+		}
+		rules = append(rules, CasbinRule{PType: "p", V0: sub, V1: path, V2: method})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return tx.Create(&rules).Error
 }
 
 func NewCasbinRuleRepository(db *gorm.DB, loggerInstance *logger.Logger) ICasbinRuleRepository {
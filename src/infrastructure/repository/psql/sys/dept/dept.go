@@ -0,0 +1,215 @@
+package dept
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainDept "github.com/gbrayhan/microservices-go/src/domain/sys/dept"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SysDept struct {
+	ID        int64          `gorm:"column:id;primary_key;autoIncrement" json:"id,omitempty"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	Name      string         `gorm:"column:name;not null"`
+	ParentID  int64          `gorm:"column:parent_id;index"`
+	Order     int64          `gorm:"column:order"`
+	Status    int16          `gorm:"column:status"`
+}
+
+func (SysDept) TableName() string {
+	return "sys_depts"
+}
+
+// SysUserDept 用户所属部门，一个用户可以属于多个部门
+type SysUserDept struct {
+	SysUserID int64 `gorm:"column:sys_user_id;primaryKey" json:"sysUserId"`
+	SysDeptID int64 `gorm:"column:sys_dept_id;primaryKey;index" json:"sysDeptId"`
+	IsLeader  bool  `gorm:"column:is_leader;not null;default:false" json:"isLeader"`
+}
+
+func (SysUserDept) TableName() string {
+	return "sys_user_depts"
+}
+
+type ISysDeptRepository interface {
+	GetAll(status int) (*[]domainDept.Dept, error)
+	GetByID(id int64) (*domainDept.Dept, error)
+	Create(dept *domainDept.Dept) (*domainDept.Dept, error)
+	Update(id int64, deptMap map[string]interface{}) (*domainDept.Dept, error)
+	Delete(id int64) error
+	CountChildren(id int64) (int64, error)
+	GetMembers(deptIds ...int64) ([]domainDept.Member, error)
+	GetUserDepts(userId int64) ([]domainDept.Member, error)
+	// SetMembers 替换部门的全部成员和负责人
+	SetMembers(deptId int64, members []domainDept.Member) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewSysDeptRepository(db *gorm.DB, loggerInstance *logger.Logger) ISysDeptRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetAll(status int) (*[]domainDept.Dept, error) {
+	var depts []SysDept
+	tx := r.DB.Order(`"order" asc, id asc`)
+	if status != 0 {
+		tx = tx.Where("status = ?", status)
+	}
+	if err := tx.Find(&depts).Error; err != nil {
+		r.Logger.Error("Error getting all depts", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&depts), nil
+}
+
+func (r *Repository) GetByID(id int64) (*domainDept.Dept, error) {
+	var dept SysDept
+	if err := r.DB.Where("id = ?", id).First(&dept).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.Logger.Warn("Dept not found", zap.Int64("id", id))
+			return &domainDept.Dept{}, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+		}
+		r.Logger.Error("Error getting dept by ID", zap.Error(err), zap.Int64("id", id))
+		return &domainDept.Dept{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return dept.toDomainMapper(), nil
+}
+
+func (r *Repository) Create(dept *domainDept.Dept) (*domainDept.Dept, error) {
+	model := fromDomainMapper(dept)
+	if err := r.DB.Create(model).Error; err != nil {
+		r.Logger.Error("Error creating dept", zap.Error(err), zap.String("name", dept.Name))
+		return &domainDept.Dept{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully created dept", zap.Int64("id", model.ID), zap.String("name", model.Name))
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) Update(id int64, deptMap map[string]interface{}) (*domainDept.Dept, error) {
+	delete(deptMap, "updated_at")
+	delete(deptMap, "id")
+	if err := r.DB.Model(&SysDept{ID: id}).Updates(deptMap).Error; err != nil {
+		r.Logger.Error("Error updating dept", zap.Error(err), zap.Int64("id", id))
+		return &domainDept.Dept{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return r.GetByID(id)
+}
+
+// Delete 删除部门并移除其成员关系
+func (r *Repository) Delete(id int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&SysDept{}, id)
+		if result.Error != nil {
+			r.Logger.Error("Error deleting dept", zap.Error(result.Error), zap.Int64("id", id))
+			return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		if result.RowsAffected == 0 {
+			return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+		}
+		if err := tx.Where("sys_dept_id = ?", id).Delete(&SysUserDept{}).Error; err != nil {
+			r.Logger.Error("Error deleting dept members", zap.Error(err), zap.Int64("id", id))
+			return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		r.Logger.Info("Successfully deleted dept", zap.Int64("id", id))
+		return nil
+	})
+}
+
+func (r *Repository) CountChildren(id int64) (int64, error) {
+	var count int64
+	if err := r.DB.Model(&SysDept{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		r.Logger.Error("Error counting child depts", zap.Error(err), zap.Int64("id", id))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return count, nil
+}
+
+func (r *Repository) GetMembers(deptIds ...int64) ([]domainDept.Member, error) {
+	var members []SysUserDept
+	if len(deptIds) == 0 {
+		return []domainDept.Member{}, nil
+	}
+	if err := r.DB.Where("sys_dept_id IN ?", deptIds).Find(&members).Error; err != nil {
+		r.Logger.Error("Error getting dept members", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return membersToDomainMapper(members), nil
+}
+
+func (r *Repository) GetUserDepts(userId int64) ([]domainDept.Member, error) {
+	var members []SysUserDept
+	if err := r.DB.Where("sys_user_id = ?", userId).Find(&members).Error; err != nil {
+		r.Logger.Error("Error getting user depts", zap.Error(err), zap.Int64("userId", userId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return membersToDomainMapper(members), nil
+}
+
+func (r *Repository) SetMembers(deptId int64, members []domainDept.Member) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sys_dept_id = ?", deptId).Delete(&SysUserDept{}).Error; err != nil {
+			r.Logger.Error("Error clearing dept members", zap.Error(err), zap.Int64("deptId", deptId))
+			return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		models := make([]SysUserDept, 0, len(members))
+		for _, member := range members {
+			models = append(models, SysUserDept{SysUserID: member.UserID, SysDeptID: deptId, IsLeader: member.IsLeader})
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			r.Logger.Error("Error saving dept members", zap.Error(err), zap.Int64("deptId", deptId))
+			return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		return nil
+	})
+}
+
+func (d *SysDept) toDomainMapper() *domainDept.Dept {
+	return &domainDept.Dept{
+		ID:        d.ID,
+		Name:      d.Name,
+		ParentID:  d.ParentID,
+		Order:     d.Order,
+		Status:    d.Status,
+		CreatedAt: domain.CustomTime{Time: d.CreatedAt},
+		UpdatedAt: domain.CustomTime{Time: d.UpdatedAt},
+	}
+}
+
+func fromDomainMapper(d *domainDept.Dept) *SysDept {
+	return &SysDept{
+		ID:       d.ID,
+		Name:     d.Name,
+		ParentID: d.ParentID,
+		Order:    d.Order,
+		Status:   d.Status,
+	}
+}
+
+func arrayToDomainMapper(depts *[]SysDept) *[]domainDept.Dept {
+	deptsDomain := make([]domainDept.Dept, len(*depts))
+	for i, dept := range *depts {
+		deptsDomain[i] = *dept.toDomainMapper()
+	}
+	return &deptsDomain
+}
+
+func membersToDomainMapper(members []SysUserDept) []domainDept.Member {
+	result := make([]domainDept.Member, 0, len(members))
+	for _, member := range members {
+		result = append(result, domainDept.Member{DeptID: member.SysDeptID, UserID: member.SysUserID, IsLeader: member.IsLeader})
+	}
+	return result
+}
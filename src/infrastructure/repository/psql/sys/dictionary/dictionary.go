@@ -59,6 +59,8 @@ type DictionaryRepositoryInterface interface {
 	GetOneByMap(dictionaryMap map[string]interface{}) (*domainDictionary.Dictionary, error)
 
 	GetByType(typeText string) (*domainDictionary.Dictionary, error)
+	// GetByTypes 启用的字典及其启用的详情，types 为空时返回全部启用的字典
+	GetByTypes(types []string) ([]domainDictionary.Dictionary, error)
 }
 
 type Repository struct {
@@ -338,3 +340,24 @@ func (r *Repository) GetByType(typeText string) (*domainDictionary.Dictionary, e
 	r.Logger.Info("Successfully retrieved all dictionaries", zap.String("typeText", typeText))
 	return dictionaries.toDomainMapper(), nil
 }
+
+func (r *Repository) GetByTypes(types []string) ([]domainDictionary.Dictionary, error) {
+	var dictionaries []SysDictionary
+	tx := r.DB.
+		Preload("Details", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", constants.StatusEnabled).Order("sort desc")
+		}).
+		Where("status = ?", constants.StatusEnabled)
+	if len(types) > 0 {
+		tx = tx.Where("type IN ?", types)
+	}
+	if err := tx.Find(&dictionaries).Error; err != nil {
+		r.Logger.Error("Error getting dictionaries by types", zap.Error(err), zap.Strings("types", types))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	result := make([]domainDictionary.Dictionary, 0, len(dictionaries))
+	for i := range dictionaries {
+		result = append(result, *dictionaries[i].toDomainMapper())
+	}
+	return result, nil
+}
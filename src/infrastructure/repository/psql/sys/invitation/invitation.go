@@ -0,0 +1,177 @@
+package invitation
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainInvitation "github.com/gbrayhan/microservices-go/src/domain/sys/invitation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SysInvitation represents the sys_user_invitations table structure.
+type SysInvitation struct {
+	ID             int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	CreatedAt      time.Time  `gorm:"column:created_at" json:"createdAt,omitempty"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at" json:"updatedAt,omitempty"`
+	Email          string     `gorm:"column:email;type:varchar(255);index;not null" json:"email"`
+	RoleID         int64      `gorm:"column:role_id" json:"roleId"`
+	InvitedBy      int64      `gorm:"column:invited_by" json:"invitedBy"`
+	TokenHash      string     `gorm:"column:token_hash;type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at" json:"expiresAt"`
+	AcceptedAt     *time.Time `gorm:"column:accepted_at" json:"acceptedAt,omitempty"`
+	AcceptedUserID int64      `gorm:"column:accepted_user_id" json:"acceptedUserId"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at" json:"revokedAt,omitempty"`
+}
+
+func (*SysInvitation) TableName() string {
+	return "sys_user_invitations"
+}
+
+type ISysInvitationRepository interface {
+	Create(invitation *domainInvitation.Invitation) (*domainInvitation.Invitation, error)
+	GetByID(id int64) (*domainInvitation.Invitation, error)
+	GetByTokenHash(tokenHash string) (*domainInvitation.Invitation, error)
+	GetAll() (*[]domainInvitation.Invitation, error)
+	// Claim 将可用的邀请标记为已使用，并发注册时只有一个请求能成功
+	Claim(id int64, now time.Time) (bool, error)
+	// Release 注册失败时恢复邀请
+	Release(id int64) error
+	SetAcceptedUser(id int64, userId int64) error
+	Revoke(id int64) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewSysInvitationRepository(db *gorm.DB, loggerInstance *logger.Logger) ISysInvitationRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) Create(invitation *domainInvitation.Invitation) (*domainInvitation.Invitation, error) {
+	model := fromDomainMapper(invitation)
+	if err := r.DB.Create(model).Error; err != nil {
+		r.Logger.Error("Error creating invitation", zap.Error(err), zap.String("email", invitation.Email))
+		return &domainInvitation.Invitation{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully created invitation", zap.Int64("id", model.ID), zap.String("email", model.Email))
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) GetByID(id int64) (*domainInvitation.Invitation, error) {
+	var model SysInvitation
+	if err := r.DB.Where("id = ?", id).First(&model).Error; err != nil {
+		return &domainInvitation.Invitation{}, r.notFoundOrUnknown(err, "id", id)
+	}
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) GetByTokenHash(tokenHash string) (*domainInvitation.Invitation, error) {
+	var model SysInvitation
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		return &domainInvitation.Invitation{}, r.notFoundOrUnknown(err, "tokenHash", tokenHash)
+	}
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) GetAll() (*[]domainInvitation.Invitation, error) {
+	var models []SysInvitation
+	if err := r.DB.Order("id desc").Find(&models).Error; err != nil {
+		r.Logger.Error("Error getting invitations", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&models), nil
+}
+
+func (r *Repository) Claim(id int64, now time.Time) (bool, error) {
+	tx := r.DB.Model(&SysInvitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", id, now).
+		Update("accepted_at", now)
+	if tx.Error != nil {
+		r.Logger.Error("Error claiming invitation", zap.Error(tx.Error), zap.Int64("id", id))
+		return false, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (r *Repository) Release(id int64) error {
+	err := r.DB.Model(&SysInvitation{}).Where("id = ? AND accepted_user_id = 0", id).
+		Update("accepted_at", nil).Error
+	if err != nil {
+		r.Logger.Error("Error releasing invitation", zap.Error(err), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) SetAcceptedUser(id int64, userId int64) error {
+	err := r.DB.Model(&SysInvitation{}).Where("id = ?", id).Update("accepted_user_id", userId).Error
+	if err != nil {
+		r.Logger.Error("Error updating invitation user", zap.Error(err), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) Revoke(id int64) error {
+	tx := r.DB.Model(&SysInvitation{}).
+		Where("id = ? AND revoked_at IS NULL AND accepted_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if tx.Error != nil {
+		r.Logger.Error("Error revoking invitation", zap.Error(tx.Error), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	if tx.RowsAffected == 0 {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	r.Logger.Info("Successfully revoked invitation", zap.Int64("id", id))
+	return nil
+}
+
+func (r *Repository) notFoundOrUnknown(err error, field string, value any) error {
+	if err == gorm.ErrRecordNotFound {
+		r.Logger.Warn("Invitation not found", zap.Any(field, value))
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	r.Logger.Error("Error getting invitation", zap.Error(err), zap.Any(field, value))
+	return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+}
+
+func (m *SysInvitation) toDomainMapper() *domainInvitation.Invitation {
+	return &domainInvitation.Invitation{
+		ID:             m.ID,
+		Email:          m.Email,
+		RoleID:         m.RoleID,
+		InvitedBy:      m.InvitedBy,
+		TokenHash:      m.TokenHash,
+		ExpiresAt:      m.ExpiresAt,
+		AcceptedAt:     m.AcceptedAt,
+		AcceptedUserID: m.AcceptedUserID,
+		RevokedAt:      m.RevokedAt,
+		CreatedAt:      domain.CustomTime{Time: m.CreatedAt},
+		UpdatedAt:      domain.CustomTime{Time: m.UpdatedAt},
+	}
+}
+
+func fromDomainMapper(i *domainInvitation.Invitation) *SysInvitation {
+	return &SysInvitation{
+		ID:        i.ID,
+		Email:     i.Email,
+		RoleID:    i.RoleID,
+		InvitedBy: i.InvitedBy,
+		TokenHash: i.TokenHash,
+		ExpiresAt: i.ExpiresAt,
+	}
+}
+
+func arrayToDomainMapper(models *[]SysInvitation) *[]domainInvitation.Invitation {
+	invitations := make([]domainInvitation.Invitation, len(*models))
+	for i, model := range *models {
+		invitations[i] = *model.toDomainMapper()
+	}
+	return &invitations
+}
@@ -0,0 +1,452 @@
+package menu_api
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainMenu "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
+	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SysBaseMenuApi 菜单或按钮依赖的接口，SysBaseMenuBtnID 为 0 表示菜单本身
+type SysBaseMenuApi struct {
+	SysBaseMenuID    int64 `gorm:"column:sys_base_menu_id;type:int8;primaryKey"`
+	SysBaseMenuBtnID int64 `gorm:"column:sys_base_menu_btn_id;type:int8;primaryKey"`
+	SysApiID         int64 `gorm:"column:sys_api_id;type:int8;primaryKey"`
+}
+
+// TableName 指定表名
+func (SysBaseMenuApi) TableName() string {
+	return "sys_base_menu_apis"
+}
+
+// SysRoleImpliedApi casbin 中仅因菜单或按钮关联而存在的接口策略，其余策略视为直接绑定
+type SysRoleImpliedApi struct {
+	RoleID   int64 `gorm:"column:role_id;type:int8;primaryKey"`
+	SysApiID int64 `gorm:"column:sys_api_id;type:int8;primaryKey"`
+}
+
+// TableName 指定表名
+func (SysRoleImpliedApi) TableName() string {
+	return "sys_role_implied_apis"
+}
+
+type IMenuApiRepository interface {
+	GetByMenuId(menuId int64) (*domainMenu.MenuApis, error)
+	// Replace 替换菜单及其按钮关联的接口，并同步拥有该菜单或按钮的角色的策略
+	Replace(menuApis *domainMenu.MenuApis) error
+	// SyncRoles 按角色当前的菜单和按钮补齐或回收关联接口的策略，不影响直接绑定的接口
+	SyncRoles(roleIds ...int64) error
+	// SyncMenuRoles 菜单或按钮被删除后同步拥有它们的角色
+	SyncMenuRoles(menuId int64) error
+	GetRoleGrants(roleId int64) ([]domainRole.ApiGrant, error)
+	// MarkExplicit 清除接口的关联标记，直接绑定的接口即使同时由菜单或按钮带来，菜单回收后也保留
+	MarkExplicit(roleId int64, apiKeys []string) error
+	// DeletePolicies 删除 casbin_rule 记录，并清理已没有对应策略的关联接口标记
+	DeletePolicies(ruleIds []int64) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewMenuApiRepository(db *gorm.DB, loggerInstance *logger.Logger) IMenuApiRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetByMenuId(menuId int64) (*domainMenu.MenuApis, error) {
+	var links []SysBaseMenuApi
+	err := r.DB.Where("sys_base_menu_id = ?", menuId).
+		Where("sys_api_id IN (?)", r.DB.Model(&apiRepo.SysApi{}).Select("id")).
+		Order("sys_base_menu_btn_id, sys_api_id").
+		Find(&links).Error
+	if err != nil {
+		r.Logger.Error("Error getting menu apis", zap.Error(err), zap.Int64("menuId", menuId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	result := &domainMenu.MenuApis{MenuID: menuId, Apis: []int64{}, Buttons: map[int64][]int64{}}
+	for _, link := range links {
+		if link.SysBaseMenuBtnID == 0 {
+			result.Apis = append(result.Apis, link.SysApiID)
+			continue
+		}
+		result.Buttons[link.SysBaseMenuBtnID] = append(result.Buttons[link.SysBaseMenuBtnID], link.SysApiID)
+	}
+	return result, nil
+}
+
+func (r *Repository) Replace(menuApis *domainMenu.MenuApis) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := validateMenuApis(tx, menuApis); err != nil {
+			return err
+		}
+		if err := tx.Where("sys_base_menu_id = ?", menuApis.MenuID).Delete(&SysBaseMenuApi{}).Error; err != nil {
+			return err
+		}
+		links := make([]SysBaseMenuApi, 0)
+		seen := make(map[SysBaseMenuApi]bool)
+		add := func(btnId int64, apiIds []int64) {
+			for _, apiId := range apiIds {
+				link := SysBaseMenuApi{SysBaseMenuID: menuApis.MenuID, SysBaseMenuBtnID: btnId, SysApiID: apiId}
+				if !seen[link] {
+					seen[link] = true
+					links = append(links, link)
+				}
+			}
+		}
+		add(0, menuApis.Apis)
+		for btnId, apiIds := range menuApis.Buttons {
+			add(btnId, apiIds)
+		}
+		if len(links) > 0 {
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		roleIds, err := menuRoleIds(tx, menuApis.MenuID)
+		if err != nil {
+			return err
+		}
+		return SyncRolePolicies(tx, roleIds...)
+	})
+	if err != nil {
+		var appErr *domainErrors.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+		r.Logger.Error("Error replacing menu apis", zap.Error(err), zap.Int64("menuId", menuApis.MenuID))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Menu apis replaced", zap.Int64("menuId", menuApis.MenuID))
+	return nil
+}
+
+func (r *Repository) SyncRoles(roleIds ...int64) error {
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		return SyncRolePolicies(tx, roleIds...)
+	}); err != nil {
+		r.Logger.Error("Error synchronizing implied api policies", zap.Error(err), zap.Int64s("roleIds", roleIds))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) SyncMenuRoles(menuId int64) error {
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		roleIds, err := menuRoleIds(tx, menuId)
+		if err != nil {
+			return err
+		}
+		return SyncRolePolicies(tx, roleIds...)
+	}); err != nil {
+		r.Logger.Error("Error synchronizing implied api policies", zap.Error(err), zap.Int64("menuId", menuId))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) GetRoleGrants(roleId int64) ([]domainRole.ApiGrant, error) {
+	current, err := rolePolicies(r.DB, roleId)
+	if err != nil {
+		r.Logger.Error("Error getting role policies", zap.Error(err), zap.Int64("roleId", roleId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	impliedOnly, err := impliedOnlyApis(r.DB, roleId)
+	if err != nil {
+		r.Logger.Error("Error getting implied role policies", zap.Error(err), zap.Int64("roleId", roleId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	implied, err := impliedApis(r.DB, roleId)
+	if err != nil {
+		r.Logger.Error("Error getting menu apis of role", zap.Error(err), zap.Int64("roleId", roleId))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	grants := make([]domainRole.ApiGrant, 0, len(current))
+	for _, key := range current {
+		path, method, _ := strings.Cut(key, "---")
+		grant := domainRole.ApiGrant{Path: path, Method: method, Explicit: !impliedOnly[key], ImpliedBy: []string{}}
+		if api, ok := implied[key]; ok {
+			grant.ImpliedBy = api.sources
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+func (r *Repository) MarkExplicit(roleId int64, apiKeys []string) error {
+	if len(apiKeys) == 0 {
+		return nil
+	}
+	apiIds := r.DB.Model(&apiRepo.SysApi{}).Select("id").Where("path || '---' || method IN ?", apiKeys)
+	if err := r.DB.Where("role_id = ? AND sys_api_id IN (?)", roleId, apiIds).Delete(&SysRoleImpliedApi{}).Error; err != nil {
+		r.Logger.Error("Error marking role apis explicit", zap.Error(err), zap.Int64("roleId", roleId))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (r *Repository) DeletePolicies(ruleIds []int64) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := casbinRepo.DeleteRules(tx, ruleIds); err != nil {
+			return err
+		}
+		return pruneRoleImplied(tx)
+	})
+	if err != nil {
+		r.Logger.Error("Error deleting casbin policies", zap.Error(err))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+// pruneRoleImplied 删除角色或接口已不存在、或角色已没有对应接口策略的关联接口标记
+func pruneRoleImplied(tx *gorm.DB) error {
+	policies := tx.Model(&casbinRepo.CasbinRule{}).
+		Select("1").
+		Joins("JOIN sys_apis ON sys_apis.path = casbin_rule.v1 AND sys_apis.method = casbin_rule.v2 AND sys_apis.deleted_at IS NULL").
+		Where("casbin_rule.ptype = ? AND casbin_rule.v0 = CAST(sys_role_implied_apis.role_id AS TEXT) AND sys_apis.id = sys_role_implied_apis.sys_api_id", "p")
+	return tx.Where("NOT EXISTS (?)", policies).Delete(&SysRoleImpliedApi{}).Error
+}
+
+// SyncRolePolicies 在调用方的事务中重算角色的接口策略：直接绑定的接口加上已授予菜单和按钮关联的接口
+func SyncRolePolicies(tx *gorm.DB, roleIds ...int64) error {
+	for _, roleId := range roleIds {
+		current, err := rolePolicies(tx, roleId)
+		if err != nil {
+			return err
+		}
+		impliedOnly, err := impliedOnlyApis(tx, roleId)
+		if err != nil {
+			return err
+		}
+		implied, err := impliedApis(tx, roleId)
+		if err != nil {
+			return err
+		}
+		impliedKeys := make(map[string]bool, len(implied))
+		for key := range implied {
+			impliedKeys[key] = true
+		}
+		rules, marked := planRolePolicies(current, impliedOnly, impliedKeys)
+		if !slices.Equal(current, rules) {
+			if err := casbinRepo.ReplaceRolePolicies(tx, roleId, rules); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("role_id = ?", roleId).Delete(&SysRoleImpliedApi{}).Error; err != nil {
+			return err
+		}
+		if len(marked) == 0 {
+			continue
+		}
+		rows := make([]SysRoleImpliedApi, 0, len(marked))
+		for _, key := range marked {
+			rows = append(rows, SysRoleImpliedApi{RoleID: roleId, SysApiID: implied[key].id})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRoleImplied 在调用方的事务中删除角色的关联接口标记
+func DeleteRoleImplied(tx *gorm.DB, roleId int64) error {
+	return tx.Where("role_id = ?", roleId).Delete(&SysRoleImpliedApi{}).Error
+}
+
+// planRolePolicies current 为角色现有的接口策略，impliedOnly 为其中仅因关联存在的部分，implied 为当前关联的接口。
+// 返回新的策略和其中仅因关联存在的部分，均为 path---method
+func planRolePolicies(current []string, impliedOnly, implied map[string]bool) ([]string, []string) {
+	rules := make(map[string]bool, len(current)+len(implied))
+	explicit := make(map[string]bool, len(current))
+	for _, key := range current {
+		if !impliedOnly[key] {
+			explicit[key] = true
+			rules[key] = true
+		}
+	}
+	marked := make([]string, 0)
+	for key := range implied {
+		rules[key] = true
+		if !explicit[key] {
+			marked = append(marked, key)
+		}
+	}
+	result := make([]string, 0, len(rules))
+	for key := range rules {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	sort.Strings(marked)
+	return result, marked
+}
+
+type impliedApi struct {
+	id      int64
+	sources []string
+}
+
+// impliedApis 角色已授予的菜单和按钮关联的接口，键为 path---method，sources 为菜单名或 菜单名/按钮名
+func impliedApis(tx *gorm.DB, roleId int64) (map[string]*impliedApi, error) {
+	var links []SysBaseMenuApi
+	err := tx.Where("(sys_base_menu_btn_id = 0 AND sys_base_menu_id IN (?)) OR (sys_base_menu_btn_id <> 0 AND sys_base_menu_btn_id IN (?))",
+		tx.Model(&roleMenuRepo.SysRoleMenu{}).Select("sys_base_menu_id").Where("sys_role_id = ?", roleId),
+		tx.Model(&roleBtnRepo.SysRoleBtn{}).Select("sys_base_menu_btn_id").Where("role_id = ?", roleId)).
+		Order("sys_base_menu_id, sys_base_menu_btn_id, sys_api_id").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	menuIds, btnIds, apiIds := make([]int64, 0), make([]int64, 0), make([]int64, 0)
+	for _, link := range links {
+		menuIds = append(menuIds, link.SysBaseMenuID)
+		btnIds = append(btnIds, link.SysBaseMenuBtnID)
+		apiIds = append(apiIds, link.SysApiID)
+	}
+	var menus []menuRepo.SysBaseMenu
+	if err := tx.Where("id IN ?", menuIds).Find(&menus).Error; err != nil {
+		return nil, err
+	}
+	var btns []menuBtnRepo.SysBaseMenuBtn
+	if err := tx.Where("id IN ?", btnIds).Find(&btns).Error; err != nil {
+		return nil, err
+	}
+	var apis []apiRepo.SysApi
+	if err := tx.Where("id IN ?", apiIds).Find(&apis).Error; err != nil {
+		return nil, err
+	}
+	menuNames := make(map[int64]string, len(menus))
+	for _, menu := range menus {
+		menuNames[int64(menu.ID)] = menu.Name
+	}
+	btnNames := make(map[int64]string, len(btns))
+	for _, btn := range btns {
+		btnNames[int64(btn.ID)] = btn.Name
+	}
+	apiKeys := make(map[int64]string, len(apis))
+	for _, api := range apis {
+		apiKeys[int64(api.ID)] = fmt.Sprintf("%v---%v", api.Path, api.Method)
+	}
+
+	result := make(map[string]*impliedApi)
+	for _, link := range links {
+		key, source := apiKeys[link.SysApiID], menuNames[link.SysBaseMenuID]
+		// 已删除的菜单、按钮和接口不再带来授权
+		if key == "" || source == "" {
+			continue
+		}
+		if link.SysBaseMenuBtnID != 0 {
+			if btnNames[link.SysBaseMenuBtnID] == "" {
+				continue
+			}
+			source += "/" + btnNames[link.SysBaseMenuBtnID]
+		}
+		if _, ok := result[key]; !ok {
+			result[key] = &impliedApi{id: link.SysApiID}
+		}
+		result[key].sources = append(result[key].sources, source)
+	}
+	return result, nil
+}
+
+// rolePolicies 角色自身的接口策略，不含继承，格式为 path---method
+func rolePolicies(tx *gorm.DB, roleId int64) ([]string, error) {
+	var rules []casbinRepo.CasbinRule
+	if err := tx.Where(&casbinRepo.CasbinRule{PType: "p", V0: strconv.FormatInt(roleId, 10)}).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		key := fmt.Sprintf("%v---%v", rule.V1, rule.V2)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func impliedOnlyApis(tx *gorm.DB, roleId int64) (map[string]bool, error) {
+	var apis []apiRepo.SysApi
+	err := tx.Where("id IN (?)", tx.Model(&SysRoleImpliedApi{}).Select("sys_api_id").Where("role_id = ?", roleId)).
+		Find(&apis).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(apis))
+	for _, api := range apis {
+		result[fmt.Sprintf("%v---%v", api.Path, api.Method)] = true
+	}
+	return result, nil
+}
+
+// menuRoleIds 拥有菜单或其按钮的角色
+func menuRoleIds(tx *gorm.DB, menuId int64) ([]int64, error) {
+	var roleIds []int64
+	err := tx.Raw("SELECT sys_role_id FROM ? WHERE sys_base_menu_id = ? UNION SELECT role_id FROM ? WHERE sys_menu_id = ?",
+		gorm.Expr(roleMenuRepo.SysRoleMenu{}.TableName()), menuId,
+		gorm.Expr(roleBtnRepo.SysRoleBtn{}.TableName()), menuId).
+		Scan(&roleIds).Error
+	return roleIds, err
+}
+
+func validateMenuApis(tx *gorm.DB, menuApis *domainMenu.MenuApis) error {
+	var menuCount int64
+	if err := tx.Model(&menuRepo.SysBaseMenu{}).Where("id = ?", menuApis.MenuID).Count(&menuCount).Error; err != nil {
+		return err
+	}
+	if menuCount == 0 {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	apiIds := append([]int64{}, menuApis.Apis...)
+	btnIds := make([]int64, 0, len(menuApis.Buttons))
+	for btnId, ids := range menuApis.Buttons {
+		btnIds = append(btnIds, btnId)
+		apiIds = append(apiIds, ids...)
+	}
+	if len(btnIds) > 0 {
+		var btnCount int64
+		if err := tx.Model(&menuBtnRepo.SysBaseMenuBtn{}).
+			Where("id IN ? AND sys_base_menu_id = ?", btnIds, menuApis.MenuID).
+			Count(&btnCount).Error; err != nil {
+			return err
+		}
+		if int(btnCount) != len(btnIds) {
+			return domainErrors.NewAppError(fmt.Errorf("buttons %v do not all belong to menu %d", btnIds, menuApis.MenuID), domainErrors.ValidationError)
+		}
+	}
+	if len(apiIds) > 0 {
+		var found []int64
+		if err := tx.Model(&apiRepo.SysApi{}).Where("id IN ?", apiIds).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		exists := make(map[int64]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, id := range apiIds {
+			if !exists[id] {
+				return domainErrors.NewAppError(fmt.Errorf("api %d not found", id), domainErrors.ValidationError)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package menu_api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanRolePolicies(t *testing.T) {
+	current := []string{"/v1/role---GET", "/v1/user---GET", "/v1/user---POST"}
+	impliedOnly := map[string]bool{"/v1/user---GET": true, "/v1/user---POST": true}
+
+	// 菜单仍关联 /v1/user---GET，新增关联 /v1/dept---GET，/v1/user---POST 的关联已被移除
+	implied := map[string]bool{"/v1/user---GET": true, "/v1/dept---GET": true}
+	rules, marked := planRolePolicies(current, impliedOnly, implied)
+	assert.Equal(t, []string{"/v1/dept---GET", "/v1/role---GET", "/v1/user---GET"}, rules)
+	assert.Equal(t, []string{"/v1/dept---GET", "/v1/user---GET"}, marked)
+
+	// 直接绑定的接口在菜单关联移除后保留，且不再标记为关联授权
+	rules, marked = planRolePolicies([]string{"/v1/role---GET"}, map[string]bool{}, map[string]bool{"/v1/role---GET": true})
+	assert.Equal(t, []string{"/v1/role---GET"}, rules)
+	assert.Empty(t, marked)
+	rules, marked = planRolePolicies(rules, map[string]bool{}, map[string]bool{})
+	assert.Equal(t, []string{"/v1/role---GET"}, rules)
+	assert.Empty(t, marked)
+}
@@ -0,0 +1,83 @@
+package menu_api
+
+import (
+	"strings"
+	"testing"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	apiRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
+	menuBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu_btn"
+	casbinRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	roleBtnRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	roleMenuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestRepository 使用内存 SQLite，菜单1关联接口1 GET /v1/user
+func newTestRepository(t *testing.T) (*Repository, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// 部分表名带 public. 前缀，附加同名库；内存库按连接隔离，只保留一个连接
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.Exec("ATTACH DATABASE ':memory:' AS public").Error)
+	// sys_base_menu_btns 与 sys_apis 的 deleted_at 索引同名，SQLite 中索引名全局唯一
+	require.NoError(t, db.AutoMigrate(&menuBtnRepo.SysBaseMenuBtn{}))
+	require.NoError(t, db.Exec("DROP INDEX idx_sys_apis_deleted_at").Error)
+	require.NoError(t, db.AutoMigrate(&menuRepo.SysBaseMenu{}, &apiRepo.SysApi{}, &roleMenuRepo.SysRoleMenu{},
+		&roleBtnRepo.SysRoleBtn{}, &casbinRepo.CasbinRule{}, &SysBaseMenuApi{}, &SysRoleImpliedApi{}))
+	// numeric 主键在 SQLite 中不会自增，改为整数主键重建
+	var ddl string
+	require.NoError(t, db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", "casbin_rule").Scan(&ddl).Error)
+	require.NoError(t, db.Exec("DROP TABLE casbin_rule").Error)
+	require.NoError(t, db.Exec(strings.Replace(ddl, "`id` numeric(20,0)", "`id` integer", 1)).Error)
+
+	require.NoError(t, db.Create(&menuRepo.SysBaseMenu{ID: 1, Name: "user"}).Error)
+	require.NoError(t, db.Create(&apiRepo.SysApi{ID: 1, Path: "/v1/user", Method: "GET"}).Error)
+	require.NoError(t, db.Create(&SysBaseMenuApi{SysBaseMenuID: 1, SysApiID: 1}).Error)
+	return &Repository{DB: db, Logger: &logger.Logger{Log: zap.NewNop()}}, db
+}
+
+func TestExplicitBindingSurvivesMenuRevoke(t *testing.T) {
+	r, db := newTestRepository(t)
+	require.NoError(t, db.Create(&roleMenuRepo.SysRoleMenu{SysBaseMenuID: 1, SysRoleID: 2}).Error)
+	require.NoError(t, r.SyncRoles(2))
+	grants, err := r.GetRoleGrants(2)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.False(t, grants[0].Explicit)
+
+	// 管理员直接绑定同一个接口，等同 BindApiRule
+	require.NoError(t, casbinRepo.ReplaceRolePolicies(db, 2, []string{"/v1/user---GET"}))
+	require.NoError(t, r.MarkExplicit(2, []string{"/v1/user---GET"}))
+	require.NoError(t, r.SyncRoles(2))
+	grants, err = r.GetRoleGrants(2)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.True(t, grants[0].Explicit)
+
+	// 回收菜单后直接绑定的接口仍然保留
+	require.NoError(t, db.Where("sys_role_id = ?", 2).Delete(&roleMenuRepo.SysRoleMenu{}).Error)
+	require.NoError(t, r.SyncRoles(2))
+	policies, err := rolePolicies(db, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1/user---GET"}, policies)
+}
+
+func TestMenuRevokeDropsImpliedOnlyApis(t *testing.T) {
+	r, db := newTestRepository(t)
+	require.NoError(t, db.Create(&roleMenuRepo.SysRoleMenu{SysBaseMenuID: 1, SysRoleID: 2}).Error)
+	require.NoError(t, r.SyncRoles(2))
+
+	require.NoError(t, db.Where("sys_role_id = ?", 2).Delete(&roleMenuRepo.SysRoleMenu{}).Error)
+	require.NoError(t, r.SyncRoles(2))
+	policies, err := rolePolicies(db, 2)
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
@@ -17,20 +17,21 @@ import (
 
 // SysOperationRecord represents the sys_operation_records table structure.
 type SysOperationRecord struct {
-	ID           int            `gorm:"column:id;primary_key;autoIncrement" json:"id,omitempty"`
-	CreatedAt    time.Time      `gorm:"column:created_at" json:"createdAt,omitempty"`
-	UpdatedAt    time.Time      `gorm:"column:updated_at" json:"updatedAt,omitempty"`
-	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;index" json:"deletedAt,omitempty"`
-	IP           string         `gorm:"column:ip" json:"ip,omitempty"`
-	Method       string         `gorm:"column:method" json:"method,omitempty"`
-	Path         string         `gorm:"column:path" json:"path,omitempty"`
-	Status       int64          `gorm:"column:status" json:"status,omitempty"`
-	Latency      int64          `gorm:"column:latency" json:"latency,omitempty"`
-	Agent        string         `gorm:"column:agent" json:"agent,omitempty"`
-	ErrorMessage string         `gorm:"column:error_message" json:"errorMessage,omitempty"`
-	Body         string         `gorm:"column:body" json:"body,omitempty"`
-	Resp         string         `gorm:"column:resp" json:"resp,omitempty"`
-	UserID       int64          `gorm:"column:user_id" json:"userId,omitempty"`
+	ID             int            `gorm:"column:id;primary_key;autoIncrement" json:"id,omitempty"`
+	CreatedAt      time.Time      `gorm:"column:created_at" json:"createdAt,omitempty"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at" json:"updatedAt,omitempty"`
+	DeletedAt      gorm.DeletedAt `gorm:"column:deleted_at;index" json:"deletedAt,omitempty"`
+	IP             string         `gorm:"column:ip" json:"ip,omitempty"`
+	Method         string         `gorm:"column:method" json:"method,omitempty"`
+	Path           string         `gorm:"column:path" json:"path,omitempty"`
+	Status         int64          `gorm:"column:status" json:"status,omitempty"`
+	Latency        int64          `gorm:"column:latency" json:"latency,omitempty"`
+	Agent          string         `gorm:"column:agent" json:"agent,omitempty"`
+	ErrorMessage   string         `gorm:"column:error_message" json:"errorMessage,omitempty"`
+	Body           string         `gorm:"column:body" json:"body,omitempty"`
+	Resp           string         `gorm:"column:resp" json:"resp,omitempty"`
+	UserID         int64          `gorm:"column:user_id" json:"userId,omitempty"`
+	ImpersonatorID int64          `gorm:"column:impersonator_id;index" json:"impersonatorId,omitempty"`
 }
 
 func (*SysOperationRecord) TableName() string {
@@ -38,10 +39,11 @@ func (*SysOperationRecord) TableName() string {
 }
 
 var ColumnsOperationMapping = map[string]string{
-	"id":     "id",
-	"status": "status",
-	"path":   "path",
-	"method": "method",
+	"id":              "id",
+	"status":          "status",
+	"path":            "path",
+	"method":          "method",
+	"impersonator_id": "impersonator_id",
 }
 
 // OperationRepositoryInterface defines the interface for api repository operations
@@ -165,7 +167,7 @@ func (r *Repository) Delete(ids []int) error {
 }
 
 func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainOperation.SysOperationRecord], error) {
-	query := r.DB.Model(&SysOperationRecord{})
+	query := r.DB.Model(&SysOperationRecord{}).Scopes(utils.ApplyDataScope(filters.DataScope, "user_id", ColumnsOperationMapping))
 
 	// Apply like filters
 	for field, values := range filters.LikeFilters {
@@ -278,15 +280,17 @@ func (r *Repository) SearchByProperty(property string, searchText string) (*[]st
 
 func (u *SysOperationRecord) toDomainMapper() *domainOperation.SysOperationRecord {
 	return &domainOperation.SysOperationRecord{
-		ID:           u.ID,
-		IP:           u.IP,
-		Path:         u.Path,
-		Method:       u.Method,
-		Status:       u.Status,
-		Latency:      u.Latency,
-		Agent:        u.Agent,
-		ErrorMessage: u.ErrorMessage,
-		Body:         u.Body,
+		ID:             u.ID,
+		IP:             u.IP,
+		Path:           u.Path,
+		Method:         u.Method,
+		Status:         u.Status,
+		Latency:        u.Latency,
+		Agent:          u.Agent,
+		ErrorMessage:   u.ErrorMessage,
+		Body:           u.Body,
+		UserID:         u.UserID,
+		ImpersonatorID: u.ImpersonatorID,
 
 		CreatedAt: domain.CustomTime{Time: u.CreatedAt},
 		UpdatedAt: domain.CustomTime{Time: u.UpdatedAt},
@@ -317,16 +321,17 @@ func (r *Repository) GetOneByMap(apiMap map[string]interface{}) (*domainOperatio
 
 func fromDomainMapper(u *domainOperation.SysOperationRecord) *SysOperationRecord {
 	return &SysOperationRecord{
-		CreatedAt:    u.CreatedAt.Time,
-		IP:           u.IP,
-		Method:       u.Method,
-		Path:         u.Path,
-		Status:       u.Status,
-		Latency:      u.Latency,
-		Agent:        u.Agent,
-		ErrorMessage: u.ErrorMessage,
-		Body:         u.Body,
-		Resp:         u.Resp,
-		UserID:       u.UserID,
+		CreatedAt:      u.CreatedAt.Time,
+		IP:             u.IP,
+		Method:         u.Method,
+		Path:           u.Path,
+		Status:         u.Status,
+		Latency:        u.Latency,
+		Agent:          u.Agent,
+		ErrorMessage:   u.ErrorMessage,
+		Body:           u.Body,
+		Resp:           u.Resp,
+		UserID:         u.UserID,
+		ImpersonatorID: u.ImpersonatorID,
 	}
 }
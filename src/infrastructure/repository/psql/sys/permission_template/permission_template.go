@@ -0,0 +1,144 @@
+package permission_template
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SysPermissionTemplate 命名权限模板，权限集合以 JSON 保存
+type SysPermissionTemplate struct {
+	ID          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	Name        string    `gorm:"column:name;type:varchar(100);uniqueIndex;not null"`
+	Description string    `gorm:"column:description;type:varchar(255)"`
+	Permissions string    `gorm:"column:permissions;type:text"`
+}
+
+// TableName 指定表名
+func (SysPermissionTemplate) TableName() string {
+	return "sys_permission_templates"
+}
+
+type IPermissionTemplateRepository interface {
+	GetAll() ([]domainRole.PermissionTemplate, error)
+	GetByID(id int64) (*domainRole.PermissionTemplate, error)
+	Create(template *domainRole.PermissionTemplate) (*domainRole.PermissionTemplate, error)
+	Update(id int64, template *domainRole.PermissionTemplate) (*domainRole.PermissionTemplate, error)
+	Delete(id int64) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewPermissionTemplateRepository(db *gorm.DB, loggerInstance *logger.Logger) IPermissionTemplateRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetAll() ([]domainRole.PermissionTemplate, error) {
+	var templates []SysPermissionTemplate
+	if err := r.DB.Order("id").Find(&templates).Error; err != nil {
+		r.Logger.Error("Error getting permission templates", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	result := make([]domainRole.PermissionTemplate, 0, len(templates))
+	for _, template := range templates {
+		result = append(result, *template.toDomainMapper())
+	}
+	return result, nil
+}
+
+func (r *Repository) GetByID(id int64) (*domainRole.PermissionTemplate, error) {
+	var template SysPermissionTemplate
+	if err := r.DB.Where("id = ?", id).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Logger.Warn("Permission template not found", zap.Int64("id", id))
+			return nil, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+		}
+		r.Logger.Error("Error getting permission template", zap.Error(err), zap.Int64("id", id))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return template.toDomainMapper(), nil
+}
+
+func (r *Repository) Create(template *domainRole.PermissionTemplate) (*domainRole.PermissionTemplate, error) {
+	model := fromDomainMapper(template)
+	if err := r.DB.Create(model).Error; err != nil {
+		r.Logger.Error("Error creating permission template", zap.Error(err), zap.String("name", template.Name))
+		return nil, saveError(err)
+	}
+	r.Logger.Info("Successfully created permission template", zap.String("name", template.Name), zap.Int64("id", model.ID))
+	return model.toDomainMapper(), nil
+}
+
+func (r *Repository) Update(id int64, template *domainRole.PermissionTemplate) (*domainRole.PermissionTemplate, error) {
+	model := fromDomainMapper(template)
+	result := r.DB.Model(&SysPermissionTemplate{ID: id}).Updates(map[string]interface{}{
+		"name":        model.Name,
+		"description": model.Description,
+		"permissions": model.Permissions,
+	})
+	if result.Error != nil {
+		r.Logger.Error("Error updating permission template", zap.Error(result.Error), zap.Int64("id", id))
+		return nil, saveError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	return r.GetByID(id)
+}
+
+func (r *Repository) Delete(id int64) error {
+	result := r.DB.Delete(&SysPermissionTemplate{}, id)
+	if result.Error != nil {
+		r.Logger.Error("Error deleting permission template", zap.Error(result.Error), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	if result.RowsAffected == 0 {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	return nil
+}
+
+// saveError 模板名称唯一，重名时返回 ResourceAlreadyExists
+func saveError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+		return domainErrors.NewAppErrorWithType(domainErrors.ResourceAlreadyExists)
+	}
+	return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+}
+
+func (t *SysPermissionTemplate) toDomainMapper() *domainRole.PermissionTemplate {
+	template := &domainRole.PermissionTemplate{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		CreatedAt:   domain.CustomTime{Time: t.CreatedAt},
+		UpdatedAt:   domain.CustomTime{Time: t.UpdatedAt},
+	}
+	if t.Permissions != "" {
+		_ = json.Unmarshal([]byte(t.Permissions), &template.Permissions)
+	}
+	return template
+}
+
+func fromDomainMapper(t *domainRole.PermissionTemplate) *SysPermissionTemplate {
+	raw, _ := json.Marshal(t.Permissions)
+	return &SysPermissionTemplate{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Permissions: string(raw),
+	}
+}
@@ -6,9 +6,16 @@ import (
 	"time"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
+	"github.com/gbrayhan/microservices-go/src/domain/constants"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/casbin_rule"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/menu_api"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_field"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/utils"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -26,6 +33,11 @@ type SysRole struct {
 	Order         int64          `gorm:"column:order;type:numeric(10,0)"`
 	Label         string         `gorm:"column:label"`
 	Description   string         `gorm:"column:description"`
+	// IsSuper 超级管理员角色跳过接口权限校验并拥有全部菜单
+	IsSuper bool `gorm:"column:is_super;not null;default:false"`
+	// DataScope 行级数据权限，DataScopeFilter 为 custom 范围的 JSON 过滤条件
+	DataScope       string `gorm:"column:data_scope;type:varchar(20);not null;default:all"`
+	DataScopeFilter string `gorm:"column:data_scope_filter;type:text"`
 }
 
 var ColumnsRoleMapping = map[string]string{
@@ -38,6 +50,8 @@ var ColumnsRoleMapping = map[string]string{
 	"email":         "email",
 	"status":        "status",
 	"label":         "label",
+	"isSuper":       "is_super",
+	"dataScope":     "data_scope",
 	"createdAt":     "created_at",
 	"updatedAt":     "updated_at",
 }
@@ -50,12 +64,21 @@ type ISysRolesRepository interface {
 	GetAll(status int) (*[]domainRole.Role, error)
 	Create(roleDomain *domainRole.Role) (*domainRole.Role, error)
 	GetByID(id int) (*domainRole.Role, error)
+	GetByIDs(ids []int64) (*[]domainRole.Role, error)
 	GetByName(name string) (*domainRole.Role, error)
 	Update(id int, roleMap map[string]interface{}) (*domainRole.Role, error)
 	Delete(id int) error
 	SearchPaginated(filters domain.DataFilters) (*domainRole.SearchResultRole, error)
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(roleMap map[string]interface{}) (*domainRole.Role, error)
+	IsSuperRole(roleIds ...int64) (bool, error)
+	ContainsSuperRole(roleIds ...int64) (bool, error)
+	IsSuperUser(userId int64) (bool, error)
+	CountSuperUsers(excludeRoleId int64, excludeUserId int64) (int64, error)
+	// CreateWithPermissions 在同一事务中创建角色并写入菜单、按钮、接口和字段权限
+	CreateWithPermissions(roleDomain *domainRole.Role, permissions *domainRole.PermissionSet) (*domainRole.Role, error)
+	// ReplacePermissions 在同一事务中替换角色的菜单、按钮、接口和字段权限
+	ReplacePermissions(roleId int64, permissions *domainRole.PermissionSet) error
 }
 
 type Repository struct {
@@ -123,6 +146,18 @@ func (r *Repository) GetByID(id int) (*domainRole.Role, error) {
 	return role.toDomainMapper(), nil
 }
 
+func (r *Repository) GetByIDs(ids []int64) (*[]domainRole.Role, error) {
+	var roles []SysRole
+	if len(ids) == 0 {
+		return ArrayToDomainMapper(&roles), nil
+	}
+	if err := r.DB.Where("id IN ?", ids).Find(&roles).Error; err != nil {
+		r.Logger.Error("Error getting roles by IDs", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return ArrayToDomainMapper(&roles), nil
+}
+
 func (r *Repository) GetByName(name string) (*domainRole.Role, error) {
 	var role SysRole
 	err := r.DB.Where("name = ?", name).First(&role).Error
@@ -169,13 +204,28 @@ func (r *Repository) Update(id int, roleMap map[string]interface{}) (*domainRole
 	return roleObj.toDomainMapper(), nil
 }
 
+// Delete 在同一事务中删除角色的接口策略和继承规则
 func (r *Repository) Delete(id int) error {
-	tx := r.DB.Delete(&SysRole{}, id)
-	if tx.Error != nil {
-		r.Logger.Error("Error deleting role", zap.Error(tx.Error), zap.Int("id", id))
+	var rowsAffected int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&SysRole{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		if err := casbin_rule.DeleteRolePolicies(tx, int64(id)); err != nil {
+			return err
+		}
+		return menu_api.DeleteRoleImplied(tx, int64(id))
+	})
+	if err != nil {
+		r.Logger.Error("Error deleting role", zap.Error(err), zap.Int("id", id))
 		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
 	}
-	if tx.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		r.Logger.Warn("Role not found for deletion", zap.Int("id", id))
 		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
 	}
@@ -183,6 +233,48 @@ func (r *Repository) Delete(id int) error {
 	return nil
 }
 
+func (r *Repository) CreateWithPermissions(roleDomain *domainRole.Role, permissions *domainRole.PermissionSet) (*domainRole.Role, error) {
+	roleRepository := fromDomainMapper(roleDomain)
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(roleRepository).Error; err != nil {
+			return err
+		}
+		return replacePermissions(tx, roleRepository.ID, permissions)
+	})
+	if err != nil {
+		r.Logger.Error("Error creating role with permissions", zap.Error(err), zap.String("Name", roleDomain.Name))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully created role with permissions", zap.String("Name", roleDomain.Name), zap.Int64("id", roleRepository.ID))
+	return roleRepository.toDomainMapper(), nil
+}
+
+func (r *Repository) ReplacePermissions(roleId int64, permissions *domainRole.PermissionSet) error {
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		return replacePermissions(tx, roleId, permissions)
+	}); err != nil {
+		r.Logger.Error("Error replacing role permissions", zap.Error(err), zap.Int64("id", roleId))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func replacePermissions(tx *gorm.DB, roleId int64, permissions *domainRole.PermissionSet) error {
+	if err := role_menu.ReplaceRoleMenus(tx, roleId, permissions.Menus); err != nil {
+		return err
+	}
+	if err := role_btn.ReplaceRoleBtns(tx, roleId, permissions.Btns); err != nil {
+		return err
+	}
+	if err := casbin_rule.ReplaceRolePolicies(tx, roleId, permissions.Apis); err != nil {
+		return err
+	}
+	if err := menu_api.SyncRolePolicies(tx, roleId); err != nil {
+		return err
+	}
+	return role_field.ReplaceRoleFields(tx, roleId, permissions.Fields)
+}
+
 func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domainRole.SearchResultRole, error) {
 	query := r.DB.Model(&SysRole{})
 
@@ -297,31 +389,58 @@ func (r *Repository) SearchByProperty(property string, searchText string) (*[]st
 
 func (u *SysRole) toDomainMapper() *domainRole.Role {
 	return &domainRole.Role{
-		ID:            u.ID,
-		Name:          u.Name,
-		ParentID:      u.ParentID,
-		Order:         u.Order,
-		Label:         u.Label,
-		Description:   u.Description,
-		Status:        u.Status,
-		DefaultRouter: u.DefaultRouter,
-		CreatedAt:     domain.CustomTime{Time: u.CreatedAt},
-		UpdatedAt:     domain.CustomTime{Time: u.UpdatedAt},
+		ID:              u.ID,
+		Name:            u.Name,
+		ParentID:        u.ParentID,
+		Order:           u.Order,
+		Label:           u.Label,
+		Description:     u.Description,
+		Status:          u.Status,
+		DefaultRouter:   u.DefaultRouter,
+		IsSuper:         u.IsSuper,
+		DataScope:       u.DataScope,
+		DataScopeFilter: parseDataScopeFilter(u.DataScopeFilter),
+		CreatedAt:       domain.CustomTime{Time: u.CreatedAt},
+		UpdatedAt:       domain.CustomTime{Time: u.UpdatedAt},
 	}
 }
 
 func fromDomainMapper(u *domainRole.Role) *SysRole {
 	return &SysRole{
-		ID:          u.ID,
-		Name:        u.Name,
-		ParentID:    u.ParentID,
-		Order:       u.Order,
-		Label:       u.Label,
-		Description: u.Description,
-		Status:      u.Status,
+		ID:              u.ID,
+		Name:            u.Name,
+		ParentID:        u.ParentID,
+		Order:           u.Order,
+		Label:           u.Label,
+		Description:     u.Description,
+		Status:          u.Status,
+		DefaultRouter:   u.DefaultRouter,
+		IsSuper:         u.IsSuper,
+		DataScope:       u.DataScope,
+		DataScopeFilter: FormatDataScopeFilter(u.DataScopeFilter),
 	}
 }
 
+// FormatDataScopeFilter 自定义数据权限条件以 JSON 保存
+func FormatDataScopeFilter(filter map[string][]string) string {
+	if len(filter) == 0 {
+		return ""
+	}
+	raw, _ := json.Marshal(filter)
+	return string(raw)
+}
+
+func parseDataScopeFilter(raw string) map[string][]string {
+	if raw == "" {
+		return nil
+	}
+	var filter map[string][]string
+	if err := json.Unmarshal([]byte(raw), &filter); err != nil {
+		return nil
+	}
+	return filter
+}
+
 func ArrayToDomainMapper(roles *[]SysRole) *[]domainRole.Role {
 	rolesDomain := make([]domainRole.Role, len(*roles))
 	for i, role := range *roles {
@@ -343,3 +462,56 @@ func (r *Repository) GetOneByMap(roleMap map[string]interface{}) (*domainRole.Ro
 	}
 	return roleRepository.toDomainMapper(), nil
 }
+
+// IsSuperRole 任一角色为启用的超级管理员角色时返回 true，停用的超级管理员角色不再享有任何特权
+func (r *Repository) IsSuperRole(roleIds ...int64) (bool, error) {
+	return r.countSuperRoles(r.DB.Where("status = ?", constants.StatusEnabled), roleIds)
+}
+
+// ContainsSuperRole 不论状态，任一角色为超级管理员角色时返回 true。
+// 用于绑定角色等检查，停用的超级管理员角色重新启用后仍是超级管理员
+func (r *Repository) ContainsSuperRole(roleIds ...int64) (bool, error) {
+	return r.countSuperRoles(r.DB, roleIds)
+}
+
+func (r *Repository) countSuperRoles(query *gorm.DB, roleIds []int64) (bool, error) {
+	if len(roleIds) == 0 {
+		return false, nil
+	}
+	var count int64
+	if err := query.Model(&SysRole{}).Where("id IN ? AND is_super = ?", roleIds, true).Count(&count).Error; err != nil {
+		r.Logger.Error("Error checking super role", zap.Error(err))
+		return false, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return count > 0, nil
+}
+
+// IsSuperUser 用户是否拥有超级管理员角色
+func (r *Repository) IsSuperUser(userId int64) (bool, error) {
+	var count int64
+	err := r.DB.Model(&SysRole{}).
+		Joins("JOIN sys_user_roles ON sys_user_roles.sys_role_id = sys_roles.id").
+		Where("sys_user_roles.sys_user_id = ? AND sys_roles.is_super = ?", userId, true).
+		Count(&count).Error
+	if err != nil {
+		r.Logger.Error("Error checking super user", zap.Error(err), zap.Int64("userId", userId))
+		return false, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return count > 0, nil
+}
+
+// CountSuperUsers 统计通过启用的超级管理员角色拥有超级权限的启用用户数，可排除一个角色或一个用户用于删除或禁用前的检查
+func (r *Repository) CountSuperUsers(excludeRoleId int64, excludeUserId int64) (int64, error) {
+	var count int64
+	err := r.DB.Model(&SysRole{}).
+		Joins("JOIN sys_user_roles ON sys_user_roles.sys_role_id = sys_roles.id").
+		Joins("JOIN sys_users ON sys_users.id = sys_user_roles.sys_user_id AND sys_users.deleted_at IS NULL AND sys_users.status = ?", domainUser.UserStatusEnabled).
+		Where("sys_roles.is_super = ? AND sys_roles.status = ? AND sys_roles.id <> ? AND sys_users.id <> ?", true, constants.StatusEnabled, excludeRoleId, excludeUserId).
+		Distinct("sys_users.id").
+		Count(&count).Error
+	if err != nil {
+		r.Logger.Error("Error counting super users", zap.Error(err))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return count, nil
+}
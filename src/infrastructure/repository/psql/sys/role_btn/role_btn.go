@@ -89,3 +89,20 @@ func (r *Repository) Insert(roleId int64, UpdateMap map[string]any) error {
 	}
 	return nil
 }
+
+// ReplaceRoleBtns 在调用方的事务中替换角色在所有菜单上的按钮，btns 为菜单ID -> 按钮ID
+func ReplaceRoleBtns(tx *gorm.DB, roleId int64, btns map[int64][]int64) error {
+	if err := tx.Where("role_id = ?", roleId).Delete(&SysRoleBtn{}).Error; err != nil {
+		return err
+	}
+	roleBtns := make([]SysRoleBtn, 0)
+	for menuId, btnIds := range btns {
+		for _, btnId := range btnIds {
+			roleBtns = append(roleBtns, SysRoleBtn{RoleID: roleId, SysMenuID: menuId, SysBaseMenuBtnID: btnId})
+		}
+	}
+	if len(roleBtns) == 0 {
+		return nil
+	}
+	return tx.Create(&roleBtns).Error
+}
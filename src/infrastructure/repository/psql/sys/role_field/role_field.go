@@ -0,0 +1,85 @@
+package role_field
+
+import (
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SysRoleField 角色隐藏的响应字段
+type SysRoleField struct {
+	RoleID   int64  `gorm:"column:role_id;type:int8;primaryKey"`
+	Resource string `gorm:"column:resource;type:varchar(50);primaryKey"`
+	Field    string `gorm:"column:field;type:varchar(50);primaryKey"`
+}
+
+// TableName 指定表名
+func (SysRoleField) TableName() string {
+	return "sys_role_fields"
+}
+
+type ISysRoleFieldRepository interface {
+	GetByRoleIds(roleIds []int64) ([]*SysRoleField, error)
+	// Replace 替换角色在某个资源上隐藏的全部字段
+	Replace(roleId int64, resource string, fields []string) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewRoleFieldRepository(db *gorm.DB, loggerInstance *logger.Logger) ISysRoleFieldRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetByRoleIds(roleIds []int64) ([]*SysRoleField, error) {
+	var roleFields []*SysRoleField
+	if len(roleIds) == 0 {
+		return roleFields, nil
+	}
+	if err := r.DB.Where("role_id IN ?", roleIds).Find(&roleFields).Error; err != nil {
+		r.Logger.Error("Error getting role fields", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return roleFields, nil
+}
+
+func (r *Repository) Replace(roleId int64, resource string, fields []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ? AND resource = ?", roleId, resource).Delete(&SysRoleField{}).Error; err != nil {
+			r.Logger.Error("Error clearing role fields", zap.Error(err), zap.Int64("roleId", roleId))
+			return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		roleFields := make([]SysRoleField, 0, len(fields))
+		for _, field := range fields {
+			roleFields = append(roleFields, SysRoleField{RoleID: roleId, Resource: resource, Field: field})
+		}
+		if err := tx.Create(&roleFields).Error; err != nil {
+			r.Logger.Error("Error saving role fields", zap.Error(err), zap.Int64("roleId", roleId))
+			return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		return nil
+	})
+}
+
+// ReplaceRoleFields 在调用方的事务中替换角色在所有资源上隐藏的字段
+func ReplaceRoleFields(tx *gorm.DB, roleId int64, fields map[string][]string) error {
+	if err := tx.Where("role_id = ?", roleId).Delete(&SysRoleField{}).Error; err != nil {
+		return err
+	}
+	roleFields := make([]SysRoleField, 0)
+	for resource, names := range fields {
+		for _, field := range names {
+			roleFields = append(roleFields, SysRoleField{RoleID: roleId, Resource: resource, Field: field})
+		}
+	}
+	if len(roleFields) == 0 {
+		return nil
+	}
+	return tx.Create(&roleFields).Error
+}
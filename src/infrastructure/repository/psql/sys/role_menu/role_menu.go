@@ -88,6 +88,21 @@ func (r *Repository) Insert(roleId int, UpdateMap map[string]any) error {
 	return nil
 }
 
+// ReplaceRoleMenus 在调用方的事务中替换角色的全部菜单
+func ReplaceRoleMenus(tx *gorm.DB, roleId int64, menuIds []int64) error {
+	if err := tx.Where("sys_role_id = ?", roleId).Delete(&SysRoleMenu{}).Error; err != nil {
+		return err
+	}
+	if len(menuIds) == 0 {
+		return nil
+	}
+	roleMenus := make([]SysRoleMenu, 0, len(menuIds))
+	for _, menuId := range menuIds {
+		roleMenus = append(roleMenus, SysRoleMenu{SysBaseMenuID: uint64(menuId), SysRoleID: uint64(roleId)})
+	}
+	return tx.Create(&roleMenus).Error
+}
+
 func NewSysRoleMenuRepository(db *gorm.DB, loggerInstance *logger.Logger) ISysRoleMenuRepository {
 	return &Repository{DB: db, Logger: loggerInstance}
 }
@@ -0,0 +1,119 @@
+package translation
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SysTranslation 菜单标题、字典标签和错误消息的多语言翻译
+type SysTranslation struct {
+	ID        int64     `gorm:"column:id;primary_key;autoIncrement" json:"id,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	Locale    string    `gorm:"column:locale;type:varchar(20);not null;uniqueIndex:idx_sys_translations_key,priority:1"`
+	Scope     string    `gorm:"column:scope;type:varchar(30);not null;uniqueIndex:idx_sys_translations_key,priority:2"`
+	Key       string    `gorm:"column:key;type:varchar(191);not null;uniqueIndex:idx_sys_translations_key,priority:3"`
+	Value     string    `gorm:"column:value;type:text;not null"`
+}
+
+func (SysTranslation) TableName() string {
+	return "sys_translations"
+}
+
+type ITranslationRepository interface {
+	// GetAll locale、scope 为空时不过滤
+	GetAll(locale string, scope string) ([]domainTranslation.Translation, error)
+	// Save 按 locale、scope、key 新增或覆盖翻译
+	Save(translations []domainTranslation.Translation) ([]domainTranslation.Translation, error)
+	Delete(id int64) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewTranslationRepository(db *gorm.DB, loggerInstance *logger.Logger) ITranslationRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetAll(locale string, scope string) ([]domainTranslation.Translation, error) {
+	var translations []SysTranslation
+	tx := r.DB.Order("locale asc, scope asc, key asc")
+	if locale != "" {
+		tx = tx.Where("locale = ?", locale)
+	}
+	if scope != "" {
+		tx = tx.Where("scope = ?", scope)
+	}
+	if err := tx.Find(&translations).Error; err != nil {
+		r.Logger.Error("Error getting translations", zap.Error(err), zap.String("locale", locale), zap.String("scope", scope))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(translations), nil
+}
+
+func (r *Repository) Save(translations []domainTranslation.Translation) ([]domainTranslation.Translation, error) {
+	if len(translations) == 0 {
+		return []domainTranslation.Translation{}, nil
+	}
+	models := make([]SysTranslation, 0, len(translations))
+	for _, translation := range translations {
+		models = append(models, SysTranslation{
+			Locale: translation.Locale,
+			Scope:  translation.Scope,
+			Key:    translation.Key,
+			Value:  translation.Value,
+		})
+	}
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "locale"}, {Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models).Error
+	if err != nil {
+		r.Logger.Error("Error saving translations", zap.Error(err), zap.Int("count", len(models)))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully saved translations", zap.Int("count", len(models)))
+	return arrayToDomainMapper(models), nil
+}
+
+func (r *Repository) Delete(id int64) error {
+	result := r.DB.Delete(&SysTranslation{}, id)
+	if result.Error != nil {
+		r.Logger.Error("Error deleting translation", zap.Error(result.Error), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	if result.RowsAffected == 0 {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	r.Logger.Info("Successfully deleted translation", zap.Int64("id", id))
+	return nil
+}
+
+func (t *SysTranslation) toDomainMapper() domainTranslation.Translation {
+	return domainTranslation.Translation{
+		ID:        t.ID,
+		Locale:    t.Locale,
+		Scope:     t.Scope,
+		Key:       t.Key,
+		Value:     t.Value,
+		CreatedAt: domain.CustomTime{Time: t.CreatedAt},
+		UpdatedAt: domain.CustomTime{Time: t.UpdatedAt},
+	}
+}
+
+func arrayToDomainMapper(models []SysTranslation) []domainTranslation.Translation {
+	translations := make([]domainTranslation.Translation, 0, len(models))
+	for i := range models {
+		translations = append(translations, models[i].toDomainMapper())
+	}
+	return translations
+}
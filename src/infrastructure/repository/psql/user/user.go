@@ -17,20 +17,23 @@ import (
 )
 
 type User struct {
-	ID            int64              `gorm:"primaryKey;column:id;type:numeric(20,0)"`
-	UUID          string             `gorm:"column:uuid;type:text"`
-	UserName      string             `gorm:"column:user_name;type:text;uniqueIndex:uni_sys_users_user_name"`
-	NickName      string             `gorm:"column:nick_name;type:text"`
-	Email         string             `gorm:"column:email;type:text;uniqueIndex:uni_sys_users_email"`
-	HashPassword  string             `gorm:"column:hash_password;type:text"`
-	HeaderImg     string             `gorm:"column:header_img;type:text"`
-	Phone         string             `gorm:"column:phone;type:text"`
-	Status        int16              `gorm:"column:status"`
-	OriginSetting string             `gorm:"column:origin_setting;type:text"`
-	CreatedAt     time.Time          `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt     time.Time          `gorm:"column:updated_at;autoUpdateTime:milli"`
-	DeletedAt     gorm.DeletedAt     `gorm:"column:deleted_at;index"`
-	Roles         []roleRepo.SysRole `gorm:"many2many:public.sys_user_roles;joinForeignKey:SysUserID;joinOtherKey:SysRoleID;"`
+	ID            int64  `gorm:"primaryKey;column:id;type:numeric(20,0)"`
+	UUID          string `gorm:"column:uuid;type:text"`
+	UserName      string `gorm:"column:user_name;type:text;uniqueIndex:uni_sys_users_user_name"`
+	NickName      string `gorm:"column:nick_name;type:text"`
+	Email         string `gorm:"column:email;type:text;uniqueIndex:uni_sys_users_email"`
+	HashPassword  string `gorm:"column:hash_password;type:text"`
+	HeaderImg     string `gorm:"column:header_img;type:text"`
+	Phone         string `gorm:"column:phone;type:text"`
+	Status        int16  `gorm:"column:status"`
+	OriginSetting string `gorm:"column:origin_setting;type:text"`
+	// PasswordChangedAt 为空时按 CreatedAt 计算密码有效期
+	PasswordChangedAt  *time.Time         `gorm:"column:password_changed_at"`
+	MustChangePassword bool               `gorm:"column:must_change_password;default:false"`
+	CreatedAt          time.Time          `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt          time.Time          `gorm:"column:updated_at;autoUpdateTime:milli"`
+	DeletedAt          gorm.DeletedAt     `gorm:"column:deleted_at;index"`
+	Roles              []roleRepo.SysRole `gorm:"many2many:public.sys_user_roles;joinForeignKey:SysUserID;joinOtherKey:SysRoleID;"`
 }
 
 func (User) TableName() string {
@@ -38,33 +41,40 @@ func (User) TableName() string {
 }
 
 var ColumnsUserMapping = map[string]string{
-	"id":            "id",
-	"uuid":          "uuid",
-	"userName":      "user_name",
-	"nickName":      "nick_name",
-	"headerImg":     "header_img",
-	"roleId":        "role_id",
-	"phone":         "phone",
-	"originSetting": "origin_setting",
-	"email":         "email",
-	"status":        "status",
-	"hashPassword":  "hash_password",
-	"createdAt":     "created_at",
-	"updatedAt":     "updated_at",
+	"id":                 "id",
+	"uuid":               "uuid",
+	"userName":           "user_name",
+	"nickName":           "nick_name",
+	"headerImg":          "header_img",
+	"roleId":             "role_id",
+	"phone":              "phone",
+	"originSetting":      "origin_setting",
+	"email":              "email",
+	"status":             "status",
+	"hashPassword":       "hash_password",
+	"mustChangePassword": "must_change_password",
+	"createdAt":          "created_at",
+	"updatedAt":          "updated_at",
 }
 
+// DeptFilterField 按所属部门过滤用户的查询字段
+const DeptFilterField = "deptId"
+
 // UserRepositoryInterface defines the interface for user repository operations
 type UserRepositoryInterface interface {
-	GetAll() (*[]domainUser.User, error)
+	GetAll(scope *domain.DataScope) (*[]domainUser.User, error)
 	Create(userDomain *domainUser.User) (*domainUser.User, error)
 	GetByID(id int) (*domainUser.User, error)
+	// GetByIDInScope 用户不在数据权限范围内时与不存在一样返回 NotFound
+	GetByIDInScope(id int, scope *domain.DataScope) (*domainUser.User, error)
 	GetByEmail(email string) (*domainUser.User, error)
 	GetByUsername(username string) (*domainUser.User, error)
 	Update(id int64, userMap map[string]interface{}) (*domainUser.User, error)
 	Delete(id int) error
 	SearchPaginated(filters domain.DataFilters) (*domainUser.SearchResultUser, error)
-	SearchByProperty(property string, searchText string) (*[]string, error)
+	SearchByProperty(property string, searchText string, scope *domain.DataScope) (*[]string, error)
 	GetOneByMap(userMap map[string]interface{}) (*domainUser.User, error)
+	DeleteUnverifiedBefore(before time.Time) (int64, error)
 }
 
 type Repository struct {
@@ -76,9 +86,9 @@ func NewUserRepository(db *gorm.DB, loggerInstance *logger.Logger) UserRepositor
 	return &Repository{DB: db, Logger: loggerInstance}
 }
 
-func (r *Repository) GetAll() (*[]domainUser.User, error) {
+func (r *Repository) GetAll(scope *domain.DataScope) (*[]domainUser.User, error) {
 	var users []User
-	if err := r.DB.Find(&users).Error; err != nil {
+	if err := r.DB.Scopes(utils.ApplyDataScope(scope, "id", ColumnsUserMapping)).Find(&users).Error; err != nil {
 		r.Logger.Error("Error getting all users", zap.Error(err))
 		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
 	}
@@ -112,8 +122,16 @@ func (r *Repository) Create(userDomain *domainUser.User) (*domainUser.User, erro
 }
 
 func (r *Repository) GetByID(id int) (*domainUser.User, error) {
+	return r.getByID(r.DB, id)
+}
+
+func (r *Repository) GetByIDInScope(id int, scope *domain.DataScope) (*domainUser.User, error) {
+	return r.getByID(r.DB.Scopes(utils.ApplyDataScope(scope, "id", ColumnsUserMapping)), id)
+}
+
+func (r *Repository) getByID(query *gorm.DB, id int) (*domainUser.User, error) {
 	var user User
-	err := r.DB.Where("id = ?", id).Preload("Roles", func(db *gorm.DB) *gorm.DB {
+	err := query.Where("id = ?", id).Preload("Roles", func(db *gorm.DB) *gorm.DB {
 		return db.Where("status = ?", constants.StatusEnabled).Order("id asc")
 	}).First(&user).Error
 	if err != nil {
@@ -172,7 +190,8 @@ func (r *Repository) Update(id int64, userMap map[string]interface{}) (*domainUs
 	userObj.ID = id
 	delete(userMap, "updated_at")
 	err := r.DB.Model(&userObj).
-		Select("user_name", "email", "nick_name", "status", "phone", "header_img", "hash_password").
+		Select("user_name", "email", "nick_name", "status", "phone", "header_img", "hash_password",
+			"password_changed_at", "must_change_password").
 		Updates(userMap).Error
 	if err != nil {
 		r.Logger.Error("Error updating user", zap.Error(err), zap.Int64("id", id))
@@ -211,8 +230,35 @@ func (r *Repository) Delete(id int) error {
 	return nil
 }
 
+// DeleteUnverifiedBefore 物理删除过期未验证的注册用户，释放用户名和邮箱
+func (r *Repository) DeleteUnverifiedBefore(before time.Time) (int64, error) {
+	var deleted int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&User{}).Where("status = ? AND created_at < ?", domainUser.UserStatusPending, before).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Exec("DELETE FROM sys_user_roles WHERE sys_user_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&User{})
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		r.Logger.Error("Error deleting unverified users", zap.Error(err))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Deleted unverified users", zap.Int64("count", deleted))
+	return deleted, nil
+}
+
 func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domainUser.SearchResultUser, error) {
-	query := r.DB.Model(&User{})
+	query := r.DB.Model(&User{}).Scopes(utils.ApplyDataScope(filters.DataScope, "id", ColumnsUserMapping))
 
 	// Apply like filters
 	for field, values := range filters.LikeFilters {
@@ -228,6 +274,11 @@ func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domainUser.Se
 		}
 	}
 
+	// 按所属部门过滤，deptId 不在字段映射中，下面的循环不会处理
+	if deptIds := filters.Matches[DeptFilterField]; len(deptIds) > 0 {
+		query = query.Where("id IN (SELECT sys_user_id FROM sys_user_depts WHERE sys_dept_id IN ?)", deptIds)
+	}
+
 	// Apply exact matches
 	for field, values := range filters.Matches {
 		if len(values) > 0 {
@@ -299,7 +350,7 @@ func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domainUser.Se
 	return result, nil
 }
 
-func (r *Repository) SearchByProperty(property string, searchText string) (*[]string, error) {
+func (r *Repository) SearchByProperty(property string, searchText string, scope *domain.DataScope) (*[]string, error) {
 	column := ColumnsUserMapping[property]
 	if column == "" {
 		r.Logger.Warn("Invalid property for search", zap.String("property", property))
@@ -308,6 +359,7 @@ func (r *Repository) SearchByProperty(property string, searchText string) (*[]st
 
 	var coincidences []string
 	if err := r.DB.Model(&User{}).
+		Scopes(utils.ApplyDataScope(scope, "id", ColumnsUserMapping)).
 		Distinct(column).
 		Where(column+" ILIKE ?", "%"+searchText+"%").
 		Limit(20).
@@ -325,36 +377,40 @@ func (r *Repository) SearchByProperty(property string, searchText string) (*[]st
 
 func (u *User) toDomainMapper() *domainUser.User {
 	return &domainUser.User{
-		ID:            u.ID,
-		UUID:          u.UUID,
-		UserName:      u.UserName,
-		Email:         u.Email,
-		NickName:      u.NickName,
-		HeaderImg:     u.HeaderImg,
-		Status:        u.Status,
-		Phone:         u.Phone,
-		HashPassword:  u.HashPassword,
-		OriginSetting: u.OriginSetting,
-		CreatedAt:     u.CreatedAt,
-		UpdatedAt:     u.UpdatedAt,
-		Roles:         *roleRepo.ArrayToDomainMapper(&u.Roles),
+		ID:                 u.ID,
+		UUID:               u.UUID,
+		UserName:           u.UserName,
+		Email:              u.Email,
+		NickName:           u.NickName,
+		HeaderImg:          u.HeaderImg,
+		Status:             u.Status,
+		Phone:              u.Phone,
+		HashPassword:       u.HashPassword,
+		OriginSetting:      u.OriginSetting,
+		PasswordChangedAt:  u.PasswordChangedAt,
+		MustChangePassword: u.MustChangePassword,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
+		Roles:              *roleRepo.ArrayToDomainMapper(&u.Roles),
 	}
 }
 
 func fromDomainMapper(u *domainUser.User) *User {
 	return &User{
-		ID:            u.ID,
-		UUID:          u.UUID,
-		NickName:      u.NickName,
-		HeaderImg:     u.HeaderImg,
-		Phone:         u.Phone,
-		OriginSetting: u.OriginSetting,
-		UserName:      u.UserName,
-		Email:         u.Email,
-		Status:        u.Status,
-		HashPassword:  u.HashPassword,
-		CreatedAt:     u.CreatedAt,
-		UpdatedAt:     u.UpdatedAt,
+		ID:                 u.ID,
+		UUID:               u.UUID,
+		NickName:           u.NickName,
+		HeaderImg:          u.HeaderImg,
+		Phone:              u.Phone,
+		OriginSetting:      u.OriginSetting,
+		UserName:           u.UserName,
+		Email:              u.Email,
+		Status:             u.Status,
+		HashPassword:       u.HashPassword,
+		PasswordChangedAt:  u.PasswordChangedAt,
+		MustChangePassword: u.MustChangePassword,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
 	}
 }
 
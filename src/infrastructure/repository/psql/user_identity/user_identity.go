@@ -0,0 +1,102 @@
+package user_identity
+
+import (
+	"errors"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	ProviderOIDC = "oidc"
+	ProviderLDAP = "ldap"
+)
+
+// ErrIdentityLinked 外部身份已绑定其他本地用户
+var ErrIdentityLinked = errors.New("external identity is already linked to another user")
+
+// UserIdentity 外部身份源账号与本地用户的绑定。OIDC 以 issuer + sub 标识，LDAP 以 DN 或 objectGUID 标识
+type UserIdentity struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updatedAt"`
+	UserID    int64     `gorm:"column:user_id;index;not null" json:"userId"`
+	Provider  string    `gorm:"column:provider;type:varchar(20);not null;uniqueIndex:idx_sys_user_identities_key,priority:1" json:"provider"`
+	Issuer    string    `gorm:"column:issuer;type:varchar(255);not null;default:'';uniqueIndex:idx_sys_user_identities_key,priority:2" json:"issuer"`
+	Subject   string    `gorm:"column:subject;type:varchar(255);not null;uniqueIndex:idx_sys_user_identities_key,priority:3" json:"subject"`
+}
+
+func (*UserIdentity) TableName() string {
+	return "sys_user_identities"
+}
+
+type IUserIdentityRepository interface {
+	// GetUserID 返回绑定的本地用户，未绑定或用户已删除时返回 0
+	GetUserID(provider, issuer, subject string) (int64, error)
+	// Link 绑定外部身份，已绑定其他有效用户时返回 ErrIdentityLinked
+	Link(userId int64, provider, issuer, subject string) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewUserIdentityRepository(db *gorm.DB, loggerInstance *logger.Logger) IUserIdentityRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetUserID(provider, issuer, subject string) (int64, error) {
+	var userIds []int64
+	err := r.DB.Model(&UserIdentity{}).
+		Joins("JOIN sys_users ON sys_users.id = sys_user_identities.user_id AND sys_users.deleted_at IS NULL").
+		Where("sys_user_identities.provider = ? AND sys_user_identities.issuer = ? AND sys_user_identities.subject = ?", provider, issuer, subject).
+		Limit(1).
+		Pluck("sys_user_identities.user_id", &userIds).Error
+	if err != nil {
+		r.Logger.Error("Error getting user identity", zap.Error(err), zap.String("provider", provider), zap.String("subject", subject))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	if len(userIds) == 0 {
+		return 0, nil
+	}
+	return userIds[0], nil
+}
+
+func (r *Repository) Link(userId int64, provider, issuer, subject string) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing UserIdentity
+		err := tx.Where("provider = ? AND issuer = ? AND subject = ?", provider, issuer, subject).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&UserIdentity{UserID: userId, Provider: provider, Issuer: issuer, Subject: subject}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if existing.UserID == userId {
+			return nil
+		}
+		var active int64
+		if err := tx.Table("sys_users").Where("id = ? AND deleted_at IS NULL", existing.UserID).Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrIdentityLinked
+		}
+		// 原用户已删除，绑定转移给新用户
+		return tx.Model(&existing).Update("user_id", userId).Error
+	})
+	if errors.Is(err, ErrIdentityLinked) {
+		r.Logger.Warn("External identity already linked", zap.Int64("userId", userId), zap.String("provider", provider), zap.String("subject", subject))
+		return domainErrors.NewAppError(err, domainErrors.ResourceAlreadyExists)
+	}
+	if err != nil {
+		r.Logger.Error("Error linking user identity", zap.Error(err), zap.Int64("userId", userId), zap.String("provider", provider))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Linked external identity", zap.Int64("userId", userId), zap.String("provider", provider), zap.String("subject", subject))
+	return nil
+}
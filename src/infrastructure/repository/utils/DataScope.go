@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	"gorm.io/gorm"
+)
+
+// ApplyDataScope 行级数据权限，ownerColumn 为记录所属用户的列，custom 条件中的字段通过 columnMapping 转换为列名
+func ApplyDataScope(scope *domain.DataScope, ownerColumn string, columnMapping map[string]string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if scope == nil || scope.All {
+			return db
+		}
+		var conditions []string
+		var args []interface{}
+		if scope.Self && ownerColumn != "" {
+			conditions = append(conditions, ownerColumn+" = ?")
+			args = append(args, scope.UserID)
+		}
+		if len(scope.DeptUserIDs) > 0 && ownerColumn != "" {
+			conditions = append(conditions, ownerColumn+" IN ?")
+			args = append(args, scope.DeptUserIDs)
+		}
+		for _, filter := range scope.Custom {
+			if clause, clauseArgs, ok := customScopeClause(filter, columnMapping); ok {
+				conditions = append(conditions, clause)
+				args = append(args, clauseArgs...)
+			}
+		}
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// customScopeClause 字段不在映射中或没有取值时整条规则不生效，避免放宽权限
+func customScopeClause(filter map[string][]string, columnMapping map[string]string) (string, []interface{}, bool) {
+	if len(filter) == 0 {
+		return "", nil, false
+	}
+	fields := make([]string, 0, len(filter))
+	for field := range filter {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	args := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		column := columnMapping[field]
+		if column == "" || len(filter[field]) == 0 {
+			return "", nil, false
+		}
+		parts = append(parts, column+" IN ?")
+		args = append(args, filter[field])
+	}
+	return "(" + strings.Join(parts, " AND ") + ")", args, true
+}
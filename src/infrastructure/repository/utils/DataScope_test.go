@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type scopedRecord struct {
+	ID     int64
+	UserID int64
+	Status int16
+}
+
+func scopedSQL(t *testing.T, scope *domain.DataScope) string {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	mapping := map[string]string{"status": "status"}
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&scopedRecord{}).Scopes(ApplyDataScope(scope, "user_id", mapping)).Find(&[]scopedRecord{})
+	})
+}
+
+func TestApplyDataScope(t *testing.T) {
+	assert.NotContains(t, scopedSQL(t, nil), "WHERE")
+	assert.NotContains(t, scopedSQL(t, &domain.DataScope{All: true, Self: true, UserID: 3}), "WHERE")
+
+	assert.Contains(t, scopedSQL(t, &domain.DataScope{Self: true, UserID: 3}), "WHERE (user_id = 3)")
+	assert.Contains(t,
+		scopedSQL(t, &domain.DataScope{Self: true, UserID: 3, Custom: []map[string][]string{{"status": {"1"}}}}),
+		"WHERE (user_id = 3 OR (status IN ('1')))")
+	assert.Contains(t, scopedSQL(t, &domain.DataScope{UserID: 3, DeptUserIDs: []int64{3, 5}}), "WHERE (user_id IN (3,5))")
+
+	// 未知字段的规则不生效，没有任何条件时不返回数据
+	assert.Contains(t, scopedSQL(t, &domain.DataScope{Custom: []map[string][]string{{"password": {"x"}}}}), "WHERE 1 = 0")
+}
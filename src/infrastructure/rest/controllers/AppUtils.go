@@ -1,14 +1,30 @@
 package controllers
 
 import (
+	"errors"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
 	"github.com/gin-gonic/gin"
 )
 
+// DataScopeContextKey DataScopeMiddleware 写入的数据权限
+const DataScopeContextKey = "data_scope"
+
+// HiddenFieldsContextKey FieldPermissionMiddleware 写入的隐藏字段
+const HiddenFieldsContextKey = "hidden_fields"
+
+// ErrImpersonating 模拟登录期间不允许的操作返回该错误
+var ErrImpersonating = errors.New("not allowed while impersonating, exit impersonation first")
+
 type IAppUtils interface {
 	GinContext() *gin.Context
 
 	GetUserID() (int, bool)
 	GetRoleID() (int, bool)
+	GetRoleIDs() ([]int64, bool)
+	GetImpersonatorID() (int64, bool)
+	GetDataScope() *domain.DataScope
+	GetHiddenFields() domain.FieldMask
 
 	BindJSON(obj interface{}) error
 	AbortWithError(code int, err error)
@@ -45,6 +61,51 @@ func (u *AppUtils) GetRoleID() (int64, bool) {
 	return id, ok
 }
 
+// GetRoleIDs 返回参与鉴权的全部角色，单角色令牌只包含当前角色
+func (u *AppUtils) GetRoleIDs() ([]int64, bool) {
+	if roleIDs, exists := u.c.Get("role_ids"); exists {
+		if ids, ok := roleIDs.([]int64); ok && len(ids) > 0 {
+			return ids, true
+		}
+	}
+	roleID, ok := u.GetRoleID()
+	if !ok {
+		return nil, false
+	}
+	return []int64{roleID}, true
+}
+
+// GetImpersonatorID 模拟登录时返回真实管理员ID
+func (u *AppUtils) GetImpersonatorID() (int64, bool) {
+	impersonatorID, exists := u.c.Get("impersonator_id")
+	if !exists {
+		return 0, false
+	}
+	id, ok := impersonatorID.(int64)
+	return id, ok
+}
+
+// GetDataScope 未经过 DataScopeMiddleware 时只能看到自己的数据
+func (u *AppUtils) GetDataScope() *domain.DataScope {
+	if value, exists := u.c.Get(DataScopeContextKey); exists {
+		if scope, ok := value.(*domain.DataScope); ok {
+			return scope
+		}
+	}
+	userID, _ := u.GetUserID()
+	return &domain.DataScope{UserID: int64(userID), Self: true}
+}
+
+// GetHiddenFields 未经过 FieldPermissionMiddleware 时不隐藏字段
+func (u *AppUtils) GetHiddenFields() domain.FieldMask {
+	if value, exists := u.c.Get(HiddenFieldsContextKey); exists {
+		if mask, ok := value.(domain.FieldMask); ok {
+			return mask
+		}
+	}
+	return domain.FieldMask{}
+}
+
 func (u *AppUtils) BindJSON(obj interface{}) error {
 	return u.c.ShouldBindJSON(obj)
 }
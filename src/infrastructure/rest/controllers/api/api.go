@@ -32,8 +32,10 @@ type DeleteBatchApiRequest struct {
 	IDS []int `json:"ids"`
 }
 
+// SynchronizeResponse Count 为新增的接口数
 type SynchronizeResponse struct {
 	Count int `json:"count"`
+	*domainApi.SyncResult
 }
 
 type ResponseApi struct {
@@ -465,10 +467,12 @@ func (c *ApiController) GetApisGroup(ctx *gin.Context) {
 
 // synchronize apis with router
 // @Summary synchronize apis with router
-// @Description synchronize apis with router
+// @Description diff router against sys_apis, stale routes are kept, flagged or deleted with their policies
 // @Tags sync apis
 // @Accept json
 // @Produce json
+// @Param dry_run query bool false "only report the diff"
+// @Param stale query string false "keep, flag or delete"
 // @Success 200 {object} SynchronizeResponse
 // @Router /v1/api/synchronize [post]
 func (c *ApiController) SynchronizeRouterToApi(ctx *gin.Context) {
@@ -481,9 +485,19 @@ func (c *ApiController) SynchronizeRouterToApi(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
+	options := domainApi.SyncOptions{
+		DryRun: ctx.Query("dry_run") == "true",
+		Stale:  ctx.DefaultQuery("stale", domainApi.SyncStaleKeep),
+	}
+	switch options.Stale {
+	case domainApi.SyncStaleKeep, domainApi.SyncStaleFlag, domainApi.SyncStaleDelete:
+	default:
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("stale must be one of keep, flag, delete"), domainErrors.ValidationError))
+		return
+	}
 	// 获取所有路由信息
 	routes := c.Router.Routes()
-	apis, err := c.apiService.SynchronizeRouterToApi(routes)
+	result, err := c.apiService.SynchronizeRouterToApi(routes, options)
 	if err != nil {
 		c.Logger.Error("Error synchronizing router to api", zap.Error(err))
 		appError := domainErrors.NewAppError(err, domainErrors.RepositoryError)
@@ -491,11 +505,11 @@ func (c *ApiController) SynchronizeRouterToApi(ctx *gin.Context) {
 		return
 	}
 	apiResponse := controllers.NewCommonResponseBuilder[SynchronizeResponse]().
-		Data(SynchronizeResponse{Count: *apis}).
+		Data(SynchronizeResponse{Count: len(result.Added), SyncResult: result}).
 		Message("success").
 		Status(0).
 		Build()
-	c.Logger.Info("Successfully synchronized router to api", zap.Int("count", *apis))
+	c.Logger.Info("Successfully synchronized router to api", zap.Int("count", len(result.Added)), zap.Bool("dryRun", options.DryRun))
 	ctx.JSON(http.StatusOK, apiResponse)
 }
 
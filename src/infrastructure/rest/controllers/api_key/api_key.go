@@ -0,0 +1,128 @@
+package api_key
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	apiKeyUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/api_key"
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainApiKey "github.com/gbrayhan/microservices-go/src/domain/sys/api_key"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type IApiKeyController interface {
+	CreateApiKey(ctx *gin.Context)
+	GetMyApiKeys(ctx *gin.Context)
+	RevokeApiKey(ctx *gin.Context)
+}
+
+type ApiKeyController struct {
+	apiKeyService apiKeyUseCase.ISysApiKeyService
+	Logger        *logger.Logger
+}
+
+func NewApiKeyController(apiKeyService apiKeyUseCase.ISysApiKeyService, loggerInstance *logger.Logger) IApiKeyController {
+	return &ApiKeyController{apiKeyService: apiKeyService, Logger: loggerInstance}
+}
+
+// CreateApiKey
+// @Summary create api key
+// @Description create a personal api key for the current user, the key is only returned once
+// @Tags api key
+// @Accept json
+// @Produce json
+// @Param book body apiKeyUseCase.CreateApiKeyRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainApiKey.CreatedApiKey]
+// @Router /v1/api-key [post]
+func (c *ApiKeyController) CreateApiKey(ctx *gin.Context) {
+	// 不允许用API密钥再创建密钥
+	if _, isApiKey := ctx.Get("api_key_id"); isApiKey {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("api keys cannot create api keys"), domainErrors.NotAuthorized))
+		return
+	}
+	appCtx := controllers.NewAppUtils(ctx)
+	// 模拟登录期间创建的密钥会以被模拟用户身份长期有效，且不再记录真实管理员
+	if _, impersonating := appCtx.GetImpersonatorID(); impersonating {
+		_ = ctx.Error(domainErrors.NewAppError(controllers.ErrImpersonating, domainErrors.NotAuthorized))
+		return
+	}
+	userId, ok := appCtx.GetUserID()
+	if !ok {
+		_ = ctx.Error(domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated))
+		return
+	}
+	var request apiKeyUseCase.CreateApiKeyRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for new api key", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	created, err := c.apiKeyService.Create(int64(userId), request)
+	if err != nil {
+		c.Logger.Error("Error creating api key", zap.Error(err), zap.Int("userId", userId))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainApiKey.CreatedApiKey]{
+		Data:    created,
+		Message: "success",
+	})
+}
+
+// GetMyApiKeys
+// @Summary get my api keys
+// @Description list the api keys of the current user
+// @Tags api key
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainApiKey.ApiKey]
+// @Router /v1/api-key [get]
+func (c *ApiKeyController) GetMyApiKeys(ctx *gin.Context) {
+	userId, ok := controllers.NewAppUtils(ctx).GetUserID()
+	if !ok {
+		_ = ctx.Error(domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated))
+		return
+	}
+	apiKeys, err := c.apiKeyService.GetByUserID(int64(userId))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*[]domainApiKey.ApiKey]{
+		Data:    apiKeys,
+		Message: "success",
+	})
+}
+
+// RevokeApiKey
+// @Summary revoke api key
+// @Description revoke one of the current user's api keys
+// @Tags api key
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[int]
+// @Router /v1/api-key/{id} [delete]
+func (c *ApiKeyController) RevokeApiKey(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError))
+		return
+	}
+	userId, ok := controllers.NewAppUtils(ctx).GetUserID()
+	if !ok {
+		_ = ctx.Error(domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated))
+		return
+	}
+	if err := c.apiKeyService.Revoke(int64(userId), id); err != nil {
+		c.Logger.Error("Error revoking api key", zap.Error(err), zap.Int64("id", id))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int64]{
+		Data:    id,
+		Message: "success",
+	})
+}
@@ -0,0 +1,34 @@
+package api_key
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestCreateApiKeyRejectsImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// service 为 nil，调用到 service 会 panic
+	c := NewApiKeyController(nil, &logger.Logger{Log: zap.NewNop()})
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/api-key", strings.NewReader(`{"name":"ci"}`))
+	ctx.Set("user_id", 7)
+	ctx.Set("impersonator_id", int64(1))
+
+	c.CreateApiKey(ctx)
+
+	assert.Len(t, ctx.Errors, 1)
+	var appErr *domainErrors.AppError
+	assert.True(t, errors.As(ctx.Errors[0].Err, &appErr))
+	assert.Equal(t, domainErrors.NotAuthorized, appErr.Type)
+	assert.Equal(t, controllers.ErrImpersonating, appErr.Err)
+}
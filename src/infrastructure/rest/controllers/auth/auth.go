@@ -11,6 +11,8 @@ import (
 	useCaseAuth "github.com/gbrayhan/microservices-go/src/application/services/auth"
 	domain "github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
 	"github.com/gin-gonic/gin"
@@ -23,6 +25,15 @@ type IAuthController interface {
 	Register(ctx *gin.Context)
 	GetAccessTokenByRefreshToken(ctx *gin.Context)
 	SwitchRole(ctx *gin.Context)
+	OIDCAuthorize(ctx *gin.Context)
+	OIDCCallback(ctx *gin.Context)
+	OIDCLinkAuthorize(ctx *gin.Context)
+	OIDCLink(ctx *gin.Context)
+	LDAPLink(ctx *gin.Context)
+	VerifyEmail(ctx *gin.Context)
+	ResendVerification(ctx *gin.Context)
+	Impersonate(ctx *gin.Context)
+	ExitImpersonation(ctx *gin.Context)
 }
 
 type AuthController struct {
@@ -48,9 +59,10 @@ func (c *AuthController) Register(ctx *gin.Context) {
 		return
 	}
 	userRegister := useCaseAuth.RegisterUser{
-		UserName: request.UserName,
-		Email:    request.Email,
-		Password: request.Password,
+		UserName:        request.UserName,
+		Email:           request.Email,
+		Password:        request.Password,
+		InvitationToken: request.InvitationToken,
 	}
 
 	registerUser, err := c.authUseCase.Register(userRegister)
@@ -121,27 +133,7 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	response := &domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser]{
-		Data: useCaseAuth.SecurityAuthenticatedUser{
-			UserInfo: useCaseAuth.DataUserAuthenticated{
-				UserName:    domainUser.UserName,
-				Email:       domainUser.Email,
-				ID:          domainUser.ID,
-				Status:      domainUser.Status,
-				NickName:    domainUser.NickName,
-				Phone:       domainUser.Phone,
-				HeaderImg:   domainUser.HeaderImg,
-				Roles:       roleService.BuildRoleTree(&domainUser.Roles),
-				CurrentRole: role,
-			},
-			Security: useCaseAuth.DataSecurityAuthenticated{
-				JWTAccessToken:            authTokens.AccessToken,
-				JWTRefreshToken:           authTokens.RefreshToken,
-				ExpirationAccessDateTime:  authTokens.ExpirationAccessDateTime,
-				ExpirationRefreshDateTime: authTokens.ExpirationRefreshDateTime,
-			},
-		},
-	}
+	response := newAuthenticatedResponse(domainUser, authTokens, role)
 
 	c.Logger.Info("Login successful", zap.String("email", request.Username), zap.Int64("userID", domainUser.ID))
 	ctx.JSON(http.StatusOK, response)
@@ -181,6 +173,10 @@ func (c *AuthController) GetAccessTokenByRefreshToken(ctx *gin.Context) {
 				JWTRefreshToken:           authTokens.RefreshToken,
 				ExpirationAccessDateTime:  authTokens.ExpirationAccessDateTime,
 				ExpirationRefreshDateTime: authTokens.ExpirationRefreshDateTime,
+				PasswordChangeRequired:    authTokens.PasswordChangeRequired,
+				PasswordResetToken:        authTokens.PasswordResetToken,
+				ExpirationResetDateTime:   authTokens.ExpirationResetDateTime,
+				RoleIDs:                   authTokens.RoleIDs,
 			},
 		}).
 		Message("success").
@@ -193,7 +189,7 @@ func (c *AuthController) GetAccessTokenByRefreshToken(ctx *gin.Context) {
 
 // SwitchRole
 // @Summary switch role
-// @Description switch role
+// @Description switch to a single assigned role, role_id=0 restores all roles when role union is enabled
 // @Tags switch role
 // @Accept json
 // @Produce json
@@ -215,6 +211,11 @@ func (c *AuthController) SwitchRole(ctx *gin.Context) {
 		_ = ctx.Error(errors.New("user id is invalid"))
 		return
 	}
+	// 模拟登录期间切换角色会签发被模拟用户的正常令牌
+	if _, impersonating := appCtx.GetImpersonatorID(); impersonating {
+		_ = ctx.Error(domainErrors.NewAppError(controllers.ErrImpersonating, domainErrors.NotAuthorized))
+		return
+	}
 	domainUser, authTokens, role, err := c.authUseCase.SwitchRole(userId, int64(roleId))
 	if err != nil {
 		c.Logger.Error("Login failed", zap.Error(err), zap.Int("userId", userId))
@@ -222,17 +223,25 @@ func (c *AuthController) SwitchRole(ctx *gin.Context) {
 		return
 	}
 
-	response := &domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser]{
+	response := newAuthenticatedResponse(domainUser, authTokens, role)
+
+	c.Logger.Info("Switch role successful", zap.Int("Int", userId))
+	ctx.JSON(http.StatusOK, response)
+}
+
+// newAuthenticatedResponse 构建登录成功后的用户信息和令牌响应
+func newAuthenticatedResponse(user *domainUser.User, authTokens *useCaseAuth.AuthTokens, role *domainRole.Role) *domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser] {
+	return &domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser]{
 		Data: useCaseAuth.SecurityAuthenticatedUser{
 			UserInfo: useCaseAuth.DataUserAuthenticated{
-				UserName:    domainUser.UserName,
-				Email:       domainUser.Email,
-				ID:          domainUser.ID,
-				Status:      domainUser.Status,
-				NickName:    domainUser.NickName,
-				Phone:       domainUser.Phone,
-				HeaderImg:   domainUser.HeaderImg,
-				Roles:       roleService.BuildRoleTree(&domainUser.Roles),
+				UserName:    user.UserName,
+				Email:       user.Email,
+				ID:          user.ID,
+				Status:      user.Status,
+				NickName:    user.NickName,
+				Phone:       user.Phone,
+				HeaderImg:   user.HeaderImg,
+				Roles:       roleService.BuildRoleTree(&user.Roles),
 				CurrentRole: role,
 			},
 			Security: useCaseAuth.DataSecurityAuthenticated{
@@ -240,10 +249,12 @@ func (c *AuthController) SwitchRole(ctx *gin.Context) {
 				JWTRefreshToken:           authTokens.RefreshToken,
 				ExpirationAccessDateTime:  authTokens.ExpirationAccessDateTime,
 				ExpirationRefreshDateTime: authTokens.ExpirationRefreshDateTime,
+				PasswordChangeRequired:    authTokens.PasswordChangeRequired,
+				PasswordResetToken:        authTokens.PasswordResetToken,
+				ExpirationResetDateTime:   authTokens.ExpirationResetDateTime,
+				ImpersonatorID:            authTokens.ImpersonatorID,
+				RoleIDs:                   authTokens.RoleIDs,
 			},
 		},
 	}
-
-	c.Logger.Info("Switch role successful", zap.Int("Int", userId))
-	ctx.JSON(http.StatusOK, response)
 }
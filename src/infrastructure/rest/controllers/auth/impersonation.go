@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Impersonate godoc
+// @Summary impersonate user
+// @Description log in as another user with a short-lived token, operation records keep the real administrator
+// @Tags impersonation
+// @Accept json
+// @Produce json
+// @Param book body ImpersonateRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser]
+// @Router /v1/auth/impersonate [post]
+func (c *AuthController) Impersonate(ctx *gin.Context) {
+	appCtx := controllers.NewAppUtils(ctx)
+	if _, impersonating := appCtx.GetImpersonatorID(); impersonating {
+		_ = ctx.Error(domainErrors.NewAppError(controllers.ErrImpersonating, domainErrors.NotAuthorized))
+		return
+	}
+	adminId, ok := appCtx.GetUserID()
+	if !ok {
+		_ = ctx.Error(domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated))
+		return
+	}
+	adminRoleIds, _ := appCtx.GetRoleIDs()
+	var request ImpersonateRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for impersonation", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+
+	user, authTokens, role, err := c.authUseCase.Impersonate(int64(adminId), adminRoleIds, request.UserID)
+	if err != nil {
+		c.Logger.Error("Impersonation failed", zap.Error(err), zap.Int("adminId", adminId), zap.Int64("userId", request.UserID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newAuthenticatedResponse(user, authTokens, role))
+}
+
+// ExitImpersonation godoc
+// @Summary exit impersonation
+// @Description revoke the impersonation token and return fresh tokens for the real administrator
+// @Tags impersonation
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser]
+// @Router /v1/auth/impersonate/exit [post]
+func (c *AuthController) ExitImpersonation(ctx *gin.Context) {
+	tokens := strings.Split(ctx.Request.Header.Get("Authorization"), " ")
+	if len(tokens) < 2 {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("token error"), domainErrors.ValidationError))
+		return
+	}
+	user, authTokens, role, err := c.authUseCase.ExitImpersonation(tokens[1])
+	if err != nil {
+		c.Logger.Error("Exit impersonation failed", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newAuthenticatedResponse(user, authTokens, role))
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LDAPLink godoc
+// @Summary ldap link
+// @Description link a directory account to the signed-in user after verifying the directory password
+// @Tags login
+// @Accept json
+// @Produce json
+// @Param book body LDAPLinkRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[string]
+// @Router /v1/auth/ldap/link [post]
+func (c *AuthController) LDAPLink(ctx *gin.Context) {
+	userId, ok := c.linkingUserID(ctx)
+	if !ok {
+		return
+	}
+	var request LDAPLinkRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for ldap link", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	if err := c.authUseCase.LDAPLink(userId, request.Username, request.Password); err != nil {
+		c.Logger.Error("LDAP link failed", zap.Error(err), zap.Int64("userID", userId))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[string]{Data: "true", Message: "success", Status: 0})
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	useCaseAuth "github.com/gbrayhan/microservices-go/src/application/services/auth"
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// oidcBindingCookie 把state绑定到发起授权的浏览器，回调时必须带回
+	oidcBindingCookie     = "oidc_binding"
+	oidcBindingCookiePath = "/v1/auth/oidc"
+)
+
+// OIDCAuthorize godoc
+// @Summary oidc authorize url
+// @Description get the identity provider authorization url (authorization code + PKCE)
+// @Tags login
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[useCaseAuth.OIDCAuthorization]
+// @Router /v1/auth/oidc/authorize [get]
+func (c *AuthController) OIDCAuthorize(ctx *gin.Context) {
+	authorization, err := c.authUseCase.OIDCAuthURL()
+	if err != nil {
+		c.Logger.Error("Error building oidc authorization url", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	setOIDCBindingCookie(ctx, authorization.Binding, int(useCaseAuth.OIDCStateExpireDuration.Seconds()))
+	response := controllers.NewCommonResponseBuilder[*useCaseAuth.OIDCAuthorization]().
+		Data(authorization).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// OIDCCallback godoc
+// @Summary oidc login callback
+// @Description exchange the authorization code returned by the identity provider for local tokens, the oidc_binding cookie set by the authorize call is required
+// @Tags login
+// @Accept json
+// @Produce json
+// @Param book body OIDCCallbackRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[useCaseAuth.SecurityAuthenticatedUser]
+// @Router /v1/auth/oidc/callback [post]
+func (c *AuthController) OIDCCallback(ctx *gin.Context) {
+	var request OIDCCallbackRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for oidc callback", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+
+	domainUser, authTokens, role, err := c.authUseCase.OIDCLogin(request.Code, request.State, takeOIDCBinding(ctx), ctx)
+	if err != nil {
+		c.Logger.Error("OIDC login failed", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	c.Logger.Info("OIDC login successful", zap.Int64("userID", domainUser.ID))
+	ctx.JSON(http.StatusOK, newAuthenticatedResponse(domainUser, authTokens, role))
+}
+
+// OIDCLinkAuthorize godoc
+// @Summary oidc link authorize url
+// @Description get the identity provider authorization url for linking an identity provider account to the signed-in user
+// @Tags login
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[useCaseAuth.OIDCAuthorization]
+// @Router /v1/auth/oidc/link [get]
+func (c *AuthController) OIDCLinkAuthorize(ctx *gin.Context) {
+	userId, ok := c.linkingUserID(ctx)
+	if !ok {
+		return
+	}
+	authorization, err := c.authUseCase.OIDCLinkURL(userId)
+	if err != nil {
+		c.Logger.Error("Error building oidc link authorization url", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	setOIDCBindingCookie(ctx, authorization.Binding, int(useCaseAuth.OIDCStateExpireDuration.Seconds()))
+	response := controllers.NewCommonResponseBuilder[*useCaseAuth.OIDCAuthorization]().
+		Data(authorization).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// OIDCLink godoc
+// @Summary oidc link callback
+// @Description link the identity provider account returned by the callback to the signed-in user
+// @Tags login
+// @Accept json
+// @Produce json
+// @Param book body OIDCCallbackRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[string]
+// @Router /v1/auth/oidc/link [post]
+func (c *AuthController) OIDCLink(ctx *gin.Context) {
+	userId, ok := c.linkingUserID(ctx)
+	if !ok {
+		return
+	}
+	var request OIDCCallbackRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for oidc link", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	if err := c.authUseCase.OIDCLink(request.Code, request.State, takeOIDCBinding(ctx), userId); err != nil {
+		c.Logger.Error("OIDC link failed", zap.Error(err), zap.Int64("userID", userId))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[string]{Data: "true", Message: "success", Status: 0})
+}
+
+// linkingUserID 绑定外部身份的当前用户，模拟登录期间不允许绑定
+func (c *AuthController) linkingUserID(ctx *gin.Context) (int64, bool) {
+	appCtx := controllers.NewAppUtils(ctx)
+	if _, impersonating := appCtx.GetImpersonatorID(); impersonating {
+		_ = ctx.Error(domainErrors.NewAppError(controllers.ErrImpersonating, domainErrors.NotAuthorized))
+		return 0, false
+	}
+	userId, ok := appCtx.GetUserID()
+	if !ok {
+		_ = ctx.Error(domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated))
+		return 0, false
+	}
+	return int64(userId), true
+}
+
+// setOIDCBindingCookie 写入 HttpOnly、SameSite 的绑定cookie，maxAge 为负时删除
+func setOIDCBindingCookie(ctx *gin.Context, value string, maxAge int) {
+	secure := ctx.Request.TLS != nil || strings.EqualFold(ctx.GetHeader("X-Forwarded-Proto"), "https")
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    value,
+		Path:     oidcBindingCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// takeOIDCBinding 读取并清除绑定cookie，state 是一次性的，cookie 也只用一次
+func takeOIDCBinding(ctx *gin.Context) string {
+	binding, err := ctx.Cookie(oidcBindingCookie)
+	if err != nil {
+		return ""
+	}
+	setOIDCBindingCookie(ctx, "", -1)
+	return binding
+}
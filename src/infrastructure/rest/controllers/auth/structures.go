@@ -21,7 +21,30 @@ type SecurityData struct {
 }
 
 type RegisterRequest struct {
-	UserName string `json:"user_name" binding:"required"`
-	Email    string `json:"email" binding:"required"`
+	UserName        string `json:"user_name" binding:"required"`
+	Email           string `json:"email" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+	InvitationToken string `json:"invitation_token"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type LDAPLinkRequest struct {
+	Username string `json:"user_name" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ImpersonateRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"net/http"
+
+	useCaseAuth "github.com/gbrayhan/microservices-go/src/application/services/auth"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// VerifyEmail godoc
+// @Summary verify email
+// @Description activate a self-registered account with the token from the verification email
+// @Tags register user
+// @Accept json
+// @Produce json
+// @Param book body VerifyEmailRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[useCaseAuth.DataUserAuthenticated]
+// @Router /v1/auth/verify-email [post]
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	var request VerifyEmailRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for email verification", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	user, err := c.authUseCase.VerifyEmail(request.Token)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[useCaseAuth.DataUserAuthenticated]().
+		Data(useCaseAuth.DataUserAuthenticated{
+			ID:       user.ID,
+			UUID:     user.UUID,
+			UserName: user.UserName,
+			NickName: user.NickName,
+			Email:    user.Email,
+			Status:   user.Status,
+		}).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ResendVerification godoc
+// @Summary resend verification email
+// @Description send a new verification link; previous links stop working
+// @Tags register user
+// @Accept json
+// @Produce json
+// @Param book body ResendVerificationRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[string]
+// @Router /v1/auth/verify-email/resend [post]
+func (c *AuthController) ResendVerification(ctx *gin.Context) {
+	var request ResendVerificationRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for resend verification", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	if err := c.authUseCase.ResendVerificationEmail(request.Email); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[string]().
+		Data("").
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
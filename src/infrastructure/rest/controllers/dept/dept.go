@@ -0,0 +1,267 @@
+package dept
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainDept "github.com/gbrayhan/microservices-go/src/domain/sys/dept"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Structures
+type NewDeptRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID int64  `json:"parent_id"`
+	Order    int64  `json:"order"`
+	Status   int16  `json:"status"`
+}
+
+type SetMembersRequest struct {
+	UserIDs   []int64 `json:"user_ids"`
+	LeaderIDs []int64 `json:"leader_ids"`
+}
+
+type IDeptController interface {
+	NewDept(ctx *gin.Context)
+	GetAllDepts(ctx *gin.Context)
+	GetDeptByID(ctx *gin.Context)
+	UpdateDept(ctx *gin.Context)
+	DeleteDept(ctx *gin.Context)
+	GetTreeDepts(ctx *gin.Context)
+	GetDeptMembers(ctx *gin.Context)
+	SetDeptMembers(ctx *gin.Context)
+}
+
+type DeptController struct {
+	deptService domainDept.IDeptService
+	Logger      *logger.Logger
+}
+
+func NewDeptController(deptService domainDept.IDeptService, loggerInstance *logger.Logger) IDeptController {
+	return &DeptController{deptService: deptService, Logger: loggerInstance}
+}
+
+// NewDept
+// @Summary create dept
+// @Description create dept
+// @Tags dept
+// @Accept json
+// @Produce json
+// @Param book body NewDeptRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainDept.Dept]
+// @Router /v1/dept [post]
+func (c *DeptController) NewDept(ctx *gin.Context) {
+	var request NewDeptRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for new dept", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	dept, err := c.deptService.Create(&domainDept.Dept{
+		Name:     request.Name,
+		ParentID: request.ParentID,
+		Order:    request.Order,
+		Status:   request.Status,
+	})
+	if err != nil {
+		c.Logger.Error("Error creating dept", zap.Error(err), zap.String("name", request.Name))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*domainDept.Dept]().
+		Data(dept).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetAllDepts
+// @Summary get all depts
+// @Description get dept tree with leaders
+// @Tags dept
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainDept.DeptTree]
+// @Router /v1/dept [get]
+func (c *DeptController) GetAllDepts(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Query("status"))
+	if err != nil {
+		status = 0
+	}
+	depts, err := c.deptService.GetAll(status)
+	if err != nil {
+		c.Logger.Error("Error getting all depts", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[[]*domainDept.DeptTree]().
+		Data(depts).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetDeptByID
+// @Summary get dept
+// @Description get dept by id
+// @Tags dept
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainDept.Dept]
+// @Router /v1/dept/{id} [get]
+func (c *DeptController) GetDeptByID(ctx *gin.Context) {
+	deptID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	dept, err := c.deptService.GetByID(deptID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainDept.Dept]{Data: dept, Message: "success"})
+}
+
+// UpdateDept
+// @Summary update dept
+// @Description update dept, moving it under its own descendant is rejected
+// @Tags dept
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainDept.Dept]
+// @Router /v1/dept/{id} [put]
+func (c *DeptController) UpdateDept(ctx *gin.Context) {
+	deptID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	var requestMap map[string]any
+	if err := controllers.BindJSONMap(ctx, &requestMap); err != nil {
+		c.Logger.Error("Error binding JSON for dept update", zap.Error(err), zap.Int64("id", deptID))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	if err := updateValidation(requestMap); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	dept, err := c.deptService.Update(deptID, requestMap)
+	if err != nil {
+		c.Logger.Error("Error updating dept", zap.Error(err), zap.Int64("id", deptID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*domainDept.Dept]().
+		Data(dept).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// DeleteDept
+// @Summary delete dept
+// @Description delete dept without sub departments
+// @Tags dept
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[int64]
+// @Router /v1/dept/{id} [delete]
+func (c *DeptController) DeleteDept(ctx *gin.Context) {
+	deptID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.deptService.Delete(deptID); err != nil {
+		c.Logger.Error("Error deleting dept", zap.Error(err), zap.Int64("id", deptID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int64]{
+		Data:    deptID,
+		Message: "resource deleted successfully",
+		Status:  0,
+	})
+}
+
+// GetTreeDepts
+// @Summary get tree depts
+// @Description get dept tree for selectors
+// @Tags dept
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainDept.DeptNode]
+// @Router /v1/dept/tree [get]
+func (c *DeptController) GetTreeDepts(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Query("status"))
+	if err != nil {
+		status = 0
+	}
+	depts, err := c.deptService.GetTreeDepts(status)
+	if err != nil {
+		c.Logger.Error("Error getting dept tree", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainDept.DeptNode]{
+		Data: depts,
+	})
+}
+
+// GetDeptMembers
+// @Summary get dept members
+// @Description get users and leaders of a dept
+// @Tags dept
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainDept.Member]
+// @Router /v1/dept/{id}/members [get]
+func (c *DeptController) GetDeptMembers(ctx *gin.Context) {
+	deptID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	members, err := c.deptService.GetMembers(deptID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[[]domainDept.Member]{Data: members, Message: "success"})
+}
+
+// SetDeptMembers
+// @Summary set dept members
+// @Description replace the users and leaders of a dept, leaders are added as members
+// @Tags dept
+// @Accept json
+// @Produce json
+// @Param book body SetMembersRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[bool]
+// @Router /v1/dept/{id}/members [put]
+func (c *DeptController) SetDeptMembers(ctx *gin.Context) {
+	deptID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	var request SetMembersRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	if err := c.deptService.SetMembers(deptID, request.UserIDs, request.LeaderIDs); err != nil {
+		c.Logger.Error("Error updating dept members", zap.Error(err), zap.Int64("id", deptID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[bool]{Data: true, Message: "success"})
+}
+
+func (c *DeptController) paramID(ctx *gin.Context) (int64, bool) {
+	deptID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger.Error("Invalid dept ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError))
+		return 0, false
+	}
+	return deptID, true
+}
@@ -0,0 +1,15 @@
+package dept
+
+import "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+
+var customRules = map[string]string{
+	"name":      "required",
+	"order":     "numeric",
+	"parent_id": "numeric",
+	"status":    "required,status_enum",
+}
+
+func updateValidation(request map[string]any) error {
+	validator := controllers.NewCommonValidator(customRules)
+	return validator.ValidateUpdate(request)
+}
@@ -2,13 +2,17 @@ package dictionary
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	domainDictionary "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary"
+	domainDetail "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary_detail"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	dictionaryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
@@ -16,6 +20,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxBatchTypes 批量获取字典时一次最多的类型数
+const maxBatchTypes = 50
+
 // Structures
 type NewDictionaryRequest struct {
 	ID             int    `json:"id"`
@@ -45,14 +52,16 @@ type IDictionaryController interface {
 	SearchPaginated(ctx *gin.Context)
 	SearchByProperty(ctx *gin.Context)
 	GetByType(ctx *gin.Context)
+	GetByTypes(ctx *gin.Context)
 }
 type DictionaryController struct {
-	dictionaryService domainDictionary.IDictionaryService
-	Logger            *logger.Logger
+	dictionaryService  domainDictionary.IDictionaryService
+	translationService domainTranslation.ITranslationService
+	Logger             *logger.Logger
 }
 
-func NewDictionaryController(dictionaryService domainDictionary.IDictionaryService, loggerInstance *logger.Logger) IDictionaryController {
-	return &DictionaryController{dictionaryService: dictionaryService, Logger: loggerInstance}
+func NewDictionaryController(dictionaryService domainDictionary.IDictionaryService, translationService domainTranslation.ITranslationService, loggerInstance *logger.Logger) IDictionaryController {
+	return &DictionaryController{dictionaryService: dictionaryService, translationService: translationService, Logger: loggerInstance}
 }
 
 // CreateDictionary
@@ -372,6 +381,16 @@ func (c *DictionaryController) SearchByProperty(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, coincidences)
 }
 
+// GetByType
+// @Summary get dictionary by type
+// @Description get dictionary with its details by type, detail labels translated by Accept-Language
+// @Tags dictionaries
+// @Accept json
+// @Produce json
+// @Param type path string true "dictionary type"
+// @Param Accept-Language header string false "preferred languages, e.g. zh-CN,zh;q=0.9,en;q=0.8"
+// @Success 200 {object} domain.CommonResponse[domainDictionary.Dictionary]
+// @Router /v1/dictionary/type/{type} [get]
 func (c *DictionaryController) GetByType(ctx *gin.Context) {
 	typeText := ctx.Param("type")
 	c.Logger.Info("getting dictionary by type", zap.String("type", typeText))
@@ -382,6 +401,10 @@ func (c *DictionaryController) GetByType(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
+	if dictionaries != nil && dictionaries.Details != nil {
+		locale := c.translationService.ResolveLocale(ctx.GetHeader("Accept-Language"))
+		domainDetail.TranslateLabels(*dictionaries.Details, c.translationService, locale)
+	}
 	dictionaryResponse := controllers.NewCommonResponseBuilder[*domainDictionary.Dictionary]().
 		Data(dictionaries).
 		Message("success").
@@ -424,3 +447,47 @@ func toUsecaseMapper(req *NewDictionaryRequest) *domainDictionary.Dictionary {
 		IsGenerateFile: req.IsGenerateFile,
 	}
 }
+
+// GetByTypes
+// @Summary get dictionaries by types
+// @Description get several dictionaries with their details in one request, keyed by type; unknown or disabled types are omitted. Detail labels translated by Accept-Language
+// @Tags dictionaries
+// @Accept json
+// @Produce json
+// @Param types query string true "comma separated dictionary types, e.g. gender,status"
+// @Param Accept-Language header string false "preferred languages, e.g. zh-CN,zh;q=0.9,en;q=0.8"
+// @Success 200 {object} domain.CommonResponse[map[string]domainDictionary.Dictionary]
+// @Router /v1/dictionary/types [get]
+func (c *DictionaryController) GetByTypes(ctx *gin.Context) {
+	types := make([]string, 0)
+	for _, value := range ctx.QueryArray("types") {
+		for _, typeText := range strings.Split(value, ",") {
+			if typeText = strings.TrimSpace(typeText); typeText != "" {
+				types = append(types, typeText)
+			}
+		}
+	}
+	if len(types) == 0 || len(types) > maxBatchTypes {
+		appError := domainErrors.NewAppError(fmt.Errorf("between 1 and %d dictionary types are required", maxBatchTypes), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	c.Logger.Info("Getting dictionaries by types", zap.Strings("types", types))
+	dictionaries, err := c.dictionaryService.GetByTypes(types)
+	if err != nil {
+		c.Logger.Error("Error getting dictionaries by types", zap.Error(err), zap.Strings("types", types))
+		_ = ctx.Error(err)
+		return
+	}
+	locale := c.translationService.ResolveLocale(ctx.GetHeader("Accept-Language"))
+	for _, dictionary := range dictionaries {
+		if dictionary.Details != nil {
+			domainDetail.TranslateLabels(*dictionary.Details, c.translationService, locale)
+		}
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[map[string]*domainDictionary.Dictionary]().
+		Data(dictionaries).
+		Message("success").
+		Status(0).
+		Build())
+}
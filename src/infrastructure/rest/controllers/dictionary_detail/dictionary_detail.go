@@ -10,6 +10,7 @@ import (
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	domainDictionary "github.com/gbrayhan/microservices-go/src/domain/sys/dictionary_detail"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	dictionaryRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary_detail"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
@@ -57,12 +58,13 @@ type IIDictionaryDetailController interface {
 	DeleteDictionaryDetails(ctx *gin.Context)
 }
 type DictionaryDetailController struct {
-	dictionaryService domainDictionary.IDictionaryDetailService
-	Logger            *logger.Logger
+	dictionaryService  domainDictionary.IDictionaryDetailService
+	translationService domainTranslation.ITranslationService
+	Logger             *logger.Logger
 }
 
-func NewIDictionaryDetailController(dictionaryService domainDictionary.IDictionaryDetailService, loggerInstance *logger.Logger) IIDictionaryDetailController {
-	return &DictionaryDetailController{dictionaryService: dictionaryService, Logger: loggerInstance}
+func NewIDictionaryDetailController(dictionaryService domainDictionary.IDictionaryDetailService, translationService domainTranslation.ITranslationService, loggerInstance *logger.Logger) IIDictionaryDetailController {
+	return &DictionaryDetailController{dictionaryService: dictionaryService, translationService: translationService, Logger: loggerInstance}
 }
 
 // CreateDictionary
@@ -100,10 +102,11 @@ func (c *DictionaryDetailController) NewDictionary(ctx *gin.Context) {
 
 // GetAllDictionaries
 // @Summary get all dictionaries by
-// @Description get  all dictionaries by where
+// @Description get  all dictionaries by where, labels translated by Accept-Language
 // @Tags dictionaries
 // @Accept json
 // @Produce json
+// @Param Accept-Language header string false "preferred languages, e.g. zh-CN,zh;q=0.9,en;q=0.8"
 // @Success 200 {object} domain.CommonResponse[[]domainDictionary.Dictionary]
 // @Router /v1/dictionary [get]
 func (c *DictionaryDetailController) GetAllDictionaries(ctx *gin.Context) {
@@ -116,6 +119,7 @@ func (c *DictionaryDetailController) GetAllDictionaries(ctx *gin.Context) {
 		return
 	}
 	c.Logger.Info("Successfully retrieved all dictionaries", zap.Int("count", len(*dictionaries)))
+	c.translateLabels(ctx, *dictionaries)
 	ctx.JSON(http.StatusOK, domain.CommonResponse[*[]domainDictionary.DictionaryDetail]{
 		Data: dictionaries,
 	})
@@ -228,10 +232,11 @@ func (c *DictionaryDetailController) DeleteDictionary(ctx *gin.Context) {
 
 // SearchDictionaryPageList
 // @Summary search dictionaries
-// @Description search dictionaries by query
+// @Description search dictionaries by query, labels translated by Accept-Language
 // @Tags search dictionaries
 // @Accept json
 // @Produce json
+// @Param Accept-Language header string false "preferred languages, e.g. zh-CN,zh;q=0.9,en;q=0.8"
 // @Success 200 {object} domain.PageList[[]ResponseDictionary]
 // @Router /v1/dictionary/search [get]
 func (c *DictionaryDetailController) SearchPaginated(ctx *gin.Context) {
@@ -316,6 +321,7 @@ func (c *DictionaryDetailController) SearchPaginated(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	c.translateLabels(ctx, *result.Data)
 	type PageResult = domain.PageList[*[]*ResponseDictionary]
 	response := controllers.NewCommonResponseBuilder[PageResult]().
 		Data(PageResult{
@@ -417,6 +423,11 @@ func (c *DictionaryDetailController) DeleteDictionaryDetails(ctx *gin.Context) {
 	})
 }
 
+func (c *DictionaryDetailController) translateLabels(ctx *gin.Context, details []domainDictionary.DictionaryDetail) {
+	locale := c.translationService.ResolveLocale(ctx.GetHeader("Accept-Language"))
+	domainDictionary.TranslateLabels(details, c.translationService, locale)
+}
+
 // Mappers
 func domainToResponseMapper(domainDictionary *domainDictionary.DictionaryDetail) *ResponseDictionary {
 
@@ -0,0 +1,107 @@
+package invitation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	invitationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/invitation"
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainInvitation "github.com/gbrayhan/microservices-go/src/domain/sys/invitation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type IInvitationController interface {
+	CreateInvitation(ctx *gin.Context)
+	GetInvitations(ctx *gin.Context)
+	RevokeInvitation(ctx *gin.Context)
+}
+
+type InvitationController struct {
+	invitationService invitationUseCase.ISysInvitationService
+	Logger            *logger.Logger
+}
+
+func NewInvitationController(invitationService invitationUseCase.ISysInvitationService, loggerInstance *logger.Logger) IInvitationController {
+	return &InvitationController{invitationService: invitationService, Logger: loggerInstance}
+}
+
+// CreateInvitation
+// @Summary create invitation
+// @Description invite an email address to register with the given role, the token is only returned once
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param book body invitationUseCase.CreateInvitationRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainInvitation.CreatedInvitation]
+// @Router /v1/invitation [post]
+func (c *InvitationController) CreateInvitation(ctx *gin.Context) {
+	userId, ok := controllers.NewAppUtils(ctx).GetUserID()
+	if !ok {
+		_ = ctx.Error(domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated))
+		return
+	}
+	var request invitationUseCase.CreateInvitationRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for new invitation", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	created, err := c.invitationService.Create(int64(userId), request)
+	if err != nil {
+		c.Logger.Error("Error creating invitation", zap.Error(err), zap.String("email", request.Email))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainInvitation.CreatedInvitation]{
+		Data:    created,
+		Message: "success",
+	})
+}
+
+// GetInvitations
+// @Summary get invitations
+// @Description list all invitations
+// @Tags invitation
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainInvitation.Invitation]
+// @Router /v1/invitation [get]
+func (c *InvitationController) GetInvitations(ctx *gin.Context) {
+	invitations, err := c.invitationService.GetAll()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*[]domainInvitation.Invitation]{
+		Data:    invitations,
+		Message: "success",
+	})
+}
+
+// RevokeInvitation
+// @Summary revoke invitation
+// @Description revoke an invitation that has not been used yet
+// @Tags invitation
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[int]
+// @Router /v1/invitation/{id} [delete]
+func (c *InvitationController) RevokeInvitation(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError))
+		return
+	}
+	if err := c.invitationService.Revoke(id); err != nil {
+		c.Logger.Error("Error revoking invitation", zap.Error(err), zap.Int64("id", id))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int64]{
+		Data:    id,
+		Message: "success",
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	domainMenu "github.com/gbrayhan/microservices-go/src/domain/sys/menu"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
 	"github.com/gin-gonic/gin"
@@ -48,16 +50,20 @@ type IMenuController interface {
 	GetAllMenus(ctx *gin.Context)
 	GetMenusByID(ctx *gin.Context)
 	UpdateMenu(ctx *gin.Context)
+	MoveMenus(ctx *gin.Context)
+	GetMenuApis(ctx *gin.Context)
+	UpdateMenuApis(ctx *gin.Context)
 	DeleteMenu(ctx *gin.Context)
 	GetUserMenus(ctx *gin.Context)
 }
 type MenuController struct {
-	menuService domainMenu.IMenuService
-	Logger      *logger.Logger
+	menuService        domainMenu.IMenuService
+	translationService domainTranslation.ITranslationService
+	Logger             *logger.Logger
 }
 
-func NewMenuController(menuService domainMenu.IMenuService, loggerInstance *logger.Logger) IMenuController {
-	return &MenuController{menuService: menuService, Logger: loggerInstance}
+func NewMenuController(menuService domainMenu.IMenuService, translationService domainTranslation.ITranslationService, loggerInstance *logger.Logger) IMenuController {
+	return &MenuController{menuService: menuService, translationService: translationService, Logger: loggerInstance}
 }
 
 // CreateMenu
@@ -201,6 +207,103 @@ func (c *MenuController) UpdateMenu(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// MoveMenus
+// @Summary move and reorder menus
+// @Description place the given menu subtree under parent_id; parent, sort (position among siblings) and menu_level are updated in one transaction, cycles and moves across menu groups are rejected
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param book body domainMenu.MenuMoveRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[[]domainMenu.Menu]
+// @Router /v1/menu/tree [put]
+func (c *MenuController) MoveMenus(ctx *gin.Context) {
+	var request domainMenu.MenuMoveRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for menu move", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	menus, err := c.menuService.Move(request)
+	if err != nil {
+		c.Logger.Error("Error moving menus", zap.Error(err), zap.Int("parentId", request.ParentID))
+		_ = ctx.Error(err)
+		return
+	}
+	if menus == nil {
+		menus = []*domainMenu.Menu{}
+	}
+	c.Logger.Info("Menus moved successfully", zap.Int("groupId", request.MenuGroupId), zap.Int("parentId", request.ParentID))
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[[]*domainMenu.Menu]().
+		Data(menus).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetMenuApis
+// @Summary get menu apis
+// @Description apis required by the menu and by each of its buttons
+// @Tags menu
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainMenu.MenuApis]
+// @Router /v1/menu/{id}/apis [get]
+func (c *MenuController) GetMenuApis(ctx *gin.Context) {
+	menuID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("menu id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	menuApis, err := c.menuService.GetMenuApis(menuID)
+	if err != nil {
+		c.Logger.Error("Error getting menu apis", zap.Error(err), zap.Int64("id", menuID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainMenu.MenuApis]{
+		Data:    menuApis,
+		Message: "success",
+	})
+}
+
+// UpdateMenuApis
+// @Summary replace menu apis
+// @Description replace the apis required by the menu (apis) and its buttons (buttons: button id -> api ids); roles granted the menu or a button get or lose the matching api policies immediately
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param book body domainMenu.MenuApis true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainMenu.MenuApis]
+// @Router /v1/menu/{id}/apis [put]
+func (c *MenuController) UpdateMenuApis(ctx *gin.Context) {
+	menuID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("menu id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	var request domainMenu.MenuApis
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for menu apis", zap.Error(err), zap.Int64("id", menuID))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	request.MenuID = menuID
+	if err := c.menuService.UpdateMenuApis(&request); err != nil {
+		c.Logger.Error("Error updating menu apis", zap.Error(err), zap.Int64("id", menuID))
+		_ = ctx.Error(err)
+		return
+	}
+	menuApis, err := c.menuService.GetMenuApis(menuID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Menu apis updated successfully", zap.Int64("id", menuID))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainMenu.MenuApis]{
+		Data:    menuApis,
+		Message: "success",
+	})
+}
+
 // DeleteMenu
 // @Summary delete menu
 // @Description delete menu by id
@@ -234,22 +337,26 @@ func (c *MenuController) DeleteMenu(ctx *gin.Context) {
 
 // GetUserMenus
 // @Summary get user menus
-// @Description user menus
+// @Description user menus, cached per role set; send the returned ETag in If-None-Match to get 304 while the menus are unchanged.
+// @Description Menu titles are translated to the best match of Accept-Language when a translation exists.
 // @Tags menus
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag of the menus already held by the client"
+// @Param Accept-Language header string false "preferred languages, e.g. zh-CN,zh;q=0.9,en;q=0.8"
 // @Success 200 {array} models.User
+// @Success 304 "menus not modified"
 // @Router /v1/menu/user [get]
 func (c *MenuController) GetUserMenus(ctx *gin.Context) {
 	c.Logger.Info("Getting user menus")
 	isGetAll := ctx.Query("all") == "true"
-	var roleID int64
+	var roleIDs []int64
 
 	// get user menu available login after
 	if !isGetAll {
 		var ok bool
 		appUtils := controllers.NewAppUtils(ctx)
-		roleID, ok = appUtils.GetRoleID()
+		roleIDs, ok = appUtils.GetRoleIDs()
 		if !ok {
 			// no login send empty to front
 			menuResponse := controllers.NewCommonResponseBuilder[[]*domainMenu.MenuGroup]().
@@ -262,24 +369,44 @@ func (c *MenuController) GetUserMenus(ctx *gin.Context) {
 		}
 	}
 
-	menus, err := c.menuService.GetUserMenus(roleID)
+	locale := c.translationService.ResolveLocale(ctx.GetHeader("Accept-Language"))
+	userMenus, err := c.menuService.GetCachedUserMenus(roleIDs, locale)
 	if err != nil {
 		c.Logger.Error("Error getting all menu user", zap.Error(err))
 		appError := domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
 		_ = ctx.Error(appError)
 		return
 	}
-	if menus == nil {
-		menus = []*domainMenu.MenuGroup{}
+	// 浏览器每次都带 If-None-Match 校验，菜单未变化时返回 304
+	ctx.Header("ETag", userMenus.ETag)
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Header("Vary", "Accept-Language")
+	if etagMatches(ctx.GetHeader("If-None-Match"), userMenus.ETag) {
+		ctx.Status(http.StatusNotModified)
+		return
 	}
 	menuResponse := controllers.NewCommonResponseBuilder[[]*domainMenu.MenuGroup]().
-		Data(menus).
+		Data(userMenus.Groups).
 		Message("success").
 		Status(0).
 		Build()
 	ctx.JSON(http.StatusOK, menuResponse)
 }
 
+// etagMatches 按 If-None-Match 的弱比较规则判断，支持逗号分隔的多个 ETag 和 *
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
 // Mappers
 func domainToResponseMapper(domainMenu *domainMenu.Menu) *ResponseMenu {
 
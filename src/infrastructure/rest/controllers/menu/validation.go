@@ -3,15 +3,16 @@ package menu
 import "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
 
 var customRules = map[string]string{
-	"component":  "required,lt=191",
-	"title":      "required,lt=191",
-	"name":       "required,lt=191",
-	"path":       "required,lt=191",
-	"hidden":     "omitempty",
-	"keep_alive": "omitempty",
-	"parent_id":  "omitempty,min=0",
-	"icon":       "required,lt=191",
-	"sort":       "omitempty",
+	"component":     "required,lt=191",
+	"title":         "required,lt=191",
+	"name":          "required,lt=191",
+	"path":          "required,lt=191",
+	"hidden":        "omitempty",
+	"keep_alive":    "omitempty",
+	"parent_id":     "omitempty,min=0",
+	"icon":          "required,lt=191",
+	"sort":          "omitempty",
+	"menu_group_id": "omitempty,min=1",
 }
 
 func updateValidation(request map[string]any) error {
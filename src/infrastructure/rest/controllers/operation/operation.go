@@ -190,8 +190,9 @@ func (c *OperationController) SearchPaginated(ctx *gin.Context) {
 
 	// Build filters
 	filters := domain.DataFilters{
-		Page:     page,
-		PageSize: pageSize,
+		Page:      page,
+		PageSize:  pageSize,
+		DataScope: controllers.NewAppUtils(ctx).GetDataScope(),
 	}
 
 	// Parse like filters
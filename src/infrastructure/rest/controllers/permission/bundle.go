@@ -0,0 +1,84 @@
+package permission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+// ExportBundle
+// @Summary export permission bundle
+// @Description download menus, menu groups, buttons, parameters, apis, roles and their bindings as json or yaml
+// @Tags permission
+// @Produce json
+// @Param format query string false "json (default) or yaml"
+// @Success 200 {object} domainPermission.Bundle
+// @Router /v1/permission/bundle [get]
+func (c *PermissionController) ExportBundle(ctx *gin.Context) {
+	bundle, err := c.permissionService.ExportBundle()
+	if err != nil {
+		c.Logger.Error("Error exporting permission bundle", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	filename := fmt.Sprintf("permission-bundle-%s", bundle.ExportedAt.Format("20060102150405"))
+	if ctx.Query("format") == "yaml" {
+		content, err := yaml.Marshal(bundle)
+		if err != nil {
+			_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.UnknownError))
+			return
+		}
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.yaml", filename))
+		ctx.Data(http.StatusOK, "application/x-yaml", content)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+	ctx.JSON(http.StatusOK, bundle)
+}
+
+// ImportBundle
+// @Summary import permission bundle
+// @Description create or update by natural keys; the body is json, or yaml when the content type or format query says so
+// @Tags permission
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "only report the diff"
+// @Param format query string false "json (default) or yaml"
+// @Success 200 {object} domain.CommonResponse[domainPermission.BundleImportResult]
+// @Router /v1/permission/bundle/import [post]
+func (c *PermissionController) ImportBundle(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	var bundle domainPermission.Bundle
+	if ctx.Query("format") == "yaml" || strings.Contains(ctx.ContentType(), "yaml") {
+		err = yaml.Unmarshal(body, &bundle)
+	} else {
+		err = json.Unmarshal(body, &bundle)
+	}
+	if err != nil {
+		c.Logger.Error("Error decoding permission bundle", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	dryRun := ctx.Query("dry_run") == "true"
+	operatorRoleIds, _ := controllers.NewAppUtils(ctx).GetRoleIDs()
+	result, err := c.permissionService.ImportBundle(&bundle, dryRun, operatorRoleIds)
+	if err != nil {
+		c.Logger.Error("Error importing permission bundle", zap.Error(err), zap.Bool("dryRun", dryRun))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainPermission.BundleImportResult]{Data: result, Message: "success"})
+}
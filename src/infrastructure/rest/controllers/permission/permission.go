@@ -0,0 +1,119 @@
+package permission
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainPermission "github.com/gbrayhan/microservices-go/src/domain/sys/permission"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type IPermissionController interface {
+	CheckPermission(ctx *gin.Context)
+	GetApiRoles(ctx *gin.Context)
+	CheckConsistency(ctx *gin.Context)
+	RepairConsistency(ctx *gin.Context)
+	ExportBundle(ctx *gin.Context)
+	ImportBundle(ctx *gin.Context)
+}
+
+type PermissionController struct {
+	permissionService domainPermission.IPermissionService
+	Logger            *logger.Logger
+}
+
+func NewPermissionController(permissionService domainPermission.IPermissionService, loggerInstance *logger.Logger) IPermissionController {
+	return &PermissionController{permissionService: permissionService, Logger: loggerInstance}
+}
+
+// CheckPermission
+// @Summary simulate permission check
+// @Description report whether a role or user can call method/path and the matching policy
+// @Tags permission
+// @Accept json
+// @Produce json
+// @Param book body domainPermission.CheckRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainPermission.CheckResult]
+// @Router /v1/permission/check [post]
+func (c *PermissionController) CheckPermission(ctx *gin.Context) {
+	var request domainPermission.CheckRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for permission check", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	result, err := c.permissionService.Check(request)
+	if err != nil {
+		c.Logger.Error("Error checking permission", zap.Error(err), zap.String("path", request.Path))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*domainPermission.CheckResult]().
+		Data(result).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetApiRoles
+// @Summary get roles of api
+// @Description list the roles that can access a sys api
+// @Tags permission
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainPermission.ApiAccess]
+// @Router /v1/permission/api/{id}/roles [get]
+func (c *PermissionController) GetApiRoles(ctx *gin.Context) {
+	apiID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid api ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError))
+		return
+	}
+	access, err := c.permissionService.GetApiRoles(apiID)
+	if err != nil {
+		c.Logger.Error("Error getting api roles", zap.Error(err), zap.Int("id", apiID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainPermission.ApiAccess]{Data: access, Message: "success"})
+}
+
+// CheckConsistency
+// @Summary check casbin consistency
+// @Description list casbin_rule rows whose role or api no longer exists and whether the enforcer matches the table
+// @Tags permission
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainPermission.ConsistencyReport]
+// @Router /v1/permission/consistency [get]
+func (c *PermissionController) CheckConsistency(ctx *gin.Context) {
+	report, err := c.permissionService.CheckConsistency()
+	if err != nil {
+		c.Logger.Error("Error checking casbin consistency", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainPermission.ConsistencyReport]{Data: report, Message: "success"})
+}
+
+// RepairConsistency
+// @Summary repair casbin consistency
+// @Description delete drifted casbin_rule rows and reload the enforcer
+// @Tags permission
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainPermission.ConsistencyReport]
+// @Router /v1/permission/consistency/repair [post]
+func (c *PermissionController) RepairConsistency(ctx *gin.Context) {
+	report, err := c.permissionService.RepairConsistency()
+	if err != nil {
+		c.Logger.Error("Error repairing casbin consistency", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainPermission.ConsistencyReport]{Data: report, Message: "success"})
+}
@@ -23,25 +23,38 @@ type NewRoleRequest struct {
 	Label       string `json:"label"`
 	Status      int16  `json:"status"`
 	Description string `json:"description"`
+	IsSuper     bool   `json:"is_super"`
+	// DataScope 数据权限范围，默认 all
+	DataScope       string              `json:"data_scope"`
+	DataScopeFilter map[string][]string `json:"data_scope_filter"`
 }
 
 type ResponseRole struct {
-	ID            int64             `json:"id"`
-	Name          string            `json:"name"`
-	ParentID      int64             `json:"parent_id"`
-	Order         int64             `json:"order"`
-	Label         string            `json:"label"`
-	Status        int16             `json:"status"`
-	Description   string            `json:"description"`
-	DefaultRouter string            `json:"default_router"`
-	CreatedAt     domain.CustomTime `json:"created_at,omitempty"`
-	UpdatedAt     domain.CustomTime `json:"updated_at,omitempty"`
+	ID              int64               `json:"id"`
+	Name            string              `json:"name"`
+	ParentID        int64               `json:"parent_id"`
+	Order           int64               `json:"order"`
+	Label           string              `json:"label"`
+	Status          int16               `json:"status"`
+	Description     string              `json:"description"`
+	DefaultRouter   string              `json:"default_router"`
+	IsSuper         bool                `json:"is_super"`
+	DataScope       string              `json:"data_scope"`
+	DataScopeFilter map[string][]string `json:"data_scope_filter,omitempty"`
+	CreatedAt       domain.CustomTime   `json:"created_at,omitempty"`
+	UpdatedAt       domain.CustomTime   `json:"updated_at,omitempty"`
 }
 
 type MenuRoleResponse struct {
 	RoleMenus map[int][]int     `json:"role_menus"`
 	RoleBtns  map[int64][]int64 `json:"role_btns"`
 	RoleApis  []string          `json:"role_apis"`
+	// EffectiveApis 包含从父角色继承的接口权限
+	EffectiveApis []string `json:"effective_apis"`
+	// RoleFields 各资源上对该角色隐藏的字段
+	RoleFields map[string][]string `json:"role_fields"`
+	// ApiGrants 仅在 grants=true 时返回，区分直接绑定的接口和由菜单、按钮带来的接口
+	ApiGrants []domainRole.ApiGrant `json:"api_grants,omitempty"`
 }
 type IRoleController interface {
 	NewRole(ctx *gin.Context)
@@ -54,6 +67,16 @@ type IRoleController interface {
 	UpdateRoleMenuIds(ctx *gin.Context)
 	BindApiRule(ctx *gin.Context)
 	BindRoleMenuBtns(ctx *gin.Context)
+	BindRoleFields(ctx *gin.Context)
+	GetMaskableFields(ctx *gin.Context)
+	CloneRole(ctx *gin.Context)
+	GetTemplates(ctx *gin.Context)
+	GetTemplate(ctx *gin.Context)
+	NewTemplate(ctx *gin.Context)
+	UpdateTemplate(ctx *gin.Context)
+	DeleteTemplate(ctx *gin.Context)
+	DiffTemplate(ctx *gin.Context)
+	ApplyTemplate(ctx *gin.Context)
 }
 type RoleController struct {
 	roleService domainRole.IRoleService
@@ -82,6 +105,12 @@ func (c *RoleController) NewRole(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
+	if request.IsSuper {
+		if err := c.requireSuper(ctx); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
 	roleModel, err := c.roleService.Create(toUsecaseMapper(&request))
 	if err != nil {
 		c.Logger.Error("Error creating role", zap.Error(err), zap.String("Name", request.Name))
@@ -186,6 +215,12 @@ func (c *RoleController) UpdateRole(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	if _, ok := requestMap["is_super"]; ok {
+		if err := c.requireSuper(ctx); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
 	roleUpdated, err := c.roleService.Update(roleID, requestMap)
 	if err != nil {
 		c.Logger.Error("Error updating role", zap.Error(err), zap.Int("id", roleID))
@@ -263,6 +298,12 @@ func (c *RoleController) GetTreeRoles(ctx *gin.Context) {
 }
 
 // GetRoleSetting
+// @Summary get role menus, buttons, apis and hidden fields
+// @Description with grants=true the response also lists every api policy of the role with whether it was bound directly and which granted menus or buttons imply it
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[MenuRoleResponse]
+// @Router /v1/role/{id}/setting [get]
 func (c *RoleController) GetRoleSetting(ctx *gin.Context) {
 	roleID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
@@ -291,11 +332,35 @@ func (c *RoleController) GetRoleSetting(ctx *gin.Context) {
 	if rules == nil {
 		rules = []string{}
 	}
+	effectiveRules, err := c.roleService.GetEffectiveApiRules(roleID)
+	if err != nil {
+		c.Logger.Error("Error getting effective apis", zap.Error(err), zap.Int("id", roleID))
+		_ = ctx.Error(err)
+		return
+	}
+	roleFields, err := c.roleService.GetRoleFields(int64(roleID))
+	if err != nil {
+		c.Logger.Error("Error getting role fields", zap.Error(err), zap.Int("id", roleID))
+		_ = ctx.Error(err)
+		return
+	}
+	var apiGrants []domainRole.ApiGrant
+	if ctx.Query("grants") == "true" {
+		apiGrants, err = c.roleService.GetApiGrants(int64(roleID))
+		if err != nil {
+			c.Logger.Error("Error getting role api grants", zap.Error(err), zap.Int("id", roleID))
+			_ = ctx.Error(err)
+			return
+		}
+	}
 	response := controllers.NewCommonResponseBuilder[MenuRoleResponse]().
 		Data(MenuRoleResponse{
-			RoleMenus: roleMenus,
-			RoleBtns:  roleBtns,
-			RoleApis:  rules,
+			RoleMenus:     roleMenus,
+			RoleBtns:      roleBtns,
+			RoleApis:      rules,
+			EffectiveApis: effectiveRules,
+			RoleFields:    roleFields,
+			ApiGrants:     apiGrants,
 		}).
 		Message("success").
 		Status(0).
@@ -414,29 +479,100 @@ func (c *RoleController) BindRoleMenuBtns(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// BindRoleFields
+// @Summary bind role hidden fields
+// @Description replace the response fields hidden from a role on one resource
+// @Tags role
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[bool]
+// @Router /v1/role/{id}/fields [post]
+func (c *RoleController) BindRoleFields(ctx *gin.Context) {
+	roleID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid role ID parameter for bind role fields", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	var requestMap map[string]any
+	if err = controllers.BindJSONMap(ctx, &requestMap); err != nil {
+		c.Logger.Error("Error binding JSON for role fields update", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	if err = c.roleService.BindRoleFields(int64(roleID), requestMap); err != nil {
+		c.Logger.Error("Error updating role fields", zap.Error(err), zap.Int("id", roleID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Role fields updated successfully", zap.Int("id", roleID))
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[bool]().
+		Data(true).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetMaskableFields
+// @Summary get maskable fields
+// @Description fields that can be hidden per role, grouped by resource
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[map[string][]string]
+// @Router /v1/role/fields [get]
+func (c *RoleController) GetMaskableFields(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, domain.CommonResponse[map[string][]string]{
+		Data:    domain.MaskableFields,
+		Message: "success",
+	})
+}
+
 // Mappers
 func domainToResponseMapper(domainRole *domainRole.Role) *ResponseRole {
 	return &ResponseRole{
-		ID:            domainRole.ID,
-		Name:          domainRole.Name,
-		ParentID:      domainRole.ParentID,
-		Order:         domainRole.Order,
-		Label:         domainRole.Label,
-		Description:   domainRole.Description,
-		Status:        domainRole.Status,
-		DefaultRouter: domainRole.DefaultRouter,
-		CreatedAt:     domainRole.CreatedAt,
-		UpdatedAt:     domainRole.UpdatedAt,
+		ID:              domainRole.ID,
+		Name:            domainRole.Name,
+		ParentID:        domainRole.ParentID,
+		Order:           domainRole.Order,
+		Label:           domainRole.Label,
+		Description:     domainRole.Description,
+		Status:          domainRole.Status,
+		DefaultRouter:   domainRole.DefaultRouter,
+		IsSuper:         domainRole.IsSuper,
+		DataScope:       domainRole.DataScope,
+		DataScopeFilter: domainRole.DataScopeFilter,
+		CreatedAt:       domainRole.CreatedAt,
+		UpdatedAt:       domainRole.UpdatedAt,
 	}
 }
 
 func toUsecaseMapper(req *NewRoleRequest) *domainRole.Role {
 	return &domainRole.Role{
-		Name:        req.Name,
-		ParentID:    req.ParentID,
-		Description: req.Description,
-		Order:       req.Order,
-		Label:       req.Label,
-		Status:      req.Status,
+		Name:            req.Name,
+		ParentID:        req.ParentID,
+		Description:     req.Description,
+		Order:           req.Order,
+		Label:           req.Label,
+		Status:          req.Status,
+		IsSuper:         req.IsSuper,
+		DataScope:       req.DataScope,
+		DataScopeFilter: req.DataScopeFilter,
+	}
+}
+
+// requireSuper 只有超级管理员可以授予或取消角色的超级管理员标记
+func (c *RoleController) requireSuper(ctx *gin.Context) error {
+	roleIds, ok := controllers.NewAppUtils(ctx).GetRoleIDs()
+	if !ok {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotAuthenticated)
+	}
+	isSuper, err := c.roleService.IsSuperRole(roleIds...)
+	if err != nil {
+		return err
+	}
+	if !isSuper {
+		return domainErrors.NewAppError(errors.New("only super administrators can change the super flag"), domainErrors.NotAuthorized)
 	}
+	return nil
 }
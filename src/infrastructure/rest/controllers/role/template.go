@@ -0,0 +1,248 @@
+package role
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CloneRoleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	ParentID    int64  `json:"parent_id"`
+	Order       int64  `json:"order"`
+}
+
+type TemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	// RoleID 不为 0 时忽略 Permissions，以该角色当前权限创建模板
+	RoleID      int64                    `json:"role_id"`
+	Permissions domainRole.PermissionSet `json:"permissions"`
+}
+
+// CloneRole
+// @Summary clone role
+// @Description create a role with the menus, buttons, api rules and hidden fields of another role
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param book body CloneRoleRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[ResponseRole]
+// @Router /v1/role/{id}/clone [post]
+func (c *RoleController) CloneRole(ctx *gin.Context) {
+	roleID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError))
+		return
+	}
+	var request CloneRoleRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for role clone", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	role, err := c.roleService.CloneRole(roleID, &domainRole.Role{
+		Name:        request.Name,
+		Label:       request.Label,
+		Description: request.Description,
+		ParentID:    request.ParentID,
+		Order:       request.Order,
+	})
+	if err != nil {
+		c.Logger.Error("Error cloning role", zap.Error(err), zap.Int64("sourceId", roleID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Role cloned successfully", zap.Int64("sourceId", roleID), zap.Int64("id", role.ID))
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*ResponseRole]().
+		Data(domainToResponseMapper(role)).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetTemplates
+// @Summary list permission templates
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainRole.PermissionTemplate]
+// @Router /v1/role/templates [get]
+func (c *RoleController) GetTemplates(ctx *gin.Context) {
+	templates, err := c.roleService.GetTemplates()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[[]domainRole.PermissionTemplate]{
+		Data:    templates,
+		Message: "success",
+	})
+}
+
+// GetTemplate
+// @Summary get permission template
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainRole.PermissionTemplate]
+// @Router /v1/role/templates/{id} [get]
+func (c *RoleController) GetTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("template id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	template, err := c.roleService.GetTemplate(templateID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainRole.PermissionTemplate]{
+		Data:    template,
+		Message: "success",
+	})
+}
+
+// NewTemplate
+// @Summary create permission template
+// @Description create a template from explicit permissions or from the current permissions of role_id
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param book body TemplateRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainRole.PermissionTemplate]
+// @Router /v1/role/templates [post]
+func (c *RoleController) NewTemplate(ctx *gin.Context) {
+	var request TemplateRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for permission template", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	template, err := c.roleService.CreateTemplate(&domainRole.PermissionTemplate{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: request.Permissions,
+	}, request.RoleID)
+	if err != nil {
+		c.Logger.Error("Error creating permission template", zap.Error(err), zap.String("name", request.Name))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainRole.PermissionTemplate]{
+		Data:    template,
+		Message: "success",
+	})
+}
+
+// UpdateTemplate
+// @Summary update permission template
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param book body TemplateRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainRole.PermissionTemplate]
+// @Router /v1/role/templates/{id} [put]
+func (c *RoleController) UpdateTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("template id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	var request TemplateRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for permission template", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	template, err := c.roleService.UpdateTemplate(templateID, &domainRole.PermissionTemplate{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: request.Permissions,
+	})
+	if err != nil {
+		c.Logger.Error("Error updating permission template", zap.Error(err), zap.Int64("id", templateID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainRole.PermissionTemplate]{
+		Data:    template,
+		Message: "success",
+	})
+}
+
+// DeleteTemplate
+// @Summary delete permission template
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[int64]
+// @Router /v1/role/templates/{id} [delete]
+func (c *RoleController) DeleteTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("template id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	if err := c.roleService.DeleteTemplate(templateID); err != nil {
+		c.Logger.Error("Error deleting permission template", zap.Error(err), zap.Int64("id", templateID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int64]{
+		Data:    templateID,
+		Message: "resource deleted successfully",
+	})
+}
+
+// DiffTemplate
+// @Summary diff role against permission template
+// @Description permissions the role would gain and lose if the template were applied with mode replace (default) or merge
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainRole.PermissionDiff]
+// @Router /v1/role/{id}/templates/{templateId}/diff [get]
+func (c *RoleController) DiffTemplate(ctx *gin.Context) {
+	c.handleTemplate(ctx, c.roleService.DiffTemplate)
+}
+
+// ApplyTemplate
+// @Summary apply permission template to role
+// @Description mode replace (default) overwrites the role permissions, merge adds the template permissions to them
+// @Tags role
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainRole.PermissionDiff]
+// @Router /v1/role/{id}/templates/{templateId}/apply [post]
+func (c *RoleController) ApplyTemplate(ctx *gin.Context) {
+	c.handleTemplate(ctx, c.roleService.ApplyTemplate)
+}
+
+func (c *RoleController) handleTemplate(ctx *gin.Context, action func(roleId int64, templateId int64, mode string) (*domainRole.PermissionDiff, error)) {
+	roleID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("param id is necessary"), domainErrors.ValidationError))
+		return
+	}
+	templateID, err := strconv.ParseInt(ctx.Param("templateId"), 10, 64)
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("template id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	diff, err := action(roleID, templateID, ctx.Query("mode"))
+	if err != nil {
+		c.Logger.Error("Error handling permission template", zap.Error(err), zap.Int64("roleId", roleID), zap.Int64("templateId", templateID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*domainRole.PermissionDiff]{
+		Data:    diff,
+		Message: "success",
+	})
+}
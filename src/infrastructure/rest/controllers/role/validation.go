@@ -10,6 +10,8 @@ var customRules = map[string]string{
 	"description":    "required",
 	"parent_id":      "required,lt=11",
 	"status":         "required,status_enum",
+	"is_super":       "boolean",
+	"data_scope":     "oneof=all self dept custom",
 }
 
 func updateValidation(request map[string]any) error {
@@ -0,0 +1,147 @@
+package translation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Structures
+type TranslationRequest struct {
+	Locale string `json:"locale" binding:"required"`
+	Scope  string `json:"scope" binding:"required,oneof=menu dictionary_detail error"`
+	Key    string `json:"key" binding:"required"`
+	Value  string `json:"value" binding:"required"`
+}
+
+type SaveTranslationsRequest struct {
+	Items []TranslationRequest `json:"items" binding:"required,dive"`
+}
+
+type ITranslationController interface {
+	GetTranslations(ctx *gin.Context)
+	GetLocales(ctx *gin.Context)
+	SaveTranslations(ctx *gin.Context)
+	DeleteTranslation(ctx *gin.Context)
+}
+
+type TranslationController struct {
+	translationService domainTranslation.ITranslationService
+	Logger             *logger.Logger
+}
+
+func NewTranslationController(translationService domainTranslation.ITranslationService, loggerInstance *logger.Logger) ITranslationController {
+	return &TranslationController{translationService: translationService, Logger: loggerInstance}
+}
+
+// GetTranslations
+// @Summary list translations
+// @Description list translations, optionally filtered by locale and scope (menu, dictionary_detail, error)
+// @Tags translation
+// @Accept json
+// @Produce json
+// @Param locale query string false "locale, e.g. zh-CN"
+// @Param scope query string false "menu | dictionary_detail | error"
+// @Success 200 {object} domain.CommonResponse[[]domainTranslation.Translation]
+// @Router /v1/translation [get]
+func (c *TranslationController) GetTranslations(ctx *gin.Context) {
+	translations, err := c.translationService.GetAll(ctx.Query("locale"), ctx.Query("scope"))
+	if err != nil {
+		c.Logger.Error("Error getting translations", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[[]domainTranslation.Translation]().
+		Data(translations).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetLocales
+// @Summary list locales
+// @Description locales that have translations, including the default locale
+// @Tags translation
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]string]
+// @Router /v1/translation/locales [get]
+func (c *TranslationController) GetLocales(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[[]string]().
+		Data(c.translationService.Locales()).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// SaveTranslations
+// @Summary save translations
+// @Description create or overwrite translations by locale, scope and key. Menu keys are menu names, dictionary_detail keys are dictionary detail ids, error keys are the original error messages
+// @Tags translation
+// @Accept json
+// @Produce json
+// @Param book body SaveTranslationsRequest true "JSON Data"
+// @Success 200 {object} domain.CommonResponse[[]domainTranslation.Translation]
+// @Router /v1/translation [put]
+func (c *TranslationController) SaveTranslations(ctx *gin.Context) {
+	var request SaveTranslationsRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for translations", zap.Error(err))
+		_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+		return
+	}
+	items := make([]domainTranslation.Translation, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, domainTranslation.Translation{
+			Locale: item.Locale,
+			Scope:  item.Scope,
+			Key:    item.Key,
+			Value:  item.Value,
+		})
+	}
+	saved, err := c.translationService.Save(items)
+	if err != nil {
+		c.Logger.Error("Error saving translations", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[[]domainTranslation.Translation]().
+		Data(saved).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// DeleteTranslation
+// @Summary delete translation
+// @Description delete translation by id
+// @Tags translation
+// @Produce json
+// @Param id path int true "translation id"
+// @Success 200 {object} domain.CommonResponse[int64]
+// @Router /v1/translation/{id} [delete]
+func (c *TranslationController) DeleteTranslation(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger.Error("Invalid translation ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("translation id is invalid"), domainErrors.ValidationError))
+		return
+	}
+	if err := c.translationService.Delete(id); err != nil {
+		c.Logger.Error("Error deleting translation", zap.Error(err), zap.Int64("id", id))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int64]{
+		Data:    id,
+		Message: "resource deleted successfully",
+		Status:  0,
+	})
+}
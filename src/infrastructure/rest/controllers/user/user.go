@@ -40,6 +40,8 @@ type ResponseUser struct {
 	Roles     []domainRole.Role `json:"roles"`
 	CreatedAt domain.CustomTime `json:"created_at,omitempty"`
 	UpdatedAt domain.CustomTime `json:"updated_at,omitempty"`
+	// OneTimePassword 新建用户的一次性密码，只在创建时返回一次
+	OneTimePassword string `json:"one_time_password,omitempty"`
 }
 
 type IUserController interface {
@@ -67,7 +69,7 @@ func NewUserController(userService domainUser.IUserService, loggerInstance *logg
 
 // CreateUser
 // @Summary create user
-// @Description create user
+// @Description create user, the response carries a one-time password that must be changed at first login
 // @Tags user create
 // @Accept json
 // @Produce json
@@ -89,8 +91,10 @@ func (c *UserController) NewUser(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	response := domainToResponseMapper(userModel, controllers.NewAppUtils(ctx).GetHiddenFields())
+	response.OneTimePassword = userModel.Password
 	userResponse := controllers.NewCommonResponseBuilder[*ResponseUser]().
-		Data(domainToResponseMapper(userModel)).
+		Data(response).
 		Message("success").
 		Status(0).
 		Build()
@@ -108,7 +112,7 @@ func (c *UserController) NewUser(ctx *gin.Context) {
 // @Router /v1/user [get]
 func (c *UserController) GetAllUsers(ctx *gin.Context) {
 	c.Logger.Info("Getting all users")
-	users, err := c.userService.GetAll()
+	users, err := c.userService.GetAll(controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
 		c.Logger.Error("Error getting all users", zap.Error(err))
 		appError := domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
@@ -117,7 +121,7 @@ func (c *UserController) GetAllUsers(ctx *gin.Context) {
 	}
 	c.Logger.Info("Successfully retrieved all users", zap.Int("count", len(*users)))
 	ctx.JSON(http.StatusOK, domain.CommonResponse[*[]*ResponseUser]{
-		Data: arrayDomainToResponseMapper(users),
+		Data: arrayDomainToResponseMapper(users, controllers.NewAppUtils(ctx).GetHiddenFields()),
 	})
 }
 
@@ -138,14 +142,14 @@ func (c *UserController) GetUsersByID(ctx *gin.Context) {
 		return
 	}
 	c.Logger.Info("Getting user by ID", zap.Int("id", userID))
-	user, err := c.userService.GetByID(userID)
+	user, err := c.userService.GetByID(userID, controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
 		c.Logger.Error("Error getting user by ID", zap.Error(err), zap.Int("id", userID))
 		_ = ctx.Error(err)
 		return
 	}
 	c.Logger.Info("Successfully retrieved user by ID", zap.Int("id", userID))
-	ctx.JSON(http.StatusOK, domainToResponseMapper(user))
+	ctx.JSON(http.StatusOK, domainToResponseMapper(user, controllers.NewAppUtils(ctx).GetHiddenFields()))
 }
 
 // UpdateUserInfo
@@ -158,6 +162,11 @@ func (c *UserController) GetUsersByID(ctx *gin.Context) {
 // @Success 200 {array} controllers.CommonResponseBuilder[ResponseUser]
 // @Router /v1/user [put]
 func (c *UserController) UpdateUser(ctx *gin.Context) {
+	// 模拟登录期间不能修改被模拟用户的资料和密码
+	if _, impersonating := controllers.NewAppUtils(ctx).GetImpersonatorID(); impersonating {
+		_ = ctx.Error(domainErrors.NewAppError(controllers.ErrImpersonating, domainErrors.NotAuthorized))
+		return
+	}
 	userID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		c.Logger.Error("Invalid user ID parameter for update", zap.Error(err), zap.String("id", ctx.Param("id")))
@@ -180,14 +189,14 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
-	userUpdated, err := c.userService.Update(int64(userID), requestMap)
+	userUpdated, err := c.userService.Update(int64(userID), requestMap, operatorRoleIds(ctx), controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
 		c.Logger.Error("Error updating user", zap.Error(err), zap.Int("id", userID))
 		_ = ctx.Error(err)
 		return
 	}
 	response := controllers.NewCommonResponseBuilder[*ResponseUser]().
-		Data(domainToResponseMapper(userUpdated)).
+		Data(domainToResponseMapper(userUpdated, controllers.NewAppUtils(ctx).GetHiddenFields())).
 		Message("success").
 		Status(0).
 		Build()
@@ -212,7 +221,7 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 		return
 	}
 	c.Logger.Info("Deleting user", zap.Int("id", userID))
-	err = c.userService.Delete(userID)
+	err = c.userService.Delete(userID, operatorRoleIds(ctx), controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
 		c.Logger.Error("Error deleting user", zap.Error(err), zap.Int("id", userID))
 		_ = ctx.Error(err)
@@ -238,6 +247,7 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 // @Param sortDirection query string false "sortDirection"
 // @Param status_match query string  false "status"
 // @Param user_name_like query string false "userName"
+// @Param deptId_match query string false "deptId"
 // @Success 200 {object} domain.PageList[[]ResponseUser]
 // @Router /v1/user/search [get]
 func (c *UserController) SearchPaginated(ctx *gin.Context) {
@@ -255,8 +265,9 @@ func (c *UserController) SearchPaginated(ctx *gin.Context) {
 
 	// Build filters
 	filters := domain.DataFilters{
-		Page:     page,
-		PageSize: pageSize,
+		Page:      page,
+		PageSize:  pageSize,
+		DataScope: controllers.NewAppUtils(ctx).GetDataScope(),
 	}
 
 	// Parse like filters
@@ -275,6 +286,9 @@ func (c *UserController) SearchPaginated(ctx *gin.Context) {
 			matches[field] = values
 		}
 	}
+	if values := ctx.QueryArray(user.DeptFilterField + "_match"); len(values) > 0 {
+		matches[user.DeptFilterField] = values
+	}
 	filters.Matches = matches
 
 	// Parse date range filters
@@ -316,6 +330,12 @@ func (c *UserController) SearchPaginated(ctx *gin.Context) {
 		filters.SortDirection = sortDirection
 	}
 
+	if field, hidden := hiddenFilterField(filters, controllers.NewAppUtils(ctx).GetHiddenFields()); hidden {
+		c.Logger.Warn("Filter on hidden field rejected", zap.String("field", field))
+		_ = ctx.Error(domainErrors.NewAppError(errHiddenField, domainErrors.ValidationError))
+		return
+	}
+
 	result, err := c.userService.SearchPaginated(filters)
 	if err != nil {
 		c.Logger.Error("Error searching users", zap.Error(err))
@@ -325,7 +345,7 @@ func (c *UserController) SearchPaginated(ctx *gin.Context) {
 	type PageResult = domain.PageList[*[]*ResponseUser]
 	response := controllers.NewCommonResponseBuilder[PageResult]().
 		Data(PageResult{
-			List:       arrayDomainToResponseMapper(result.Data),
+			List:       arrayDomainToResponseMapper(result.Data, controllers.NewAppUtils(ctx).GetHiddenFields()),
 			Total:      result.Total,
 			Page:       result.Page,
 			PageSize:   result.PageSize,
@@ -375,8 +395,13 @@ func (c *UserController) SearchByProperty(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
+	if controllers.NewAppUtils(ctx).GetHiddenFields().Hidden(property) {
+		c.Logger.Warn("Search on hidden field rejected", zap.String("property", property))
+		_ = ctx.Error(domainErrors.NewAppError(errHiddenField, domainErrors.ValidationError))
+		return
+	}
 
-	coincidences, err := c.userService.SearchByProperty(property, searchText)
+	coincidences, err := c.userService.SearchByProperty(property, searchText, controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
 		c.Logger.Error("Error searching by property", zap.Error(err), zap.String("property", property))
 		_ = ctx.Error(err)
@@ -413,7 +438,7 @@ func (c *UserController) UserBindRoles(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
-	err = c.userService.UserBindRoles(int64(userId), requestMap)
+	err = c.userService.UserBindRoles(int64(userId), requestMap, operatorRoleIds(ctx), controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
 		c.Logger.Error("Error updating  user bind role ", zap.Error(err), zap.Int("id", userId))
 		_ = ctx.Error(err)
@@ -430,10 +455,11 @@ func (c *UserController) UserBindRoles(ctx *gin.Context) {
 
 // ResetPassword
 // @Summary reset password
-// @Description reset password
+// @Description reset password with a one-time password (method=password) or an email reset link (method=link)
 // @Tags password
 // @Accept json
 // @Produce json
+// @Param method query string false "password or link"
 // @Success 200 {object} domain.CommonResponse
 // @Router /v1/user/{id}/reset-password [post]
 func (c *UserController) ResetPassword(ctx *gin.Context) {
@@ -444,18 +470,18 @@ func (c *UserController) ResetPassword(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
-	userModal, err := c.userService.ResetPassword(int64(userId))
+	result, err := c.userService.ResetPassword(int64(userId), ctx.DefaultQuery("method", domainUser.ResetMethodPassword), operatorRoleIds(ctx), controllers.NewAppUtils(ctx).GetDataScope())
 	if err != nil {
-		c.Logger.Error("Error updating  user bind role ", zap.Error(err), zap.Int("id", userId))
+		c.Logger.Error("Error resetting user password", zap.Error(err), zap.Int("id", userId))
 		_ = ctx.Error(err)
 		return
 	}
-	response := controllers.NewCommonResponseBuilder[*domainUser.User]().
-		Data(userModal).
+	response := controllers.NewCommonResponseBuilder[*domainUser.ResetPasswordResult]().
+		Data(result).
 		Message("success").
 		Status(0).
 		Build()
-	c.Logger.Info("Role updated successfully", zap.Int("id", userId))
+	c.Logger.Info("Password reset successfully", zap.Int("id", userId))
 	ctx.JSON(http.StatusOK, response)
 }
 
@@ -468,6 +494,11 @@ func (c *UserController) ResetPassword(ctx *gin.Context) {
 // @Success 200 {object} domain.CommonResponse
 // @Router /v1/user/{id}/edit-password [post]
 func (c *UserController) EditPassword(ctx *gin.Context) {
+	// 模拟登录期间不能修改被模拟用户的资料和密码
+	if _, impersonating := controllers.NewAppUtils(ctx).GetImpersonatorID(); impersonating {
+		_ = ctx.Error(domainErrors.NewAppError(controllers.ErrImpersonating, domainErrors.NotAuthorized))
+		return
+	}
 	userId, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		c.Logger.Error("Invalid user ID parameter ", zap.Error(err), zap.String("id", ctx.Param("id")))
@@ -483,7 +514,7 @@ func (c *UserController) EditPassword(ctx *gin.Context) {
 		_ = ctx.Error(appError)
 		return
 	}
-	userModal, err := c.userService.EditPassword(int64(userId), request)
+	userModal, err := c.userService.EditPassword(int64(userId), request, operatorRoleIds(ctx))
 	if err != nil {
 		c.Logger.Error("Error updating  user bind role ", zap.Error(err), zap.Int("id", userId))
 		_ = ctx.Error(err)
@@ -537,9 +568,34 @@ func (c *UserController) ChangePassword(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// errHiddenField 对请求用户隐藏的字段不能用于搜索、过滤和排序，否则可以推测出字段的值
+var errHiddenField = errors.New("field is not visible to the current role")
+
+// hiddenFilterField 返回搜索条件中第一个被隐藏的字段
+func hiddenFilterField(filters domain.DataFilters, hidden domain.FieldMask) (string, bool) {
+	fields := make([]string, 0, len(filters.LikeFilters)+len(filters.Matches)+len(filters.DateRangeFilters)+len(filters.SortBy))
+	for field := range filters.LikeFilters {
+		fields = append(fields, field)
+	}
+	for field := range filters.Matches {
+		fields = append(fields, field)
+	}
+	for _, dateRange := range filters.DateRangeFilters {
+		fields = append(fields, dateRange.Field)
+	}
+	fields = append(fields, filters.SortBy...)
+	for _, field := range fields {
+		if hidden.Hidden(field) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
 // Mappers
-func domainToResponseMapper(domainUser *domainUser.User) *ResponseUser {
-	return &ResponseUser{
+// domainToResponseMapper hidden 中的字段按角色字段权限置空
+func domainToResponseMapper(domainUser *domainUser.User, hidden domain.FieldMask) *ResponseUser {
+	response := &ResponseUser{
 		ID:        domainUser.ID,
 		UserName:  domainUser.UserName,
 		Email:     domainUser.Email,
@@ -552,12 +608,19 @@ func domainToResponseMapper(domainUser *domainUser.User) *ResponseUser {
 		CreatedAt: domain.CustomTime{Time: domainUser.CreatedAt},
 		UpdatedAt: domain.CustomTime{Time: domainUser.UpdatedAt},
 	}
+	if hidden.Hidden("phone") {
+		response.Phone = ""
+	}
+	if hidden.Hidden("email") {
+		response.Email = ""
+	}
+	return response
 }
 
-func arrayDomainToResponseMapper(users *[]domainUser.User) *[]*ResponseUser {
+func arrayDomainToResponseMapper(users *[]domainUser.User, hidden domain.FieldMask) *[]*ResponseUser {
 	res := make([]*ResponseUser, len(*users))
 	for i, u := range *users {
-		res[i] = domainToResponseMapper(&u)
+		res[i] = domainToResponseMapper(&u, hidden)
 	}
 	return &res
 }
@@ -572,3 +635,9 @@ func toUsecaseMapper(req *NewUserRequest) *domainUser.User {
 		Status:    req.Status,
 	}
 }
+
+// operatorRoleIds 当前操作者的角色，API Key 调用时为密钥绑定的角色
+func operatorRoleIds(ctx *gin.Context) []int64 {
+	roleIds, _ := controllers.NewAppUtils(ctx).GetRoleIDs()
+	return roleIds
+}
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// impersonatingContext 模拟登录中的请求，service 为 nil，调用到 service 会 panic
+func impersonatingContext(method, path, body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+	ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+	ctx.Set("user_id", 7)
+	ctx.Set("impersonator_id", int64(1))
+	return ctx
+}
+
+func assertImpersonationRejected(t *testing.T, ctx *gin.Context) {
+	assert.Len(t, ctx.Errors, 1)
+	var appErr *domainErrors.AppError
+	assert.True(t, errors.As(ctx.Errors[0].Err, &appErr))
+	assert.Equal(t, domainErrors.NotAuthorized, appErr.Type)
+	assert.Equal(t, controllers.ErrImpersonating, appErr.Err)
+}
+
+func TestEditPasswordRejectsImpersonation(t *testing.T) {
+	c := NewUserController(nil, &logger.Logger{Log: zap.NewNop()})
+	ctx := impersonatingContext(http.MethodPost, "/v1/user/7/edit-password", `{"oldPasswd":"a","newPasswd":"b"}`)
+	c.EditPassword(ctx)
+	assertImpersonationRejected(t, ctx)
+}
+
+func TestUpdateUserRejectsImpersonation(t *testing.T) {
+	c := NewUserController(nil, &logger.Logger{Log: zap.NewNop()})
+	ctx := impersonatingContext(http.MethodPut, "/v1/user/7", `{"nickName":"x"}`)
+	c.UpdateUser(ctx)
+	assertImpersonationRejected(t, ctx)
+}
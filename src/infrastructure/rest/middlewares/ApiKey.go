@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	apiKeyUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/api_key"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ApiKeyHeader = "X-API-Key"
+	// ApiKeyContextKey 通过API密钥认证的请求在上下文中记录密钥ID
+	ApiKeyContextKey = "api_key_id"
+)
+
+// VerifyApiKey 校验API密钥，通过后以密钥所属用户和角色写入上下文
+func VerifyApiKey(c *gin.Context, rawKey string, apiKeys apiKeyUseCase.ISysApiKeyService) bool {
+	apiKey, err := apiKeys.Authenticate(rawKey, c.Request.URL.Path, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, apiKeyUseCase.ErrInvalidApiKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid api key"})
+		} else if errors.Is(err, apiKeyUseCase.ErrPathNotAllowed) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Api key is not allowed for this path"})
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return false
+	}
+
+	c.Set("user_id", int(apiKey.UserID))
+	c.Set("role_id", apiKey.RoleID)
+	c.Set(ApiKeyContextKey, apiKey.ID)
+	return true
+}
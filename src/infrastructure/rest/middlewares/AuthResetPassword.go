@@ -5,14 +5,14 @@ import (
 	"os"
 	"strings"
 
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/redis/go-redis/v9"
-	"gorm.io/gorm"
 )
 
 // AuthResetPassword 使用重置密码认证中间件
-func AuthResetPassword(redisClient *redis.Client, db *gorm.DB) gin.HandlerFunc {
+func AuthResetPassword(redisClient *redis.Client, blacklist jwt_blacklist.JwtBlacklistRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.Query("token")
 		if tokenString == "" {
@@ -41,7 +41,7 @@ func AuthResetPassword(redisClient *redis.Client, db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// check token if in blacklist
-		exists, err := IsTokenInBlacklist(db, tokenString)
+		exists, err := blacklist.IsJwtInBlacklist(tokenString)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			c.Abort()
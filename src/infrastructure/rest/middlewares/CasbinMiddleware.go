@@ -9,13 +9,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// CasbinMiddleware 创建一个Casbin权限验证中间件
-func CasbinMiddleware(enforcer *casbin.Enforcer) gin.HandlerFunc {
+// SuperRoleChecker 判断角色是否为超级管理员角色
+type SuperRoleChecker interface {
+	IsSuperRole(roleIds ...int64) (bool, error)
+}
+
+// CasbinMiddleware 创建一个Casbin权限验证中间件，任一角色有权限即通过，超级管理员角色跳过接口权限校验
+func CasbinMiddleware(enforcer *casbin.Enforcer, superRoles SuperRoleChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取应用上下文
 		appCtx := controllers.NewAppUtils(c)
 		// 获取角色ID
-		roleId, ok := appCtx.GetRoleID()
+		roleIds, ok := appCtx.GetRoleIDs()
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "Unauthorized - Role not found",
@@ -23,31 +28,42 @@ func CasbinMiddleware(enforcer *casbin.Enforcer) gin.HandlerFunc {
 			return
 		}
 
-		userId, ok := appCtx.GetUserID()
-		if !ok {
+		if _, ok := appCtx.GetUserID(); !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "Unauthorized - User not found",
 			})
 			return
 		}
-		// super user jump verify
-		if userId == 1 {
-			c.Next()
-			return
-		}
 
-		// 将用户ID作为角色进行权限验证
-		role := fmt.Sprintf("%d", roleId) // v0字段存储的是角色ID
-		path := c.Request.URL.Path        // v1字段存储的是API路径
-		method := c.Request.Method        // v3字段存储的是请求方法
+		path := c.Request.URL.Path // v1字段存储的是API路径
+		method := c.Request.Method // v3字段存储的是请求方法
 
-		// 使用Casbin进行权限检查
-		ok, err := enforcer.Enforce(role, path, method)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error during authorization",
-			})
-			return
+		// 使用Casbin进行权限检查，v0字段存储的是角色ID
+		ok = false
+		for _, roleId := range roleIds {
+			allowed, err := enforcer.Enforce(fmt.Sprintf("%d", roleId), path, method)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal server error during authorization",
+				})
+				return
+			}
+			if allowed {
+				ok = true
+				break
+			}
+		}
+
+		// super role jump verify, api keys are always limited to their role policies
+		if _, isApiKey := c.Get(ApiKeyContextKey); !ok && !isApiKey && superRoles != nil {
+			isSuper, err := superRoles.IsSuperRole(roleIds...)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal server error during authorization",
+				})
+				return
+			}
+			ok = isSuper
 		}
 
 		if !ok {
@@ -0,0 +1,140 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type fakeSuperRoles map[int64]bool
+
+func (f fakeSuperRoles) IsSuperRole(roleIds ...int64) (bool, error) {
+	for _, roleId := range roleIds {
+		if f[roleId] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newTestEnforcer 使用线上同一份模型，角色4可删除用户，角色6继承角色4
+func newTestEnforcer(t *testing.T) *casbin.Enforcer {
+	enforcer, err := casbin.NewEnforcer("../../../../config/model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = enforcer.AddPolicy("2", "/v1/user", "GET")
+	_, _ = enforcer.AddPolicy("4", "/v1/user/:id", "DELETE")
+	_, _ = enforcer.AddGroupingPolicy("6", "4")
+	return enforcer
+}
+
+func serveCasbin(enforcer *casbin.Enforcer, userId int, roleId int64, apiKey bool) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userId)
+		c.Set("role_id", roleId)
+		if apiKey {
+			c.Set(ApiKeyContextKey, int64(1))
+		}
+	})
+	router.Use(CasbinMiddleware(enforcer, fakeSuperRoles{9: true}))
+	router.DELETE("/v1/user/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/v1/user/3", nil)
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestCasbinMiddleware_SuperRole(t *testing.T) {
+	enforcer := newTestEnforcer(t)
+
+	if code := serveCasbin(enforcer, 5, 9, false); code != http.StatusOK {
+		t.Errorf("super role: expected 200, got %d", code)
+	}
+	// 用户ID为1不再自动跳过鉴权
+	if code := serveCasbin(enforcer, 1, 2, false); code != http.StatusForbidden {
+		t.Errorf("user 1 without super role: expected 403, got %d", code)
+	}
+	if code := serveCasbin(enforcer, 5, 9, true); code != http.StatusForbidden {
+		t.Errorf("api key of super role: expected 403, got %d", code)
+	}
+}
+
+func TestCasbinMiddleware_RoleUnion(t *testing.T) {
+	enforcer := newTestEnforcer(t)
+	serve := func(roleIds []int64) int {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", 5)
+			c.Set("role_id", roleIds[0])
+			c.Set("role_ids", roleIds)
+		})
+		router.Use(CasbinMiddleware(enforcer, fakeSuperRoles{9: true}))
+		router.DELETE("/v1/user/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/v1/user/3", nil)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := serve([]int64{2, 4}); code != http.StatusOK {
+		t.Errorf("union with permitted role: expected 200, got %d", code)
+	}
+	if code := serve([]int64{2, 3}); code != http.StatusForbidden {
+		t.Errorf("union without permitted role: expected 403, got %d", code)
+	}
+	if code := serve([]int64{2, 6}); code != http.StatusOK {
+		t.Errorf("union with inherited role: expected 200, got %d", code)
+	}
+	if code := serve([]int64{2, 9}); code != http.StatusOK {
+		t.Errorf("union with super role: expected 200, got %d", code)
+	}
+}
+
+func TestCasbinMiddleware_DisabledSuperRole(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&roleRepo.SysRole{}); err != nil {
+		t.Fatal(err)
+	}
+	// 角色9为启用的超级管理员角色，角色8为停用的超级管理员角色
+	db.Create(&roleRepo.SysRole{ID: 9, Name: "super", IsSuper: true, Status: 1})
+	db.Create(&roleRepo.SysRole{ID: 8, Name: "disabled super", IsSuper: true, Status: 2})
+	superRoles := roleRepo.NewSysRolesRepository(db, &logger.Logger{Log: zap.NewNop()})
+	enforcer := newTestEnforcer(t)
+	serve := func(roleId int64) int {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", 5)
+			c.Set("role_id", roleId)
+		})
+		router.Use(CasbinMiddleware(enforcer, superRoles))
+		router.DELETE("/v1/user/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/v1/user/3", nil)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := serve(9); code != http.StatusOK {
+		t.Errorf("enabled super role: expected 200, got %d", code)
+	}
+	if code := serve(8); code != http.StatusForbidden {
+		t.Errorf("disabled super role: expected 403, got %d", code)
+	}
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// DataScopeResolver 根据用户和角色计算数据权限
+type DataScopeResolver interface {
+	GetDataScope(userId int64, roleIds []int64) (*domain.DataScope, error)
+}
+
+// DataScopeMiddleware 计算请求用户的数据权限，控制器查询列表时通过 AppUtils.GetDataScope 传给仓储
+func DataScopeMiddleware(resolver DataScopeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		appCtx := controllers.NewAppUtils(c)
+		userId, userOk := appCtx.GetUserID()
+		roleIds, roleOk := appCtx.GetRoleIDs()
+		if !userOk || !roleOk {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized - User not found",
+			})
+			return
+		}
+		scope, err := resolver.GetDataScope(int64(userId), roleIds)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal server error during authorization",
+			})
+			return
+		}
+		c.Set(controllers.DataScopeContextKey, scope)
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// FieldMaskResolver 根据角色计算资源上需要隐藏的字段
+type FieldMaskResolver interface {
+	GetHiddenFields(roleIds []int64, resource string) (domain.FieldMask, error)
+}
+
+// FieldPermissionMiddleware 计算请求用户在 resource 上的隐藏字段，响应映射时通过 AppUtils.GetHiddenFields 读取
+func FieldPermissionMiddleware(resolver FieldMaskResolver, resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleIds, ok := controllers.NewAppUtils(c).GetRoleIDs()
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized - User not found",
+			})
+			return
+		}
+		mask, err := resolver.GetHiddenFields(roleIds, resource)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal server error during authorization",
+			})
+			return
+		}
+		c.Set(controllers.HiddenFieldsContextKey, mask)
+		c.Next()
+	}
+}
@@ -2,8 +2,10 @@ package middlewares
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 	"time"
 
@@ -29,56 +31,143 @@ func (w bodyLogWriter) Write(b []byte) (int, error) {
 func GinBodyLogMiddleware(db *gorm.DB, logger *logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqBody string
-		var resp string
+		var blw *bodyLogWriter
 		// skip over upload api
-		if checkURIIsUpload(c.Request.RequestURI) {
-			reqBody = ""
-			resp = ""
-		} else {
-			blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		if !checkURIIsUpload(c.Request.RequestURI) {
+			blw = &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
 			c.Writer = blw
 
-			buf := make([]byte, 4096)
-			num, err := c.Request.Body.Read(buf)
-			if err != nil && err.Error() != "EOF" {
-				_ = fmt.Errorf("error reading buffer: %s", err.Error())
+			if c.Request.Body != nil {
+				buf, err := io.ReadAll(c.Request.Body)
+				if err != nil {
+					_ = fmt.Errorf("error reading buffer: %s", err.Error())
+				}
+				reqBody = string(buf)
+				c.Request.Body = io.NopCloser(bytes.NewBuffer(buf))
 			}
-			reqBody := string(buf[0:num])
-			resp = blw.body.String()
-			c.Request.Body = io.NopCloser(bytes.NewBuffer([]byte(reqBody)))
 		}
 
 		start := time.Now()
 
 		c.Next()
 
-		userId, _ := controllers.NewAppUtils(c).GetUserID()
+		var resp string
+		if blw != nil {
+			resp = blw.body.String()
+		}
+		// 审计记录可被管理员查看，令牌和密码不能原样落库
+		reqBody = truncateRecord(redactSensitive(reqBody))
+		resp = truncateRecord(redactSensitive(resp))
+
+		appUtils := controllers.NewAppUtils(c)
+		userId, _ := appUtils.GetUserID()
+		impersonatorId, _ := appUtils.GetImpersonatorID()
 
 		operationRecordsRepository := operationRecordsRepository.NewOperationRepository(db, logger)
 		operationRecordsRepository.Create(&operationRecordsDomain.SysOperationRecord{
-			IP:           c.ClientIP(),
-			Method:       c.Request.Method,
-			Path:         c.Request.RequestURI,
-			Status:       int64(c.Writer.Status()),
-			Agent:        c.Request.UserAgent(),
-			Body:         reqBody,
-			Resp:         resp,
-			ErrorMessage: c.Errors.String(),
-			UserID:       int64(userId),
-			Latency:      time.Since(start).Milliseconds(),
-			CreatedAt:    domain.CustomTime{Time: time.Now()},
+			IP:             c.ClientIP(),
+			Method:         c.Request.Method,
+			Path:           redactQuery(c.Request.RequestURI),
+			Status:         int64(c.Writer.Status()),
+			Agent:          c.Request.UserAgent(),
+			Body:           reqBody,
+			Resp:           resp,
+			ErrorMessage:   c.Errors.String(),
+			UserID:         int64(userId),
+			ImpersonatorID: impersonatorId,
+			Latency:        time.Since(start).Milliseconds(),
+			CreatedAt:      domain.CustomTime{Time: time.Now()},
 		})
+	}
+}
 
-		_ = fmt.Sprintf("%v", map[string]any{
-			"ruta":          c.FullPath(),
-			"request_uri":   c.Request.RequestURI,
-			"raw_request":   reqBody,
-			"status_code":   c.Writer.Status(),
-			"body_response": resp,
-			"errors":        c.Errors.Errors(),
-			"created_at":    time.Now().Format("2006-01-02T15:04:05"),
-		})
+const (
+	redactedValue = "******"
+	// maxRecordLength 单条请求/响应体最多保存的字节数
+	maxRecordLength = 4096
+)
+
+// isSensitiveField 令牌、密码、密钥类字段，按去掉下划线后的小写名的后缀判断
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for _, suffix := range []string{"token", "password", "passwd", "secret"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSensitive 将 JSON 中敏感字段的值替换为掩码，非 JSON 内容原样返回
+func redactSensitive(body string) string {
+	if body == "" {
+		return body
+	}
+	var data any
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return body
+	}
+	if !redactValue(data) {
+		return body
+	}
+	redacted, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return string(redacted)
+}
+
+// redactValue 递归处理对象和数组，返回是否有字段被替换
+func redactValue(value any) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSensitiveField(key) {
+				if item != nil && item != "" {
+					v[key] = redactedValue
+					changed = true
+				}
+				continue
+			}
+			changed = redactValue(item) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = redactValue(item) || changed
+		}
+	}
+	return changed
+}
+
+// redactQuery 替换 URL 查询参数中的敏感值，如 ?token=
+func redactQuery(uri string) string {
+	path, rawQuery, found := strings.Cut(uri, "?")
+	if !found {
+		return uri
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path
+	}
+	changed := false
+	for key := range query {
+		if isSensitiveField(key) {
+			query[key] = []string{redactedValue}
+			changed = true
+		}
+	}
+	if !changed {
+		return uri
+	}
+	return path + "?" + query.Encode()
+}
+
+func truncateRecord(body string) string {
+	if len(body) > maxRecordLength {
+		return body[:maxRecordLength]
 	}
+	return body
 }
 
 // check uri is upload file
@@ -10,7 +10,14 @@ import (
 	"strings"
 	"testing"
 
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	operationRecordsRepository "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/operation_records"
 	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // MockResponseWriter implements gin.ResponseWriter for testing
@@ -190,3 +197,56 @@ func TestGinBodyLogMiddleware_LargeBody(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 }
+
+func TestRedactSensitive(t *testing.T) {
+	body := `{"data":{"jwtAccessToken":"a.b.c","user":{"userName":"bob","password":""},"items":[{"refresh_token":"r"}]},"message":"ok"}`
+
+	redacted := redactSensitive(body)
+
+	assert.NotContains(t, redacted, "a.b.c")
+	assert.NotContains(t, redacted, `"r"`)
+	assert.Contains(t, redacted, `"jwtAccessToken":"******"`)
+	assert.Contains(t, redacted, `"password":""`)
+	assert.Contains(t, redacted, `"userName":"bob"`)
+	assert.Equal(t, "not json token=x", redactSensitive("not json token=x"))
+	assert.Equal(t, `{"name":"x"}`, redactSensitive(`{"name":"x"}`))
+}
+
+func TestRedactQuery(t *testing.T) {
+	assert.Equal(t, "/v1/files/1?id=2&token=%2A%2A%2A%2A%2A%2A", redactQuery("/v1/files/1?token=secret&id=2"))
+	assert.Equal(t, "/v1/user?page=1", redactQuery("/v1/user?page=1"))
+	assert.Equal(t, "/v1/user", redactQuery("/v1/user"))
+}
+
+func TestGinBodyLogMiddleware_RedactsImpersonationToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&operationRecordsRepository.SysOperationRecord{}))
+
+	router := gin.New()
+	router.Use(GinBodyLogMiddleware(db, &logger.Logger{Log: zap.NewNop()}))
+	router.POST("/v1/auth/impersonate/:id", func(c *gin.Context) {
+		var request map[string]any
+		_ = c.ShouldBindJSON(&request)
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"jwtAccessToken": "impersonation.jwt.token", "echo": request["reason"]}})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/auth/impersonate/2", strings.NewReader(`{"reason":"support","password":"pw"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RequestURI = "/v1/auth/impersonate/2"
+	router.ServeHTTP(w, req)
+
+	// 客户端仍拿到原始令牌，落库的记录被脱敏
+	assert.Contains(t, w.Body.String(), "impersonation.jwt.token")
+	assert.Contains(t, w.Body.String(), "support")
+
+	var record operationRecordsRepository.SysOperationRecord
+	require.NoError(t, db.First(&record).Error)
+	assert.Equal(t, "/v1/auth/impersonate/2", record.Path)
+	assert.NotContains(t, record.Resp, "impersonation.jwt.token")
+	assert.Contains(t, record.Resp, "support")
+	assert.NotContains(t, record.Body, `"pw"`)
+	assert.Contains(t, record.Body, "support")
+}
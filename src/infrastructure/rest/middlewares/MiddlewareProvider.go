@@ -1,8 +1,7 @@
 package middlewares
 
 import (
-	"errors"
-
+	apiKeyUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/api_key"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -10,42 +9,38 @@ import (
 )
 
 type MiddlewareProvider struct {
-	RedisClient *redis.Client
-	DB          *gorm.DB
+	RedisClient            *redis.Client
+	DB                     *gorm.DB
+	JwtBlacklistRepository jwt_blacklist.JwtBlacklistRepository
+	ApiKeyService          apiKeyUseCase.ISysApiKeyService
 }
 
-func NewMiddlewareProvider(redisClient *redis.Client, db *gorm.DB) *MiddlewareProvider {
+func NewMiddlewareProvider(
+	redisClient *redis.Client,
+	db *gorm.DB,
+	jwtBlacklistRepository jwt_blacklist.JwtBlacklistRepository,
+	apiKeyService apiKeyUseCase.ISysApiKeyService,
+) *MiddlewareProvider {
 	return &MiddlewareProvider{
-		RedisClient: redisClient,
-		DB:          db,
+		RedisClient:            redisClient,
+		DB:                     db,
+		JwtBlacklistRepository: jwtBlacklistRepository,
+		ApiKeyService:          apiKeyService,
 	}
 }
 
 func (mp *MiddlewareProvider) AuthJWTMiddleware() gin.HandlerFunc {
-	return AuthJWTMiddlewareWithRedis(mp.RedisClient, mp.DB)
+	return AuthJWTMiddlewareWithRedis(mp.RedisClient, mp.JwtBlacklistRepository, mp.ApiKeyService)
 }
 
 func (mp *MiddlewareProvider) OptionalAuthMiddleware() gin.HandlerFunc {
-	return OptionalAuthMiddlewareWithRedis(mp.RedisClient, mp.DB)
+	return OptionalAuthMiddlewareWithRedis(mp.RedisClient, mp.JwtBlacklistRepository)
 }
 
 func (mp *MiddlewareProvider) UrlAuthMiddleware() gin.HandlerFunc {
-	return UrlAuthMiddlewareWithRedis(mp.RedisClient, mp.DB)
+	return UrlAuthMiddlewareWithRedis(mp.RedisClient, mp.JwtBlacklistRepository)
 }
 
 func (mp *MiddlewareProvider) AuthResetPasswordMiddleware() gin.HandlerFunc {
-	return AuthResetPassword(mp.RedisClient, mp.DB)
-}
-
-// IsTokenInBlacklist 检查token是否在黑名单中
-func IsTokenInBlacklist(db *gorm.DB, tokenString string) (bool, error) {
-	var blacklist jwt_blacklist.JwtBlacklist
-	err := db.Where("jwt = ? AND deleted_at IS NULL", tokenString).First(&blacklist).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil
-		}
-		return false, err
-	}
-	return true, nil
+	return AuthResetPassword(mp.RedisClient, mp.JwtBlacklistRepository)
 }
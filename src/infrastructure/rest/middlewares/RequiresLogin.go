@@ -7,16 +7,25 @@ import (
 	"strings"
 
 	authUseCase "github.com/gbrayhan/microservices-go/src/application/services/auth"
+	apiKeyUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/api_key"
 
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/redis/go-redis/v9"
-	"gorm.io/gorm"
 )
 
-// AuthJWTMiddlewareWithRedis 使用Redis的认证中间件
-func AuthJWTMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.HandlerFunc {
+// AuthJWTMiddlewareWithRedis 使用Redis的认证中间件，同时接受 X-API-Key 请求头
+func AuthJWTMiddlewareWithRedis(redisClient *redis.Client, blacklist jwt_blacklist.JwtBlacklistRepository, apiKeys apiKeyUseCase.ISysApiKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(ApiKeyHeader); rawKey != "" && apiKeys != nil {
+			if !VerifyApiKey(c, rawKey, apiKeys) {
+				return
+			}
+			c.Next()
+			return
+		}
+
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token not provided"})
@@ -24,7 +33,7 @@ func AuthJWTMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.Hand
 			return
 		}
 
-		if !CommonVerifyWithRedis(c, tokenString, redisClient, db) {
+		if !CommonVerifyWithRedis(c, tokenString, redisClient, blacklist) {
 			return
 		}
 
@@ -33,7 +42,7 @@ func AuthJWTMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.Hand
 }
 
 // OptionalAuthMiddlewareWithRedis 可选认证中间件
-func OptionalAuthMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.HandlerFunc {
+func OptionalAuthMiddlewareWithRedis(redisClient *redis.Client, blacklist jwt_blacklist.JwtBlacklistRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
@@ -41,13 +50,13 @@ func OptionalAuthMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin
 			return
 		}
 
-		CommonVerifyWithRedis(c, tokenString, redisClient, db)
+		CommonVerifyWithRedis(c, tokenString, redisClient, blacklist)
 		c.Next()
 	}
 }
 
 // UrlAuthMiddlewareWithRedis URL参数认证中间件
-func UrlAuthMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.HandlerFunc {
+func UrlAuthMiddlewareWithRedis(redisClient *redis.Client, blacklist jwt_blacklist.JwtBlacklistRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.Query("token")
 		if tokenString == "" {
@@ -55,7 +64,7 @@ func UrlAuthMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.Hand
 			c.Abort()
 			return
 		}
-		if !CommonVerifyWithRedis(c, tokenString, redisClient, db) {
+		if !CommonVerifyWithRedis(c, tokenString, redisClient, blacklist) {
 			return
 		}
 
@@ -64,7 +73,7 @@ func UrlAuthMiddlewareWithRedis(redisClient *redis.Client, db *gorm.DB) gin.Hand
 }
 
 // CommonVerifyWithRedis 带Redis验证的通用验证函数
-func CommonVerifyWithRedis(c *gin.Context, tokenString string, redisClient *redis.Client, db *gorm.DB) bool {
+func CommonVerifyWithRedis(c *gin.Context, tokenString string, redisClient *redis.Client, blacklist jwt_blacklist.JwtBlacklistRepository) bool {
 	accessSecret := os.Getenv("JWT_ACCESS_SECRET")
 	if accessSecret == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "JWT_ACCESS_SECRET not configured"})
@@ -85,7 +94,7 @@ func CommonVerifyWithRedis(c *gin.Context, tokenString string, redisClient *redi
 	}
 
 	// check token if in blacklist
-	exists, err := IsTokenInBlacklist(db, tokenString)
+	exists, err := blacklist.IsJwtInBlacklist(tokenString)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		c.Abort()
@@ -98,8 +107,11 @@ func CommonVerifyWithRedis(c *gin.Context, tokenString string, redisClient *redi
 		return false
 	}
 
+	// 模拟登录令牌不参与单点登录检查，避免被模拟用户的会话被顶替
+	impersonatorID, impersonating := claims["impersonator_id"].(float64)
+
 	// 检查用户当前有效token（实现单点登录）
-	if userID, ok := claims["id"].(float64); ok && os.Getenv("SERVER_SINGLE_SIGN_ON") == "true" {
+	if userID, ok := claims["id"].(float64); ok && !impersonating && os.Getenv("SERVER_SINGLE_SIGN_ON") == "true" {
 		currentToken, err := redisClient.Get(context.Background(), authUseCase.GetUserTokenKey(int64(userID))).Result()
 		if err == nil && currentToken != tokenString {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been replaced"})
@@ -150,5 +162,20 @@ func CommonVerifyWithRedis(c *gin.Context, tokenString string, redisClient *redi
 		c.Set("role_id", id)
 	}
 
+	// 合并模式的令牌携带全部角色，鉴权时按角色并集计算
+	if rawRoleIds, ok := claims["role_ids"].([]interface{}); ok && len(rawRoleIds) > 0 {
+		roleIds := make([]int64, 0, len(rawRoleIds))
+		for _, raw := range rawRoleIds {
+			if roleId, ok := raw.(float64); ok {
+				roleIds = append(roleIds, int64(roleId))
+			}
+		}
+		c.Set("role_ids", roleIds)
+	}
+
+	if impersonating && impersonatorID != 0 {
+		c.Set("impersonator_id", int64(impersonatorID))
+	}
+
 	return true
 }
@@ -5,22 +5,31 @@ import (
 	"net/http"
 
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainTranslation "github.com/gbrayhan/microservices-go/src/domain/sys/translation"
 	"github.com/gin-gonic/gin"
 )
 
 func ErrorHandler() gin.HandlerFunc {
+	return LocalizedErrorHandler(nil)
+}
+
+// LocalizedErrorHandler 按 Accept-Language 翻译错误消息，translationService 为 nil 或没有翻译时返回原文
+func LocalizedErrorHandler(translationService domainTranslation.ITranslationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last().Err
+			status, message := http.StatusInternalServerError, domainErrors.InternalServerErrorMessage
 			var appErr *domainErrors.AppError
 			if errors.As(err, &appErr) {
-				status, message := domainErrors.AppErrorToHTTP(appErr)
-				c.JSON(status, gin.H{"error": message})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+				status, message = domainErrors.AppErrorToHTTP(appErr)
+			}
+			if translationService != nil {
+				locale := translationService.ResolveLocale(c.GetHeader("Accept-Language"))
+				message = translationService.Translate(locale, domainTranslation.ScopeError, message, message)
 			}
+			c.JSON(status, gin.H{"error": message})
 		}
 	}
 }
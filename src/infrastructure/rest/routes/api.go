@@ -17,7 +17,7 @@ func ApiRouters(
 	}
 	u := router.Group("/api")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewApi)
 		u.GET("", controller.GetAllApis)
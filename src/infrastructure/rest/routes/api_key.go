@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApiKeyRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.ApiKeyModule.Controller
+	u := router.Group("/api-key")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
+	{
+		u.GET("", controller.GetMyApiKeys)
+		u.POST("", controller.CreateApiKey)
+		u.DELETE("/:id", controller.RevokeApiKey)
+	}
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,10 +13,19 @@ func AuthRoutes(router *gin.RouterGroup, appContext *di.ApplicationContext) {
 		routerAuth.POST("/signin", controller.Login)
 		routerAuth.POST("/signup", controller.Register)
 		routerAuth.POST("/access-token", controller.GetAccessTokenByRefreshToken)
+		routerAuth.GET("/oidc/authorize", controller.OIDCAuthorize)
+		routerAuth.POST("/oidc/callback", controller.OIDCCallback)
+		routerAuth.POST("/verify-email", controller.VerifyEmail)
+		routerAuth.POST("/verify-email/resend", controller.ResendVerification)
 	}
 	loginAuth := routerAuth.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
 	{
 		loginAuth.POST("/switch-role", controller.SwitchRole)
 		loginAuth.GET("/logout", controller.Logout)
+		loginAuth.GET("/oidc/link", controller.OIDCLinkAuthorize)
+		loginAuth.POST("/oidc/link", controller.OIDCLink)
+		loginAuth.POST("/ldap/link", controller.LDAPLink)
+		loginAuth.POST("/impersonate", middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase), controller.Impersonate)
+		loginAuth.POST("/impersonate/exit", controller.ExitImpersonation)
 	}
 }
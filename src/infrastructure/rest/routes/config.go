@@ -13,7 +13,7 @@ func ConfigRouters(
 	u.GET("/site", controller.GetConfigBySite)
 
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.GET("", controller.GetAllConfigs)
 		u.PUT("/:module", controller.UpdateConfig)
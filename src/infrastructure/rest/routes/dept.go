@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func DeptRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.DeptModule.Controller
+	u := router.Group("/dept")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
+	{
+		u.POST("", controller.NewDept)
+		u.GET("", controller.GetAllDepts)
+		u.GET("/tree", controller.GetTreeDepts)
+		u.GET("/:id", controller.GetDeptByID)
+		u.PUT("/:id", controller.UpdateDept)
+		u.DELETE("/:id", controller.DeleteDept)
+		u.GET("/:id/members", controller.GetDeptMembers)
+		u.PUT("/:id/members", controller.SetDeptMembers)
+	}
+}
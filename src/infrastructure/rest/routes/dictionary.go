@@ -12,7 +12,7 @@ func DictionaryRouters(
 	u := router.Group("/dictionary")
 
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewDictionary)
 		u.GET("", controller.GetAllDictionaries)
@@ -22,6 +22,7 @@ func DictionaryRouters(
 		u.GET("/search", controller.SearchPaginated)
 		u.GET("/search-property", controller.SearchByProperty)
 		u.GET("/type/:type", controller.GetByType)
+		u.GET("/types", controller.GetByTypes)
 
 	}
 }
@@ -11,7 +11,7 @@ func DictionaryDetailRouters(
 	controller := appContext.DictionaryDetailModule.Controller
 	u := router.Group("/dictionary_detail")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewDictionary)
 		u.GET("", controller.GetAllDictionaries)
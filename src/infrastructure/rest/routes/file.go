@@ -11,7 +11,7 @@ func FileRouters(
 	controller := appContext.FileModule.Controller
 	u := router.Group("/file")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewFile)
 		u.GET("", controller.GetAllFiles)
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func InvitationRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.InvitationModule.Controller
+	u := router.Group("/invitation")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
+	{
+		u.GET("", controller.GetInvitations)
+		u.POST("", controller.CreateInvitation)
+		u.DELETE("/:id", controller.RevokeInvitation)
+	}
+}
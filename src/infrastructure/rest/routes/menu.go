@@ -15,13 +15,16 @@ func MenuRouters(
 
 	protected := u.Group("")
 	protected.Use(middlewareProvider.AuthJWTMiddleware())
-	protected.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	protected.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		protected.POST("", controller.NewMenu)
 		protected.GET("", controller.GetAllMenus)
+		protected.PUT("/tree", controller.MoveMenus)
 		protected.GET("/:id", controller.GetMenusByID)
 		protected.PUT("/:id", controller.UpdateMenu)
 		protected.DELETE("/:id", controller.DeleteMenu)
+		protected.GET("/:id/apis", controller.GetMenuApis)
+		protected.PUT("/:id/apis", controller.UpdateMenuApis)
 	}
 
 }
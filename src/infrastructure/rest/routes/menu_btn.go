@@ -11,7 +11,7 @@ func MenuBtnRouters(
 	controller := appContext.MenuBtnModule.Controller
 	u := router.Group("/menu_btn")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewMenuBtn)
 		u.GET("", controller.GetAllMenuBtns)
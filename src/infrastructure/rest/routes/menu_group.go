@@ -11,7 +11,7 @@ func MenuGroupRouters(
 	controller := appContext.MenuGroupModule.Controller
 	u := router.Group("/menu_group")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewMenuGroup)
 		u.GET("", controller.GetAllMenuGroups)
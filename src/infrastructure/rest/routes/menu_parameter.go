@@ -11,7 +11,7 @@ func MenuParameterRouters(
 	controller := appContext.MenuParameterModule.Controller
 	u := router.Group("/menu_parameter")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewMenuParameter)
 		u.GET("", controller.GetAllMenuParameters)
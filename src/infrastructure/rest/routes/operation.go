@@ -11,12 +11,12 @@ func OperationRouters(
 	controller := appContext.OperationModule.Controller
 	u := router.Group("/operation")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.GET("", controller.GetAllOperations)
 		u.GET("/:id", controller.GetOperationsByID)
 		u.DELETE("/:id", controller.DeleteOperation)
 		u.POST("/delete-batch", controller.DeleteOperations)
-		u.GET("/search", controller.SearchPaginated)
+		u.GET("/search", middlewares.DataScopeMiddleware(appContext.RoleModule.UseCase), controller.SearchPaginated)
 	}
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func PermissionRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.PermissionModule.Controller
+	u := router.Group("/permission")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
+	{
+		u.POST("/check", controller.CheckPermission)
+		u.GET("/api/:id/roles", controller.GetApiRoles)
+		u.GET("/consistency", controller.CheckConsistency)
+		u.POST("/consistency/repair", controller.RepairConsistency)
+		u.GET("/bundle", controller.ExportBundle)
+		u.POST("/bundle/import", controller.ImportBundle)
+	}
+}
@@ -11,7 +11,7 @@ func RoleRoutes(
 	controller := appContext.RoleModule.Controller
 	u := router.Group("/role")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewRole)
 		u.GET("", controller.GetAllRoles)
@@ -19,9 +19,19 @@ func RoleRoutes(
 		u.PUT("/:id", controller.UpdateRole)
 		u.DELETE("/:id", controller.DeleteRole)
 		u.GET("/tree", controller.GetTreeRoles)
+		u.GET("/fields", controller.GetMaskableFields)
 		u.GET("/:id/setting", controller.GetRoleSetting)
 		u.POST("/:id/menu", controller.UpdateRoleMenuIds)
 		u.POST("/:id/api", controller.BindApiRule)
 		u.POST("/:id/menu-btns", controller.BindRoleMenuBtns)
+		u.POST("/:id/fields", controller.BindRoleFields)
+		u.POST("/:id/clone", controller.CloneRole)
+		u.GET("/templates", controller.GetTemplates)
+		u.POST("/templates", controller.NewTemplate)
+		u.GET("/templates/:id", controller.GetTemplate)
+		u.PUT("/templates/:id", controller.UpdateTemplate)
+		u.DELETE("/templates/:id", controller.DeleteTemplate)
+		u.GET("/:id/templates/:templateId/diff", controller.DiffTemplate)
+		u.POST("/:id/templates/:templateId/apply", controller.ApplyTemplate)
 	}
 }
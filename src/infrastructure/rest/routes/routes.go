@@ -40,4 +40,9 @@ func ApplicationRouter(router *gin.Engine, appContext *di.ApplicationContext) {
 	TaskExecutionLogRouters(v1, appContext)
 	EmailRouters(v1, appContext)
 	CaptchaRoutes(v1, appContext)
+	ApiKeyRouters(v1, appContext)
+	InvitationRouters(v1, appContext)
+	DeptRouters(v1, appContext)
+	PermissionRouters(v1, appContext)
+	TranslationRouters(v1, appContext)
 }
@@ -11,7 +11,7 @@ func ScheduledTaskRouters(
 	controller := appContext.ScheduledTaskModule.Controller
 	u := router.Group("/scheduled_task")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("", controller.NewScheduledTask)
 		u.GET("", controller.GetAllScheduledTasks)
@@ -11,7 +11,7 @@ func TaskExecutionLogRouters(
 	controller := appContext.TaskExecutionLogModule.Controller
 	u := router.Group("/task_execution_log")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.GET("/search", controller.SearchPaginated)
 	}
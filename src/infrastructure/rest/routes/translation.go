@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func TranslationRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.TranslationModule.Controller
+	u := router.Group("/translation")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
+	{
+		u.GET("", controller.GetTranslations)
+		u.GET("/locales", controller.GetLocales)
+		u.PUT("", controller.SaveTranslations)
+		u.DELETE("/:id", controller.DeleteTranslation)
+	}
+}
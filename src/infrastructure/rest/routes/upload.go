@@ -10,7 +10,7 @@ func UploadRoutes(router *gin.RouterGroup, appContext *di.ApplicationContext) {
 	controller := appContext.UploadModule.Controller
 	u := router.Group("/upload")
 	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
-	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	{
 		u.POST("/single", controller.Single)
 		u.POST("/multiple", controller.Multiple)
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"github.com/gbrayhan/microservices-go/src/domain"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
 	"github.com/gin-gonic/gin"
@@ -16,17 +17,19 @@ func UserRoutes(router *gin.RouterGroup, appContext *di.ApplicationContext) {
 
 	protected := u.Group("")
 	protected.Use(middlewareProvider.AuthJWTMiddleware())
-	protected.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	protected.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
+	protected.Use(middlewares.FieldPermissionMiddleware(appContext.RoleModule.UseCase, domain.FieldResourceUser))
+	dataScope := middlewares.DataScopeMiddleware(appContext.RoleModule.UseCase)
 	{
 		protected.POST("", controller.NewUser)
-		protected.GET("", controller.GetAllUsers)
-		protected.GET("/:id", controller.GetUsersByID)
-		protected.PUT("/:id", controller.UpdateUser)
-		protected.DELETE("/:id", controller.DeleteUser)
-		protected.GET("/search", controller.SearchPaginated)
-		protected.GET("/search-property", controller.SearchByProperty)
-		protected.POST("/:id/role", controller.UserBindRoles)
-		protected.POST("/:id/reset-password", controller.ResetPassword)
+		protected.GET("", dataScope, controller.GetAllUsers)
+		protected.GET("/:id", dataScope, controller.GetUsersByID)
+		protected.PUT("/:id", dataScope, controller.UpdateUser)
+		protected.DELETE("/:id", dataScope, controller.DeleteUser)
+		protected.GET("/search", dataScope, controller.SearchPaginated)
+		protected.GET("/search-property", dataScope, controller.SearchByProperty)
+		protected.POST("/:id/role", dataScope, controller.UserBindRoles)
+		protected.POST("/:id/reset-password", dataScope, controller.ResetPassword)
 		protected.POST("/:id/edit-password", controller.EditPassword)
 	}
 
@@ -9,12 +9,14 @@ import (
 
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 const (
 	Access  = "access"
 	Refresh = "refresh"
 	Reset   = "reset"
+	Verify  = "verify"
 )
 
 type AppToken struct {
@@ -23,10 +25,16 @@ type AppToken struct {
 	ExpirationTime time.Time `json:"expirationTime"`
 }
 
+// Claims is the JWT payload. The embedded RegisteredClaims.ID is serialized as
+// the "jti" claim and uniquely identifies the token for revocation.
 type Claims struct {
 	ID     int64  `json:"id"`
 	RoleID int64  `json:"role_id"`
 	Type   string `json:"type"`
+	// ImpersonatorID 模拟登录时为真实管理员ID，ID 为被模拟的用户
+	ImpersonatorID int64 `json:"impersonator_id,omitempty"`
+	// RoleIDs 多角色合并模式下的全部角色，RoleID 为其中的主角色
+	RoleIDs []int64 `json:"role_ids,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -35,13 +43,21 @@ type JWTConfig struct {
 	AccessSecret  string
 	RefreshSecret string
 	ResetSecret   string
+	VerifySecret  string
 	AccessTime    int64
 	RefreshTime   int64
+	VerifyTime    int64
+	// ResetTime 修改密码令牌有效期（分钟），用于一次性密码登录和重置链接
+	ResetTime int64
+	// ImpersonateTime 模拟登录令牌有效期（分钟）
+	ImpersonateTime int64
 }
 
 // IJWTService defines the interface for JWT operations
 type IJWTService interface {
 	GenerateJWTToken(userID int64, roleID int64, tokenType string) (*AppToken, error)
+	GenerateJWTTokenWithRoles(userID int64, roleID int64, roleIDs []int64, tokenType string) (*AppToken, error)
+	GenerateImpersonationToken(userID int64, roleID int64, roleIDs []int64, impersonatorID int64) (*AppToken, error)
 	GetClaimsAndVerifyToken(tokenString string, tokenType string) (jwt.MapClaims, error)
 }
 
@@ -68,16 +84,25 @@ func NewJWTServiceWithConfig(config JWTConfig) IJWTService {
 // loadJWTConfig loads JWT configuration from environment variables
 func loadJWTConfig() JWTConfig {
 	return JWTConfig{
-		AccessSecret:  getEnvOrDefault("JWT_ACCESS_SECRET", "default_access_secret"),
-		RefreshSecret: getEnvOrDefault("JWT_REFRESH_SECRET", "default_refresh_secret"),
-		ResetSecret:   getEnvOrDefault("JWT_RESET_SECRET", "default_access_secret"),
-		AccessTime:    getEnvAsInt64OrDefault("JWT_ACCESS_TIME_MINUTE", 60),
-		RefreshTime:   getEnvAsInt64OrDefault("JWT_REFRESH_TIME_HOUR", 24),
+		AccessSecret:    getEnvOrDefault("JWT_ACCESS_SECRET", "default_access_secret"),
+		RefreshSecret:   getEnvOrDefault("JWT_REFRESH_SECRET", "default_refresh_secret"),
+		ResetSecret:     getEnvOrDefault("JWT_RESET_SECRET", "default_access_secret"),
+		VerifySecret:    getEnvOrDefault("JWT_VERIFY_SECRET", "default_verify_secret"),
+		AccessTime:      getEnvAsInt64OrDefault("JWT_ACCESS_TIME_MINUTE", 60),
+		RefreshTime:     getEnvAsInt64OrDefault("JWT_REFRESH_TIME_HOUR", 24),
+		VerifyTime:      getEnvAsInt64OrDefault("JWT_VERIFY_TIME_HOUR", 24),
+		ResetTime:       getEnvAsInt64OrDefault("JWT_RESET_TIME_MINUTE", 30),
+		ImpersonateTime: getEnvAsInt64OrDefault("JWT_IMPERSONATE_TIME_MINUTE", 30),
 	}
 }
 
 // GenerateJWTToken generates a JWT token for the given user ID and type
 func (s *JWTService) GenerateJWTToken(userID int64, roleID int64, tokenType string) (*AppToken, error) {
+	return s.GenerateJWTTokenWithRoles(userID, roleID, nil, tokenType)
+}
+
+// GenerateJWTTokenWithRoles 签发携带全部角色的令牌，权限按这些角色的并集计算
+func (s *JWTService) GenerateJWTTokenWithRoles(userID int64, roleID int64, roleIDs []int64, tokenType string) (*AppToken, error) {
 	var secretKey string
 	var duration time.Duration
 
@@ -90,19 +115,34 @@ func (s *JWTService) GenerateJWTToken(userID int64, roleID int64, tokenType stri
 		duration = time.Duration(s.config.RefreshTime) * time.Hour
 	case Reset:
 		secretKey = s.config.ResetSecret
-		duration = time.Duration(s.config.RefreshTime) * time.Hour
+		duration = time.Duration(s.config.ResetTime) * time.Minute
+	case Verify:
+		secretKey = s.config.VerifySecret
+		duration = time.Duration(s.config.VerifyTime) * time.Hour
 	default:
 		return nil, errors.New("invalid token type")
 	}
 
+	return signToken(&Claims{Type: tokenType, RoleIDs: roleIDs}, userID, roleID, secretKey, duration)
+}
+
+// GenerateImpersonationToken 签发短期访问令牌，同时携带被模拟用户ID和真实管理员ID，不提供刷新令牌
+func (s *JWTService) GenerateImpersonationToken(userID int64, roleID int64, roleIDs []int64, impersonatorID int64) (*AppToken, error) {
+	if impersonatorID == 0 {
+		return nil, errors.New("impersonator id is required")
+	}
+	duration := time.Duration(s.config.ImpersonateTime) * time.Minute
+	return signToken(&Claims{Type: Access, ImpersonatorID: impersonatorID, RoleIDs: roleIDs}, userID, roleID, s.config.AccessSecret, duration)
+}
+
+func signToken(tokenClaims *Claims, userID int64, roleID int64, secretKey string, duration time.Duration) (*AppToken, error) {
 	nowTime := time.Now()
 	expirationTokenTime := nowTime.Add(duration)
 
-	tokenClaims := &Claims{
-		Type: tokenType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTokenTime),
-		},
+	tokenClaims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		IssuedAt:  jwt.NewNumericDate(nowTime),
+		ExpiresAt: jwt.NewNumericDate(expirationTokenTime),
 	}
 	if userID != 0 {
 		tokenClaims.ID = userID
@@ -120,7 +160,7 @@ func (s *JWTService) GenerateJWTToken(userID int64, roleID int64, tokenType stri
 
 	return &AppToken{
 		Token:          tokenStr,
-		TokenType:      tokenType,
+		TokenType:      tokenClaims.Type,
 		ExpirationTime: expirationTokenTime,
 	}, nil
 }
@@ -136,6 +176,8 @@ func (s *JWTService) GetClaimsAndVerifyToken(tokenString string, tokenType strin
 		secretKey = s.config.RefreshSecret
 	case Reset:
 		secretKey = s.config.ResetSecret
+	case Verify:
+		secretKey = s.config.VerifySecret
 	default:
 		return nil, errors.New("invalid token type")
 	}
@@ -0,0 +1,17 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJWTToken_ResetUsesOwnLifetime(t *testing.T) {
+	service := NewJWTServiceWithConfig(JWTConfig{ResetSecret: "reset", RefreshTime: 24, ResetTime: 15})
+
+	token, err := service.GenerateJWTToken(1, 0, Reset)
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), token.ExpirationTime, time.Minute)
+}
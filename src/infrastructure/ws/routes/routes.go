@@ -14,7 +14,7 @@ func WebSocketRoute(router *gin.Engine, appContext *di.ApplicationContext) {
 	r := router.Group("/ws")
 
 	r.Use(appContext.MiddlewareProvider.UrlAuthMiddleware())
-	r.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	r.Use(middlewares.CasbinMiddleware(appContext.Enforcer, appContext.RoleModule.UseCase))
 	r.GET("user/status", func(ctx *gin.Context) {
 		appContext.WsRouter.HandleConnectionWithRoute(ctx, "/user/status")
 	})
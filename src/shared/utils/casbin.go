@@ -3,10 +3,13 @@ package utils
 import (
 	"os"
 	"path/filepath"
+	"sync"
 
 	casbin "github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -77,3 +80,32 @@ func InitCasbinEnforcer(db *gorm.DB, logger *logger.Logger) (*casbin.Enforcer, e
 
 	return enforcer, nil
 }
+
+// policyWatchers enforcer 对应的 watcher，casbin 没有提供读取 watcher 的方法
+var policyWatchers sync.Map
+
+// SetCasbinWatcher 设置 enforcer 的 watcher，ReloadCasbinPolicy 重新加载后通过它通知其他实例
+func SetCasbinWatcher(enforcer *casbin.Enforcer, watcher persist.Watcher) error {
+	if err := enforcer.SetWatcher(watcher); err != nil {
+		return err
+	}
+	policyWatchers.Store(enforcer, watcher)
+	return nil
+}
+
+// ReloadCasbinPolicy 直接修改 casbin_rule 表后重新加载内存中的策略，并通知其他实例全量重新加载。
+// 失败时返回 UnknownError 类型的 AppError，服务层可直接返回
+func ReloadCasbinPolicy(enforcer *casbin.Enforcer) error {
+	if enforcer == nil {
+		return nil
+	}
+	if err := enforcer.LoadPolicy(); err != nil {
+		return domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	if watcher, ok := policyWatchers.Load(enforcer); ok {
+		if err := watcher.(persist.Watcher).Update(); err != nil {
+			return domainErrors.NewAppError(err, domainErrors.UnknownError)
+		}
+	}
+	return nil
+}